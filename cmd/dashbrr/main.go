@@ -25,7 +25,8 @@ import (
 	"github.com/autobrr/dashbrr/internal/config"
 	"github.com/autobrr/dashbrr/internal/database"
 	"github.com/autobrr/dashbrr/internal/logger"
-	"github.com/autobrr/dashbrr/internal/services"
+	_ "github.com/autobrr/dashbrr/internal/services" // Register service integrations
+	"github.com/autobrr/dashbrr/internal/tracing"
 	"github.com/autobrr/dashbrr/web"
 )
 
@@ -52,34 +53,7 @@ func startServer() {
 		Str("build_date", buildinfo.Date).
 		Msg("Starting dashbrr")
 
-	// Check environment variable first, then fall back to flag
-	defaultConfigPath := "config.toml"
-	if envPath := os.Getenv(config.EnvConfigPath); envPath != "" {
-		defaultConfigPath = envPath
-	} else {
-		// Check user config directory
-		userConfigDir, err := os.UserConfigDir()
-		if err != nil {
-			log.Error().Err(err).Msg("failed to get user config directory")
-		}
-
-		base := []string{filepath.Join(userConfigDir, "dashbrr"), "/config"}
-		configs := []string{"config.toml", "config.yaml", "config.yml"}
-
-		for _, b := range base {
-			for _, c := range configs {
-				p := filepath.Join(b, c)
-				if _, err := os.Stat(p); err == nil {
-					defaultConfigPath = p
-					break
-				}
-			}
-			if defaultConfigPath != "config.toml" {
-				break
-			}
-		}
-	}
-	configPath := flag.String("config", defaultConfigPath, "path to config file")
+	configPath := flag.String("config", config.DefaultPath(), "path to config file")
 
 	var dbPath string
 	flag.StringVar(&dbPath, "db", "", "path to database file")
@@ -112,17 +86,39 @@ func startServer() {
 				},
 			}
 			log.Warn().Err(err).Msg("Failed to load configuration file, using defaults")
+
+			// Tracing can still be configured through the environment
+			envCfg := &config.Config{}
+			if err := config.LoadEnvOverrides(envCfg); err == nil {
+				cfg.Tracing = envCfg.Tracing
+			}
+		}
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize tracing")
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to flush traces")
 		}
+	}()
+
+	dbConfig := database.NewConfig()
+	if dbConfig.Driver == "sqlite" {
+		dbConfig.Path = cfg.Database.Path
 	}
+	dbConfig.ApplyFileEncryptionKey(cfg.Database.EncryptionKey, cfg.Database.EncryptionKeyFile)
 
-	db, err := database.InitDB(cfg.Database.Path)
+	db, err := database.InitDBWithConfig(dbConfig)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to initialize database")
 	}
 	defer db.Close()
 
-	healthService := services.NewHealthService()
-
 	if os.Getenv("GIN_MODE") == "debug" {
 		gin.SetMode(gin.DebugMode)
 	} else {
@@ -130,6 +126,8 @@ func startServer() {
 	}
 
 	r := gin.New()
+	r.ContextWithFallback = true // Let handlers passing *gin.Context as context see the request span
+	r.Use(tracing.Middleware())
 	r.Use(middleware.Logger())
 	r.Use(gin.Recovery())
 
@@ -144,7 +142,7 @@ func startServer() {
 
 	r.Use(middleware.SetupCORS())
 
-	cacheStore := routes.SetupRoutes(r, db, healthService)
+	cacheStore := routes.SetupRoutes(r, db, *configPath)
 	defer func() {
 		if err := cacheStore.Close(); err != nil {
 			cacheType := strings.ToLower(os.Getenv("CACHE_TYPE"))
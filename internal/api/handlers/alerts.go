@@ -0,0 +1,276 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/alerts"
+)
+
+type AlertsHandler struct {
+	db     *database.DB
+	engine *alerts.Engine
+}
+
+func NewAlertsHandler(db *database.DB, engine *alerts.Engine) *AlertsHandler {
+	return &AlertsHandler{
+		db:     db,
+		engine: engine,
+	}
+}
+
+// GetActiveAlerts returns the alerts that are currently firing
+func (h *AlertsHandler) GetActiveAlerts(c *gin.Context) {
+	c.JSON(http.StatusOK, h.engine.ActiveAlerts())
+}
+
+// GetNotifierTypes returns the available notifier types
+func (h *AlertsHandler) GetNotifierTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, alerts.NotifierTypes())
+}
+
+// GetRules returns all alert rules
+func (h *AlertsHandler) GetRules(c *gin.Context) {
+	rules, err := h.db.GetAlertRules()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch alert rules")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert rules"})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateRule creates a new alert rule
+func (h *AlertsHandler) CreateRule(c *gin.Context) {
+	rule := models.AlertRule{
+		FailureThreshold: 1,
+		NotifyRecovery:   true,
+		Enabled:          true,
+	}
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if !h.validateRule(c, rule) {
+		return
+	}
+
+	if err := h.db.CreateAlertRule(&rule); err != nil {
+		log.Error().Err(err).Str("rule", rule.Name).Msg("Failed to create alert rule")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create alert rule"})
+		return
+	}
+
+	h.engine.Invalidate()
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule updates an existing alert rule
+func (h *AlertsHandler) UpdateRule(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	existing, err := h.db.GetAlertRuleByID(id)
+	if err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to fetch alert rule")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert rule"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+		return
+	}
+
+	rule := *existing
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	rule.ID = id
+
+	if !h.validateRule(c, rule) {
+		return
+	}
+
+	if err := h.db.UpdateAlertRule(&rule); err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to update alert rule")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert rule"})
+		return
+	}
+
+	h.engine.Invalidate()
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule deletes an alert rule
+func (h *AlertsHandler) DeleteRule(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteAlertRule(id); err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to delete alert rule")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete alert rule"})
+		return
+	}
+
+	h.engine.Invalidate()
+	c.JSON(http.StatusOK, gin.H{"message": "Alert rule deleted successfully"})
+}
+
+// GetNotifiers returns all notifier configurations
+func (h *AlertsHandler) GetNotifiers(c *gin.Context) {
+	notifiers, err := h.db.GetNotifiers()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch notifiers")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifiers"})
+		return
+	}
+	c.JSON(http.StatusOK, notifiers)
+}
+
+// CreateNotifier creates a new notifier configuration
+func (h *AlertsHandler) CreateNotifier(c *gin.Context) {
+	notifier := models.NotifierConfig{
+		Enabled: true,
+	}
+	if err := c.ShouldBindJSON(&notifier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := alerts.ValidateNotifier(notifier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.CreateNotifier(&notifier); err != nil {
+		log.Error().Err(err).Str("notifier", notifier.Name).Msg("Failed to create notifier")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notifier"})
+		return
+	}
+
+	h.engine.Invalidate()
+	c.JSON(http.StatusCreated, notifier)
+}
+
+// UpdateNotifier updates an existing notifier configuration
+func (h *AlertsHandler) UpdateNotifier(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	existing, err := h.db.GetNotifierByID(id)
+	if err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to fetch notifier")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifier"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notifier not found"})
+		return
+	}
+
+	notifier := *existing
+	if err := c.ShouldBindJSON(&notifier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	notifier.ID = id
+
+	if err := alerts.ValidateNotifier(notifier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.UpdateNotifier(&notifier); err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to update notifier")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifier"})
+		return
+	}
+
+	h.engine.Invalidate()
+	c.JSON(http.StatusOK, notifier)
+}
+
+// DeleteNotifier deletes a notifier configuration
+func (h *AlertsHandler) DeleteNotifier(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteNotifier(id); err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to delete notifier")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notifier"})
+		return
+	}
+
+	h.engine.Invalidate()
+	c.JSON(http.StatusOK, gin.H{"message": "Notifier deleted successfully"})
+}
+
+// TestNotifier sends a test alert through a notifier configuration
+func (h *AlertsHandler) TestNotifier(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	notifier, err := h.db.GetNotifierByID(id)
+	if err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to fetch notifier")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifier"})
+		return
+	}
+	if notifier == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notifier not found"})
+		return
+	}
+
+	if err := alerts.SendTestNotification(c.Request.Context(), *notifier); err != nil {
+		log.Error().Err(err).Int64("id", id).Str("type", notifier.Type).Msg("Failed to send test notification")
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test notification sent successfully"})
+}
+
+func (h *AlertsHandler) validateRule(c *gin.Context, rule models.AlertRule) bool {
+	notifiers, err := h.db.GetNotifiers()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch notifiers")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifiers"})
+		return false
+	}
+
+	if err := alerts.ValidateRule(rule, notifiers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// parseIDParam parses the numeric :id route parameter
+func parseIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return 0, false
+	}
+	return id, true
+}
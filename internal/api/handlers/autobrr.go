@@ -5,7 +5,6 @@ package handlers
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"time"
 
@@ -13,6 +12,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
 	"github.com/autobrr/dashbrr/internal/services/autobrr"
 	"github.com/autobrr/dashbrr/internal/services/cache"
 	"github.com/autobrr/dashbrr/internal/services/core"
@@ -49,6 +49,19 @@ func (h *AutobrrHandler) GetAutobrrReleaseStats(c *gin.Context) {
 		Str("instanceId", instanceId).
 		Msg("GetAutobrrReleaseStats called")
 
+	autobrrConfig, ok := loadCredentials(c, h.db, instanceId)
+	if !ok {
+		return
+	}
+	if autobrrConfig == nil || autobrrConfig.URL == "" {
+		// Return empty response for unconfigured service
+		log.Debug().
+			Str("instanceId", instanceId).
+			Msg("Service not configured, returning empty stats")
+		c.JSON(http.StatusOK, autobrr.AutobrrStats{})
+		return
+	}
+
 	cacheKey := statsPrefix + instanceId
 	ctx := context.Background()
 
@@ -64,22 +77,13 @@ func (h *AutobrrHandler) GetAutobrrReleaseStats(c *gin.Context) {
 		c.JSON(http.StatusOK, stats)
 
 		// Refresh cache in background without delay
-		go h.refreshStatsCache(instanceId, cacheKey)
+		go h.refreshStatsCache(*autobrrConfig, cacheKey)
 		return
 	}
 
 	// If not in cache, fetch from service
-	stats, err = h.fetchAndCacheStats(instanceId, cacheKey)
+	stats, err = h.fetchAndCacheStats(*autobrrConfig, cacheKey)
 	if err != nil {
-		if err.Error() == "service not configured" {
-			// Return empty response for unconfigured service
-			log.Debug().
-				Str("instanceId", instanceId).
-				Msg("Service not configured, returning empty stats")
-			c.JSON(http.StatusOK, autobrr.AutobrrStats{})
-			return
-		}
-
 		status := http.StatusInternalServerError
 		if err == context.DeadlineExceeded || err == context.Canceled {
 			status = http.StatusGatewayTimeout
@@ -107,6 +111,16 @@ func (h *AutobrrHandler) GetAutobrrIRCStatus(c *gin.Context) {
 		return
 	}
 
+	autobrrConfig, ok := loadCredentials(c, h.db, instanceId)
+	if !ok {
+		return
+	}
+	if autobrrConfig == nil || autobrrConfig.URL == "" {
+		// Return empty response for unconfigured service
+		c.JSON(http.StatusOK, []autobrr.IRCStatus{})
+		return
+	}
+
 	cacheKey := ircPrefix + instanceId
 	ctx := context.Background()
 
@@ -120,19 +134,13 @@ func (h *AutobrrHandler) GetAutobrrIRCStatus(c *gin.Context) {
 		c.JSON(http.StatusOK, status)
 
 		// Refresh cache in background without delay
-		go h.refreshIRCCache(instanceId, cacheKey)
+		go h.refreshIRCCache(*autobrrConfig, cacheKey)
 		return
 	}
 
 	// If not in cache, fetch from service
-	status, err = h.fetchAndCacheIRC(instanceId, cacheKey)
+	status, err = h.fetchAndCacheIRC(*autobrrConfig, cacheKey)
 	if err != nil {
-		if err.Error() == "service not configured" {
-			// Return empty response for unconfigured service
-			c.JSON(http.StatusOK, []autobrr.IRCStatus{})
-			return
-		}
-
 		httpStatus := http.StatusInternalServerError
 		if err == context.DeadlineExceeded || err == context.Canceled {
 			httpStatus = http.StatusGatewayTimeout
@@ -151,71 +159,54 @@ func (h *AutobrrHandler) GetAutobrrIRCStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, status)
 }
 
-func (h *AutobrrHandler) fetchAndCacheStats(instanceId, cacheKey string) (autobrr.AutobrrStats, error) {
-	autobrrConfig, err := h.db.GetServiceByInstanceID(instanceId)
-	if err != nil {
-		return autobrr.AutobrrStats{}, err
-	}
-
-	if autobrrConfig == nil || autobrrConfig.URL == "" {
-		return autobrr.AutobrrStats{}, fmt.Errorf("service not configured")
-	}
-
+func (h *AutobrrHandler) fetchAndCacheStats(autobrrConfig models.ServiceConfiguration, cacheKey string) (autobrr.AutobrrStats, error) {
 	service := &autobrr.AutobrrService{
 		ServiceCore: core.ServiceCore{},
 	}
 
-	stats, err := service.GetReleaseStats(autobrrConfig.URL, autobrrConfig.APIKey)
+	ctx := context.Background()
+	stats, err := service.GetReleaseStats(ctx, autobrrConfig.URL, autobrrConfig.APIKey)
 	if err != nil {
 		return autobrr.AutobrrStats{}, err
 	}
 
 	// Cache the results
-	ctx := context.Background()
 	if err := h.store.Set(ctx, cacheKey, stats, autobrrStatsCacheDuration); err != nil {
 		log.Warn().
 			Err(err).
-			Str("instanceId", instanceId).
+			Str("instanceId", autobrrConfig.InstanceID).
 			Msg("Failed to cache Autobrr release stats")
 	}
 
 	return stats, nil
 }
 
-func (h *AutobrrHandler) fetchAndCacheIRC(instanceId, cacheKey string) ([]autobrr.IRCStatus, error) {
-	autobrrConfig, err := h.db.GetServiceByInstanceID(instanceId)
-	if err != nil {
-		return nil, err
-	}
-
-	if autobrrConfig == nil || autobrrConfig.URL == "" {
-		return nil, fmt.Errorf("service not configured")
-	}
-
+func (h *AutobrrHandler) fetchAndCacheIRC(autobrrConfig models.ServiceConfiguration, cacheKey string) ([]autobrr.IRCStatus, error) {
 	service := &autobrr.AutobrrService{
 		ServiceCore: core.ServiceCore{},
 	}
 
-	status, err := service.GetIRCStatus(autobrrConfig.URL, autobrrConfig.APIKey)
+	ctx := context.Background()
+	status, err := service.GetIRCStatus(ctx, autobrrConfig.URL, autobrrConfig.APIKey)
 	if err != nil {
 		return nil, err
 	}
 
 	// Cache the results
-	ctx := context.Background()
 	if err := h.store.Set(ctx, cacheKey, status, autobrrIRCCacheDuration); err != nil {
 		log.Warn().
 			Err(err).
-			Str("instanceId", instanceId).
+			Str("instanceId", autobrrConfig.InstanceID).
 			Msg("Failed to cache Autobrr IRC status")
 	}
 
 	return status, nil
 }
 
-func (h *AutobrrHandler) refreshStatsCache(instanceId, cacheKey string) {
-	_, err := h.fetchAndCacheStats(instanceId, cacheKey)
-	if err != nil && err.Error() != "service not configured" {
+func (h *AutobrrHandler) refreshStatsCache(autobrrConfig models.ServiceConfiguration, cacheKey string) {
+	instanceId := autobrrConfig.InstanceID
+	_, err := h.fetchAndCacheStats(autobrrConfig, cacheKey)
+	if err != nil {
 		log.Error().
 			Err(err).
 			Str("instanceId", instanceId).
@@ -228,9 +219,10 @@ func (h *AutobrrHandler) refreshStatsCache(instanceId, cacheKey string) {
 		Msg("Successfully refreshed Autobrr release stats cache")
 }
 
-func (h *AutobrrHandler) refreshIRCCache(instanceId, cacheKey string) {
-	_, err := h.fetchAndCacheIRC(instanceId, cacheKey)
-	if err != nil && err.Error() != "service not configured" {
+func (h *AutobrrHandler) refreshIRCCache(autobrrConfig models.ServiceConfiguration, cacheKey string) {
+	instanceId := autobrrConfig.InstanceID
+	_, err := h.fetchAndCacheIRC(autobrrConfig, cacheKey)
+	if err != nil {
 		log.Error().
 			Err(err).
 			Str("instanceId", instanceId).
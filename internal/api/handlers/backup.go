@@ -0,0 +1,64 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/services/backup"
+)
+
+type BackupHandler struct {
+	db      *database.DB
+	options backup.Options
+}
+
+func NewBackupHandler(db *database.DB, options backup.Options) *BackupHandler {
+	return &BackupHandler{
+		db:      db,
+		options: options,
+	}
+}
+
+// Download streams a new backup archive of the dashbrr state. The archive
+// leaves out the sessions, which hold live session tokens. Unless backups are
+// encrypted it leaves out the config file as well, which can hold the
+// encryption key, the database password and the OIDC client secret.
+func (h *BackupHandler) Download(c *gin.Context) {
+	options := h.options
+	options.SessionsDir = ""
+	if options.Passphrase == "" {
+		options.ConfigPath = ""
+	}
+
+	snapshot, err := backup.NewSnapshot(h.db, options)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create backup")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create backup"})
+		return
+	}
+	defer snapshot.Close()
+
+	contentType := "application/gzip"
+	if snapshot.Encrypted() {
+		contentType = "application/octet-stream"
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+backup.FileName(snapshot.Manifest.CreatedAt, snapshot.Encrypted())+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Dashbrr-Backup-Format", strconv.Itoa(snapshot.Manifest.Format))
+	c.Status(http.StatusOK)
+
+	// The status is sent with the first write, so a failure leaves a truncated
+	// archive that cannot be restored
+	if err := snapshot.Write(c.Writer); err != nil {
+		log.Error().Err(err).Msg("Failed to stream backup")
+	}
+}
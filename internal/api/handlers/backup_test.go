@@ -0,0 +1,79 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/backup"
+)
+
+func TestBackupHandler_Download(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := database.InitDBWithConfig(&database.Config{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "dashbrr.db")})
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.CreateService(&models.ServiceConfiguration{InstanceID: "sonarr-1", DisplayName: "Sonarr"}))
+
+	sessionsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sessionsDir, "sessions.json"), []byte(`{"session:abc":{}}`), 0600))
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte("[database]\nencryption_key = \"secret\"\n"), 0600))
+
+	tests := []struct {
+		name        string
+		passphrase  string
+		contentType string
+		extension   string
+		files       []string
+	}{
+		{name: "plain", contentType: "application/gzip", extension: `.tar.gz"`, files: []string{"dashbrr.db"}},
+		{name: "encrypted", passphrase: "secret", contentType: "application/octet-stream", extension: `.tar.gz.enc"`, files: []string{"config/config.toml", "dashbrr.db"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/api/admin/backup", NewBackupHandler(db, backup.Options{ConfigPath: configPath, SessionsDir: sessionsDir, Passphrase: tt.passphrase}).Download)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/backup", nil))
+			require.Equal(t, http.StatusOK, w.Code)
+
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment; filename=\"dashbrr-backup-")
+			assert.Contains(t, w.Header().Get("Content-Disposition"), tt.extension)
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			assert.Equal(t, tt.passphrase != "", backup.IsEncrypted(w.Body.Bytes()))
+
+			result, err := backup.Restore(db, bytes.NewReader(w.Body.Bytes()), backup.RestoreOptions{
+				Options: backup.Options{SessionsDir: t.TempDir(), Passphrase: tt.passphrase},
+			})
+			require.NoError(t, err)
+			assert.NotEmpty(t, result.Tables)
+
+			// Live session tokens never leave the server, the config file only
+			// in encrypted archives
+			assert.Equal(t, tt.files, result.Manifest.Files)
+			assert.Equal(t, []string{"dashbrr.db"}, result.Files)
+
+			services, err := db.GetAllServices()
+			require.NoError(t, err)
+			require.Len(t, services, 1)
+			assert.Equal(t, "sonarr-1", services[0].InstanceID)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/services/bazarr"
+	"github.com/autobrr/dashbrr/internal/services/cache"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+const (
+	bazarrCacheDuration = 60 * time.Second
+	bazarrStatsPrefix   = "bazarr:stats:"
+)
+
+type BazarrHandler struct {
+	db    *database.DB
+	cache cache.Store
+}
+
+func NewBazarrHandler(db *database.DB, cache cache.Store) *BazarrHandler {
+	return &BazarrHandler{
+		db:    db,
+		cache: cache,
+	}
+}
+
+func (h *BazarrHandler) GetStats(c *gin.Context) {
+	instanceId := c.Query("instanceId")
+	if instanceId == "" {
+		log.Error().Msg("No instanceId provided")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "instanceId is required"})
+		return
+	}
+
+	// Verify this is a Bazarr instance
+	if !strings.HasPrefix(instanceId, "bazarr") {
+		log.Error().Str("instanceId", instanceId).Msg("Invalid Bazarr instance ID")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Bazarr instance ID"})
+		return
+	}
+
+	cacheKey := bazarrStatsPrefix + instanceId
+	ctx := context.Background()
+
+	// Try to get from cache first
+	var statsResp types.BazarrStatsResponse
+	if err := h.cache.Get(ctx, cacheKey, &statsResp); err == nil {
+		log.Debug().
+			Str("instanceId", instanceId).
+			Int("wantedEpisodes", statsResp.WantedEpisodes).
+			Int("wantedMovies", statsResp.WantedMovies).
+			Msg("Serving Bazarr stats from cache")
+		c.JSON(http.StatusOK, statsResp)
+		return
+	}
+
+	// If not in cache, fetch from service
+	bazarrConfig, err := h.db.GetServiceByInstanceID(instanceId)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to get Bazarr configuration")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Bazarr configuration"})
+		return
+	}
+
+	if bazarrConfig == nil {
+		log.Error().Str("instanceId", instanceId).Msg("Bazarr is not configured")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bazarr is not configured"})
+		return
+	}
+
+	if !resolveCredentials(c, bazarrConfig) {
+		return
+	}
+
+	service := &bazarr.BazarrService{}
+	stats, err := service.GetStats(instanceContext(c, bazarrConfig), bazarrConfig.URL, bazarrConfig.APIKey)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to fetch Bazarr stats")
+		if bazarrErr, ok := err.(*bazarr.ErrBazarr); ok && bazarrErr.HttpCode > 0 {
+			c.JSON(bazarrErr.HttpCode, gin.H{"error": bazarrErr.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch stats: %v", err)})
+		return
+	}
+
+	// Cache the results
+	if err := h.cache.Set(ctx, cacheKey, stats, bazarrCacheDuration); err != nil {
+		log.Warn().
+			Err(err).
+			Str("instanceId", instanceId).
+			Msg("Failed to cache Bazarr stats")
+	}
+
+	log.Debug().
+		Str("instanceId", instanceId).
+		Int("wantedEpisodes", stats.WantedEpisodes).
+		Int("wantedMovies", stats.WantedMovies).
+		Msg("Successfully retrieved and cached Bazarr stats")
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/secrets"
+	"github.com/autobrr/dashbrr/internal/services/core"
+)
+
+// resolveCredentials replaces a secret reference in the API key of a loaded
+// service configuration. It writes the error response and returns false when
+// the reference cannot be resolved.
+func resolveCredentials(c *gin.Context, config *models.ServiceConfiguration) bool {
+	if err := secrets.ResolveService(c.Request.Context(), config); err != nil {
+		log.Error().Err(err).Str("instanceId", config.InstanceID).Msg("Failed to resolve API key")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to resolve API key"})
+		return false
+	}
+	return true
+}
+
+// loadCredentials loads the configuration of instanceId and resolves its
+// credentials. A nil configuration with ok set means the instance is not
+// configured. When loading or resolving fails it writes the error response and
+// returns false.
+func loadCredentials(c *gin.Context, db *database.DB, instanceId string) (config *models.ServiceConfiguration, ok bool) {
+	config, err := db.GetServiceByInstanceID(instanceId)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to get service configuration")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get service configuration"})
+		return nil, false
+	}
+	if config == nil {
+		return nil, true
+	}
+	if !resolveCredentials(c, config) {
+		return nil, false
+	}
+	return config, true
+}
+
+// instanceContext returns the request context carrying the per-instance
+// options of config, so requests made on behalf of the handler use the same
+// TLS settings and custom headers as the health checks.
+func instanceContext(c *gin.Context, config *models.ServiceConfiguration) context.Context {
+	return core.WithOptions(c.Request.Context(), config.Options)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/cache"
+)
+
+func TestHandlers_UnresolvedCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := database.InitDBWithConfig(&database.Config{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "dashbrr.db")})
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := cache.NewMemoryStore(t.TempDir())
+
+	tests := []struct {
+		instanceID string
+		path       string
+		handler    gin.HandlerFunc
+	}{
+		{instanceID: "autobrr-1", path: "/autobrr/stats", handler: NewAutobrrHandler(db, store).GetAutobrrReleaseStats},
+		{instanceID: "autobrr-1", path: "/autobrr/irc", handler: NewAutobrrHandler(db, store).GetAutobrrIRCStatus},
+		{instanceID: "omegabrr-1", path: "/omegabrr/status", handler: NewOmegabrrHandler(db, store).GetOmegabrrStatus},
+		{instanceID: "plex-1", path: "/plex/sessions", handler: NewPlexHandler(db, store).GetPlexSessions},
+		{instanceID: "tailscale-1", path: "/tailscale/devices", handler: NewTailscaleHandler(db, store).GetTailscaleDevices},
+		{instanceID: "maintainerr-1", path: "/maintainerr/collections", handler: NewMaintainerrHandler(db, store).GetMaintainerrCollections},
+		{instanceID: "overseerr-1", path: "/overseerr/requests", handler: NewOverseerrHandler(db, store, nil).GetRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			service, err := db.GetServiceByInstanceID(tt.instanceID)
+			require.NoError(t, err)
+			if service == nil {
+				require.NoError(t, db.CreateService(&models.ServiceConfiguration{
+					InstanceID:  tt.instanceID,
+					DisplayName: tt.instanceID,
+					URL:         "http://localhost:1",
+					APIKey:      "env:DASHBRR_TEST_UNSET_API_KEY",
+				}))
+			}
+
+			router := gin.New()
+			router.GET(tt.path, tt.handler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path+"?instanceId="+tt.instanceID, nil))
+
+			assert.Equal(t, http.StatusBadGateway, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), "Failed to resolve API key")
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/cache"
+	"github.com/autobrr/dashbrr/internal/services/downloadclient"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+const downloadClientCacheDuration = 5 * time.Second
+
+// DownloadClientHandler serves the qBittorrent, Transmission and Deluge endpoints
+type DownloadClientHandler struct {
+	db         *database.DB
+	cache      cache.Store
+	clientType string
+	name       string
+}
+
+func NewQbittorrentHandler(db *database.DB, cache cache.Store) *DownloadClientHandler {
+	return newDownloadClientHandler(db, cache, "qbittorrent", "qBittorrent")
+}
+
+func NewTransmissionHandler(db *database.DB, cache cache.Store) *DownloadClientHandler {
+	return newDownloadClientHandler(db, cache, "transmission", "Transmission")
+}
+
+func NewDelugeHandler(db *database.DB, cache cache.Store) *DownloadClientHandler {
+	return newDownloadClientHandler(db, cache, "deluge", "Deluge")
+}
+
+func newDownloadClientHandler(db *database.DB, cache cache.Store, clientType, name string) *DownloadClientHandler {
+	return &DownloadClientHandler{
+		db:         db,
+		cache:      cache,
+		clientType: clientType,
+		name:       name,
+	}
+}
+
+// getConfig validates the instance ID and loads its configuration. It writes
+// the error response and returns nil when the request cannot proceed.
+func (h *DownloadClientHandler) getConfig(c *gin.Context, instanceId string) *models.ServiceConfiguration {
+	if instanceId == "" {
+		log.Error().Msg("No instanceId provided")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "instanceId is required"})
+		return nil
+	}
+
+	if !strings.HasPrefix(instanceId, h.clientType) {
+		log.Error().Str("instanceId", instanceId).Str("type", h.clientType).Msg("Invalid download client instance ID")
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s instance ID", h.name)})
+		return nil
+	}
+
+	config, err := h.db.GetServiceByInstanceID(instanceId)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to get download client configuration")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get %s configuration", h.name)})
+		return nil
+	}
+
+	if config == nil {
+		log.Error().Str("instanceId", instanceId).Msg("Download client is not configured")
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("%s is not configured", h.name)})
+		return nil
+	}
+
+	if !resolveCredentials(c, config) {
+		return nil
+	}
+
+	return config
+}
+
+// client returns the registered implementation of the handler's client type.
+// It writes the error response and returns nil when there is none.
+func (h *DownloadClientHandler) client(c *gin.Context) downloadclient.Client {
+	client, ok := models.NewServiceRegistry().CreateService(h.clientType).(downloadclient.Client)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s support is not available", h.name)})
+		return nil
+	}
+	return client
+}
+
+// writeDownloadClientError responds with the upstream status code when the client returned one
+func writeDownloadClientError(c *gin.Context, err error, message string) {
+	if clientErr, ok := err.(*downloadclient.ErrDownloadClient); ok && clientErr.HttpCode > 0 {
+		c.JSON(clientErr.HttpCode, gin.H{"error": clientErr.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+}
+
+func (h *DownloadClientHandler) statsCacheKey(instanceId string) string {
+	return h.clientType + ":stats:" + instanceId
+}
+
+func (h *DownloadClientHandler) GetStats(c *gin.Context) {
+	config := h.getConfig(c, c.Query("instanceId"))
+	if config == nil {
+		return
+	}
+
+	cacheKey := h.statsCacheKey(config.InstanceID)
+	ctx := context.Background()
+
+	// Try to get from cache first
+	var stats types.DownloadClientStats
+	if err := h.cache.Get(ctx, cacheKey, &stats); err == nil {
+		log.Debug().
+			Str("instanceId", config.InstanceID).
+			Int("total", stats.Total).
+			Msg("Serving download client stats from cache")
+		c.JSON(http.StatusOK, stats)
+		return
+	}
+
+	client := h.client(c)
+	if client == nil {
+		return
+	}
+
+	result, err := client.GetStats(instanceContext(c, config), config.URL, config.APIKey)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", config.InstanceID).Msg("Failed to fetch download client stats")
+		writeDownloadClientError(c, err, "Failed to fetch stats")
+		return
+	}
+
+	if err := h.cache.Set(ctx, cacheKey, result, downloadClientCacheDuration); err != nil {
+		log.Warn().
+			Err(err).
+			Str("instanceId", config.InstanceID).
+			Msg("Failed to cache download client stats")
+	}
+
+	log.Debug().
+		Str("instanceId", config.InstanceID).
+		Int("total", result.Total).
+		Int("active", result.Active).
+		Int("stalled", result.Stalled).
+		Int("errored", result.Errored).
+		Msg("Successfully retrieved and cached download client stats")
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PauseTorrents pauses the torrents listed in the request body, or all torrents
+func (h *DownloadClientHandler) PauseTorrents(c *gin.Context) {
+	h.torrentAction(c, "pause", downloadclient.Client.PauseTorrents)
+}
+
+// ResumeTorrents resumes the torrents listed in the request body, or all torrents
+func (h *DownloadClientHandler) ResumeTorrents(c *gin.Context) {
+	h.torrentAction(c, "resume", downloadclient.Client.ResumeTorrents)
+}
+
+func (h *DownloadClientHandler) torrentAction(c *gin.Context, action string, run func(downloadclient.Client, context.Context, string, string, []string) error) {
+	config := h.getConfig(c, c.Param("instanceId"))
+	if config == nil {
+		return
+	}
+
+	// The body is optional, without one the action applies to all torrents
+	var req types.DownloadClientActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	client := h.client(c)
+	if client == nil {
+		return
+	}
+
+	if err := run(client, instanceContext(c, config), config.URL, config.APIKey, req.Hashes); err != nil {
+		log.Error().Err(err).Str("instanceId", config.InstanceID).Str("action", action).Msg("Failed to run download client action")
+		writeDownloadClientError(c, err, fmt.Sprintf("Failed to %s torrents", action))
+		return
+	}
+
+	// The cached stats no longer reflect the torrent states
+	if err := h.cache.Delete(context.Background(), h.statsCacheKey(config.InstanceID)); err != nil && err != cache.ErrKeyNotFound {
+		log.Warn().Err(err).Str("instanceId", config.InstanceID).Msg("Failed to clear download client stats cache")
+	}
+
+	log.Info().
+		Str("instanceId", config.InstanceID).
+		Str("action", action).
+		Int("hashes", len(req.Hashes)).
+		Msg("Download client action completed")
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Torrents %sd", action)})
+}
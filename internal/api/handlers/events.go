@@ -6,7 +6,10 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"strings"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -14,23 +17,38 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/metrics"
 	"github.com/autobrr/dashbrr/internal/models"
-	"github.com/autobrr/dashbrr/internal/services"
+	"github.com/autobrr/dashbrr/internal/secrets"
+	"github.com/autobrr/dashbrr/internal/services/alerts"
+	"github.com/autobrr/dashbrr/internal/services/scheduler"
 )
 
 type EventsHandler struct {
-	db     *database.DB
-	health *services.HealthService
+	db        *database.DB
+	alerts    *alerts.Engine
+	scheduler *scheduler.Scheduler
+
+	latestMu sync.RWMutex
+	latest   map[string]models.ServiceHealth
 }
 
-func NewEventsHandler(db *database.DB, health *services.HealthService) *EventsHandler {
+func NewEventsHandler(db *database.DB, alertEngine *alerts.Engine) *EventsHandler {
 	handler := &EventsHandler{
 		db:     db,
-		health: health,
+		alerts: alertEngine,
+		latest: make(map[string]models.ServiceHealth),
 	}
+	handler.scheduler = scheduler.New(db, handler.runCheck, handler.handleResult)
+	handler.scheduler.OnSync(handler.pruneLatest)
 	return handler
 }
 
+// Scheduler returns the scheduler running the background health checks
+func (h *EventsHandler) Scheduler() *scheduler.Scheduler {
+	return h.scheduler
+}
+
 type client struct {
 	send chan models.ServiceHealth
 	done chan struct{}
@@ -39,19 +57,13 @@ type client struct {
 var (
 	clients   = make(map[*client]bool)
 	clientsMu sync.RWMutex
-
-	// Increased concurrent checks from 5 to 10
-	healthCheckSemaphore = make(chan struct{}, 10)
-
-	// Track last check time per service
-	lastChecks   = make(map[string]time.Time)
-	lastChecksMu sync.RWMutex
 )
 
 const (
-	minCheckInterval  = 30 * time.Second
-	checkTimeout      = 15 * time.Second
 	keepAliveInterval = 15 * time.Second
+
+	defaultHealthRetention = 30 * 24 * time.Hour
+	healthPruneInterval    = time.Hour
 )
 
 // safeClose safely closes a channel if it's not already closed
@@ -70,136 +82,146 @@ func safeClose(ch chan struct{}) {
 	}
 }
 
-// checkAndBroadcastHealth performs health checks for all services and broadcasts results
-func (h *EventsHandler) checkAndBroadcastHealth(ctx context.Context) []models.ServiceHealth {
-	services, err := h.db.GetAllServices()
-	if err != nil {
-		log.Error().Err(err).Msg("Error fetching services")
-		return nil
+// runCheck performs a single health check for the scheduler
+func (h *EventsHandler) runCheck(ctx context.Context, svc models.ServiceConfiguration) models.ServiceHealth {
+	serviceType := models.ServiceTypeFromInstanceID(svc.InstanceID)
+
+	serviceChecker := models.NewServiceRegistry().CreateService(serviceType)
+	if serviceChecker == nil {
+		return models.ServiceHealth{
+			ServiceID:   svc.InstanceID,
+			Status:      "error",
+			Message:     "Unsupported service type: " + serviceType,
+			LastChecked: time.Now(),
+		}
 	}
 
-	if len(services) == 0 {
-		return nil
+	if err := secrets.ResolveService(ctx, &svc); err != nil {
+		return models.ServiceHealth{
+			ServiceID:   svc.InstanceID,
+			Status:      "error",
+			Message:     "Failed to resolve API key: " + err.Error(),
+			LastChecked: time.Now(),
+		}
 	}
 
-	var wg sync.WaitGroup
-	// Make results channel buffered to prevent blocking
-	results := make(chan models.ServiceHealth, len(services))
-	allResults := make([]models.ServiceHealth, 0, len(services))
-
-	// Create a context that will be cancelled when the parent context is done
-	checkCtx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	// Increased batch size from 3 to 5
-	batchSize := 5
-	for i := 0; i < len(services); i += batchSize {
-		end := i + batchSize
-		if end > len(services) {
-			end = len(services)
-		}
+	health, _ := serviceChecker.CheckHealth(ctx, svc)
+	health.ServiceID = svc.InstanceID
+	return health
+}
 
-		batch := services[i:end]
-		for _, service := range batch {
-			if service.URL == "" {
-				continue
-			}
+// handleResult records, alerts on and broadcasts a completed health check
+func (h *EventsHandler) handleResult(health models.ServiceHealth) {
+	if health.ResponseTime == 0 && health.Status == "" {
+		return
+	}
 
-			wg.Add(1)
-			go func(svc models.ServiceConfiguration) {
-				defer wg.Done()
+	h.latestMu.Lock()
+	h.latest[health.ServiceID] = health
+	h.latestMu.Unlock()
 
-				select {
-				case healthCheckSemaphore <- struct{}{}:
-					defer func() { <-healthCheckSemaphore }()
-
-					serviceType := strings.Split(svc.InstanceID, "-")[0]
-					serviceHealth := models.ServiceHealth{
-						ServiceID:   svc.InstanceID,
-						Status:      "checking",
-						LastChecked: time.Now(),
-					}
-
-					serviceChecker := models.NewServiceRegistry().CreateService(serviceType)
-					if serviceChecker != nil {
-						health, _ := serviceChecker.CheckHealth(svc.URL, svc.APIKey)
-						health.ServiceID = svc.InstanceID
-
-						lastChecksMu.Lock()
-						lastChecks[svc.InstanceID] = time.Now()
-						lastChecksMu.Unlock()
-
-						select {
-						case results <- health:
-						case <-checkCtx.Done():
-							log.Warn().
-								Str("service", svc.InstanceID).
-								Msg("Context cancelled while sending results")
-							return
-						}
-					} else {
-						serviceHealth.Status = "error"
-						serviceHealth.Message = "Unsupported service type: " + serviceType
-						select {
-						case results <- serviceHealth:
-						case <-checkCtx.Done():
-							return
-						}
-					}
-				case <-time.After(10 * time.Second):
-					log.Warn().
-						Str("service", svc.InstanceID).
-						Msg("Health check skipped due to concurrency limit")
-				case <-checkCtx.Done():
-					return
-				}
-			}(service)
+	h.recordHealth(health)
+	if h.alerts != nil {
+		h.alerts.Process(health)
+	}
+	BroadcastHealth(health)
+}
+
+// latestHealth returns the most recent result of every checked service
+func (h *EventsHandler) latestHealth() []models.ServiceHealth {
+	h.latestMu.RLock()
+	defer h.latestMu.RUnlock()
+
+	results := make([]models.ServiceHealth, 0, len(h.latest))
+	for _, health := range h.latest {
+		results = append(results, health)
+	}
+	return results
+}
+
+// pruneLatest drops the results of removed services, so new clients are not
+// sent their last health
+func (h *EventsHandler) pruneLatest(services []models.ServiceConfiguration) {
+	configured := make(map[string]bool, len(services))
+	for _, service := range services {
+		configured[service.InstanceID] = true
+	}
+
+	h.latestMu.Lock()
+	defer h.latestMu.Unlock()
+	for instanceID := range h.latest {
+		if !configured[instanceID] {
+			delete(h.latest, instanceID)
 		}
+	}
+}
 
-		// Wait for current batch to complete or context to be cancelled
-		done := make(chan struct{})
-		go func() {
-			wg.Wait()
-			close(done)
-		}()
+// TriggerCheck runs a health check for a single instance right away
+func (h *EventsHandler) TriggerCheck(c *gin.Context) {
+	instanceID := c.Param("service")
 
-		select {
-		case <-done:
-			// Batch completed successfully
-		case <-checkCtx.Done():
-			return allResults
+	health, err := h.scheduler.CheckNow(c.Request.Context(), instanceID)
+	if err != nil {
+		if errors.Is(err, scheduler.ErrUnknownService) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+			return
+		}
+		if c.Request.Context().Err() != nil {
+			return
 		}
+		log.Error().Err(err).Str("service", instanceID).Msg("Failed to run health check")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run health check"})
+		return
+	}
 
-		// Reduced delay between batches
-		time.Sleep(500 * time.Millisecond)
+	if next, ok := h.scheduler.NextCheck(instanceID); ok {
+		c.Header("X-Next-Check", next.UTC().Format(time.RFC3339))
 	}
+	c.JSON(http.StatusOK, health)
+}
 
-	// Close results channel after all goroutines are done
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+// recordHealth persists a health check result to the history table
+func (h *EventsHandler) recordHealth(health models.ServiceHealth) {
+	metrics.ObserveHealth(health)
 
-	// Collect results with timeout
-	resultsTimer := time.NewTimer(5 * time.Second)
-	defer resultsTimer.Stop()
+	if h.db == nil || health.Status == "checking" {
+		return
+	}
 
-	for {
-		select {
-		case health, ok := <-results:
-			if !ok {
-				return allResults
-			}
-			if health.ResponseTime > 0 || health.Status != "" {
-				allResults = append(allResults, health)
-				BroadcastHealth(health)
-			}
-		case <-resultsTimer.C:
-			return allResults
-		case <-checkCtx.Done():
-			return allResults
+	if err := h.db.RecordHealthCheck(health); err != nil {
+		log.Error().
+			Err(err).
+			Str("service", health.ServiceID).
+			Msg("Failed to record health check")
+	}
+}
+
+// pruneHealthHistory deletes health check records older than the retention period
+func (h *EventsHandler) pruneHealthHistory() {
+	if h.db == nil {
+		return
+	}
+
+	deleted, err := h.db.PruneHealthChecks(time.Now().Add(-healthHistoryRetention()))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to prune health check history")
+		return
+	}
+
+	if deleted > 0 {
+		log.Debug().Int64("deleted", deleted).Msg("Pruned health check history")
+	}
+}
+
+// healthHistoryRetention returns the configured health history retention period
+func healthHistoryRetention() time.Duration {
+	if env := os.Getenv("DASHBRR__HEALTH_RETENTION_DAYS"); env != "" {
+		if days, err := strconv.Atoi(env); err == nil && days > 0 {
+			return time.Duration(days) * 24 * time.Hour
 		}
+		log.Warn().Str("value", env).Msg("Invalid health history retention, using default")
 	}
+	return defaultHealthRetention
 }
 
 // StreamHealth handles SSE connections for real-time health updates
@@ -217,6 +239,7 @@ func (h *EventsHandler) StreamHealth(c *gin.Context) {
 
 	clientsMu.Lock()
 	clients[client] = true
+	metrics.SetSSEClients(len(clients))
 	clientsMu.Unlock()
 
 	ctx := c.Request.Context()
@@ -226,15 +249,24 @@ func (h *EventsHandler) StreamHealth(c *gin.Context) {
 		<-ctx.Done()
 		clientsMu.Lock()
 		delete(clients, client)
+		metrics.SetSSEClients(len(clients))
 		clientsMu.Unlock()
 		safeClose(client.done)
 		close(client.send)
 	}()
 
-	// Perform immediate health check for new connection
-	go h.checkAndBroadcastHealth(ctx)
-
 	lastUpdate := make(map[string]time.Time)
+
+	// Send the latest known results so new clients do not wait for the next scheduled check
+	for _, health := range h.latestHealth() {
+		data, err := json.Marshal(health)
+		if err != nil {
+			continue
+		}
+		lastUpdate[health.ServiceID] = time.Now()
+		c.SSEvent("health", string(data))
+	}
+	c.Writer.Flush()
 	keepAliveTicker := time.NewTicker(keepAliveInterval)
 	defer keepAliveTicker.Stop()
 
@@ -266,7 +298,6 @@ func (h *EventsHandler) StreamHealth(c *gin.Context) {
 			default:
 				c.SSEvent("keepalive", time.Now().Unix())
 				c.Writer.Flush()
-				go h.checkAndBroadcastHealth(ctx)
 			}
 		}
 	}
@@ -292,25 +323,28 @@ func BroadcastHealth(health models.ServiceHealth) {
 }
 
 var (
-	healthMonitor     *time.Ticker
 	healthMonitorOnce sync.Once
 	monitorCtx        context.Context
 	monitorCancel     context.CancelFunc
 )
 
-// StartHealthMonitor starts the background health check process
+// StartHealthMonitor starts the background health check scheduler
 func (h *EventsHandler) StartHealthMonitor() {
 	healthMonitorOnce.Do(func() {
 		monitorCtx, monitorCancel = context.WithCancel(context.Background())
 
-		go h.checkAndBroadcastHealth(monitorCtx)
+		h.scheduler.Start(monitorCtx)
 
-		healthMonitor = time.NewTicker(30 * time.Second)
 		go func() {
+			h.pruneHealthHistory()
+
+			pruneTicker := time.NewTicker(healthPruneInterval)
+			defer pruneTicker.Stop()
+
 			for {
 				select {
-				case <-healthMonitor.C:
-					h.checkAndBroadcastHealth(monitorCtx)
+				case <-pruneTicker.C:
+					h.pruneHealthHistory()
 				case <-monitorCtx.Done():
 					return
 				}
@@ -321,10 +355,8 @@ func (h *EventsHandler) StartHealthMonitor() {
 
 // StopHealthMonitor stops the health monitoring
 func (h *EventsHandler) StopHealthMonitor() {
-	if healthMonitor != nil {
-		healthMonitor.Stop()
-	}
 	if monitorCancel != nil {
 		monitorCancel()
 	}
+	h.scheduler.Stop()
 }
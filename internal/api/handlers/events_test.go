@@ -0,0 +1,37 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func TestEventsHandler_LatestHealthDropsRemovedServices(t *testing.T) {
+	db, err := database.InitDBWithConfig(&database.Config{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "dashbrr.db")})
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.CreateService(&models.ServiceConfiguration{InstanceID: "sonarr-1", DisplayName: "Sonarr", URL: "http://localhost:8989"}))
+	require.NoError(t, db.CreateService(&models.ServiceConfiguration{InstanceID: "radarr-1", DisplayName: "Radarr", URL: "http://localhost:7878"}))
+
+	handler := NewEventsHandler(db, nil)
+	for _, instanceID := range []string{"sonarr-1", "radarr-1"} {
+		handler.handleResult(models.ServiceHealth{ServiceID: instanceID, Status: "online", ResponseTime: 10, LastChecked: time.Now()})
+	}
+	require.Len(t, handler.latestHealth(), 2)
+
+	require.NoError(t, db.DeleteService("radarr-1"))
+	require.NoError(t, handler.Scheduler().Sync())
+
+	latest := handler.latestHealth()
+	require.Len(t, latest, 1)
+	assert.Equal(t, "sonarr-1", latest[0].ServiceID)
+}
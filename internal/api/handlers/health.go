@@ -12,7 +12,6 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/autobrr/dashbrr/internal/models"
-	"github.com/autobrr/dashbrr/internal/services"
 )
 
 // DatabaseService defines the database operations needed by HealthHandler
@@ -22,11 +21,10 @@ type DatabaseService interface {
 
 type HealthHandler struct {
 	db             DatabaseService
-	health         *services.HealthService
 	serviceCreator models.ServiceCreator
 }
 
-func NewHealthHandler(db DatabaseService, health *services.HealthService, creator ...models.ServiceCreator) *HealthHandler {
+func NewHealthHandler(db DatabaseService, creator ...models.ServiceCreator) *HealthHandler {
 	var sc models.ServiceCreator
 	if len(creator) > 0 {
 		sc = creator[0]
@@ -36,7 +34,6 @@ func NewHealthHandler(db DatabaseService, health *services.HealthService, creato
 
 	return &HealthHandler{
 		db:             db,
-		health:         health,
 		serviceCreator: sc,
 	}
 }
@@ -100,9 +97,9 @@ func (h *HealthHandler) CheckHealth(c *gin.Context) {
 		return
 	}
 
-	// For general service, API key is optional
-	// For other services, ensure API key is provided
-	if serviceType != "general" && service.APIKey == "" {
+	// Only services that authenticate with an API key or token require one
+	descriptor, ok := models.LookupService(serviceType)
+	if (!ok || descriptor.RequiresCredentials()) && service.APIKey == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"status":  "error",
 			"message": "API key is required for this service type",
@@ -110,10 +107,15 @@ func (h *HealthHandler) CheckHealth(c *gin.Context) {
 		return
 	}
 
-	health, statusCode := serviceChecker.CheckHealth(service.URL, service.APIKey)
+	if !resolveCredentials(c, service) {
+		return
+	}
+
+	health, err := serviceChecker.CheckHealth(c.Request.Context(), *service)
 
 	// Enhance error handling for specific status codes
-	if statusCode != http.StatusOK {
+	if err != nil {
+		statusCode := models.HealthCheckStatusCode(err)
 		var errorMessage string
 		switch statusCode {
 		case http.StatusUnauthorized:
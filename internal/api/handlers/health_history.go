@@ -0,0 +1,118 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+const defaultHistoryWindow = 24 * time.Hour
+
+// maxHistoryWindows limits the window of each resolution. Rollups are computed
+// from the raw checks on every request, so the window bounds the rows loaded.
+var maxHistoryWindows = map[string]struct {
+	window time.Duration
+	label  string
+}{
+	models.ResolutionRaw:  {window: 7 * 24 * time.Hour, label: "7 day"},
+	models.ResolutionHour: {window: 7 * 24 * time.Hour, label: "7 day"},
+	models.ResolutionDay:  {window: 31 * 24 * time.Hour, label: "31 day"},
+}
+
+// HealthHistoryStore defines the database operations needed by HealthHistoryHandler
+type HealthHistoryStore interface {
+	GetServiceByInstanceID(id string) (*models.ServiceConfiguration, error)
+	GetHealthHistory(instanceID string, from, to time.Time, resolution string) (*models.HealthHistory, error)
+}
+
+type HealthHistoryHandler struct {
+	db HealthHistoryStore
+}
+
+func NewHealthHistoryHandler(db HealthHistoryStore) *HealthHistoryHandler {
+	return &HealthHistoryHandler{
+		db: db,
+	}
+}
+
+// GetHistory returns the persisted health check history of an instance
+func (h *HealthHistoryHandler) GetHistory(c *gin.Context) {
+	instanceID := c.Param("service")
+	if instanceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Service ID is required"})
+		return
+	}
+
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		parsed, err := parseHistoryTime(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'to' parameter, expected RFC3339 or unix timestamp"})
+			return
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultHistoryWindow)
+	if value := c.Query("from"); value != "" {
+		parsed, err := parseHistoryTime(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'from' parameter, expected RFC3339 or unix timestamp"})
+			return
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'from' must be before 'to'"})
+		return
+	}
+
+	resolution := c.DefaultQuery("resolution", models.ResolutionHour)
+	limit, ok := maxHistoryWindows[resolution]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resolution, expected raw, hour or day"})
+		return
+	}
+	if to.Sub(from) > limit.window {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Resolution " + resolution + " is limited to a " + limit.label + " window"})
+		return
+	}
+
+	service, err := h.db.GetServiceByInstanceID(instanceID)
+	if err != nil {
+		log.Error().Err(err).Str("service", instanceID).Msg("Failed to fetch service configuration")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service configuration"})
+		return
+	}
+
+	if service == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	history, err := h.db.GetHealthHistory(instanceID, from, to, resolution)
+	if err != nil {
+		log.Error().Err(err).Str("service", instanceID).Msg("Failed to fetch health history")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch health history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// parseHistoryTime parses either an RFC3339 timestamp or unix seconds
+func parseHistoryTime(value string) (time.Time, error) {
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+type mockHistoryStore struct{}
+
+func (mockHistoryStore) GetServiceByInstanceID(id string) (*models.ServiceConfiguration, error) {
+	return &models.ServiceConfiguration{InstanceID: id}, nil
+}
+
+func (mockHistoryStore) GetHealthHistory(instanceID string, from, to time.Time, resolution string) (*models.HealthHistory, error) {
+	return &models.HealthHistory{InstanceID: instanceID, From: from, To: to, Resolution: resolution}, nil
+}
+
+func TestHealthHistoryHandler_GetHistory_Windows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/api/health/:service/history", NewHealthHistoryHandler(mockHistoryStore{}).GetHistory)
+
+	day := 24 * time.Hour
+	tests := []struct {
+		name         string
+		resolution   string
+		window       time.Duration
+		expectedCode int
+	}{
+		{name: "raw within limit", resolution: "raw", window: day, expectedCode: http.StatusOK},
+		{name: "raw beyond limit", resolution: "raw", window: 8 * day, expectedCode: http.StatusBadRequest},
+		{name: "hour within limit", resolution: "hour", window: 7 * day, expectedCode: http.StatusOK},
+		{name: "hour beyond limit", resolution: "hour", window: 30 * day, expectedCode: http.StatusBadRequest},
+		{name: "day within limit", resolution: "day", window: 30 * day, expectedCode: http.StatusOK},
+		{name: "day beyond limit", resolution: "day", window: 90 * day, expectedCode: http.StatusBadRequest},
+		{name: "invalid resolution", resolution: "minute", window: day, expectedCode: http.StatusBadRequest},
+	}
+
+	to := time.Now()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := "/api/health/sonarr-1/history?resolution=" + tt.resolution +
+				"&from=" + strconv.FormatInt(to.Add(-tt.window).Unix(), 10) +
+				"&to=" + strconv.FormatInt(to.Unix(), 10)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+			assert.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+		})
+	}
+}
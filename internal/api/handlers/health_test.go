@@ -4,6 +4,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -15,22 +16,21 @@ import (
 
 	testing_mocks "github.com/autobrr/dashbrr/internal/api/handlers/testing"
 	"github.com/autobrr/dashbrr/internal/models"
-	"github.com/autobrr/dashbrr/internal/services"
 )
 
 // mockServiceHealthChecker implements models.ServiceHealthChecker interface for testing
 type mockServiceHealthChecker struct {
-	checkHealthFunc func(url, apiKey string) (models.ServiceHealth, int)
+	checkHealthFunc func(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error)
 }
 
-func (m *mockServiceHealthChecker) CheckHealth(url, apiKey string) (models.ServiceHealth, int) {
+func (m *mockServiceHealthChecker) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
 	if m.checkHealthFunc != nil {
-		return m.checkHealthFunc(url, apiKey)
+		return m.checkHealthFunc(ctx, service)
 	}
 	return models.ServiceHealth{
 		Status:      "healthy",
 		LastChecked: time.Now(),
-	}, http.StatusOK
+	}, nil
 }
 
 // mockServiceCreator implements models.ServiceCreator interface for testing
@@ -53,7 +53,7 @@ func TestHealthHandler_CheckHealth(t *testing.T) {
 		name           string
 		serviceID      string
 		mockDBResponse func(string) (*models.ServiceConfiguration, error)
-		mockHealth     func(url, apiKey string) (models.ServiceHealth, int)
+		mockHealth     func(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error)
 		expectedCode   int
 		expectedBody   gin.H
 	}{
@@ -100,15 +100,35 @@ func TestHealthHandler_CheckHealth(t *testing.T) {
 					APIKey:     "test-key",
 				}, nil
 			},
-			mockHealth: func(url, apiKey string) (models.ServiceHealth, int) {
+			mockHealth: func(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
 				return models.ServiceHealth{
 					Status:      "healthy",
 					LastChecked: time.Now(),
-				}, http.StatusOK
+				}, nil
 			},
 			expectedCode: http.StatusOK,
 			expectedBody: gin.H{"status": "healthy"},
 		},
+		{
+			name:      "Invalid API Key",
+			serviceID: "autobrr-service",
+			mockDBResponse: func(id string) (*models.ServiceConfiguration, error) {
+				return &models.ServiceConfiguration{
+					ID:         1,
+					InstanceID: "autobrr-service",
+					URL:        "http://localhost:8080",
+					APIKey:     "wrong-key",
+				}, nil
+			},
+			mockHealth: func(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
+				return models.ServiceHealth{
+					Status:      "error",
+					LastChecked: time.Now(),
+				}, models.NewHealthCheckError(http.StatusUnauthorized, "unauthorized")
+			},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: gin.H{"status": "error", "message": "Invalid API key"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -134,7 +154,7 @@ func TestHealthHandler_CheckHealth(t *testing.T) {
 			}
 
 			// Create the handler with our mocks
-			handler := NewHealthHandler(mockDB, services.NewHealthService(), mockCreator)
+			handler := NewHealthHandler(mockDB, mockCreator)
 
 			// Setup the router
 			r := gin.New()
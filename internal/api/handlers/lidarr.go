@@ -0,0 +1,218 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/cache"
+	"github.com/autobrr/dashbrr/internal/services/lidarr"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+const (
+	lidarrCacheDuration = 5 * time.Second
+	lidarrQueuePrefix   = "lidarr:queue:"
+	lidarrStatsPrefix   = "lidarr:stats:"
+)
+
+type LidarrHandler struct {
+	db    *database.DB
+	cache cache.Store
+}
+
+func NewLidarrHandler(db *database.DB, cache cache.Store) *LidarrHandler {
+	return &LidarrHandler{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// getConfig validates the instanceId query parameter and loads its configuration.
+// It writes the error response and returns nil when the request cannot proceed.
+func (h *LidarrHandler) getConfig(c *gin.Context) *models.ServiceConfiguration {
+	instanceId := c.Query("instanceId")
+	if instanceId == "" {
+		log.Error().Msg("No instanceId provided")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "instanceId is required"})
+		return nil
+	}
+
+	// Verify this is a Lidarr instance
+	if !strings.HasPrefix(instanceId, "lidarr") {
+		log.Error().Str("instanceId", instanceId).Msg("Invalid Lidarr instance ID")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Lidarr instance ID"})
+		return nil
+	}
+
+	lidarrConfig, err := h.db.GetServiceByInstanceID(instanceId)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to get Lidarr configuration")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Lidarr configuration"})
+		return nil
+	}
+
+	if lidarrConfig == nil {
+		log.Error().Str("instanceId", instanceId).Msg("Lidarr is not configured")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lidarr is not configured"})
+		return nil
+	}
+
+	if !resolveCredentials(c, lidarrConfig) {
+		return nil
+	}
+
+	return lidarrConfig
+}
+
+// writeLidarrError responds with the upstream status code when Lidarr returned one
+func writeLidarrError(c *gin.Context, err error, message string) {
+	if lidarrErr, ok := err.(*lidarr.ErrLidarr); ok && lidarrErr.HttpCode > 0 {
+		c.JSON(lidarrErr.HttpCode, gin.H{"error": lidarrErr.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+}
+
+func (h *LidarrHandler) GetQueue(c *gin.Context) {
+	instanceId := c.Query("instanceId")
+	cacheKey := lidarrQueuePrefix + instanceId
+	ctx := context.Background()
+
+	// Try to get from cache first
+	var queueResp types.LidarrQueueResponse
+	if instanceId != "" && h.cache.Get(ctx, cacheKey, &queueResp) == nil {
+		log.Debug().
+			Str("instanceId", instanceId).
+			Int("totalRecords", queueResp.TotalRecords).
+			Msg("Serving Lidarr queue from cache")
+		c.JSON(http.StatusOK, queueResp)
+		return
+	}
+
+	lidarrConfig := h.getConfig(c)
+	if lidarrConfig == nil {
+		return
+	}
+
+	service := &lidarr.LidarrService{}
+	queue, err := service.GetQueue(instanceContext(c, lidarrConfig), lidarrConfig.URL, lidarrConfig.APIKey)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to fetch Lidarr queue")
+		writeLidarrError(c, err, "Failed to fetch queue")
+		return
+	}
+
+	// Cache the results
+	if err := h.cache.Set(ctx, cacheKey, queue, lidarrCacheDuration); err != nil {
+		log.Warn().
+			Err(err).
+			Str("instanceId", instanceId).
+			Msg("Failed to cache Lidarr queue")
+	}
+
+	log.Debug().
+		Str("instanceId", instanceId).
+		Int("totalRecords", queue.TotalRecords).
+		Msg("Successfully retrieved and cached Lidarr queue")
+
+	c.JSON(http.StatusOK, queue)
+}
+
+func (h *LidarrHandler) GetStats(c *gin.Context) {
+	instanceId := c.Query("instanceId")
+	cacheKey := lidarrStatsPrefix + instanceId
+	ctx := context.Background()
+
+	// Try to get from cache first
+	var statsResp types.LidarrStatsResponse
+	if instanceId != "" && h.cache.Get(ctx, cacheKey, &statsResp) == nil {
+		log.Debug().
+			Str("instanceId", instanceId).
+			Int("artists", statsResp.ArtistCount).
+			Msg("Serving Lidarr stats from cache")
+		c.JSON(http.StatusOK, statsResp)
+		return
+	}
+
+	lidarrConfig := h.getConfig(c)
+	if lidarrConfig == nil {
+		return
+	}
+
+	service := &lidarr.LidarrService{}
+	stats, err := service.GetStats(instanceContext(c, lidarrConfig), lidarrConfig.URL, lidarrConfig.APIKey)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to fetch Lidarr stats")
+		writeLidarrError(c, err, "Failed to fetch stats")
+		return
+	}
+
+	// Cache the results
+	if err := h.cache.Set(ctx, cacheKey, stats, lidarrCacheDuration); err != nil {
+		log.Warn().
+			Err(err).
+			Str("instanceId", instanceId).
+			Msg("Failed to cache Lidarr stats")
+	}
+
+	log.Debug().
+		Str("instanceId", instanceId).
+		Int("artists", stats.ArtistCount).
+		Msg("Successfully retrieved and cached Lidarr stats")
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// DeleteQueueItem handles the deletion of a queue item with specified options
+func (h *LidarrHandler) DeleteQueueItem(c *gin.Context) {
+	queueId := c.Param("id")
+	if queueId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "queue item id is required"})
+		return
+	}
+
+	lidarrConfig := h.getConfig(c)
+	if lidarrConfig == nil {
+		return
+	}
+
+	// Get options from query parameters
+	options := types.LidarrQueueDeleteOptions{
+		RemoveFromClient: c.Query("removeFromClient") == "true",
+		Blocklist:        c.Query("blocklist") == "true",
+		SkipRedownload:   c.Query("skipRedownload") == "true",
+		ChangeCategory:   c.Query("changeCategory") == "true",
+	}
+
+	service := &lidarr.LidarrService{}
+	if err := service.DeleteQueueItem(instanceContext(c, lidarrConfig), lidarrConfig.URL, lidarrConfig.APIKey, queueId, options); err != nil {
+		log.Error().
+			Err(err).
+			Str("instanceId", lidarrConfig.InstanceID).
+			Str("queueId", queueId).
+			Msg("Failed to delete queue item")
+		writeLidarrError(c, err, "Failed to delete queue item")
+		return
+	}
+
+	// Clear cache after successful deletion
+	if err := h.cache.Delete(context.Background(), lidarrQueuePrefix+lidarrConfig.InstanceID); err != nil {
+		log.Warn().
+			Err(err).
+			Str("instanceId", lidarrConfig.InstanceID).
+			Msg("Failed to clear Lidarr queue cache")
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Queue item deleted successfully"})
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
 	"github.com/autobrr/dashbrr/internal/services/cache"
 	"github.com/autobrr/dashbrr/internal/services/maintainerr"
 )
@@ -90,6 +91,16 @@ func (h *MaintainerrHandler) GetMaintainerrCollections(c *gin.Context) {
 		return
 	}
 
+	maintainerrConfig, ok := loadCredentials(c, h.db, instanceId)
+	if !ok {
+		return
+	}
+	if maintainerrConfig == nil || maintainerrConfig.URL == "" {
+		// Return empty response for unconfigured service
+		c.JSON(http.StatusOK, []maintainerr.Collection{})
+		return
+	}
+
 	cacheKey := cachePrefix + instanceId
 	ctx := context.Background()
 
@@ -104,19 +115,13 @@ func (h *MaintainerrHandler) GetMaintainerrCollections(c *gin.Context) {
 		c.JSON(http.StatusOK, collections)
 
 		// Refresh cache in background if needed
-		go h.refreshCollectionsCache(instanceId, cacheKey)
+		go h.refreshCollectionsCache(*maintainerrConfig, cacheKey)
 		return
 	}
 
 	// If not in cache, fetch from service
-	collections, err = h.fetchAndCacheCollections(instanceId, cacheKey)
+	collections, err = h.fetchAndCacheCollections(*maintainerrConfig, cacheKey)
 	if err != nil {
-		if err.Error() == "service not configured" {
-			// Return empty response for unconfigured service
-			c.JSON(http.StatusOK, []maintainerr.Collection{})
-			return
-		}
-
 		status, message := determineErrorResponse(err)
 		log.Error().
 			Err(err).
@@ -140,19 +145,10 @@ func (h *MaintainerrHandler) GetMaintainerrCollections(c *gin.Context) {
 	c.JSON(http.StatusOK, collections)
 }
 
-func (h *MaintainerrHandler) fetchAndCacheCollections(instanceId, cacheKey string) ([]maintainerr.Collection, error) {
+func (h *MaintainerrHandler) fetchAndCacheCollections(maintainerrConfig models.ServiceConfiguration, cacheKey string) ([]maintainerr.Collection, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
 
-	maintainerrConfig, err := h.db.GetServiceByInstanceID(instanceId)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get service config: %w", err)
-	}
-
-	if maintainerrConfig == nil || maintainerrConfig.URL == "" {
-		return nil, fmt.Errorf("service not configured")
-	}
-
 	service := &maintainerr.MaintainerrService{}
 	collections, err := service.GetCollections(maintainerrConfig.URL, maintainerrConfig.APIKey)
 	if err != nil {
@@ -163,28 +159,28 @@ func (h *MaintainerrHandler) fetchAndCacheCollections(instanceId, cacheKey strin
 	if err := h.cache.Set(ctx, cacheKey, collections, cacheDuration); err != nil {
 		log.Warn().
 			Err(err).
-			Str("instanceId", instanceId).
+			Str("instanceId", maintainerrConfig.InstanceID).
 			Msg("Failed to cache Maintainerr collections")
 	}
 
 	return collections, nil
 }
 
-func (h *MaintainerrHandler) refreshCollectionsCache(instanceId, cacheKey string) {
+func (h *MaintainerrHandler) refreshCollectionsCache(maintainerrConfig models.ServiceConfiguration, cacheKey string) {
+	instanceId := maintainerrConfig.InstanceID
+
 	// Add a small delay to prevent immediate refresh
 	time.Sleep(100 * time.Millisecond)
 
-	collections, err := h.fetchAndCacheCollections(instanceId, cacheKey)
+	collections, err := h.fetchAndCacheCollections(maintainerrConfig, cacheKey)
 	if err != nil {
-		if err.Error() != "service not configured" {
-			status, message := determineErrorResponse(err)
-			log.Error().
-				Err(err).
-				Str("instanceId", instanceId).
-				Int("status", status).
-				Str("message", message).
-				Msg("Failed to refresh Maintainerr collections cache")
-		}
+		status, message := determineErrorResponse(err)
+		log.Error().
+			Err(err).
+			Str("instanceId", instanceId).
+			Int("status", status).
+			Str("message", message).
+			Msg("Failed to refresh Maintainerr collections cache")
 		return
 	}
 
@@ -0,0 +1,198 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/cache"
+	"github.com/autobrr/dashbrr/internal/services/mediaserver"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+const (
+	mediaServerSessionsCacheDuration = 5 * time.Second
+	mediaServerStatsCacheDuration    = 60 * time.Second
+)
+
+// MediaServerHandler serves the Jellyfin and Emby endpoints, which share one API
+type MediaServerHandler struct {
+	db         *database.DB
+	cache      cache.Store
+	serverType string
+	name       string
+}
+
+func NewJellyfinHandler(db *database.DB, cache cache.Store) *MediaServerHandler {
+	return newMediaServerHandler(db, cache, "jellyfin", "Jellyfin")
+}
+
+func NewEmbyHandler(db *database.DB, cache cache.Store) *MediaServerHandler {
+	return newMediaServerHandler(db, cache, "emby", "Emby")
+}
+
+func newMediaServerHandler(db *database.DB, cache cache.Store, serverType, name string) *MediaServerHandler {
+	return &MediaServerHandler{
+		db:         db,
+		cache:      cache,
+		serverType: serverType,
+		name:       name,
+	}
+}
+
+// getConfig validates the instanceId query parameter and loads its configuration.
+// It writes the error response and returns nil when the request cannot proceed.
+func (h *MediaServerHandler) getConfig(c *gin.Context) *models.ServiceConfiguration {
+	instanceId := c.Query("instanceId")
+	if instanceId == "" {
+		log.Error().Msg("No instanceId provided")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "instanceId is required"})
+		return nil
+	}
+
+	if !strings.HasPrefix(instanceId, h.serverType) {
+		log.Error().Str("instanceId", instanceId).Str("type", h.serverType).Msg("Invalid media server instance ID")
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s instance ID", h.name)})
+		return nil
+	}
+
+	config, err := h.db.GetServiceByInstanceID(instanceId)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to get media server configuration")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get %s configuration", h.name)})
+		return nil
+	}
+
+	if config == nil {
+		log.Error().Str("instanceId", instanceId).Msg("Media server is not configured")
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("%s is not configured", h.name)})
+		return nil
+	}
+
+	if !resolveCredentials(c, config) {
+		return nil
+	}
+
+	return config
+}
+
+// service returns the registered implementation of the handler's server type
+func (h *MediaServerHandler) service() mediaserver.Server {
+	server, _ := models.NewServiceRegistry().CreateService(h.serverType).(mediaserver.Server)
+	return server
+}
+
+// writeMediaServerError responds with the upstream status code when the server returned one
+func writeMediaServerError(c *gin.Context, err error, message string) {
+	if serverErr, ok := err.(*mediaserver.ErrMediaServer); ok && serverErr.HttpCode > 0 {
+		c.JSON(serverErr.HttpCode, gin.H{"error": serverErr.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+}
+
+func (h *MediaServerHandler) GetSessions(c *gin.Context) {
+	config := h.getConfig(c)
+	if config == nil {
+		return
+	}
+
+	cacheKey := h.serverType + ":sessions:" + config.InstanceID
+	ctx := context.Background()
+
+	// Try to get from cache first
+	var sessions types.MediaSessionsResponse
+	if err := h.cache.Get(ctx, cacheKey, &sessions); err == nil {
+		log.Debug().
+			Str("instanceId", config.InstanceID).
+			Int("size", sessions.Size).
+			Msg("Serving media server sessions from cache")
+		c.JSON(http.StatusOK, sessions)
+		return
+	}
+
+	server := h.service()
+	if server == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s support is not available", h.name)})
+		return
+	}
+
+	result, err := server.GetSessions(instanceContext(c, config), config.URL, config.APIKey)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", config.InstanceID).Msg("Failed to fetch media server sessions")
+		writeMediaServerError(c, err, "Failed to fetch sessions")
+		return
+	}
+
+	if err := h.cache.Set(ctx, cacheKey, result, mediaServerSessionsCacheDuration); err != nil {
+		log.Warn().
+			Err(err).
+			Str("instanceId", config.InstanceID).
+			Msg("Failed to cache media server sessions")
+	}
+
+	log.Debug().
+		Str("instanceId", config.InstanceID).
+		Int("size", result.Size).
+		Msg("Successfully retrieved and cached media server sessions")
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *MediaServerHandler) GetStats(c *gin.Context) {
+	config := h.getConfig(c)
+	if config == nil {
+		return
+	}
+
+	cacheKey := h.serverType + ":stats:" + config.InstanceID
+	ctx := context.Background()
+
+	// Try to get from cache first
+	var stats types.MediaServerStats
+	if err := h.cache.Get(ctx, cacheKey, &stats); err == nil {
+		log.Debug().
+			Str("instanceId", config.InstanceID).
+			Msg("Serving media server stats from cache")
+		c.JSON(http.StatusOK, stats)
+		return
+	}
+
+	server := h.service()
+	if server == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s support is not available", h.name)})
+		return
+	}
+
+	result, err := server.GetStats(instanceContext(c, config), config.URL, config.APIKey)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", config.InstanceID).Msg("Failed to fetch media server stats")
+		writeMediaServerError(c, err, "Failed to fetch stats")
+		return
+	}
+
+	if err := h.cache.Set(ctx, cacheKey, result, mediaServerStatsCacheDuration); err != nil {
+		log.Warn().
+			Err(err).
+			Str("instanceId", config.InstanceID).
+			Msg("Failed to cache media server stats")
+	}
+
+	log.Debug().
+		Str("instanceId", config.InstanceID).
+		Int("runningTasks", result.RunningTasks).
+		Int("failedTasks", result.FailedTasks).
+		Msg("Successfully retrieved and cached media server stats")
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/metrics"
+)
+
+type MetricsHandler struct {
+	token     string
+	public    bool
+	collector *metrics.ServiceCollector
+	handler   http.Handler
+}
+
+// NewMetricsHandler creates the Prometheus metrics handler. Service statistics are
+// fetched from the services stored in db at most once per statsInterval. Scrapes must
+// send token as a bearer token; without a token the endpoint is disabled unless public
+// is set to allow unauthenticated scraping.
+func NewMetricsHandler(db metrics.ServiceStore, token string, public bool, statsInterval time.Duration) *MetricsHandler {
+	collector := metrics.NewServiceCollector(db, statsInterval)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	gatherers := prometheus.Gatherers{metrics.Registry, registry}
+
+	return &MetricsHandler{
+		token:     token,
+		public:    public,
+		collector: collector,
+		handler:   promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{ErrorLog: &promLogger{}}),
+	}
+}
+
+// ServeMetrics exposes all metrics in the Prometheus text format
+func (h *MetricsHandler) ServeMetrics(c *gin.Context) {
+	if h.token == "" && !h.public {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Metrics are disabled, set DASHBRR__METRICS_TOKEN to enable them"})
+		return
+	}
+
+	if h.token != "" {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+	}
+
+	// Upstream requests are cancelled with the scrape
+	h.collector.Refresh(c.Request.Context())
+	h.handler.ServeHTTP(c.Writer, c.Request)
+}
+
+// promLogger forwards errors from the Prometheus handler to zerolog
+type promLogger struct{}
+
+func (l *promLogger) Println(v ...interface{}) {
+	log.Error().Msg("Metrics: " + fmt.Sprint(v...))
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	testing_mocks "github.com/autobrr/dashbrr/internal/api/handlers/testing"
+	"github.com/autobrr/dashbrr/internal/metrics"
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func TestMetricsHandler_ServeMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sonarrServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/queue" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"page":1,"pageSize":1,"totalRecords":7,"records":[]}`))
+	}))
+	defer sonarrServer.Close()
+
+	db := &testing_mocks.MockDB{
+		GetAllServicesFunc: func() ([]models.ServiceConfiguration, error) {
+			return []models.ServiceConfiguration{
+				{InstanceID: "sonarr-metrics", URL: sonarrServer.URL, APIKey: "key"},
+			}, nil
+		},
+	}
+
+	metrics.ObserveHealth(models.ServiceHealth{ServiceID: "sonarr-metrics", Status: "online", ResponseTime: 250, UpdateAvailable: true})
+
+	tests := []struct {
+		name         string
+		token        string
+		public       bool
+		auth         string
+		expectedCode int
+	}{
+		{name: "missing token", token: "secret", expectedCode: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", auth: "Bearer nope", expectedCode: http.StatusUnauthorized},
+		{name: "valid token", token: "secret", auth: "Bearer secret", expectedCode: http.StatusOK},
+		{name: "no token configured", expectedCode: http.StatusForbidden},
+		{name: "public without token", public: true, expectedCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/metrics", NewMetricsHandler(db, tt.token, tt.public, time.Minute).ServeMetrics)
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+
+			body := w.Body.String()
+			assert.Contains(t, body, `dashbrr_service_up{instance="sonarr-metrics",type="sonarr"} 1`)
+			assert.Contains(t, body, `dashbrr_service_response_time_seconds{instance="sonarr-metrics",type="sonarr"} 0.25`)
+			assert.Contains(t, body, `dashbrr_service_status{instance="sonarr-metrics",status="offline",type="sonarr"} 0`)
+			assert.Contains(t, body, `dashbrr_service_update_available{instance="sonarr-metrics",type="sonarr"} 1`)
+			assert.Contains(t, body, `dashbrr_queue_items{instance="sonarr-metrics",type="sonarr"} 7`)
+			assert.Contains(t, body, `dashbrr_stats_scrape_success{instance="sonarr-metrics",type="sonarr"} 1`)
+			assert.Contains(t, body, "go_goroutines")
+		})
+	}
+}
@@ -56,6 +56,16 @@ func (h *OmegabrrHandler) GetOmegabrrStatus(c *gin.Context) {
 		return
 	}
 
+	omegabrrConfig, ok := loadCredentials(c, h.db, instanceId)
+	if !ok {
+		return
+	}
+	if omegabrrConfig == nil {
+		log.Error().Str("instanceId", instanceId).Msg("Omegabrr is not configured")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Omegabrr is not configured"})
+		return
+	}
+
 	cacheKey := omegabrrStatusPrefix + instanceId
 	ctx := context.Background()
 
@@ -69,12 +79,12 @@ func (h *OmegabrrHandler) GetOmegabrrStatus(c *gin.Context) {
 		c.JSON(http.StatusOK, health)
 
 		// Refresh cache in background if needed
-		go h.refreshStatusCache(instanceId, cacheKey)
+		go h.refreshStatusCache(*omegabrrConfig, cacheKey)
 		return
 	}
 
 	// If not in cache, fetch from service
-	health, err = h.fetchAndCacheStatus(instanceId, cacheKey)
+	health, err = h.fetchAndCacheStatus(*omegabrrConfig, cacheKey)
 	if err != nil {
 		status := http.StatusInternalServerError
 		if err == context.DeadlineExceeded || err == context.Canceled {
@@ -94,42 +104,35 @@ func (h *OmegabrrHandler) GetOmegabrrStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, health)
 }
 
-func (h *OmegabrrHandler) fetchAndCacheStatus(instanceId, cacheKey string) (models.ServiceHealth, error) {
-	omegabrrConfig, err := h.db.GetServiceByInstanceID(instanceId)
-	if err != nil {
-		return models.ServiceHealth{}, err
-	}
-
-	if omegabrrConfig == nil {
-		return models.ServiceHealth{}, fmt.Errorf("omegabrr is not configured")
-	}
-
+func (h *OmegabrrHandler) fetchAndCacheStatus(omegabrrConfig models.ServiceConfiguration, cacheKey string) (models.ServiceHealth, error) {
 	service := &omegabrr.OmegabrrService{
 		ServiceCore: core.ServiceCore{},
 	}
 
-	health, statusCode := service.CheckHealth(omegabrrConfig.URL, omegabrrConfig.APIKey)
-	if statusCode != http.StatusOK {
+	ctx := context.Background()
+	health, err := service.CheckHealth(ctx, omegabrrConfig)
+	if err != nil {
 		return models.ServiceHealth{}, fmt.Errorf("failed to get status")
 	}
 
 	// Cache the results
-	ctx := context.Background()
 	if err := h.cache.Set(ctx, cacheKey, health, omegabrrCacheDuration); err != nil {
 		log.Warn().
 			Err(err).
-			Str("instanceId", instanceId).
+			Str("instanceId", omegabrrConfig.InstanceID).
 			Msg("Failed to cache Omegabrr status")
 	}
 
 	return health, nil
 }
 
-func (h *OmegabrrHandler) refreshStatusCache(instanceId, cacheKey string) {
+func (h *OmegabrrHandler) refreshStatusCache(omegabrrConfig models.ServiceConfiguration, cacheKey string) {
+	instanceId := omegabrrConfig.InstanceID
+
 	// Add a small delay to prevent immediate refresh
 	time.Sleep(100 * time.Millisecond)
 
-	_, err := h.fetchAndCacheStatus(instanceId, cacheKey)
+	_, err := h.fetchAndCacheStatus(omegabrrConfig, cacheKey)
 	if err != nil {
 		log.Error().
 			Err(err).
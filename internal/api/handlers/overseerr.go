@@ -14,28 +14,51 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/alerts"
 	"github.com/autobrr/dashbrr/internal/services/cache"
 	"github.com/autobrr/dashbrr/internal/services/overseerr"
 	"github.com/autobrr/dashbrr/internal/types"
 )
 
-const (
-	overseerrCacheDuration = 5 * time.Minute
-	overseerrCachePrefix   = "overseerr:requests:"
-)
+const overseerrCacheDuration = 5 * time.Minute
 
+// OverseerrHandler serves the Overseerr and Jellyseerr endpoints
 type OverseerrHandler struct {
-	db    *database.DB
-	cache cache.Store
+	db      *database.DB
+	cache   cache.Store
+	alerts  *alerts.Engine
+	variant overseerr.Variant
+}
+
+func NewOverseerrHandler(db *database.DB, cache cache.Store, alertEngine *alerts.Engine) *OverseerrHandler {
+	return newOverseerrHandler(db, cache, alertEngine, overseerr.VariantOverseerr)
+}
+
+func NewJellyseerrHandler(db *database.DB, cache cache.Store, alertEngine *alerts.Engine) *OverseerrHandler {
+	return newOverseerrHandler(db, cache, alertEngine, overseerr.VariantJellyseerr)
 }
 
-func NewOverseerrHandler(db *database.DB, cache cache.Store) *OverseerrHandler {
+func newOverseerrHandler(db *database.DB, cache cache.Store, alertEngine *alerts.Engine, variant overseerr.Variant) *OverseerrHandler {
 	return &OverseerrHandler{
-		db:    db,
-		cache: cache,
+		db:      db,
+		cache:   cache,
+		alerts:  alertEngine,
+		variant: variant,
 	}
 }
 
+func (h *OverseerrHandler) cacheKey(instanceId string) string {
+	return string(h.variant) + ":requests:" + instanceId
+}
+
+// service creates a client for the handler's variant
+func (h *OverseerrHandler) service() *overseerr.OverseerrService {
+	service := overseerr.NewService(h.variant)
+	service.SetDB(h.db) // Set the database instance for fetching Radarr/Sonarr configs
+	return service
+}
+
 func (h *OverseerrHandler) UpdateRequestStatus(c *gin.Context) {
 	instanceId := c.Param("instanceId")
 	requestId := c.Param("requestId")
@@ -89,12 +112,12 @@ func (h *OverseerrHandler) UpdateRequestStatus(c *gin.Context) {
 		return
 	}
 
-	// Create Overseerr service instance
-	service := &overseerr.OverseerrService{}
-	service.SetDB(h.db)
+	if !resolveCredentials(c, overseerrConfig) {
+		return
+	}
 
 	// Update request status
-	if err := service.UpdateRequestStatus(overseerrConfig.URL, overseerrConfig.APIKey, reqID, approve); err != nil {
+	if err := h.service().UpdateRequestStatus(overseerrConfig.URL, overseerrConfig.APIKey, reqID, approve); err != nil {
 		log.Error().Err(err).
 			Str("instanceId", instanceId).
 			Int("requestId", reqID).
@@ -105,7 +128,7 @@ func (h *OverseerrHandler) UpdateRequestStatus(c *gin.Context) {
 	}
 
 	// Clear the cache for this instance to force a refresh
-	cacheKey := overseerrCachePrefix + instanceId
+	cacheKey := h.cacheKey(instanceId)
 	if err := h.cache.Delete(context.Background(), cacheKey); err != nil {
 		log.Warn().Err(err).Str("instanceId", instanceId).Msg("Failed to clear cache after status update")
 	}
@@ -121,7 +144,20 @@ func (h *OverseerrHandler) GetRequests(c *gin.Context) {
 		return
 	}
 
-	cacheKey := overseerrCachePrefix + instanceId
+	overseerrConfig, ok := loadCredentials(c, h.db, instanceId)
+	if !ok {
+		return
+	}
+	if overseerrConfig == nil || overseerrConfig.URL == "" {
+		// Return empty response for unconfigured service
+		c.JSON(http.StatusOK, &types.RequestsStats{
+			PendingCount: 0,
+			Requests:     []types.MediaRequest{},
+		})
+		return
+	}
+
+	cacheKey := h.cacheKey(instanceId)
 	ctx := context.Background()
 
 	// Try to get from cache first
@@ -132,32 +168,24 @@ func (h *OverseerrHandler) GetRequests(c *gin.Context) {
 			Str("instanceId", instanceId).
 			Int("pendingCount", response.PendingCount).
 			Int("totalRequests", len(response.Requests)).
-			Msg("Serving Overseerr requests from cache")
+			Str("variant", string(h.variant)).
+			Msg("Serving requests from cache")
 		c.JSON(http.StatusOK, response)
 
 		// Refresh cache in background if needed
-		go h.refreshRequestsCache(instanceId, cacheKey)
+		go h.refreshRequestsCache(*overseerrConfig, cacheKey)
 		return
 	}
 
 	// If not in cache, fetch from service
-	stats, err := h.fetchAndCacheRequests(instanceId, cacheKey)
+	stats, err := h.fetchAndCacheRequests(*overseerrConfig, cacheKey)
 	if err != nil {
-		if err.Error() == "service not configured" {
-			// Return empty response for unconfigured service
-			c.JSON(http.StatusOK, &types.RequestsStats{
-				PendingCount: 0,
-				Requests:     []types.MediaRequest{},
-			})
-			return
-		}
-
 		status := http.StatusInternalServerError
 		if err == context.DeadlineExceeded || err == context.Canceled {
 			status = http.StatusGatewayTimeout
-			log.Error().Err(err).Str("instanceId", instanceId).Msg("Request timeout while fetching Overseerr requests")
+			log.Error().Err(err).Str("instanceId", instanceId).Msg("Request timeout while fetching requests")
 		} else {
-			log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to fetch Overseerr requests")
+			log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to fetch requests")
 		}
 		c.JSON(status, gin.H{"error": err.Error()})
 		return
@@ -167,27 +195,20 @@ func (h *OverseerrHandler) GetRequests(c *gin.Context) {
 		Str("instanceId", instanceId).
 		Int("pendingCount", stats.PendingCount).
 		Int("totalRequests", len(stats.Requests)).
-		Msg("Successfully retrieved and cached Overseerr requests")
+		Msg("Successfully retrieved and cached requests")
 
 	c.JSON(http.StatusOK, stats)
 }
 
-func (h *OverseerrHandler) fetchAndCacheRequests(instanceId, cacheKey string) (*types.RequestsStats, error) {
-	overseerrConfig, err := h.db.GetServiceByInstanceID(instanceId)
+func (h *OverseerrHandler) fetchAndCacheRequests(overseerrConfig models.ServiceConfiguration, cacheKey string) (*types.RequestsStats, error) {
+	instanceId := overseerrConfig.InstanceID
+	stats, err := h.service().GetRequests(overseerrConfig.URL, overseerrConfig.APIKey)
 	if err != nil {
 		return nil, err
 	}
 
-	if overseerrConfig == nil || overseerrConfig.URL == "" {
-		return nil, fmt.Errorf("service not configured")
-	}
-
-	service := &overseerr.OverseerrService{}
-	service.SetDB(h.db) // Set the database instance for fetching Radarr/Sonarr configs
-
-	stats, err := service.GetRequests(overseerrConfig.URL, overseerrConfig.APIKey)
-	if err != nil {
-		return nil, err
+	if h.alerts != nil {
+		h.alerts.ProcessRequests(instanceId, stats.PendingCount)
 	}
 
 	// Cache the results
@@ -196,22 +217,24 @@ func (h *OverseerrHandler) fetchAndCacheRequests(instanceId, cacheKey string) (*
 		log.Warn().
 			Err(err).
 			Str("instanceId", instanceId).
-			Msg("Failed to cache Overseerr requests")
+			Msg("Failed to cache requests")
 	}
 
 	return stats, nil
 }
 
-func (h *OverseerrHandler) refreshRequestsCache(instanceId, cacheKey string) {
+func (h *OverseerrHandler) refreshRequestsCache(overseerrConfig models.ServiceConfiguration, cacheKey string) {
+	instanceId := overseerrConfig.InstanceID
+
 	// Add a small delay to prevent immediate refresh
 	time.Sleep(100 * time.Millisecond)
 
-	stats, err := h.fetchAndCacheRequests(instanceId, cacheKey)
-	if err != nil && err.Error() != "service not configured" {
+	stats, err := h.fetchAndCacheRequests(overseerrConfig, cacheKey)
+	if err != nil {
 		log.Error().
 			Err(err).
 			Str("instanceId", instanceId).
-			Msg("Failed to refresh Overseerr requests cache")
+			Msg("Failed to refresh requests cache")
 		return
 	}
 
@@ -219,5 +242,5 @@ func (h *OverseerrHandler) refreshRequestsCache(instanceId, cacheKey string) {
 		Str("instanceId", instanceId).
 		Int("pendingCount", stats.PendingCount).
 		Int("totalRequests", len(stats.Requests)).
-		Msg("Successfully refreshed Overseerr requests cache")
+		Msg("Successfully refreshed requests cache")
 }
@@ -5,7 +5,6 @@ package handlers
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"time"
 
@@ -13,6 +12,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
 	"github.com/autobrr/dashbrr/internal/services/cache"
 	"github.com/autobrr/dashbrr/internal/services/plex"
 	"github.com/autobrr/dashbrr/internal/types"
@@ -50,6 +50,19 @@ func (h *PlexHandler) GetPlexSessions(c *gin.Context) {
 		return
 	}
 
+	plexConfig, ok := loadCredentials(c, h.db, instanceId)
+	if !ok {
+		return
+	}
+	if plexConfig == nil || plexConfig.URL == "" {
+		// Return empty response for unconfigured service
+		emptyResponse := &types.PlexSessionsResponse{}
+		emptyResponse.MediaContainer.Size = 0
+		emptyResponse.MediaContainer.Metadata = []types.PlexSession{}
+		c.JSON(http.StatusOK, emptyResponse)
+		return
+	}
+
 	cacheKey := plexCachePrefix + instanceId
 	ctx := context.Background()
 
@@ -64,22 +77,13 @@ func (h *PlexHandler) GetPlexSessions(c *gin.Context) {
 		c.JSON(http.StatusOK, sessions)
 
 		// Refresh cache in background without delay
-		go h.refreshSessionsCache(instanceId, cacheKey)
+		go h.refreshSessionsCache(*plexConfig, cacheKey)
 		return
 	}
 
 	// If not in cache or invalid cache data, fetch from service
-	sessions, err = h.fetchAndCacheSessions(instanceId, cacheKey)
+	sessions, err = h.fetchAndCacheSessions(*plexConfig, cacheKey)
 	if err != nil {
-		if err.Error() == "service not configured" {
-			// Return empty response for unconfigured service
-			emptyResponse := &types.PlexSessionsResponse{}
-			emptyResponse.MediaContainer.Size = 0
-			emptyResponse.MediaContainer.Metadata = []types.PlexSession{}
-			c.JSON(http.StatusOK, emptyResponse)
-			return
-		}
-
 		status := http.StatusInternalServerError
 		if err == context.DeadlineExceeded || err == context.Canceled {
 			status = http.StatusGatewayTimeout
@@ -105,16 +109,7 @@ func (h *PlexHandler) GetPlexSessions(c *gin.Context) {
 	c.JSON(http.StatusOK, sessions)
 }
 
-func (h *PlexHandler) fetchAndCacheSessions(instanceId, cacheKey string) (*types.PlexSessionsResponse, error) {
-	plexConfig, err := h.db.GetServiceByInstanceID(instanceId)
-	if err != nil {
-		return nil, err
-	}
-
-	if plexConfig == nil || plexConfig.URL == "" {
-		return nil, fmt.Errorf("service not configured")
-	}
-
+func (h *PlexHandler) fetchAndCacheSessions(plexConfig models.ServiceConfiguration, cacheKey string) (*types.PlexSessionsResponse, error) {
 	service := &plex.PlexService{}
 	sessions, err := service.GetSessions(plexConfig.URL, plexConfig.APIKey)
 	if err != nil {
@@ -135,16 +130,17 @@ func (h *PlexHandler) fetchAndCacheSessions(instanceId, cacheKey string) (*types
 	if err := h.cache.Set(ctx, cacheKey, sessions, plexCacheDuration); err != nil {
 		log.Warn().
 			Err(err).
-			Str("instanceId", instanceId).
+			Str("instanceId", plexConfig.InstanceID).
 			Msg("Failed to cache Plex sessions")
 	}
 
 	return sessions, nil
 }
 
-func (h *PlexHandler) refreshSessionsCache(instanceId, cacheKey string) {
-	sessions, err := h.fetchAndCacheSessions(instanceId, cacheKey)
-	if err != nil && err.Error() != "service not configured" {
+func (h *PlexHandler) refreshSessionsCache(plexConfig models.ServiceConfiguration, cacheKey string) {
+	instanceId := plexConfig.InstanceID
+	sessions, err := h.fetchAndCacheSessions(plexConfig, cacheKey)
+	if err != nil {
 		log.Error().
 			Err(err).
 			Str("instanceId", instanceId).
@@ -15,6 +15,7 @@ import (
 
 	"github.com/autobrr/dashbrr/internal/database"
 	"github.com/autobrr/dashbrr/internal/services/cache"
+	"github.com/autobrr/dashbrr/internal/services/core"
 	"github.com/autobrr/dashbrr/internal/types"
 )
 
@@ -80,12 +81,23 @@ func (h *ProwlarrHandler) GetStats(c *gin.Context) {
 		return
 	}
 
+	if !resolveCredentials(c, prowlarrConfig) {
+		return
+	}
+
 	// Build Prowlarr API URL
 	apiURL := fmt.Sprintf("%s/api/v1/system/status?apikey=%s", prowlarrConfig.URL, prowlarrConfig.APIKey)
 
 	// Make request to Prowlarr
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(apiURL)
+	reqCtx, cancel := context.WithTimeout(instanceContext(c, prowlarrConfig), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to create Prowlarr request")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Prowlarr request"})
+		return
+	}
+	resp, err := core.Do(req)
 	if err != nil {
 		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to fetch Prowlarr stats")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch Prowlarr stats"})
@@ -175,12 +187,23 @@ func (h *ProwlarrHandler) GetIndexers(c *gin.Context) {
 		return
 	}
 
+	if !resolveCredentials(c, prowlarrConfig) {
+		return
+	}
+
 	// Build Prowlarr API URL
 	apiURL := fmt.Sprintf("%s/api/v1/indexer?apikey=%s", prowlarrConfig.URL, prowlarrConfig.APIKey)
 
 	// Make request to Prowlarr
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(apiURL)
+	reqCtx, cancel := context.WithTimeout(instanceContext(c, prowlarrConfig), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to create Prowlarr request")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Prowlarr request"})
+		return
+	}
+	resp, err := core.Do(req)
 	if err != nil {
 		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to fetch Prowlarr indexers")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch Prowlarr indexers"})
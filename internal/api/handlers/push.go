@@ -0,0 +1,107 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/push"
+	"github.com/autobrr/dashbrr/internal/services/scheduler"
+)
+
+// PushStore defines the database operations needed by PushHandler
+type PushStore interface {
+	GetServiceByURL(url string) (*models.ServiceConfiguration, error)
+}
+
+type PushHandler struct {
+	db        PushStore
+	scheduler *scheduler.Scheduler
+}
+
+func NewPushHandler(db PushStore, checks *scheduler.Scheduler) *PushHandler {
+	return &PushHandler{
+		db:        db,
+		scheduler: checks,
+	}
+}
+
+// pushRequest is the optional heartbeat payload, sent as query parameters
+// or JSON body in the style of Uptime Kuma push monitors
+type pushRequest struct {
+	Status  string `form:"status" json:"status"`
+	Message string `form:"msg" json:"msg"`
+	Ping    int64  `form:"ping" json:"ping"`
+}
+
+// Heartbeat records a heartbeat for the push service owning the token and
+// publishes the resulting health like a scheduled check
+func (h *PushHandler) Heartbeat(c *gin.Context) {
+	token := c.Param("token")
+
+	service, err := h.db.GetServiceByURL(push.PathPrefix + token)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to look up push service")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up push service"})
+		return
+	}
+	if service == nil || models.ServiceTypeFromInstanceID(service.InstanceID) != "push" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown push token"})
+		return
+	}
+
+	var req pushRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	// The body is optional and takes precedence over the query parameters
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.Status == "" {
+		req.Status = push.StatusUp
+	}
+	if req.Status != push.StatusUp && req.Status != push.StatusDown {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be up or down"})
+		return
+	}
+
+	push.RecordHeartbeat(token, push.Heartbeat{
+		Status:  req.Status,
+		Message: req.Message,
+		Ping:    req.Ping,
+	})
+
+	log.Debug().
+		Str("instanceId", service.InstanceID).
+		Str("status", req.Status).
+		Msg("Received push heartbeat")
+
+	if h.scheduler == nil {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+		return
+	}
+
+	// Checking right away records, alerts on and broadcasts the new state
+	health, err := h.scheduler.CheckNow(c.Request.Context(), service.InstanceID)
+	if err != nil {
+		if c.Request.Context().Err() != nil {
+			return
+		}
+		log.Error().Err(err).Str("instanceId", service.InstanceID).Msg("Failed to check push service")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update push service health"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true, "status": health.Status})
+}
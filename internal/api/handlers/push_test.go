@@ -0,0 +1,98 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/push"
+	"github.com/autobrr/dashbrr/internal/services/scheduler"
+)
+
+type mockPushStore struct {
+	services []models.ServiceConfiguration
+}
+
+func (m *mockPushStore) GetServiceByURL(url string) (*models.ServiceConfiguration, error) {
+	for _, service := range m.services {
+		if service.URL == url {
+			return &service, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockPushStore) GetAllServices() ([]models.ServiceConfiguration, error) {
+	return m.services, nil
+}
+
+func TestPushHandler_Heartbeat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := &mockPushStore{services: []models.ServiceConfiguration{
+		{InstanceID: "push-1", URL: "/api/push/token-1"},
+		{InstanceID: "general-1", URL: "/api/push/not-a-push-service"},
+	}}
+
+	var results []models.ServiceHealth
+	checks := scheduler.New(store, func(ctx context.Context, service models.ServiceConfiguration) models.ServiceHealth {
+		health, _ := push.NewPushService().CheckHealth(ctx, service)
+		return health
+	}, func(health models.ServiceHealth) {
+		results = append(results, health)
+	})
+	require.NoError(t, checks.Sync())
+
+	router := gin.New()
+	router.POST("/api/push/:token", NewPushHandler(store, checks).Heartbeat)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectedCode   int
+		expectedStatus string
+	}{
+		{name: "Unknown token", path: "/api/push/unknown", expectedCode: http.StatusNotFound},
+		{name: "Not a push service", path: "/api/push/not-a-push-service", expectedCode: http.StatusNotFound},
+		{name: "Invalid status", path: "/api/push/token-1?status=sideways", expectedCode: http.StatusBadRequest},
+		{name: "Up", path: "/api/push/token-1?msg=OK&ping=12", expectedCode: http.StatusOK, expectedStatus: "online"},
+		{name: "Down in body", path: "/api/push/token-1", body: `{"status": "down", "msg": "disk full"}`, expectedCode: http.StatusOK, expectedStatus: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedStatus == "" {
+				return
+			}
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedStatus, response["status"])
+
+			// The result is published like a scheduled check
+			require.NotEmpty(t, results)
+			assert.Equal(t, "push-1", results[len(results)-1].ServiceID)
+			assert.Equal(t, tt.expectedStatus, results[len(results)-1].Status)
+		})
+	}
+}
@@ -148,11 +148,15 @@ func (h *RadarrHandler) GetQueue(c *gin.Context) {
 		return
 	}
 
+	if !resolveCredentials(c, radarrConfig) {
+		return
+	}
+
 	// Create Radarr service instance
 	service := &radarr.RadarrService{}
 
 	// Get queue records using the service
-	records, err := service.GetQueue(radarrConfig.URL, radarrConfig.APIKey)
+	records, err := service.GetQueue(instanceContext(c, radarrConfig), radarrConfig.URL, radarrConfig.APIKey)
 	if err != nil {
 		if radarrErr, ok := err.(*radarr.ErrRadarr); ok {
 			log.Error().
@@ -226,11 +230,15 @@ func (h *RadarrHandler) DeleteQueueItem(c *gin.Context) {
 		return
 	}
 
+	if !resolveCredentials(c, radarrConfig) {
+		return
+	}
+
 	// Create Radarr service instance
 	service := &radarr.RadarrService{}
 
 	// Call the service method to delete the queue item
-	if err := service.DeleteQueueItem(radarrConfig.URL, radarrConfig.APIKey, queueId, options); err != nil {
+	if err := service.DeleteQueueItem(instanceContext(c, radarrConfig), radarrConfig.URL, radarrConfig.APIKey, queueId, options); err != nil {
 		if radarrErr, ok := err.(*radarr.ErrRadarr); ok {
 			log.Error().
 				Err(radarrErr).
@@ -0,0 +1,232 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/cache"
+	"github.com/autobrr/dashbrr/internal/services/readarr"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+const (
+	readarrCacheDuration  = 5 * time.Second
+	readarrLookupDuration = 10 * time.Minute
+	readarrQueuePrefix    = "readarr:queue:"
+	readarrLookupPrefix   = "readarr:lookup:"
+)
+
+type ReadarrHandler struct {
+	db    *database.DB
+	cache cache.Store
+}
+
+func NewReadarrHandler(db *database.DB, cache cache.Store) *ReadarrHandler {
+	return &ReadarrHandler{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// getConfig validates the instanceId query parameter and loads its configuration.
+// It writes the error response and returns nil when the request cannot proceed.
+func (h *ReadarrHandler) getConfig(c *gin.Context) *models.ServiceConfiguration {
+	instanceId := c.Query("instanceId")
+	if instanceId == "" {
+		log.Error().Msg("No instanceId provided")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "instanceId is required"})
+		return nil
+	}
+
+	// Verify this is a Readarr instance
+	if !strings.HasPrefix(instanceId, "readarr") {
+		log.Error().Str("instanceId", instanceId).Msg("Invalid Readarr instance ID")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Readarr instance ID"})
+		return nil
+	}
+
+	readarrConfig, err := h.db.GetServiceByInstanceID(instanceId)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to get Readarr configuration")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Readarr configuration"})
+		return nil
+	}
+
+	if readarrConfig == nil {
+		log.Error().Str("instanceId", instanceId).Msg("Readarr is not configured")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Readarr is not configured"})
+		return nil
+	}
+
+	if !resolveCredentials(c, readarrConfig) {
+		return nil
+	}
+
+	return readarrConfig
+}
+
+// writeReadarrError responds with the upstream status code when Readarr returned one
+func writeReadarrError(c *gin.Context, err error, message string) {
+	if readarrErr, ok := err.(*readarr.ErrReadarr); ok && readarrErr.HttpCode > 0 {
+		c.JSON(readarrErr.HttpCode, gin.H{"error": readarrErr.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+}
+
+func (h *ReadarrHandler) GetQueue(c *gin.Context) {
+	instanceId := c.Query("instanceId")
+	cacheKey := readarrQueuePrefix + instanceId
+	ctx := context.Background()
+
+	// Try to get from cache first
+	var queueResp types.ReadarrQueueResponse
+	if instanceId != "" && h.cache.Get(ctx, cacheKey, &queueResp) == nil {
+		log.Debug().
+			Str("instanceId", instanceId).
+			Int("totalRecords", queueResp.TotalRecords).
+			Msg("Serving Readarr queue from cache")
+		c.JSON(http.StatusOK, queueResp)
+		return
+	}
+
+	readarrConfig := h.getConfig(c)
+	if readarrConfig == nil {
+		return
+	}
+
+	service := &readarr.ReadarrService{}
+	queue, err := service.GetQueue(instanceContext(c, readarrConfig), readarrConfig.URL, readarrConfig.APIKey)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to fetch Readarr queue")
+		writeReadarrError(c, err, "Failed to fetch queue")
+		return
+	}
+
+	// Cache the results
+	if err := h.cache.Set(ctx, cacheKey, queue, readarrCacheDuration); err != nil {
+		log.Warn().
+			Err(err).
+			Str("instanceId", instanceId).
+			Msg("Failed to cache Readarr queue")
+	}
+
+	log.Debug().
+		Str("instanceId", instanceId).
+		Int("totalRecords", queue.TotalRecords).
+		Msg("Successfully retrieved and cached Readarr queue")
+
+	c.JSON(http.StatusOK, queue)
+}
+
+// DeleteQueueItem handles the deletion of a queue item with specified options
+func (h *ReadarrHandler) DeleteQueueItem(c *gin.Context) {
+	queueId := c.Param("id")
+	if queueId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "queue item id is required"})
+		return
+	}
+
+	readarrConfig := h.getConfig(c)
+	if readarrConfig == nil {
+		return
+	}
+
+	// Get options from query parameters
+	options := types.ReadarrQueueDeleteOptions{
+		RemoveFromClient: c.Query("removeFromClient") == "true",
+		Blocklist:        c.Query("blocklist") == "true",
+		SkipRedownload:   c.Query("skipRedownload") == "true",
+		ChangeCategory:   c.Query("changeCategory") == "true",
+	}
+
+	service := &readarr.ReadarrService{}
+	if err := service.DeleteQueueItem(instanceContext(c, readarrConfig), readarrConfig.URL, readarrConfig.APIKey, queueId, options); err != nil {
+		log.Error().
+			Err(err).
+			Str("instanceId", readarrConfig.InstanceID).
+			Str("queueId", queueId).
+			Msg("Failed to delete queue item")
+		writeReadarrError(c, err, "Failed to delete queue item")
+		return
+	}
+
+	// Clear cache after successful deletion
+	if err := h.cache.Delete(context.Background(), readarrQueuePrefix+readarrConfig.InstanceID); err != nil {
+		log.Warn().
+			Err(err).
+			Str("instanceId", readarrConfig.InstanceID).
+			Msg("Failed to clear Readarr queue cache")
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Queue item deleted successfully"})
+}
+
+// LookupAuthors searches authors by name or foreign ID using the term query parameter
+func (h *ReadarrHandler) LookupAuthors(c *gin.Context) {
+	h.lookup(c, "author", func(service *readarr.ReadarrService, config *models.ServiceConfiguration, term string) (interface{}, error) {
+		return service.LookupAuthor(instanceContext(c, config), config.URL, config.APIKey, term)
+	})
+}
+
+// LookupBooks searches books by title or identifier using the term query parameter
+func (h *ReadarrHandler) LookupBooks(c *gin.Context) {
+	h.lookup(c, "book", func(service *readarr.ReadarrService, config *models.ServiceConfiguration, term string) (interface{}, error) {
+		return service.LookupBook(instanceContext(c, config), config.URL, config.APIKey, term)
+	})
+}
+
+func (h *ReadarrHandler) lookup(c *gin.Context, kind string, fetch func(*readarr.ReadarrService, *models.ServiceConfiguration, string) (interface{}, error)) {
+	term := strings.TrimSpace(c.Query("term"))
+	if term == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "term is required"})
+		return
+	}
+
+	readarrConfig := h.getConfig(c)
+	if readarrConfig == nil {
+		return
+	}
+
+	cacheKey := readarrLookupPrefix + readarrConfig.InstanceID + ":" + kind + ":" + strings.ToLower(term)
+	ctx := context.Background()
+
+	var cached json.RawMessage
+	if h.cache.Get(ctx, cacheKey, &cached) == nil {
+		c.Data(http.StatusOK, "application/json", cached)
+		return
+	}
+
+	results, err := fetch(&readarr.ReadarrService{}, readarrConfig, term)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("instanceId", readarrConfig.InstanceID).
+			Str("term", term).
+			Str("kind", kind).
+			Msg("Failed to look up Readarr")
+		writeReadarrError(c, err, "Failed to look up "+kind)
+		return
+	}
+
+	if err := h.cache.Set(ctx, cacheKey, results, readarrLookupDuration); err != nil {
+		log.Warn().
+			Err(err).
+			Str("instanceId", readarrConfig.InstanceID).
+			Msg("Failed to cache Readarr lookup")
+	}
+
+	c.JSON(http.StatusOK, results)
+}
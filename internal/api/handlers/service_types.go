@@ -0,0 +1,24 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+type ServiceTypesHandler struct{}
+
+func NewServiceTypesHandler() *ServiceTypesHandler {
+	return &ServiceTypesHandler{}
+}
+
+// GetServiceTypes lists every registered service integration with its
+// defaults, authentication scheme and capabilities
+func (h *ServiceTypesHandler) GetServiceTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, models.ServiceDescriptors())
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func TestServiceTypesHandler_GetServiceTypes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/api/services/types", NewServiceTypesHandler().GetServiceTypes)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/services/types", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var types []models.ServiceDescriptor
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &types))
+
+	byType := make(map[string]models.ServiceDescriptor)
+	for _, d := range types {
+		byType[d.Type] = d
+	}
+
+	sonarr, ok := byType["sonarr"]
+	require.True(t, ok, "sonarr should be registered")
+	assert.Equal(t, "Sonarr", sonarr.DisplayName)
+	assert.Equal(t, "http://localhost:8989", sonarr.DefaultURL)
+	assert.Equal(t, models.AuthAPIKey, sonarr.Auth)
+	assert.True(t, sonarr.HasCapability(models.CapabilityQueue))
+
+	tailscale, ok := byType["tailscale"]
+	require.True(t, ok, "tailscale should be registered")
+	assert.True(t, tailscale.CLI.FixedURL)
+}
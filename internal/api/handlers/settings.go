@@ -12,19 +12,21 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/metrics"
 	"github.com/autobrr/dashbrr/internal/models"
-	"github.com/autobrr/dashbrr/internal/services"
+	"github.com/autobrr/dashbrr/internal/secrets"
+	"github.com/autobrr/dashbrr/internal/services/scheduler"
 )
 
 type SettingsHandler struct {
-	db     *database.DB
-	health *services.HealthService
+	db        *database.DB
+	scheduler *scheduler.Scheduler
 }
 
-func NewSettingsHandler(db *database.DB, health *services.HealthService) *SettingsHandler {
+func NewSettingsHandler(db *database.DB, checks *scheduler.Scheduler) *SettingsHandler {
 	return &SettingsHandler{
-		db:     db,
-		health: health,
+		db:        db,
+		scheduler: checks,
 	}
 }
 
@@ -62,6 +64,13 @@ func (h *SettingsHandler) SaveSettings(c *gin.Context) {
 	config.InstanceID = instanceID
 	config.URL = strings.TrimRight(config.URL, "/")
 
+	// The resolved key is sent to the service URL, so only allowed references are stored
+	if err := secrets.Validate(config.APIKey); err != nil {
+		log.Warn().Err(err).Str("instance", instanceID).Msg("Rejected API key reference")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key reference: " + err.Error()})
+		return
+	}
+
 	log.Debug().
 		Str("instance", instanceID).
 		Interface("config", config).
@@ -75,9 +84,15 @@ func (h *SettingsHandler) SaveSettings(c *gin.Context) {
 		return
 	}
 
-	// If updating, stop health monitoring first
-	if existing != nil && h.health != nil {
-		h.health.StopMonitoring(instanceID)
+	// Services that are not polled get a generated URL, which is kept on updates
+	if descriptor, ok := models.LookupService(models.ServiceTypeFromInstanceID(instanceID)); ok && descriptor.NewURL != nil && config.URL == "" {
+		if existing != nil {
+			config.URL = existing.URL
+		} else if config.URL, err = descriptor.NewURL(); err != nil {
+			log.Error().Err(err).Str("instance", instanceID).Msg("Error generating service URL")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate service URL"})
+			return
+		}
 	}
 
 	var saveErr error
@@ -97,6 +112,8 @@ func (h *SettingsHandler) SaveSettings(c *gin.Context) {
 		return
 	}
 
+	h.syncSchedule()
+
 	log.Info().Str("instance", instanceID).Msg("Successfully saved configuration")
 	c.JSON(http.StatusOK, config)
 }
@@ -118,12 +135,6 @@ func (h *SettingsHandler) DeleteSettings(c *gin.Context) {
 		return
 	}
 
-	// Stop health monitoring before deleting
-	if h.health != nil {
-		log.Debug().Str("instance", instanceID).Msg("Stopping health monitoring")
-		h.health.StopMonitoring(instanceID)
-	}
-
 	// Delete the configuration
 	if err := h.db.DeleteService(instanceID); err != nil {
 		log.Error().Err(err).Str("instance", instanceID).Msg("Error deleting configuration")
@@ -131,6 +142,19 @@ func (h *SettingsHandler) DeleteSettings(c *gin.Context) {
 		return
 	}
 
+	metrics.RemoveService(instanceID)
+	h.syncSchedule()
+
 	log.Info().Str("instance", instanceID).Msg("Successfully deleted configuration")
 	c.JSON(http.StatusOK, gin.H{"message": "Configuration deleted successfully"})
 }
+
+// syncSchedule applies configuration changes to the health check scheduler
+func (h *SettingsHandler) syncSchedule() {
+	if h.scheduler == nil {
+		return
+	}
+	if err := h.scheduler.Sync(); err != nil {
+		log.Error().Err(err).Msg("Failed to update health check schedule")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/database"
+)
+
+func TestSettingsHandler_SaveSettings_SecretReferences(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("DASHBRR__SECRETS_DIR", "/run/secrets")
+
+	db, err := database.InitDBWithConfig(&database.Config{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "dashbrr.db")})
+	require.NoError(t, err)
+	defer db.Close()
+
+	router := gin.New()
+	router.POST("/api/settings/:instance", NewSettingsHandler(db, nil).SaveSettings)
+
+	tests := []struct {
+		name         string
+		apiKey       string
+		expectedCode int
+	}{
+		{name: "plain key", apiKey: "abc123", expectedCode: http.StatusOK},
+		{name: "env reference", apiKey: "env:SONARR_API_KEY", expectedCode: http.StatusOK},
+		{name: "file reference", apiKey: "file:/run/secrets/sonarr", expectedCode: http.StatusOK},
+		{name: "dashbrr env", apiKey: "env:DASHBRR__ENCRYPTION_KEY", expectedCode: http.StatusBadRequest},
+		{name: "oidc env", apiKey: "env:OIDC_CLIENT_SECRET", expectedCode: http.StatusBadRequest},
+		{name: "file outside secret dir", apiKey: "file:/etc/shadow", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"displayName":"Sonarr","url":"http://localhost:8989","apiKey":"` + tt.apiKey + `"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/settings/sonarr-1", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+
+			service, err := db.GetServiceByInstanceID("sonarr-1")
+			require.NoError(t, err)
+			if tt.expectedCode == http.StatusOK {
+				require.NotNil(t, service)
+				assert.Equal(t, tt.apiKey, service.APIKey)
+			} else if service != nil {
+				assert.NotEqual(t, tt.apiKey, service.APIKey)
+			}
+		})
+	}
+}
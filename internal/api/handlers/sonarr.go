@@ -15,6 +15,7 @@ import (
 
 	"github.com/autobrr/dashbrr/internal/database"
 	"github.com/autobrr/dashbrr/internal/services/cache"
+	"github.com/autobrr/dashbrr/internal/services/core"
 	"github.com/autobrr/dashbrr/internal/types"
 )
 
@@ -78,6 +79,10 @@ func (h *SonarrHandler) DeleteQueueItem(c *gin.Context) {
 		return
 	}
 
+	if !resolveCredentials(c, sonarrConfig) {
+		return
+	}
+
 	// Build delete URL with query parameters
 	deleteURL := fmt.Sprintf("%s/api/v3/queue/%s?removeFromClient=%t&blocklist=%t&skipRedownload=%t",
 		sonarrConfig.URL,
@@ -91,7 +96,9 @@ func (h *SonarrHandler) DeleteQueueItem(c *gin.Context) {
 	}
 
 	// Create DELETE request
-	req, err := http.NewRequest(http.MethodDelete, deleteURL, nil)
+	reqCtx, cancel := context.WithTimeout(instanceContext(c, sonarrConfig), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodDelete, deleteURL, nil)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create delete request")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create delete request"})
@@ -102,8 +109,7 @@ func (h *SonarrHandler) DeleteQueueItem(c *gin.Context) {
 	req.Header.Add("X-Api-Key", sonarrConfig.APIKey)
 
 	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := core.Do(req)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to execute delete request")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to execute delete request"})
@@ -195,12 +201,23 @@ func (h *SonarrHandler) GetQueue(c *gin.Context) {
 		return
 	}
 
+	if !resolveCredentials(c, sonarrConfig) {
+		return
+	}
+
 	// Build Sonarr API URL
 	apiURL := fmt.Sprintf("%s/api/v3/queue?apikey=%s", sonarrConfig.URL, sonarrConfig.APIKey)
 
 	// Make request to Sonarr
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(apiURL)
+	reqCtx, cancel := context.WithTimeout(instanceContext(c, sonarrConfig), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to create Sonarr request")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Sonarr request"})
+		return
+	}
+	resp, err := core.Do(req)
 	if err != nil {
 		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to fetch Sonarr queue")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch Sonarr queue"})
@@ -290,12 +307,23 @@ func (h *SonarrHandler) GetStats(c *gin.Context) {
 		return
 	}
 
+	if !resolveCredentials(c, sonarrConfig) {
+		return
+	}
+
 	// Build Sonarr API URL
 	apiURL := fmt.Sprintf("%s/api/v3/system/status?apikey=%s", sonarrConfig.URL, sonarrConfig.APIKey)
 
 	// Make request to Sonarr
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(apiURL)
+	reqCtx, cancel := context.WithTimeout(instanceContext(c, sonarrConfig), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to create Sonarr request")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Sonarr request"})
+		return
+	}
+	resp, err := core.Do(req)
 	if err != nil {
 		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to fetch Sonarr stats")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch Sonarr stats"})
@@ -0,0 +1,51 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/cache"
+)
+
+func TestSonarrHandler_GetQueue_InstanceOptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Forwarded-User")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"page":1,"pageSize":10,"totalRecords":0,"records":[]}`))
+	}))
+	defer server.Close()
+
+	db, err := database.InitDBWithConfig(&database.Config{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "dashbrr.db")})
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.CreateService(&models.ServiceConfiguration{
+		InstanceID:  "sonarr-1",
+		DisplayName: "Sonarr",
+		URL:         server.URL,
+		APIKey:      "abc123",
+		Options:     models.ServiceOptions{Headers: map[string]string{"X-Forwarded-User": "dashbrr"}},
+	}))
+
+	router := gin.New()
+	router.GET("/api/sonarr/queue", NewSonarrHandler(db, cache.NewMemoryStore(t.TempDir())).GetQueue)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/sonarr/queue?instanceId=sonarr-1", nil))
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "dashbrr", header)
+}
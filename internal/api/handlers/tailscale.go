@@ -5,7 +5,6 @@ package handlers
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -69,6 +68,19 @@ func (h *TailscaleHandler) GetTailscaleDevices(c *gin.Context) {
 		}
 	}
 
+	if apiKey == "" {
+		tailscaleConfig, ok := loadCredentials(c, h.db, instanceId)
+		if !ok {
+			return
+		}
+		if tailscaleConfig == nil {
+			log.Error().Str("instanceId", instanceId).Msg("Tailscale is not configured")
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tailscale is not configured"})
+			return
+		}
+		apiKey = tailscaleConfig.APIKey
+	}
+
 	ctx := context.Background()
 
 	// Try to get from cache first
@@ -137,25 +149,7 @@ func (h *TailscaleHandler) GetTailscaleDevices(c *gin.Context) {
 
 func (h *TailscaleHandler) fetchAndCacheDevices(instanceId, apiKey, cacheKey string) ([]tailscale.Device, error) {
 	service := &tailscale.TailscaleService{}
-
-	var devices []tailscale.Device
-	var err error
-
-	if apiKey != "" {
-		devices, err = service.GetDevices("", apiKey)
-	} else {
-		tailscaleConfig, err := h.db.GetServiceByInstanceID(instanceId)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch tailscale configuration: %v", err)
-		}
-
-		if tailscaleConfig == nil {
-			return nil, fmt.Errorf("tailscale is not configured")
-		}
-
-		devices, err = service.GetDevices("", tailscaleConfig.APIKey)
-	}
-
+	devices, err := service.GetDevices("", apiKey)
 	if err != nil {
 		return nil, err
 	}
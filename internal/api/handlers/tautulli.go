@@ -0,0 +1,201 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/cache"
+	"github.com/autobrr/dashbrr/internal/services/tautulli"
+)
+
+const (
+	tautulliStatsCacheDuration   = 5 * time.Minute
+	tautulliHistoryCacheDuration = 60 * time.Second
+	tautulliCachePrefix          = "tautulli:"
+
+	tautulliDefaultDays   = 30
+	tautulliDefaultCount  = 5
+	tautulliDefaultRecent = 10
+	tautulliDefaultLength = 25
+)
+
+type TautulliHandler struct {
+	db    *database.DB
+	cache cache.Store
+}
+
+func NewTautulliHandler(db *database.DB, cache cache.Store) *TautulliHandler {
+	return &TautulliHandler{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// getConfig validates the instance ID and loads its configuration. It writes
+// the error response and returns nil when the request cannot proceed.
+func (h *TautulliHandler) getConfig(c *gin.Context) *models.ServiceConfiguration {
+	instanceId := c.Query("instanceId")
+	if instanceId == "" {
+		log.Error().Msg("No instanceId provided")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "instanceId is required"})
+		return nil
+	}
+
+	// Verify this is a Tautulli instance
+	if !strings.HasPrefix(instanceId, "tautulli") {
+		log.Error().Str("instanceId", instanceId).Msg("Invalid Tautulli instance ID")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Tautulli instance ID"})
+		return nil
+	}
+
+	config, err := h.db.GetServiceByInstanceID(instanceId)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to get Tautulli configuration")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Tautulli configuration"})
+		return nil
+	}
+
+	if config == nil {
+		log.Error().Str("instanceId", instanceId).Msg("Tautulli is not configured")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tautulli is not configured"})
+		return nil
+	}
+
+	if !resolveCredentials(c, config) {
+		return nil
+	}
+
+	return config
+}
+
+// positiveQuery parses an optional positive number query parameter. It writes
+// the error response and returns false when the value is invalid.
+func positiveQuery(c *gin.Context, name string, fallback int) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, true
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be a positive number", name)})
+		return 0, false
+	}
+	return parsed, true
+}
+
+// cached serves a response from the cache or fetches and caches it
+func (h *TautulliHandler) cached(c *gin.Context, config *models.ServiceConfiguration, key string, duration time.Duration, fetch func(ctx context.Context, service *tautulli.TautulliService) (interface{}, error)) {
+	cacheKey := tautulliCachePrefix + key + ":" + config.InstanceID
+	ctx := context.Background()
+
+	// Try to get from cache first
+	var cachedResponse interface{}
+	if err := h.cache.Get(ctx, cacheKey, &cachedResponse); err == nil {
+		log.Debug().
+			Str("instanceId", config.InstanceID).
+			Str("key", key).
+			Msg("Serving Tautulli response from cache")
+		c.JSON(http.StatusOK, cachedResponse)
+		return
+	}
+
+	result, err := fetch(instanceContext(c, config), &tautulli.TautulliService{})
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", config.InstanceID).Str("key", key).Msg("Failed to fetch from Tautulli")
+		if tautulliErr, ok := err.(*tautulli.ErrTautulli); ok && tautulliErr.HttpCode > 0 {
+			c.JSON(tautulliErr.HttpCode, gin.H{"error": tautulliErr.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch from Tautulli: %v", err)})
+		return
+	}
+
+	if err := h.cache.Set(ctx, cacheKey, result, duration); err != nil {
+		log.Warn().
+			Err(err).
+			Str("instanceId", config.InstanceID).
+			Str("key", key).
+			Msg("Failed to cache Tautulli response")
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetHomeStats returns the most watched media, top users and top platforms.
+// The optional days and count query parameters set the time range and list size.
+func (h *TautulliHandler) GetHomeStats(c *gin.Context) {
+	config := h.getConfig(c)
+	if config == nil {
+		return
+	}
+
+	days, ok := positiveQuery(c, "days", tautulliDefaultDays)
+	if !ok {
+		return
+	}
+	count, ok := positiveQuery(c, "count", tautulliDefaultCount)
+	if !ok {
+		return
+	}
+
+	key := fmt.Sprintf("stats:%d:%d", days, count)
+	h.cached(c, config, key, tautulliStatsCacheDuration, func(ctx context.Context, service *tautulli.TautulliService) (interface{}, error) {
+		return service.GetHomeStats(ctx, config.URL, config.APIKey, days, count)
+	})
+}
+
+// GetRecentlyAdded returns the latest library additions, limited by the optional count query parameter
+func (h *TautulliHandler) GetRecentlyAdded(c *gin.Context) {
+	config := h.getConfig(c)
+	if config == nil {
+		return
+	}
+
+	count, ok := positiveQuery(c, "count", tautulliDefaultRecent)
+	if !ok {
+		return
+	}
+
+	key := fmt.Sprintf("recent:%d", count)
+	h.cached(c, config, key, tautulliStatsCacheDuration, func(ctx context.Context, service *tautulli.TautulliService) (interface{}, error) {
+		return service.GetRecentlyAdded(ctx, config.URL, config.APIKey, count)
+	})
+}
+
+// GetHistory returns the play history, of a single user when the userId query
+// parameter is set, limited by the optional length query parameter
+func (h *TautulliHandler) GetHistory(c *gin.Context) {
+	config := h.getConfig(c)
+	if config == nil {
+		return
+	}
+
+	userID := c.Query("userId")
+	if _, err := strconv.Atoi(userID); userID != "" && err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userId must be a number"})
+		return
+	}
+
+	length, ok := positiveQuery(c, "length", tautulliDefaultLength)
+	if !ok {
+		return
+	}
+
+	key := fmt.Sprintf("history:%s:%d", userID, length)
+	h.cached(c, config, key, tautulliHistoryCacheDuration, func(ctx context.Context, service *tautulli.TautulliService) (interface{}, error) {
+		return service.GetHistory(ctx, config.URL, config.APIKey, userID, length)
+	})
+}
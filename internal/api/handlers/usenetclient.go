@@ -0,0 +1,229 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/cache"
+	"github.com/autobrr/dashbrr/internal/services/downloadclient"
+)
+
+const (
+	usenetQueueCacheDuration   = 5 * time.Second
+	usenetHistoryCacheDuration = 30 * time.Second
+	usenetDefaultHistoryLimit  = 20
+)
+
+// UsenetClientHandler serves the SABnzbd and NZBGet endpoints
+type UsenetClientHandler struct {
+	db         *database.DB
+	cache      cache.Store
+	clientType string
+	name       string
+}
+
+func NewSabnzbdHandler(db *database.DB, cache cache.Store) *UsenetClientHandler {
+	return newUsenetClientHandler(db, cache, "sabnzbd", "SABnzbd")
+}
+
+func NewNzbgetHandler(db *database.DB, cache cache.Store) *UsenetClientHandler {
+	return newUsenetClientHandler(db, cache, "nzbget", "NZBGet")
+}
+
+func newUsenetClientHandler(db *database.DB, cache cache.Store, clientType, name string) *UsenetClientHandler {
+	return &UsenetClientHandler{
+		db:         db,
+		cache:      cache,
+		clientType: clientType,
+		name:       name,
+	}
+}
+
+// getConfig validates the instance ID and loads its configuration. It writes
+// the error response and returns nil when the request cannot proceed.
+func (h *UsenetClientHandler) getConfig(c *gin.Context, instanceId string) *models.ServiceConfiguration {
+	if instanceId == "" {
+		log.Error().Msg("No instanceId provided")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "instanceId is required"})
+		return nil
+	}
+
+	if !strings.HasPrefix(instanceId, h.clientType) {
+		log.Error().Str("instanceId", instanceId).Str("type", h.clientType).Msg("Invalid usenet client instance ID")
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s instance ID", h.name)})
+		return nil
+	}
+
+	config, err := h.db.GetServiceByInstanceID(instanceId)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", instanceId).Msg("Failed to get usenet client configuration")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get %s configuration", h.name)})
+		return nil
+	}
+
+	if config == nil {
+		log.Error().Str("instanceId", instanceId).Msg("Usenet client is not configured")
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("%s is not configured", h.name)})
+		return nil
+	}
+
+	if !resolveCredentials(c, config) {
+		return nil
+	}
+
+	return config
+}
+
+// client returns the registered implementation of the handler's client type.
+// It writes the error response and returns nil when there is none.
+func (h *UsenetClientHandler) client(c *gin.Context) downloadclient.UsenetClient {
+	client, ok := models.NewServiceRegistry().CreateService(h.clientType).(downloadclient.UsenetClient)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s support is not available", h.name)})
+		return nil
+	}
+	return client
+}
+
+func (h *UsenetClientHandler) cacheKey(kind, instanceId string) string {
+	return h.clientType + ":" + kind + ":" + instanceId
+}
+
+// cached serves a response from the cache or fetches and caches it
+func (h *UsenetClientHandler) cached(c *gin.Context, kind string, duration time.Duration, fetch func(ctx context.Context, client downloadclient.UsenetClient, config *models.ServiceConfiguration) (interface{}, error)) {
+	config := h.getConfig(c, c.Query("instanceId"))
+	if config == nil {
+		return
+	}
+
+	cacheKey := h.cacheKey(kind, config.InstanceID)
+	ctx := context.Background()
+
+	// Try to get from cache first
+	var cachedResponse interface{}
+	if err := h.cache.Get(ctx, cacheKey, &cachedResponse); err == nil {
+		log.Debug().
+			Str("instanceId", config.InstanceID).
+			Str("kind", kind).
+			Msg("Serving usenet client response from cache")
+		c.JSON(http.StatusOK, cachedResponse)
+		return
+	}
+
+	client := h.client(c)
+	if client == nil {
+		return
+	}
+
+	result, err := fetch(instanceContext(c, config), client, config)
+	if err != nil {
+		log.Error().Err(err).Str("instanceId", config.InstanceID).Str("kind", kind).Msg("Failed to fetch from usenet client")
+		writeDownloadClientError(c, err, fmt.Sprintf("Failed to fetch %s", kind))
+		return
+	}
+
+	if err := h.cache.Set(ctx, cacheKey, result, duration); err != nil {
+		log.Warn().
+			Err(err).
+			Str("instanceId", config.InstanceID).
+			Str("kind", kind).
+			Msg("Failed to cache usenet client response")
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *UsenetClientHandler) GetQueue(c *gin.Context) {
+	h.cached(c, "queue", usenetQueueCacheDuration, func(ctx context.Context, client downloadclient.UsenetClient, config *models.ServiceConfiguration) (interface{}, error) {
+		return client.GetQueue(ctx, config.URL, config.APIKey)
+	})
+}
+
+// GetHistory returns the failed downloads, limited by the optional limit query parameter
+func (h *UsenetClientHandler) GetHistory(c *gin.Context) {
+	limit, ok := positiveQuery(c, "limit", usenetDefaultHistoryLimit)
+	if !ok {
+		return
+	}
+
+	h.cached(c, historyCacheKind(limit), usenetHistoryCacheDuration, func(ctx context.Context, client downloadclient.UsenetClient, config *models.ServiceConfiguration) (interface{}, error) {
+		return client.GetFailedHistory(ctx, config.URL, config.APIKey, limit)
+	})
+}
+
+// historyCacheKind keeps the cached history of each limit apart
+func historyCacheKind(limit int) string {
+	return "history:" + strconv.Itoa(limit)
+}
+
+func (h *UsenetClientHandler) GetWarnings(c *gin.Context) {
+	h.cached(c, "warnings", usenetHistoryCacheDuration, func(ctx context.Context, client downloadclient.UsenetClient, config *models.ServiceConfiguration) (interface{}, error) {
+		return client.GetWarnings(ctx, config.URL, config.APIKey)
+	})
+}
+
+// action runs a queue action and clears the listed cache kinds of the instance
+func (h *UsenetClientHandler) action(c *gin.Context, action string, kinds []string, run func(ctx context.Context, client downloadclient.UsenetClient, config *models.ServiceConfiguration) error) {
+	config := h.getConfig(c, c.Param("instanceId"))
+	if config == nil {
+		return
+	}
+
+	client := h.client(c)
+	if client == nil {
+		return
+	}
+
+	if err := run(instanceContext(c, config), client, config); err != nil {
+		log.Error().Err(err).Str("instanceId", config.InstanceID).Str("action", action).Msg("Failed to run usenet client action")
+		writeDownloadClientError(c, err, fmt.Sprintf("Failed to %s", action))
+		return
+	}
+
+	// The cached responses no longer reflect the client state
+	for _, kind := range kinds {
+		if err := h.cache.Delete(context.Background(), h.cacheKey(kind, config.InstanceID)); err != nil && err != cache.ErrKeyNotFound {
+			log.Warn().Err(err).Str("instanceId", config.InstanceID).Str("kind", kind).Msg("Failed to clear usenet client cache")
+		}
+	}
+
+	log.Info().
+		Str("instanceId", config.InstanceID).
+		Str("action", action).
+		Msg("Usenet client action completed")
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%s completed", action)})
+}
+
+func (h *UsenetClientHandler) PauseQueue(c *gin.Context) {
+	h.action(c, "pause queue", []string{"queue"}, func(ctx context.Context, client downloadclient.UsenetClient, config *models.ServiceConfiguration) error {
+		return client.PauseQueue(ctx, config.URL, config.APIKey)
+	})
+}
+
+func (h *UsenetClientHandler) ResumeQueue(c *gin.Context) {
+	h.action(c, "resume queue", []string{"queue"}, func(ctx context.Context, client downloadclient.UsenetClient, config *models.ServiceConfiguration) error {
+		return client.ResumeQueue(ctx, config.URL, config.APIKey)
+	})
+}
+
+// RetryItem downloads the failed history item given by the id parameter again
+func (h *UsenetClientHandler) RetryItem(c *gin.Context) {
+	id := c.Param("id")
+	kinds := []string{"queue", historyCacheKind(usenetDefaultHistoryLimit)}
+	h.action(c, "retry item", kinds, func(ctx context.Context, client downloadclient.UsenetClient, config *models.ServiceConfiguration) error {
+		return client.RetryItem(ctx, config.URL, config.APIKey, id)
+	})
+}
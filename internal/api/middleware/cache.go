@@ -12,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 
+	"github.com/autobrr/dashbrr/internal/metrics"
 	"github.com/autobrr/dashbrr/internal/services/cache"
 )
 
@@ -57,12 +58,15 @@ func (m *CacheMiddleware) Cache() gin.HandlerFunc {
 				c.Header(k, v)
 			}
 
+			metrics.CacheHit()
 			c.Header("X-Cache", "HIT")
 			c.Data(cachedResponse.Status, cachedResponse.ContentType, cachedResponse.Body)
 			c.Abort()
 			return
 		}
 
+		metrics.CacheMiss()
+
 		// Create a buffer to store the response
 		w := &responseWriter{
 			ResponseWriter: c.Writer,
@@ -119,9 +123,13 @@ func (m *CacheMiddleware) getTTL(path string) time.Duration {
 	switch {
 	case strings.Contains(path, "/plex/sessions"):
 		return 10 * time.Second
+	case strings.Contains(path, "/jellyfin/sessions"), strings.Contains(path, "/emby/sessions"):
+		return 10 * time.Second
+	case strings.Contains(path, "/jellyfin"), strings.Contains(path, "/emby"):
+		return 1 * time.Minute
 	case strings.Contains(path, "/autobrr"):
 		return 30 * time.Second
-	case strings.Contains(path, "/overseerr"):
+	case strings.Contains(path, "/overseerr"), strings.Contains(path, "/jellyseerr"):
 		return 1 * time.Minute
 	case strings.Contains(path, "/maintainerr"):
 		return 1 * time.Minute
@@ -129,8 +137,20 @@ func (m *CacheMiddleware) getTTL(path string) time.Duration {
 		return 30 * time.Second
 	case strings.Contains(path, "/radarr"):
 		return 30 * time.Second
+	case strings.Contains(path, "/lidarr"):
+		return 30 * time.Second
+	case strings.Contains(path, "/readarr"):
+		return 30 * time.Second
 	case strings.Contains(path, "/prowlarr"):
 		return 1 * time.Minute
+	case strings.Contains(path, "/bazarr"):
+		return 1 * time.Minute
+	case strings.Contains(path, "/tautulli"):
+		return 1 * time.Minute
+	case strings.Contains(path, "/qbittorrent"), strings.Contains(path, "/transmission"), strings.Contains(path, "/deluge"):
+		return 10 * time.Second
+	case strings.Contains(path, "/sabnzbd"), strings.Contains(path, "/nzbget"):
+		return 10 * time.Second
 	default:
 		return DefaultTTL
 	}
@@ -6,11 +6,13 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 
+	"github.com/autobrr/dashbrr/internal/metrics"
 	"github.com/autobrr/dashbrr/internal/services/cache"
 )
 
@@ -77,6 +79,7 @@ func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 			c.Header("X-RateLimit-Remaining", "0")
 			c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", windowStart+int64(rl.window.Seconds())))
 
+			metrics.RateLimitRejected(strings.TrimSuffix(rl.keyPrefix, ":"))
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":       "Rate limit exceeded",
 				"limit":       rl.limit,
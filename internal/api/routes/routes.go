@@ -6,6 +6,7 @@ package routes
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,13 +15,18 @@ import (
 	"github.com/autobrr/dashbrr/internal/api/handlers"
 	"github.com/autobrr/dashbrr/internal/api/middleware"
 	"github.com/autobrr/dashbrr/internal/database"
-	"github.com/autobrr/dashbrr/internal/services"
+	"github.com/autobrr/dashbrr/internal/metrics"
+	"github.com/autobrr/dashbrr/internal/services/alerts"
+	"github.com/autobrr/dashbrr/internal/services/backup"
 	"github.com/autobrr/dashbrr/internal/services/cache"
+	"github.com/autobrr/dashbrr/internal/services/digest"
+	"github.com/autobrr/dashbrr/internal/services/push"
 	"github.com/autobrr/dashbrr/internal/types"
 )
 
-// SetupRoutes configures all the routes for the application
-func SetupRoutes(r *gin.Engine, db *database.DB, health *services.HealthService) cache.Store {
+// SetupRoutes configures all the routes for the application. configPath is
+// the active config file, which is included in backups.
+func SetupRoutes(r *gin.Engine, db *database.DB, configPath string) cache.Store {
 	// Use custom logger instead of default Gin logger
 	r.Use(middleware.Logger())
 	r.Use(gin.Recovery())
@@ -60,6 +66,7 @@ func SetupRoutes(r *gin.Engine, db *database.DB, health *services.HealthService)
 	apiRateLimiter := middleware.NewRateLimiter(store, time.Minute, 60, "api:")       // 60 requests per minute for API
 	healthRateLimiter := middleware.NewRateLimiter(store, time.Minute, 30, "health:") // 30 health checks per minute
 	authRateLimiter := middleware.NewRateLimiter(store, time.Minute, 30, "auth:")     // 30 auth requests per minute
+	pushRateLimiter := middleware.NewRateLimiter(store, time.Minute, 120, "push:")    // 120 heartbeats per minute
 
 	// Special rate limiter for Tailscale services
 	tailscaleRateLimiter := middleware.NewRateLimiter(store, 2*time.Minute, 20, "tailscale:") // 20 requests per 2 minutes
@@ -68,18 +75,41 @@ func SetupRoutes(r *gin.Engine, db *database.DB, health *services.HealthService)
 	cacheMiddleware := middleware.NewCacheMiddleware(store)
 
 	// Initialize handlers with cache
-	settingsHandler := handlers.NewSettingsHandler(db, health)
-	healthHandler := handlers.NewHealthHandler(db, health)
-	eventsHandler := handlers.NewEventsHandler(db, health)
+	healthHandler := handlers.NewHealthHandler(db)
+	healthHistoryHandler := handlers.NewHealthHistoryHandler(db)
+	alertEngine := alerts.NewEngine(db)
+	alerts.SetTemplateDir(getEnvOrDefault("DASHBRR__TEMPLATE_DIR", filepath.Join(filepath.Dir(db.Path()), "templates")))
+	alertsHandler := handlers.NewAlertsHandler(db, alertEngine)
+	serviceTypesHandler := handlers.NewServiceTypesHandler()
+	eventsHandler := handlers.NewEventsHandler(db, alertEngine)
+	// Heartbeats of removed push monitors are kept in memory by token
+	eventsHandler.Scheduler().OnSync(push.Prune)
+	settingsHandler := handlers.NewSettingsHandler(db, eventsHandler.Scheduler())
+	pushHandler := handlers.NewPushHandler(db, eventsHandler.Scheduler())
 	autobrrHandler := handlers.NewAutobrrHandler(db, store)
 	omegabrrHandler := handlers.NewOmegabrrHandler(db, store)
 	maintainerrHandler := handlers.NewMaintainerrHandler(db, store)
 	plexHandler := handlers.NewPlexHandler(db, store)
+	jellyfinHandler := handlers.NewJellyfinHandler(db, store)
+	embyHandler := handlers.NewEmbyHandler(db, store)
 	tailscaleHandler := handlers.NewTailscaleHandler(db, store)
-	overseerrHandler := handlers.NewOverseerrHandler(db, store)
+	overseerrHandler := handlers.NewOverseerrHandler(db, store, alertEngine)
+	jellyseerrHandler := handlers.NewJellyseerrHandler(db, store, alertEngine)
 	sonarrHandler := handlers.NewSonarrHandler(db, store)
 	radarrHandler := handlers.NewRadarrHandler(db, store)
+	lidarrHandler := handlers.NewLidarrHandler(db, store)
+	readarrHandler := handlers.NewReadarrHandler(db, store)
 	prowlarrHandler := handlers.NewProwlarrHandler(db, store)
+	bazarrHandler := handlers.NewBazarrHandler(db, store)
+	tautulliHandler := handlers.NewTautulliHandler(db, store)
+	qbittorrentHandler := handlers.NewQbittorrentHandler(db, store)
+	transmissionHandler := handlers.NewTransmissionHandler(db, store)
+	delugeHandler := handlers.NewDelugeHandler(db, store)
+	sabnzbdHandler := handlers.NewSabnzbdHandler(db, store)
+	nzbgetHandler := handlers.NewNzbgetHandler(db, store)
+	backupConfig := backup.NewConfig(db, configPath)
+	backupHandler := handlers.NewBackupHandler(db, backupConfig.Options)
+	metricsHandler := handlers.NewMetricsHandler(db, os.Getenv("DASHBRR__METRICS_TOKEN"), getBoolEnv("DASHBRR__METRICS_PUBLIC", false), getDurationEnv("DASHBRR__METRICS_STATS_INTERVAL", metrics.DefaultStatsInterval))
 
 	// Initialize auth handlers and middleware
 	var oidcAuthHandler *handlers.AuthHandler
@@ -100,6 +130,12 @@ func SetupRoutes(r *gin.Engine, db *database.DB, health *services.HealthService)
 	// Start the health monitor
 	eventsHandler.StartHealthMonitor()
 
+	// Start sending scheduled email digests
+	digest.NewScheduler(db).Start()
+
+	// Start scheduled backups when DASHBRR__BACKUP_INTERVAL is set
+	backup.NewScheduler(db, backupConfig).Start()
+
 	// Public routes (no auth required)
 	public := r.Group("")
 	{
@@ -108,9 +144,15 @@ func SetupRoutes(r *gin.Engine, db *database.DB, health *services.HealthService)
 			c.JSON(200, gin.H{"status": "ok"})
 		})
 
+		// Prometheus metrics, protected by DASHBRR__METRICS_TOKEN unless DASHBRR__METRICS_PUBLIC is set
+		public.GET("/metrics", metricsHandler.ServeMetrics)
+
 		// Auth configuration endpoint
 		public.GET("/api/auth/config", handlers.GetAuthConfig)
 
+		// Push monitor heartbeats, authenticated by the token in the URL
+		public.POST("/api/push/:token", pushRateLimiter.RateLimit(), pushHandler.Heartbeat)
+
 		// OIDC auth endpoints (only if OIDC is configured)
 		if oidcAuthHandler != nil {
 			public.GET("/api/auth/callback", oidcAuthHandler.Callback)
@@ -162,11 +204,41 @@ func SetupRoutes(r *gin.Engine, db *database.DB, health *services.HealthService)
 			settings.DELETE("/:instance", settingsHandler.DeleteSettings)
 		}
 
+		// Supported service integrations
+		api.GET("/services/types", serviceTypesHandler.GetServiceTypes)
+
+		// Administration endpoints
+		admin := api.Group("/admin")
+		admin.Use(apiRateLimiter.RateLimit())
+		{
+			admin.GET("/backup", backupHandler.Download)
+		}
+
+		// Alerting endpoints - no caching to ensure fresh data
+		alertsGroup := api.Group("/alerts")
+		{
+			alertsGroup.GET("/active", alertsHandler.GetActiveAlerts)
+			alertsGroup.GET("/notifier-types", alertsHandler.GetNotifierTypes)
+
+			alertsGroup.GET("/rules", alertsHandler.GetRules)
+			alertsGroup.POST("/rules", alertsHandler.CreateRule)
+			alertsGroup.PUT("/rules/:id", alertsHandler.UpdateRule)
+			alertsGroup.DELETE("/rules/:id", alertsHandler.DeleteRule)
+
+			alertsGroup.GET("/notifiers", alertsHandler.GetNotifiers)
+			alertsGroup.POST("/notifiers", alertsHandler.CreateNotifier)
+			alertsGroup.PUT("/notifiers/:id", alertsHandler.UpdateNotifier)
+			alertsGroup.DELETE("/notifiers/:id", alertsHandler.DeleteNotifier)
+			alertsGroup.POST("/notifiers/:id/test", alertsHandler.TestNotifier)
+		}
+
 		// Health check endpoints (no cache for SSE)
 		health := api.Group("/health")
 		health.Use(healthRateLimiter.RateLimit())
 		{
 			health.GET("/:service", healthHandler.CheckHealth)
+			health.GET("/:service/history", healthHistoryHandler.GetHistory)
+			health.POST("/:service/check", eventsHandler.TriggerCheck)
 			health.GET("/events", eventsHandler.StreamHealth)
 		}
 
@@ -183,12 +255,32 @@ func SetupRoutes(r *gin.Engine, db *database.DB, health *services.HealthService)
 				regularServices.GET("/plex/sessions", plexHandler.GetPlexSessions)
 				regularServices.GET("/maintainerr/collections", maintainerrHandler.GetMaintainerrCollections)
 
+				// Jellyfin endpoints
+				jellyfin := regularServices.Group("/jellyfin")
+				{
+					jellyfin.GET("/sessions", jellyfinHandler.GetSessions)
+					jellyfin.GET("/stats", jellyfinHandler.GetStats)
+				}
+
+				// Emby endpoints
+				emby := regularServices.Group("/emby")
+				{
+					emby.GET("/sessions", embyHandler.GetSessions)
+					emby.GET("/stats", embyHandler.GetStats)
+				}
+
 				// Overseerr endpoints
 				overseerr := regularServices.Group("/overseerr")
 				{
 					overseerr.GET("/requests", overseerrHandler.GetRequests)
 				}
 
+				// Jellyseerr endpoints
+				jellyseerr := regularServices.Group("/jellyseerr")
+				{
+					jellyseerr.GET("/requests", jellyseerrHandler.GetRequests)
+				}
+
 				// Sonarr endpoints
 				sonarr := regularServices.Group("/sonarr")
 				{
@@ -204,6 +296,23 @@ func SetupRoutes(r *gin.Engine, db *database.DB, health *services.HealthService)
 					radarr.DELETE("/queue/:id", radarrHandler.DeleteQueueItem)
 				}
 
+				// Lidarr endpoints
+				lidarr := regularServices.Group("/lidarr")
+				{
+					lidarr.GET("/queue", lidarrHandler.GetQueue)
+					lidarr.GET("/stats", lidarrHandler.GetStats)
+					lidarr.DELETE("/queue/:id", lidarrHandler.DeleteQueueItem)
+				}
+
+				// Readarr endpoints
+				readarr := regularServices.Group("/readarr")
+				{
+					readarr.GET("/queue", readarrHandler.GetQueue)
+					readarr.DELETE("/queue/:id", readarrHandler.DeleteQueueItem)
+					readarr.GET("/author/lookup", readarrHandler.LookupAuthors)
+					readarr.GET("/book/lookup", readarrHandler.LookupBooks)
+				}
+
 				// Prowlarr endpoints
 				prowlarr := regularServices.Group("/prowlarr")
 				{
@@ -211,6 +320,30 @@ func SetupRoutes(r *gin.Engine, db *database.DB, health *services.HealthService)
 					prowlarr.GET("/indexers", prowlarrHandler.GetIndexers)
 				}
 
+				// Bazarr endpoints
+				regularServices.GET("/bazarr/stats", bazarrHandler.GetStats)
+
+				// Tautulli endpoints
+				tautulli := regularServices.Group("/tautulli")
+				{
+					tautulli.GET("/stats", tautulliHandler.GetHomeStats)
+					tautulli.GET("/recent", tautulliHandler.GetRecentlyAdded)
+					tautulli.GET("/history", tautulliHandler.GetHistory)
+				}
+
+				// Download client endpoints
+				regularServices.GET("/qbittorrent/stats", qbittorrentHandler.GetStats)
+				regularServices.GET("/transmission/stats", transmissionHandler.GetStats)
+				regularServices.GET("/deluge/stats", delugeHandler.GetStats)
+
+				// Usenet client endpoints
+				regularServices.GET("/sabnzbd/queue", sabnzbdHandler.GetQueue)
+				regularServices.GET("/sabnzbd/history", sabnzbdHandler.GetHistory)
+				regularServices.GET("/sabnzbd/warnings", sabnzbdHandler.GetWarnings)
+				regularServices.GET("/nzbget/queue", nzbgetHandler.GetQueue)
+				regularServices.GET("/nzbget/history", nzbgetHandler.GetHistory)
+				regularServices.GET("/nzbget/warnings", nzbgetHandler.GetWarnings)
+
 				// Omegabrr endpoints
 				omegabrr := regularServices.Group("/omegabrr")
 				{
@@ -241,6 +374,46 @@ func SetupRoutes(r *gin.Engine, db *database.DB, health *services.HealthService)
 				{
 					overseerrActions.POST("/request/:requestId/:status", overseerrHandler.UpdateRequestStatus)
 				}
+
+				// Jellyseerr action endpoints
+				jellyseerrActions := serviceActions.Group("/jellyseerr")
+				{
+					jellyseerrActions.POST("/request/:requestId/:status", jellyseerrHandler.UpdateRequestStatus)
+				}
+
+				// Download client action endpoints
+				qbittorrentActions := serviceActions.Group("/qbittorrent")
+				{
+					qbittorrentActions.POST("/pause", qbittorrentHandler.PauseTorrents)
+					qbittorrentActions.POST("/resume", qbittorrentHandler.ResumeTorrents)
+				}
+
+				transmissionActions := serviceActions.Group("/transmission")
+				{
+					transmissionActions.POST("/pause", transmissionHandler.PauseTorrents)
+					transmissionActions.POST("/resume", transmissionHandler.ResumeTorrents)
+				}
+
+				delugeActions := serviceActions.Group("/deluge")
+				{
+					delugeActions.POST("/pause", delugeHandler.PauseTorrents)
+					delugeActions.POST("/resume", delugeHandler.ResumeTorrents)
+				}
+
+				// Usenet client action endpoints
+				sabnzbdActions := serviceActions.Group("/sabnzbd")
+				{
+					sabnzbdActions.POST("/pause", sabnzbdHandler.PauseQueue)
+					sabnzbdActions.POST("/resume", sabnzbdHandler.ResumeQueue)
+					sabnzbdActions.POST("/retry/:id", sabnzbdHandler.RetryItem)
+				}
+
+				nzbgetActions := serviceActions.Group("/nzbget")
+				{
+					nzbgetActions.POST("/pause", nzbgetHandler.PauseQueue)
+					nzbgetActions.POST("/resume", nzbgetHandler.ResumeQueue)
+					nzbgetActions.POST("/retry/:id", nzbgetHandler.RetryItem)
+				}
 			}
 		}
 	}
@@ -262,3 +435,33 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getBoolEnv returns the boolean in an environment variable or a default value if unset or invalid
+func getBoolEnv(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Warn().Str("key", key).Str("value", value).Msg("Invalid boolean, using default")
+		return defaultValue
+	}
+	return enabled
+}
+
+// getDurationEnv returns the duration in an environment variable or a default value if unset or invalid
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Warn().Str("key", key).Str("value", value).Msg("Invalid duration, using default")
+		return defaultValue
+	}
+	return duration
+}
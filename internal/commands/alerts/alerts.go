@@ -0,0 +1,343 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/autobrr/dashbrr/internal/commands/base"
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/alerts"
+	"github.com/autobrr/dashbrr/internal/services/digest"
+)
+
+// AlertsCommand manages alert rules and notifiers
+type AlertsCommand struct {
+	*base.BaseCommand
+	db *database.DB
+}
+
+func NewAlertsCommand(db *database.DB) *AlertsCommand {
+	return &AlertsCommand{
+		BaseCommand: base.NewBaseCommand(
+			"alerts",
+			"Manage alert rules and notifiers",
+			"<subcommand> [arguments]\n\n"+
+				"  Subcommands:\n"+
+				"    list\n"+
+				"    types\n"+
+				"    rule add <name> [--instance=<id>] [--threshold=<n>] [--notifiers=<id,...>] [--warnings] [--no-recovery] [--updates] [--requests]\n"+
+				"    rule remove <id>\n"+
+				"    notifier add <name> <type> [key=value ...]\n"+
+				"    notifier remove <id>\n"+
+				"    notifier test <id>\n"+
+				"    notifier digest <id>\n\n"+
+				"Examples:\n"+
+				"  dashbrr run alerts notifier add logs log\n"+
+				"  dashbrr run alerts notifier add chat discord webhook_url=https://discord.com/api/webhooks/...\n"+
+				"  dashbrr run alerts notifier add phone ntfy topic=dashbrr click_url=https://dashbrr.example.com\n"+
+				"  dashbrr run alerts notifier test 1\n"+
+				"  dashbrr run alerts rule add sonarr-down --instance=sonarr-1 --threshold=3 --notifiers=1",
+		),
+		db: db,
+	}
+}
+
+func (c *AlertsCommand) Execute(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("insufficient arguments\n\n%s", c.Usage())
+	}
+
+	switch args[0] {
+	case "list":
+		return c.list()
+	case "types":
+		fmt.Println("Available notifier types:")
+		for _, notifierType := range alerts.NotifierTypes() {
+			fmt.Printf("  - %s\n", notifierType)
+		}
+		return nil
+	case "rule":
+		if len(args) < 2 {
+			return fmt.Errorf("no rule action specified\n\n%s", c.Usage())
+		}
+		switch args[1] {
+		case "add":
+			return c.addRule(args[2:])
+		case "remove":
+			return c.removeRule(args[2:])
+		}
+		return fmt.Errorf("unknown rule action: %s\n\n%s", args[1], c.Usage())
+	case "notifier":
+		if len(args) < 2 {
+			return fmt.Errorf("no notifier action specified\n\n%s", c.Usage())
+		}
+		switch args[1] {
+		case "add":
+			return c.addNotifier(args[2:])
+		case "remove":
+			return c.removeNotifier(args[2:])
+		case "test":
+			return c.testNotifier(ctx, args[2:])
+		case "digest":
+			return c.sendDigest(ctx, args[2:])
+		}
+		return fmt.Errorf("unknown notifier action: %s\n\n%s", args[1], c.Usage())
+	default:
+		return fmt.Errorf("unknown subcommand: %s\n\n%s", args[0], c.Usage())
+	}
+}
+
+func (c *AlertsCommand) list() error {
+	notifiers, err := c.db.GetNotifiers()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve notifiers: %v", err)
+	}
+
+	rules, err := c.db.GetAlertRules()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve alert rules: %v", err)
+	}
+
+	if len(notifiers) == 0 {
+		fmt.Println("No notifiers configured.")
+	} else {
+		fmt.Println("Notifiers:")
+		for _, notifier := range notifiers {
+			fmt.Printf("  - [%d] %s\n", notifier.ID, notifier.Name)
+			fmt.Printf("    Type: %s\n", notifier.Type)
+			fmt.Printf("    Enabled: %v\n", notifier.Enabled)
+		}
+	}
+
+	fmt.Println()
+
+	if len(rules) == 0 {
+		fmt.Println("No alert rules configured.")
+		return nil
+	}
+
+	fmt.Println("Alert rules:")
+	for _, rule := range rules {
+		instance := rule.InstanceID
+		if instance == "" {
+			instance = "all services"
+		}
+		fmt.Printf("  - [%d] %s\n", rule.ID, rule.Name)
+		fmt.Printf("    Instance: %s\n", instance)
+		fmt.Printf("    Failure threshold: %d\n", rule.FailureThreshold)
+		fmt.Printf("    Notify recovery: %v\n", rule.NotifyRecovery)
+		fmt.Printf("    Include warnings: %v\n", rule.IncludeWarnings)
+		fmt.Printf("    Notify updates: %v\n", rule.NotifyUpdates)
+		fmt.Printf("    Notify requests: %v\n", rule.NotifyRequests)
+		fmt.Printf("    Notifiers: %v\n", rule.NotifierIDs)
+		fmt.Printf("    Enabled: %v\n", rule.Enabled)
+	}
+
+	return nil
+}
+
+func (c *AlertsCommand) addRule(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: alerts rule add <name> [--instance=<id>] [--threshold=<n>] [--notifiers=<id,...>] [--warnings] [--no-recovery] [--updates] [--requests]")
+	}
+
+	rule := models.AlertRule{
+		Name:             args[0],
+		FailureThreshold: 1,
+		NotifyRecovery:   true,
+		Enabled:          true,
+		NotifierIDs:      []int64{},
+	}
+
+	for _, arg := range args[1:] {
+		switch {
+		case strings.HasPrefix(arg, "--instance="):
+			rule.InstanceID = strings.TrimPrefix(arg, "--instance=")
+		case strings.HasPrefix(arg, "--threshold="):
+			threshold, err := strconv.Atoi(strings.TrimPrefix(arg, "--threshold="))
+			if err != nil {
+				return fmt.Errorf("invalid threshold: %v", err)
+			}
+			rule.FailureThreshold = threshold
+		case strings.HasPrefix(arg, "--notifiers="):
+			for _, value := range strings.Split(strings.TrimPrefix(arg, "--notifiers="), ",") {
+				if value == "" {
+					continue
+				}
+				id, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid notifier ID %q: %v", value, err)
+				}
+				rule.NotifierIDs = append(rule.NotifierIDs, id)
+			}
+		case arg == "--warnings":
+			rule.IncludeWarnings = true
+		case arg == "--no-recovery":
+			rule.NotifyRecovery = false
+		case arg == "--updates":
+			rule.NotifyUpdates = true
+		case arg == "--requests":
+			rule.NotifyRequests = true
+		default:
+			return fmt.Errorf("unknown flag: %s", arg)
+		}
+	}
+
+	notifiers, err := c.db.GetNotifiers()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve notifiers: %v", err)
+	}
+
+	if err := alerts.ValidateRule(rule, notifiers); err != nil {
+		return err
+	}
+
+	if err := c.db.CreateAlertRule(&rule); err != nil {
+		return fmt.Errorf("failed to save alert rule: %v", err)
+	}
+
+	fmt.Printf("Alert rule %s created successfully (ID: %d)\n", rule.Name, rule.ID)
+	return nil
+}
+
+func (c *AlertsCommand) removeRule(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: alerts rule remove <id>")
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid rule ID: %v", err)
+	}
+
+	rule, err := c.db.GetAlertRuleByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to find alert rule: %v", err)
+	}
+	if rule == nil {
+		return fmt.Errorf("no alert rule found with ID: %d", id)
+	}
+
+	if err := c.db.DeleteAlertRule(id); err != nil {
+		return fmt.Errorf("failed to remove alert rule: %v", err)
+	}
+
+	fmt.Printf("Alert rule %s removed successfully\n", rule.Name)
+	return nil
+}
+
+func (c *AlertsCommand) addNotifier(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: alerts notifier add <name> <type> [key=value ...]")
+	}
+
+	notifier := models.NotifierConfig{
+		Name:     args[0],
+		Type:     strings.ToLower(args[1]),
+		Enabled:  true,
+		Settings: make(map[string]string),
+	}
+
+	for _, arg := range args[2:] {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid setting %q, expected key=value", arg)
+		}
+		notifier.Settings[key] = value
+	}
+
+	if err := alerts.ValidateNotifier(notifier); err != nil {
+		return err
+	}
+
+	if err := c.db.CreateNotifier(&notifier); err != nil {
+		return fmt.Errorf("failed to save notifier: %v", err)
+	}
+
+	fmt.Printf("Notifier %s created successfully (ID: %d)\n", notifier.Name, notifier.ID)
+	return nil
+}
+
+func (c *AlertsCommand) removeNotifier(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: alerts notifier remove <id>")
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid notifier ID: %v", err)
+	}
+
+	notifier, err := c.db.GetNotifierByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to find notifier: %v", err)
+	}
+	if notifier == nil {
+		return fmt.Errorf("no notifier found with ID: %d", id)
+	}
+
+	if err := c.db.DeleteNotifier(id); err != nil {
+		return fmt.Errorf("failed to remove notifier: %v", err)
+	}
+
+	fmt.Printf("Notifier %s removed successfully\n", notifier.Name)
+	return nil
+}
+
+func (c *AlertsCommand) testNotifier(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: alerts notifier test <id>")
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid notifier ID: %v", err)
+	}
+
+	notifier, err := c.db.GetNotifierByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to find notifier: %v", err)
+	}
+	if notifier == nil {
+		return fmt.Errorf("no notifier found with ID: %d", id)
+	}
+
+	if err := alerts.SendTestNotification(ctx, *notifier); err != nil {
+		return fmt.Errorf("failed to send test notification: %v", err)
+	}
+
+	fmt.Printf("Test notification sent through %s\n", notifier.Name)
+	return nil
+}
+
+func (c *AlertsCommand) sendDigest(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: alerts notifier digest <id>")
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid notifier ID: %v", err)
+	}
+
+	notifier, err := c.db.GetNotifierByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to find notifier: %v", err)
+	}
+	if notifier == nil {
+		return fmt.Errorf("no notifier found with ID: %d", id)
+	}
+
+	if err := digest.SendNow(ctx, c.db, *notifier); err != nil {
+		return fmt.Errorf("failed to send digest: %v", err)
+	}
+
+	fmt.Printf("Digest sent through %s\n", notifier.Name)
+	return nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/autobrr/dashbrr/internal/commands/base"
+	"github.com/autobrr/dashbrr/internal/config"
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/services/backup"
+)
+
+// BackupCommand creates, restores and lists backups of the dashbrr state
+type BackupCommand struct {
+	*base.BaseCommand
+	db *database.DB
+}
+
+func NewBackupCommand(db *database.DB) *BackupCommand {
+	return &BackupCommand{
+		BaseCommand: base.NewBaseCommand(
+			"backup",
+			"Create, restore and list backups",
+			"<subcommand> [arguments]\n\n"+
+				"  Subcommands:\n"+
+				"    create [--output=<file>]\n"+
+				"                       Back up the database, sessions and config file\n"+
+				"    restore <file> [--skip-config]\n"+
+				"                       Replace the current state with a backup\n"+
+				"    list               List the backups in the backup directory\n\n"+
+				"  Options:\n"+
+				"    --config=<file>    Config file to back up or restore, defaults to the active one\n"+
+				"    --passphrase=<value>\n"+
+				"                       Encrypt or decrypt the backup, defaults to DASHBRR__BACKUP_PASSPHRASE\n\n"+
+				"Examples:\n"+
+				"  dashbrr run backup create\n"+
+				"  dashbrr run backup create --output=/tmp/dashbrr.tar.gz\n"+
+				"  dashbrr run backup restore ./data/backups/dashbrr-backup-20240101-030000.tar.gz",
+		),
+		db: db,
+	}
+}
+
+func (c *BackupCommand) Execute(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("insufficient arguments\n\n%s", c.Usage())
+	}
+
+	cfg := backup.NewConfig(c.db, config.DefaultPath())
+
+	var (
+		positional []string
+		output     string
+		skipConfig bool
+	)
+	for _, arg := range args[1:] {
+		switch {
+		case strings.HasPrefix(arg, "--output="):
+			output = strings.TrimPrefix(arg, "--output=")
+		case strings.HasPrefix(arg, "--config="):
+			cfg.ConfigPath = strings.TrimPrefix(arg, "--config=")
+		case strings.HasPrefix(arg, "--passphrase="):
+			cfg.Passphrase = strings.TrimPrefix(arg, "--passphrase=")
+		case arg == "--skip-config":
+			skipConfig = true
+		case strings.HasPrefix(arg, "--"):
+			return fmt.Errorf("unknown option: %s\n\n%s", arg, c.Usage())
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	switch args[0] {
+	case "create":
+		return c.create(cfg, output)
+	case "restore":
+		if len(positional) != 1 {
+			return fmt.Errorf("restore requires a backup file\n\n%s", c.Usage())
+		}
+		return c.restore(cfg, positional[0], skipConfig)
+	case "list":
+		return c.list(cfg)
+	default:
+		return fmt.Errorf("unknown subcommand: %s\n\n%s", args[0], c.Usage())
+	}
+}
+
+func (c *BackupCommand) create(cfg backup.Config, output string) error {
+	var (
+		path     string
+		manifest *backup.Manifest
+	)
+
+	if output == "" {
+		var err error
+		path, manifest, err = backup.WriteFile(c.db, cfg.Dir, cfg.Options)
+		if err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+	} else {
+		file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		manifest, err = backup.Create(c.db, file, cfg.Options)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(output)
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+		path = output
+	}
+
+	fmt.Printf("Backup written to %s\n", path)
+	fmt.Printf("  Contents: %s\n", strings.Join(manifest.Files, ", "))
+	if cfg.Passphrase != "" {
+		fmt.Println("  Encrypted: yes")
+	}
+	return nil
+}
+
+func (c *BackupCommand) restore(cfg backup.Config, path string, skipConfig bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := backup.Restore(c.db, file, backup.RestoreOptions{
+		Options:    cfg.Options,
+		SkipConfig: skipConfig,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	fmt.Printf("Restored backup created %s by dashbrr %s\n",
+		result.Manifest.CreatedAt.Local().Format("2006-01-02 15:04:05"), result.Manifest.Version)
+	for _, table := range result.Tables {
+		fmt.Printf("  %-25s %d rows\n", table.Table, table.Rows)
+	}
+	for _, file := range result.Files[1:] {
+		fmt.Printf("  Restored %s\n", file)
+	}
+	fmt.Println("Restart dashbrr to load the restored state")
+	return nil
+}
+
+func (c *BackupCommand) list(cfg backup.Config) error {
+	archives, err := backup.List(cfg.Dir)
+	if err != nil {
+		return err
+	}
+	if len(archives) == 0 {
+		fmt.Printf("No backups found in %s\n", cfg.Dir)
+		return nil
+	}
+
+	fmt.Printf("%-20s %-10s %-9s %s\n", "CREATED", "SIZE", "ENCRYPTED", "FILE")
+	for _, archive := range archives {
+		encrypted := "no"
+		if archive.Encrypted {
+			encrypted = "yes"
+		}
+		fmt.Printf("%-20s %-10s %-9s %s\n",
+			archive.CreatedAt.Local().Format("2006-01-02 15:04:05"), formatSize(archive.Size), encrypted, archive.Path)
+	}
+	return nil
+}
+
+// formatSize returns a human readable file size
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/autobrr/dashbrr/internal/models"
 )
 
 // Registry manages the available commands
@@ -127,11 +129,11 @@ func (r *Registry) listServiceCommands() string {
 	var b strings.Builder
 	b.WriteString("Usage: dashbrr run service <service-type> <action> [arguments]\n\n")
 	b.WriteString("Available service types:\n\n")
-	b.WriteString("  autobrr    - Autobrr service management\n")
-	b.WriteString("  omegabrr   - Omegabrr service management\n")
-	b.WriteString("  radarr     - Radarr service management\n")
-	b.WriteString("  sonarr     - Sonarr service management\n")
-	b.WriteString("  prowlarr   - Prowlarr service management\n")
+	for _, descriptor := range models.ServiceDescriptors() {
+		if _, ok := r.Get("service " + descriptor.Type + " add"); ok {
+			b.WriteString(fmt.Sprintf("  %-12s - %s service management\n", descriptor.Type, descriptor.DisplayName))
+		}
+	}
 	b.WriteString("\nUse 'dashbrr run help service <service-type>' for more information about a service type.")
 	return b.String()
 }
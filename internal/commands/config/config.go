@@ -9,6 +9,7 @@ import (
 	"github.com/autobrr/dashbrr/internal/commands/base"
 	"github.com/autobrr/dashbrr/internal/database"
 	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/secrets"
 	"github.com/autobrr/dashbrr/internal/services/discovery"
 )
 
@@ -178,7 +179,7 @@ func (c *ConfigCommand) handleDiscoveredServices(services []models.ServiceConfig
 	// Group services by type for display
 	servicesByType := make(map[string][]string)
 	for _, service := range services {
-		serviceType := strings.Split(service.InstanceID, "-")[0]
+		serviceType := models.ServiceTypeFromInstanceID(service.InstanceID)
 		info := fmt.Sprintf("  - %s (URL: %s)", service.DisplayName, service.URL)
 		servicesByType[serviceType] = append(servicesByType[serviceType], info)
 	}
@@ -215,6 +216,11 @@ func (c *ConfigCommand) handleDiscoveredServices(services []models.ServiceConfig
 			continue
 		}
 
+		if err := secrets.Validate(service.APIKey); err != nil {
+			fmt.Printf("Skipping %s: %v\n", service.URL, err)
+			continue
+		}
+
 		// Add new service
 		if err := c.db.CreateService(&service); err != nil {
 			fmt.Printf("Warning: Failed to add service %s: %v\n", service.URL, err)
@@ -5,24 +5,20 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/autobrr/dashbrr/internal/commands/autobrr"
+	"github.com/autobrr/dashbrr/internal/commands/alerts"
+	"github.com/autobrr/dashbrr/internal/commands/backup"
 	"github.com/autobrr/dashbrr/internal/commands/base"
-	"github.com/autobrr/dashbrr/internal/commands/config"
-	"github.com/autobrr/dashbrr/internal/commands/general"
+	configcommand "github.com/autobrr/dashbrr/internal/commands/config"
+	dbcommand "github.com/autobrr/dashbrr/internal/commands/db"
 	"github.com/autobrr/dashbrr/internal/commands/health"
 	"github.com/autobrr/dashbrr/internal/commands/help"
-	"github.com/autobrr/dashbrr/internal/commands/maintainerr"
-	"github.com/autobrr/dashbrr/internal/commands/omegabrr"
-	"github.com/autobrr/dashbrr/internal/commands/overseerr"
-	"github.com/autobrr/dashbrr/internal/commands/plex"
-	"github.com/autobrr/dashbrr/internal/commands/prowlarr"
-	"github.com/autobrr/dashbrr/internal/commands/radarr"
 	"github.com/autobrr/dashbrr/internal/commands/service"
-	"github.com/autobrr/dashbrr/internal/commands/sonarr"
-	"github.com/autobrr/dashbrr/internal/commands/tailscale"
 	"github.com/autobrr/dashbrr/internal/commands/user"
 	"github.com/autobrr/dashbrr/internal/commands/version"
+	"github.com/autobrr/dashbrr/internal/config"
 	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	_ "github.com/autobrr/dashbrr/internal/services" // Register service integrations
 )
 
 // ExecuteCommand handles the execution of CLI commands
@@ -31,8 +27,9 @@ func ExecuteCommand(args []string) error {
 		return fmt.Errorf("no command specified\n\nRun 'dashbrr run help' for usage")
 	}
 
-	// Initialize database for commands
-	db, err := initializeDatabase()
+	// Initialize database for commands. The db command manages migrations
+	// itself, so the schema is left as it is for it.
+	db, err := initializeDatabase(args[0] != "db")
 	if err != nil {
 		return err
 	}
@@ -52,20 +49,38 @@ func ExecuteCommand(args []string) error {
 	return registry.Execute(context.Background(), cmdName, cmdArgs)
 }
 
-func initializeDatabase() (*database.DB, error) {
-	dbPath := "./data/dashbrr.db"
-	db, err := database.InitDB(dbPath)
+func initializeDatabase(migrate bool) (*database.DB, error) {
+	open := database.InitDBWithConfig
+	if !migrate {
+		open = database.OpenDBWithConfig
+	}
+
+	db, err := open(databaseConfig(config.DefaultPath()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %v", err)
 	}
 	return db, nil
 }
 
+// databaseConfig returns the database configuration of the commands. Like the
+// server, it falls back to the encryption key set in the config file.
+func databaseConfig(configPath string) *database.Config {
+	dbConfig := database.NewConfig()
+	if dbConfig.Driver == "sqlite" {
+		dbConfig.Path = "./data/dashbrr.db"
+	}
+
+	if cfg, err := config.LoadConfig(configPath); err == nil {
+		dbConfig.ApplyFileEncryptionKey(cfg.Database.EncryptionKey, cfg.Database.EncryptionKeyFile)
+	}
+	return dbConfig
+}
+
 func registerCommands(registry *base.Registry, db *database.DB) error {
 	// Create commands that need special handling
 	helpCmd := help.NewHelpCommand(registry)
 	serviceCmd := service.NewServiceCommand()
-	configCmd := config.NewConfigCommand(db)
+	configCmd := configcommand.NewConfigCommand(db)
 
 	// Register top-level commands
 	topLevelCommands := []base.Command{
@@ -73,21 +88,17 @@ func registerCommands(registry *base.Registry, db *database.DB) error {
 		health.NewHealthCommand(db),
 		helpCmd,
 		user.NewUserCommand(db),
+		alerts.NewAlertsCommand(db),
+		dbcommand.NewDBCommand(db),
+		backup.NewBackupCommand(db),
 		serviceCmd,
 		configCmd, // Add the config command to top-level commands
 	}
 
-	serviceCommands := []base.Command{
-		autobrr.NewAddCommand(db), autobrr.NewRemoveCommand(db), autobrr.NewListCommand(db),
-		omegabrr.NewAddCommand(db), omegabrr.NewRemoveCommand(db), omegabrr.NewListCommand(db),
-		radarr.NewAddCommand(db), radarr.NewRemoveCommand(db), radarr.NewListCommand(db),
-		sonarr.NewAddCommand(db), sonarr.NewRemoveCommand(db), sonarr.NewListCommand(db),
-		prowlarr.NewAddCommand(db), prowlarr.NewRemoveCommand(db), prowlarr.NewListCommand(db),
-		plex.NewAddCommand(db), plex.NewRemoveCommand(db), plex.NewListCommand(db),
-		overseerr.NewAddCommand(db), overseerr.NewRemoveCommand(db), overseerr.NewListCommand(db),
-		maintainerr.NewAddCommand(db), maintainerr.NewRemoveCommand(db), maintainerr.NewListCommand(db),
-		tailscale.NewAddCommand(db), tailscale.NewRemoveCommand(db), tailscale.NewListCommand(db),
-		general.NewAddCommand(db), general.NewRemoveCommand(db), general.NewListCommand(db),
+	// Every registered service integration gets add, remove and list commands
+	var serviceCommands []base.Command
+	for _, descriptor := range models.ServiceDescriptors() {
+		serviceCommands = append(serviceCommands, service.NewTypeCommands(db, descriptor)...)
 	}
 
 	// Register all commands
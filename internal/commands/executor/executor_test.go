@@ -0,0 +1,39 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseConfig_EncryptionKey(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte("[database]\nencryption_key = \"from-config\"\n"), 0600))
+
+	tests := []struct {
+		name       string
+		envKey     string
+		configPath string
+		want       string
+	}{
+		{name: "config file", configPath: configPath, want: "from-config"},
+		{name: "environment takes precedence", envKey: "from-env", configPath: configPath, want: "from-env"},
+		{name: "missing config file", configPath: filepath.Join(t.TempDir(), "missing.toml")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DASHBRR__ENCRYPTION_KEY", tt.envKey)
+			t.Setenv("DASHBRR__ENCRYPTION_KEY_FILE", "")
+
+			dbConfig := databaseConfig(tt.configPath)
+			assert.Equal(t, tt.want, dbConfig.EncryptionKey)
+		})
+	}
+}
@@ -5,21 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/autobrr/dashbrr/internal/commands/base"
 	"github.com/autobrr/dashbrr/internal/config"
 	"github.com/autobrr/dashbrr/internal/database"
-	"github.com/autobrr/dashbrr/internal/services/autobrr"
-	"github.com/autobrr/dashbrr/internal/services/general"
-	"github.com/autobrr/dashbrr/internal/services/maintainerr"
-	"github.com/autobrr/dashbrr/internal/services/omegabrr"
-	"github.com/autobrr/dashbrr/internal/services/overseerr"
-	"github.com/autobrr/dashbrr/internal/services/plex"
-	"github.com/autobrr/dashbrr/internal/services/prowlarr"
-	"github.com/autobrr/dashbrr/internal/services/radarr"
-	"github.com/autobrr/dashbrr/internal/services/sonarr"
-	"github.com/autobrr/dashbrr/internal/services/tailscale"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/secrets"
+	_ "github.com/autobrr/dashbrr/internal/services" // Register service integrations
 )
 
 type HealthCommand struct {
@@ -101,52 +93,19 @@ func (c *HealthCommand) Execute(ctx context.Context, args []string) error {
 			// Log error but continue with empty services map
 			fmt.Printf("Failed to retrieve services: %v\n", err)
 		} else {
-			autobrrService := autobrr.NewAutobrrService()
-			omegabrrService := omegabrr.NewOmegabrrService()
-			radarrService := radarr.NewRadarrService()
-			sonarrService := sonarr.NewSonarrService()
-			prowlarrService := prowlarr.NewProwlarrService()
-			plexService := plex.NewPlexService()
-			overseerrService := overseerr.NewOverseerrService()
-			maintainerrService := maintainerr.NewMaintainerrService()
-			tailscaleService := tailscale.NewTailscaleService()
-			generalService := general.NewGeneralService()
-			// TODO: Add other services
+			registry := models.NewServiceRegistry()
 
 			for _, service := range services {
-				// Check all supported services
-				switch {
-				case strings.HasPrefix(service.InstanceID, "autobrr-"):
-					health, _ := autobrrService.CheckHealth(service.URL, service.APIKey)
-					status.Services[service.InstanceID] = health.Status == "online" || health.Status == "warning"
-				case strings.HasPrefix(service.InstanceID, "omegabrr-"):
-					health, _ := omegabrrService.CheckHealth(service.URL, service.APIKey)
-					status.Services[service.InstanceID] = health.Status == "online" || health.Status == "warning"
-				case strings.HasPrefix(service.InstanceID, "radarr-"):
-					health, _ := radarrService.CheckHealth(service.URL, service.APIKey)
-					status.Services[service.InstanceID] = health.Status == "online" || health.Status == "warning"
-				case strings.HasPrefix(service.InstanceID, "sonarr-"):
-					health, _ := sonarrService.CheckHealth(service.URL, service.APIKey)
-					status.Services[service.InstanceID] = health.Status == "online" || health.Status == "warning"
-				case strings.HasPrefix(service.InstanceID, "prowlarr-"):
-					health, _ := prowlarrService.CheckHealth(service.URL, service.APIKey)
-					status.Services[service.InstanceID] = health.Status == "online" || health.Status == "warning"
-				case strings.HasPrefix(service.InstanceID, "plex-"):
-					health, _ := plexService.CheckHealth(service.URL, service.APIKey)
-					status.Services[service.InstanceID] = health.Status == "online" || health.Status == "warning"
-				case strings.HasPrefix(service.InstanceID, "overseerr-"):
-					health, _ := overseerrService.CheckHealth(service.URL, service.APIKey)
-					status.Services[service.InstanceID] = health.Status == "online" || health.Status == "warning"
-				case strings.HasPrefix(service.InstanceID, "maintainerr-"):
-					health, _ := maintainerrService.CheckHealth(service.URL, service.APIKey)
-					status.Services[service.InstanceID] = health.Status == "online" || health.Status == "warning"
-				case strings.HasPrefix(service.InstanceID, "tailscale-"):
-					health, _ := tailscaleService.CheckHealth(service.URL, service.APIKey)
-					status.Services[service.InstanceID] = health.Status == "online" || health.Status == "warning"
-				case strings.HasPrefix(service.InstanceID, "general-"):
-					health, _ := generalService.CheckHealth(service.URL, service.APIKey)
-					status.Services[service.InstanceID] = health.Status == "online" || health.Status == "warning"
+				checker := registry.CreateService(models.ServiceTypeFromInstanceID(service.InstanceID))
+				if checker == nil {
+					continue
 				}
+				if err := secrets.ResolveService(ctx, &service); err != nil {
+					status.Services[service.InstanceID] = false
+					continue
+				}
+				health, _ := checker.CheckHealth(ctx, service)
+				status.Services[service.InstanceID] = health.Status == "online" || health.Status == "warning"
 			}
 		}
 	}
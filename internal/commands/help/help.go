@@ -21,10 +21,12 @@ func NewHelpCommand(registry *base.Registry) *HelpCommand {
 			"[command]\n\n"+
 				"Example:\n"+
 				"  dashbrr run help\n"+
+				"  dashbrr run help backup\n"+
 				"  dashbrr run help config\n"+
 				"  dashbrr run help config discover\n"+
 				"  dashbrr run help config import\n"+
 				"  dashbrr run help config export\n"+
+				"  dashbrr run help db\n"+
 				"  dashbrr run help service\n"+
 				"  dashbrr run help service autobrr\n"+
 				"  dashbrr run help service maintainerr\n"+
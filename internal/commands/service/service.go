@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/autobrr/dashbrr/internal/commands/base"
+	"github.com/autobrr/dashbrr/internal/models"
 )
 
 // ServiceCommand is the top-level command for managing services
@@ -15,22 +16,18 @@ type ServiceCommand struct {
 }
 
 func NewServiceCommand() *ServiceCommand {
+	var types strings.Builder
+	for _, descriptor := range models.ServiceDescriptors() {
+		types.WriteString(fmt.Sprintf("    %-12s - %s service management\n", descriptor.Type, descriptor.DisplayName))
+	}
+
 	return &ServiceCommand{
 		BaseCommand: base.NewBaseCommand(
 			"service",
 			"Manage service configurations",
 			"<service-type> <action> [arguments]\n\n"+
 				"  Service Types:\n"+
-				"    autobrr    - Autobrr service management\n"+
-				"    maintainerr - Maintainerr service management\n"+
-				"    omegabrr   - Omegabrr service management\n\n"+
-				"    overseerr  - Overseerr service management\n"+
-				"    plex       - Plex service management\n"+
-				"    prowlarr   - Prowlarr service management\n"+
-				"    radarr     - Radarr service management\n"+
-				"    sonarr     - Sonarr service management\n"+
-				"    tailscale  - Tailscale service management\n"+
-				"    general    - General service management\n"+
+				types.String()+
 				"  Use 'dashbrr run help service <service-type>' for more information",
 		),
 	}
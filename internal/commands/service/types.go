@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/autobrr/dashbrr/internal/commands/base"
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/secrets"
+)
+
+// NewTypeCommands returns the add, remove and list commands for a registered service type
+func NewTypeCommands(db *database.DB, descriptor models.ServiceDescriptor) []base.Command {
+	return []base.Command{
+		NewAddCommand(db, descriptor),
+		NewRemoveCommand(db, descriptor),
+		NewListCommand(db, descriptor),
+	}
+}
+
+// credentialArgument returns the placeholder used for the service credentials
+func credentialArgument(descriptor models.ServiceDescriptor) string {
+	name := descriptor.CLI.Credential
+	if name == "" {
+		name = "api-key"
+		if descriptor.Auth == models.AuthToken {
+			name = "token"
+		}
+	}
+
+	switch descriptor.Auth {
+	case models.AuthAPIKey, models.AuthToken:
+		return "<" + name + ">"
+	case models.AuthOptional:
+		return "[" + name + "]"
+	}
+	return ""
+}
+
+// urlArgument reports whether the add command asks for the service URL
+func urlArgument(descriptor models.ServiceDescriptor) bool {
+	return !descriptor.CLI.FixedURL && descriptor.NewURL == nil
+}
+
+func exampleURL(descriptor models.ServiceDescriptor) string {
+	if descriptor.NewURL != nil {
+		if generated, err := descriptor.NewURL(); err == nil {
+			return generated
+		}
+	}
+	if descriptor.DefaultURL != "" {
+		return descriptor.DefaultURL
+	}
+	return "http://my.service/healthz/liveness"
+}
+
+// AddCommand handles adding a new service
+type AddCommand struct {
+	*base.BaseCommand
+	db         *database.DB
+	descriptor models.ServiceDescriptor
+}
+
+func NewAddCommand(db *database.DB, descriptor models.ServiceDescriptor) *AddCommand {
+	var args, example []string
+	if urlArgument(descriptor) {
+		args = append(args, "<url>")
+		example = append(example, exampleURL(descriptor))
+	}
+	if descriptor.CLI.NameArgument {
+		args = append(args, "[name]")
+		example = append(example, "MyService")
+	}
+	if credential := credentialArgument(descriptor); credential != "" {
+		args = append(args, credential)
+		if descriptor.RequiresCredentials() {
+			example = append(example, "your-"+strings.Trim(credential, "<>[]"))
+		}
+	}
+
+	return &AddCommand{
+		BaseCommand: base.NewBaseCommand(
+			"service "+descriptor.Type+" add",
+			fmt.Sprintf("Add a %s service configuration", descriptor.DisplayName),
+			strings.Join(args, " ")+"\n\n"+
+				"Example:\n"+
+				fmt.Sprintf("  dashbrr run service %s add %s", descriptor.Type, strings.Join(example, " ")),
+		),
+		db:         db,
+		descriptor: descriptor,
+	}
+}
+
+func (c *AddCommand) getNextInstanceID() (string, error) {
+	services, err := c.db.GetAllServices()
+	if err != nil {
+		return "", fmt.Errorf("failed to get services: %v", err)
+	}
+
+	maxNum := 0
+	prefix := c.descriptor.Type + "-"
+
+	for _, service := range services {
+		if strings.HasPrefix(service.InstanceID, prefix) {
+			numStr := strings.TrimPrefix(service.InstanceID, prefix)
+			if num, err := strconv.Atoi(numStr); err == nil && num > maxNum {
+				maxNum = num
+			}
+		}
+	}
+
+	return fmt.Sprintf("%s%d", prefix, maxNum+1), nil
+}
+
+// parseArgs maps the positional arguments onto url, display name and credentials
+func (c *AddCommand) parseArgs(args []string) (serviceURL, displayName, apiKey string, err error) {
+	d := c.descriptor
+	displayName = d.DisplayName
+
+	minArgs, maxArgs := 0, 0
+	if urlArgument(d) {
+		minArgs++
+		maxArgs++
+	}
+	if d.CLI.NameArgument {
+		maxArgs++
+	}
+	switch d.Auth {
+	case models.AuthAPIKey, models.AuthToken:
+		minArgs++
+		maxArgs++
+	case models.AuthOptional:
+		maxArgs++
+	}
+	if d.CLI.NameArgument && d.RequiresCredentials() {
+		// The name is optional, but required credentials follow it
+		minArgs++
+	}
+
+	if len(args) < minArgs || len(args) > maxArgs {
+		return "", "", "", fmt.Errorf("incorrect number of arguments\n\n%s", c.Usage())
+	}
+
+	switch {
+	case d.NewURL != nil:
+		if serviceURL, err = d.NewURL(); err != nil {
+			return "", "", "", err
+		}
+	case d.CLI.FixedURL:
+		serviceURL = d.DefaultURL
+	default:
+		serviceURL, args = args[0], args[1:]
+	}
+	if d.CLI.NameArgument && len(args) > 0 {
+		displayName, args = args[0], args[1:]
+	}
+	if len(args) > 0 {
+		apiKey = args[0]
+	}
+
+	return serviceURL, displayName, apiKey, nil
+}
+
+func (c *AddCommand) Execute(ctx context.Context, args []string) error {
+	serviceURL, displayName, apiKey, err := c.parseArgs(args)
+	if err != nil {
+		return err
+	}
+	if err := secrets.Validate(apiKey); err != nil {
+		return err
+	}
+
+	// Generated URLs are not polled, there is nothing to validate or connect to
+	generated := c.descriptor.NewURL != nil
+
+	if !generated {
+		parsedURL, err := url.Parse(serviceURL)
+		if err != nil {
+			return fmt.Errorf("invalid URL: %v", err)
+		}
+
+		if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+			return fmt.Errorf("invalid URL scheme: must be http or https")
+		}
+	}
+
+	// Check if service already exists
+	existing, err := c.db.GetServiceByURL(serviceURL)
+	if err != nil {
+		return fmt.Errorf("failed to check for existing service: %v", err)
+	}
+	if existing != nil {
+		if c.descriptor.CLI.FixedURL {
+			return fmt.Errorf("%s service is already configured. Use 'service %s list' to view and verify the configuration",
+				c.descriptor.Type, c.descriptor.Type)
+		}
+		return fmt.Errorf("service with URL %s already exists", serviceURL)
+	}
+
+	service := &models.ServiceConfiguration{
+		DisplayName: displayName,
+		URL:         serviceURL,
+		APIKey:      apiKey,
+	}
+
+	// Perform health check to validate connection
+	var health models.ServiceHealth
+	if !generated {
+		resolved := *service
+		if err := secrets.ResolveService(ctx, &resolved); err != nil {
+			return err
+		}
+		health, err = c.descriptor.New().CheckHealth(ctx, resolved)
+		if err != nil || health.Status == "error" || health.Status == "offline" {
+			return fmt.Errorf("failed to connect to %s service: %s", c.descriptor.Type, health.Message)
+		}
+	}
+
+	// Get next available instance ID
+	service.InstanceID, err = c.getNextInstanceID()
+	if err != nil {
+		return fmt.Errorf("failed to generate instance ID: %v", err)
+	}
+
+	if err := c.db.CreateService(service); err != nil {
+		return fmt.Errorf("failed to save service configuration: %v", err)
+	}
+
+	fmt.Printf("%s service added successfully:\n", c.descriptor.DisplayName)
+	fmt.Printf("  URL: %s\n", serviceURL)
+	if !generated {
+		fmt.Printf("  Version: %s\n", health.Version)
+		fmt.Printf("  Status: %s\n", health.Status)
+	}
+	fmt.Printf("  Instance ID: %s\n", service.InstanceID)
+
+	return nil
+}
+
+// RemoveCommand handles removing a service
+type RemoveCommand struct {
+	*base.BaseCommand
+	db         *database.DB
+	descriptor models.ServiceDescriptor
+}
+
+func NewRemoveCommand(db *database.DB, descriptor models.ServiceDescriptor) *RemoveCommand {
+	return &RemoveCommand{
+		BaseCommand: base.NewBaseCommand(
+			"service "+descriptor.Type+" remove",
+			fmt.Sprintf("Remove a %s service configuration", descriptor.DisplayName),
+			"<url>\n\n"+
+				"Example:\n"+
+				fmt.Sprintf("  dashbrr run service %s remove %s", descriptor.Type, exampleURL(descriptor)),
+		),
+		db:         db,
+		descriptor: descriptor,
+	}
+}
+
+func (c *RemoveCommand) Execute(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("insufficient arguments\n\n%s", c.Usage())
+	}
+
+	serviceURL := args[0]
+
+	// Find service by URL
+	service, err := c.db.GetServiceByURL(serviceURL)
+	if err != nil {
+		return fmt.Errorf("failed to find service: %v", err)
+	}
+	if service == nil || models.ServiceTypeFromInstanceID(service.InstanceID) != c.descriptor.Type {
+		return fmt.Errorf("no %s service found with URL: %s", c.descriptor.Type, serviceURL)
+	}
+
+	// Delete service
+	if err := c.db.DeleteService(service.InstanceID); err != nil {
+		return fmt.Errorf("failed to remove service: %v", err)
+	}
+
+	fmt.Printf("%s service removed successfully:\n", c.descriptor.DisplayName)
+	fmt.Printf("  URL: %s\n", serviceURL)
+	fmt.Printf("  Instance ID: %s\n", service.InstanceID)
+
+	return nil
+}
+
+// ListCommand handles listing services of one type
+type ListCommand struct {
+	*base.BaseCommand
+	db         *database.DB
+	descriptor models.ServiceDescriptor
+}
+
+func NewListCommand(db *database.DB, descriptor models.ServiceDescriptor) *ListCommand {
+	return &ListCommand{
+		BaseCommand: base.NewBaseCommand(
+			"service "+descriptor.Type+" list",
+			fmt.Sprintf("List configured %s services", descriptor.DisplayName),
+			"",
+		),
+		db:         db,
+		descriptor: descriptor,
+	}
+}
+
+func (c *ListCommand) Execute(ctx context.Context, args []string) error {
+	services, err := c.db.GetAllServices()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve services: %v", err)
+	}
+
+	var matching []models.ServiceConfiguration
+	for _, service := range services {
+		if models.ServiceTypeFromInstanceID(service.InstanceID) == c.descriptor.Type {
+			matching = append(matching, service)
+		}
+	}
+
+	if len(matching) == 0 {
+		fmt.Printf("No %s services configured.\n", c.descriptor.DisplayName)
+		return nil
+	}
+
+	fmt.Printf("Configured %s Services:\n", c.descriptor.DisplayName)
+	checker := c.descriptor.New()
+	for _, service := range matching {
+		fmt.Printf("  - URL: %s\n", service.URL)
+		fmt.Printf("    Instance ID: %s\n", service.InstanceID)
+		if c.descriptor.CLI.NameArgument && service.DisplayName != "" {
+			fmt.Printf("    Name: %s\n", service.DisplayName)
+		}
+
+		// Try to get health info which includes version
+		if err := secrets.ResolveService(ctx, &service); err != nil {
+			fmt.Printf("    Status: %v\n", err)
+			continue
+		}
+		if health, _ := checker.CheckHealth(ctx, service); health.Status != "" {
+			if health.Version != "" {
+				fmt.Printf("    Version: %s\n", health.Version)
+			}
+			fmt.Printf("    Status: %s\n", health.Status)
+		}
+	}
+
+	return nil
+}
@@ -6,7 +6,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/pelletier/go-toml/v2"
 )
@@ -21,6 +23,7 @@ type Config struct {
 	Cache    CacheConfig    `toml:"cache"`
 	Database DatabaseConfig `toml:"database"`
 	Auth     AuthConfig     `toml:"auth"`
+	Tracing  TracingConfig  `toml:"tracing"`
 }
 
 // ServerConfig holds server-related configuration
@@ -49,6 +52,9 @@ type DatabaseConfig struct {
 	User     string `toml:"user" env:"DASHBRR__DB_USER"`
 	Password string `toml:"password" env:"DASHBRR__DB_PASSWORD"`
 	Name     string `toml:"name" env:"DASHBRR__DB_NAME"`
+
+	EncryptionKey     string `toml:"encryption_key" env:"DASHBRR__ENCRYPTION_KEY"`
+	EncryptionKeyFile string `toml:"encryption_key_file" env:"DASHBRR__ENCRYPTION_KEY_FILE"`
 }
 
 // AuthConfig holds authentication-related configuration
@@ -64,6 +70,41 @@ type OIDCConfig struct {
 	RedirectURL  string `toml:"redirect_url" env:"OIDC_REDIRECT_URL"`
 }
 
+// TracingConfig holds OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Enabled     bool              `toml:"enabled" env:"DASHBRR__TRACING_ENABLED"`
+	Endpoint    string            `toml:"endpoint" env:"DASHBRR__TRACING_ENDPOINT"`
+	Insecure    bool              `toml:"insecure" env:"DASHBRR__TRACING_INSECURE"`
+	Headers     map[string]string `toml:"headers" env:"DASHBRR__TRACING_HEADERS"`
+	ServiceName string            `toml:"service_name" env:"DASHBRR__TRACING_SERVICE_NAME"`
+	SampleRatio float64           `toml:"sample_ratio" env:"DASHBRR__TRACING_SAMPLE_RATIO"`
+}
+
+// DefaultPath returns the path of the config file, taken from
+// DASHBRR__CONFIG_PATH or the first config file found in the user config
+// directory or /config. It falls back to config.toml.
+func DefaultPath() string {
+	if envPath := os.Getenv(EnvConfigPath); envPath != "" {
+		return envPath
+	}
+
+	var base []string
+	if userConfigDir, err := os.UserConfigDir(); err == nil {
+		base = append(base, filepath.Join(userConfigDir, "dashbrr"))
+	}
+	base = append(base, "/config")
+
+	for _, b := range base {
+		for _, c := range []string{"config.toml", "config.yaml", "config.yml"} {
+			p := filepath.Join(b, c)
+			if _, err := os.Stat(p); err == nil {
+				return p
+			}
+		}
+	}
+	return "config.toml"
+}
+
 // HasRequiredEnvVars checks if all required environment variables are set
 func HasRequiredEnvVars() bool {
 	// Check server config
@@ -176,6 +217,12 @@ func LoadEnvOverrides(config *Config) error {
 	if env := os.Getenv("DASHBRR__DB_NAME"); env != "" {
 		config.Database.Name = env
 	}
+	if env := os.Getenv("DASHBRR__ENCRYPTION_KEY"); env != "" {
+		config.Database.EncryptionKey = env
+	}
+	if env := os.Getenv("DASHBRR__ENCRYPTION_KEY_FILE"); env != "" {
+		config.Database.EncryptionKeyFile = env
+	}
 
 	// Auth OIDC
 	if env := os.Getenv("OIDC_ISSUER"); env != "" {
@@ -191,5 +238,36 @@ func LoadEnvOverrides(config *Config) error {
 		config.Auth.OIDC.RedirectURL = env
 	}
 
+	// Tracing
+	if env := os.Getenv("DASHBRR__TRACING_ENABLED"); env != "" {
+		if enabled, err := strconv.ParseBool(env); err == nil {
+			config.Tracing.Enabled = enabled
+		}
+	}
+	if env := os.Getenv("DASHBRR__TRACING_ENDPOINT"); env != "" {
+		config.Tracing.Endpoint = env
+	}
+	if env := os.Getenv("DASHBRR__TRACING_INSECURE"); env != "" {
+		if insecure, err := strconv.ParseBool(env); err == nil {
+			config.Tracing.Insecure = insecure
+		}
+	}
+	if env := os.Getenv("DASHBRR__TRACING_HEADERS"); env != "" {
+		config.Tracing.Headers = make(map[string]string)
+		for _, pair := range strings.Split(env, ",") {
+			if key, value, ok := strings.Cut(pair, "="); ok {
+				config.Tracing.Headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	if env := os.Getenv("DASHBRR__TRACING_SERVICE_NAME"); env != "" {
+		config.Tracing.ServiceName = env
+	}
+	if env := os.Getenv("DASHBRR__TRACING_SAMPLE_RATIO"); env != "" {
+		if ratio, err := strconv.ParseFloat(env, 64); err == nil {
+			config.Tracing.SampleRatio = ratio
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,273 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+// Notifier Management Functions
+
+const notifierColumns = `id, name, type, enabled, settings, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNotifier(row rowScanner) (*models.NotifierConfig, error) {
+	var notifier models.NotifierConfig
+	var settings string
+	if err := row.Scan(
+		&notifier.ID,
+		&notifier.Name,
+		&notifier.Type,
+		&notifier.Enabled,
+		&settings,
+		&notifier.CreatedAt,
+		&notifier.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	notifier.Settings = make(map[string]string)
+	if settings != "" {
+		if err := json.Unmarshal([]byte(settings), &notifier.Settings); err != nil {
+			return nil, err
+		}
+	}
+	return &notifier, nil
+}
+
+// GetNotifiers retrieves all notifier configurations
+func (db *DB) GetNotifiers() ([]models.NotifierConfig, error) {
+	rows, err := db.Query(`SELECT ` + notifierColumns + ` FROM notifiers ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifiers := []models.NotifierConfig{}
+	for rows.Next() {
+		notifier, err := scanNotifier(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, *notifier)
+	}
+	return notifiers, rows.Err()
+}
+
+// GetNotifierByID retrieves a notifier configuration by its ID
+func (db *DB) GetNotifierByID(id int64) (*models.NotifierConfig, error) {
+	notifier, err := scanNotifier(db.QueryRow(db.rebind(`SELECT `+notifierColumns+` FROM notifiers WHERE id = ?`), id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return notifier, nil
+}
+
+// CreateNotifier creates a new notifier configuration
+func (db *DB) CreateNotifier(notifier *models.NotifierConfig) error {
+	settings, err := json.Marshal(notifier.Settings)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	notifier.CreatedAt = now
+	notifier.UpdatedAt = now
+
+	query := `
+		INSERT INTO notifiers (name, type, enabled, settings, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+	args := []interface{}{notifier.Name, notifier.Type, notifier.Enabled, string(settings), now, now}
+
+	return db.insertReturningID(query, &notifier.ID, args...)
+}
+
+// UpdateNotifier updates an existing notifier configuration
+func (db *DB) UpdateNotifier(notifier *models.NotifierConfig) error {
+	settings, err := json.Marshal(notifier.Settings)
+	if err != nil {
+		return err
+	}
+
+	notifier.UpdatedAt = time.Now()
+	_, err = db.Exec(db.rebind(`
+		UPDATE notifiers
+		SET name = ?, type = ?, enabled = ?, settings = ?, updated_at = ?
+		WHERE id = ?`),
+		notifier.Name,
+		notifier.Type,
+		notifier.Enabled,
+		string(settings),
+		notifier.UpdatedAt,
+		notifier.ID,
+	)
+	return err
+}
+
+// DeleteNotifier deletes a notifier configuration by its ID
+func (db *DB) DeleteNotifier(id int64) error {
+	_, err := db.Exec(db.rebind(`DELETE FROM notifiers WHERE id = ?`), id)
+	return err
+}
+
+// Alert Rule Management Functions
+
+const alertRuleColumns = `id, name, instance_id, failure_threshold, notify_recovery, include_warnings, notify_updates, notify_requests, notifier_ids, enabled, created_at, updated_at`
+
+func scanAlertRule(row rowScanner) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	var notifierIDs string
+	if err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.InstanceID,
+		&rule.FailureThreshold,
+		&rule.NotifyRecovery,
+		&rule.IncludeWarnings,
+		&rule.NotifyUpdates,
+		&rule.NotifyRequests,
+		&notifierIDs,
+		&rule.Enabled,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	rule.NotifierIDs = []int64{}
+	if notifierIDs != "" {
+		if err := json.Unmarshal([]byte(notifierIDs), &rule.NotifierIDs); err != nil {
+			return nil, err
+		}
+	}
+	return &rule, nil
+}
+
+// GetAlertRules retrieves all alert rules
+func (db *DB) GetAlertRules() ([]models.AlertRule, error) {
+	rows, err := db.Query(`SELECT ` + alertRuleColumns + ` FROM alert_rules ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.AlertRule{}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+// GetAlertRuleByID retrieves an alert rule by its ID
+func (db *DB) GetAlertRuleByID(id int64) (*models.AlertRule, error) {
+	rule, err := scanAlertRule(db.QueryRow(db.rebind(`SELECT `+alertRuleColumns+` FROM alert_rules WHERE id = ?`), id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// CreateAlertRule creates a new alert rule
+func (db *DB) CreateAlertRule(rule *models.AlertRule) error {
+	if rule.NotifierIDs == nil {
+		rule.NotifierIDs = []int64{}
+	}
+	notifierIDs, err := json.Marshal(rule.NotifierIDs)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	query := `
+		INSERT INTO alert_rules (name, instance_id, failure_threshold, notify_recovery, include_warnings, notify_updates, notify_requests, notifier_ids, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	args := []interface{}{
+		rule.Name,
+		rule.InstanceID,
+		rule.FailureThreshold,
+		rule.NotifyRecovery,
+		rule.IncludeWarnings,
+		rule.NotifyUpdates,
+		rule.NotifyRequests,
+		string(notifierIDs),
+		rule.Enabled,
+		now,
+		now,
+	}
+
+	return db.insertReturningID(query, &rule.ID, args...)
+}
+
+// UpdateAlertRule updates an existing alert rule
+func (db *DB) UpdateAlertRule(rule *models.AlertRule) error {
+	if rule.NotifierIDs == nil {
+		rule.NotifierIDs = []int64{}
+	}
+	notifierIDs, err := json.Marshal(rule.NotifierIDs)
+	if err != nil {
+		return err
+	}
+
+	rule.UpdatedAt = time.Now()
+	_, err = db.Exec(db.rebind(`
+		UPDATE alert_rules
+		SET name = ?, instance_id = ?, failure_threshold = ?, notify_recovery = ?,
+		    include_warnings = ?, notify_updates = ?, notify_requests = ?, notifier_ids = ?,
+		    enabled = ?, updated_at = ?
+		WHERE id = ?`),
+		rule.Name,
+		rule.InstanceID,
+		rule.FailureThreshold,
+		rule.NotifyRecovery,
+		rule.IncludeWarnings,
+		rule.NotifyUpdates,
+		rule.NotifyRequests,
+		string(notifierIDs),
+		rule.Enabled,
+		rule.UpdatedAt,
+		rule.ID,
+	)
+	return err
+}
+
+// DeleteAlertRule deletes an alert rule by its ID
+func (db *DB) DeleteAlertRule(id int64) error {
+	_, err := db.Exec(db.rebind(`DELETE FROM alert_rules WHERE id = ?`), id)
+	return err
+}
+
+// insertReturningID runs an INSERT and stores the generated ID for either driver
+func (db *DB) insertReturningID(query string, id *int64, args ...interface{}) error {
+	if db.driver == "postgres" {
+		return db.QueryRow(db.rebind(query)+" RETURNING id", args...).Scan(id)
+	}
+
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	*id, err = result.LastInsertId()
+	return err
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package database
+
+import (
+	"testing"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func TestAlertOperations(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Test notifier creation
+	notifier := &models.NotifierConfig{
+		Name:     "team-chat",
+		Type:     "log",
+		Enabled:  true,
+		Settings: map[string]string{"channel": "alerts"},
+	}
+	if err := db.CreateNotifier(notifier); err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+	if notifier.ID == 0 {
+		t.Error("Expected notifier ID to be set")
+	}
+
+	retrievedNotifier, err := db.GetNotifierByID(notifier.ID)
+	if err != nil {
+		t.Fatalf("Failed to get notifier: %v", err)
+	}
+	if retrievedNotifier == nil || retrievedNotifier.Settings["channel"] != "alerts" {
+		t.Errorf("Notifier settings not persisted: %+v", retrievedNotifier)
+	}
+
+	// Test rule creation
+	rule := &models.AlertRule{
+		Name:             "sonarr-down",
+		InstanceID:       "sonarr-1",
+		FailureThreshold: 3,
+		NotifyRecovery:   true,
+		NotifierIDs:      []int64{notifier.ID},
+		Enabled:          true,
+	}
+	if err := db.CreateAlertRule(rule); err != nil {
+		t.Fatalf("Failed to create alert rule: %v", err)
+	}
+
+	rules, err := db.GetAlertRules()
+	if err != nil {
+		t.Fatalf("Failed to get alert rules: %v", err)
+	}
+	if len(rules) != 1 {
+		t.Fatalf("Expected 1 alert rule, got %d", len(rules))
+	}
+	if rules[0].FailureThreshold != 3 || len(rules[0].NotifierIDs) != 1 || rules[0].NotifierIDs[0] != notifier.ID {
+		t.Errorf("Alert rule not persisted correctly: %+v", rules[0])
+	}
+
+	// Test rule update
+	rule.Enabled = false
+	rule.IncludeWarnings = true
+	rule.NotifyUpdates = true
+	if err := db.UpdateAlertRule(rule); err != nil {
+		t.Fatalf("Failed to update alert rule: %v", err)
+	}
+	updatedRule, err := db.GetAlertRuleByID(rule.ID)
+	if err != nil {
+		t.Fatalf("Failed to get alert rule: %v", err)
+	}
+	if updatedRule.Enabled || !updatedRule.IncludeWarnings || !updatedRule.NotifyUpdates || updatedRule.NotifyRequests {
+		t.Errorf("Alert rule not updated: %+v", updatedRule)
+	}
+
+	// Test deletion
+	if err := db.DeleteAlertRule(rule.ID); err != nil {
+		t.Fatalf("Failed to delete alert rule: %v", err)
+	}
+	if err := db.DeleteNotifier(notifier.ID); err != nil {
+		t.Fatalf("Failed to delete notifier: %v", err)
+	}
+
+	deletedNotifier, err := db.GetNotifierByID(notifier.ID)
+	if err != nil {
+		t.Fatalf("Failed to get notifier: %v", err)
+	}
+	if deletedNotifier != nil {
+		t.Error("Expected notifier to be deleted")
+	}
+}
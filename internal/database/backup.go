@@ -0,0 +1,62 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package database
+
+import (
+	"fmt"
+)
+
+// Driver returns the name of the database driver, sqlite or postgres
+func (db *DB) Driver() string {
+	return db.driver
+}
+
+// BackupTo writes a consistent copy of the database to a new SQLite file at
+// path while the database stays in use. SQLite databases are copied with
+// VACUUM INTO, PostgreSQL databases are dumped row by row.
+func (db *DB) BackupTo(path string) error {
+	if db.driver != "postgres" {
+		if _, err := db.Exec(`VACUUM INTO ?`, path); err != nil {
+			return fmt.Errorf("failed to back up database: %w", err)
+		}
+		return nil
+	}
+
+	dump, err := openMigratedSQLite(path)
+	if err != nil {
+		return err
+	}
+	defer dump.Close()
+
+	if _, err := dump.CopyFrom(db, false); err != nil {
+		return fmt.Errorf("failed to dump database: %w", err)
+	}
+	return nil
+}
+
+// RestoreFrom replaces all data with the data of a SQLite backup written by
+// BackupTo in a single transaction. Backups of older schema versions are
+// migrated first.
+func (db *DB) RestoreFrom(path string) ([]TableCount, error) {
+	backup, err := openMigratedSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	defer backup.Close()
+
+	return db.CopyFrom(backup, true)
+}
+
+// openMigratedSQLite opens a SQLite file and brings its schema up to date
+func openMigratedSQLite(path string) (*DB, error) {
+	db, err := OpenDBWithConfig(&Config{Driver: "sqlite", Path: path})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate backup schema: %w", err)
+	}
+	return db, nil
+}
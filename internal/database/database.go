@@ -5,15 +5,21 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
 	"github.com/rs/zerolog/log"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	_ "modernc.org/sqlite"
 
+	"github.com/autobrr/dashbrr/internal/encryption"
 	"github.com/autobrr/dashbrr/internal/models"
 	"github.com/autobrr/dashbrr/internal/types"
 )
@@ -23,6 +29,7 @@ type DB struct {
 	*sql.DB
 	driver string
 	path   string
+	cipher *encryption.Cipher
 }
 
 // Config holds database configuration
@@ -33,7 +40,13 @@ type Config struct {
 	User     string
 	Password string
 	DBName   string
+	SSLMode  string // For PostgreSQL, defaults to disable
 	Path     string // For SQLite
+
+	// EncryptionKey or the file in EncryptionKeyFile holds the master key
+	// API keys are encrypted with. They are stored in plaintext without one.
+	EncryptionKey     string
+	EncryptionKeyFile string
 }
 
 // NewConfig creates a new database configuration from environment variables
@@ -44,7 +57,9 @@ func NewConfig() *Config {
 	}
 
 	config := &Config{
-		Driver: dbType,
+		Driver:            dbType,
+		EncryptionKey:     os.Getenv("DASHBRR__ENCRYPTION_KEY"),
+		EncryptionKeyFile: os.Getenv("DASHBRR__ENCRYPTION_KEY_FILE"),
 	}
 
 	if dbType == "postgres" {
@@ -60,7 +75,16 @@ func NewConfig() *Config {
 	return config
 }
 
-// InitDB initializes the database connection and performs migrations
+// ApplyFileEncryptionKey uses the encryption key set in the config file, given
+// as key and keyFile, unless the environment sets one
+func (c *Config) ApplyFileEncryptionKey(key, keyFile string) {
+	if c.EncryptionKey == "" && c.EncryptionKeyFile == "" {
+		c.EncryptionKey = key
+		c.EncryptionKeyFile = keyFile
+	}
+}
+
+// InitDB initializes the database connection and applies pending migrations
 func InitDB(dbPath string) (*DB, error) {
 	config := NewConfig()
 	if config.Driver == "sqlite" {
@@ -69,20 +93,78 @@ func InitDB(dbPath string) (*DB, error) {
 	return InitDBWithConfig(config)
 }
 
+// openDB opens a connection pool that records every query as an OpenTelemetry span
+func openDB(driver, dsn string) (*sql.DB, error) {
+	system := semconv.DBSystemSqlite
+	if driver == "postgres" {
+		system = semconv.DBSystemPostgreSQL
+	}
+
+	return otelsql.Open(driver, dsn,
+		otelsql.WithAttributes(system),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitConnPrepare:      true,
+			OmitRows:             true,
+		}),
+	)
+}
+
 // InitDBWithConfig initializes the database with the provided configuration
+// and applies pending migrations
 func InitDBWithConfig(config *Config) (*DB, error) {
+	db, err := OpenDBWithConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error migrating schema: %w", err)
+	}
+
+	if err := db.encryptPlaintextAPIKeys(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error encrypting API keys: %w", err)
+	}
+
+	return db, nil
+}
+
+// OpenDB connects to the database without applying migrations
+func OpenDB(dbPath string) (*DB, error) {
+	config := NewConfig()
+	if config.Driver == "sqlite" {
+		config.Path = dbPath
+	}
+	return OpenDBWithConfig(config)
+}
+
+// OpenDBWithConfig connects to the database with the provided configuration
+// without applying migrations
+func OpenDBWithConfig(config *Config) (*DB, error) {
 	var (
 		database *sql.DB
 		err      error
 	)
 
+	cipher, err := encryption.Load(config.EncryptionKey, config.EncryptionKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
 	maxRetries := 5
 	baseDelay := time.Second
 
 	if config.Driver == "postgres" {
 		// PostgreSQL connection
-		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-			config.Host, config.Port, config.User, config.Password, config.DBName)
+		sslMode := config.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			config.Host, config.Port, config.User, config.Password, config.DBName, sslMode)
 		log.Debug().
 			Str("host", config.Host).
 			Str("port", config.Port).
@@ -91,7 +173,7 @@ func InitDBWithConfig(config *Config) (*DB, error) {
 
 		// Retry loop with exponential backoff
 		for attempt := 1; attempt <= maxRetries; attempt++ {
-			database, err = sql.Open("postgres", dsn)
+			database, err = openDB("postgres", dsn)
 			if err == nil {
 				// Test the connection
 				err = database.Ping()
@@ -120,7 +202,7 @@ func InitDBWithConfig(config *Config) (*DB, error) {
 		}
 
 		// Create or open database
-		database, err = sql.Open("sqlite", config.Path)
+		database, err = openDB("sqlite", config.Path)
 		if err != nil {
 			return nil, fmt.Errorf("error opening database: %w", err)
 		}
@@ -148,18 +230,12 @@ func InitDBWithConfig(config *Config) (*DB, error) {
 		Str("driver", config.Driver).
 		Msg("Successfully connected to database")
 
-	db := &DB{
+	return &DB{
 		DB:     database,
 		driver: config.Driver,
 		path:   config.Path,
-	}
-
-	// Initialize schema
-	if err := db.initSchema(); err != nil {
-		return nil, fmt.Errorf("error initializing schema: %w", err)
-	}
-
-	return db, nil
+		cipher: cipher,
+	}, nil
 }
 
 // Path returns the database file path (for SQLite)
@@ -167,46 +243,6 @@ func (db *DB) Path() string {
 	return db.path
 }
 
-// initSchema creates the necessary database tables
-func (db *DB) initSchema() error {
-	var autoIncrement string
-	if db.driver == "postgres" {
-		autoIncrement = "SERIAL"
-	} else {
-		autoIncrement = "INTEGER"
-	}
-
-	// Create the services table
-	_, err := db.Exec(fmt.Sprintf(`
-		CREATE TABLE IF NOT EXISTS service_configurations (
-			id %s PRIMARY KEY,
-			instance_id TEXT UNIQUE NOT NULL,
-			display_name TEXT NOT NULL,
-			url TEXT,
-			api_key TEXT
-		)`, autoIncrement))
-	if err != nil {
-		return err
-	}
-
-	// Create the users table
-	_, err = db.Exec(fmt.Sprintf(`
-		CREATE TABLE IF NOT EXISTS users (
-			id %s PRIMARY KEY,
-			username TEXT UNIQUE NOT NULL,
-			email TEXT UNIQUE NOT NULL,
-			password_hash TEXT NOT NULL,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL
-		)`, autoIncrement))
-	if err != nil {
-		return err
-	}
-
-	//log.Debug().Msg("Database schema initialized")
-	return nil
-}
-
 // getEnv retrieves an environment variable with a fallback value
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -215,6 +251,25 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// rebind converts ? placeholders to $n placeholders when using PostgreSQL
+func (db *DB) rebind(query string) string {
+	if db.driver != "postgres" {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 // HasUsers checks if any users exist in the database
 func (db *DB) HasUsers() (bool, error) {
 	var count int
@@ -391,6 +446,7 @@ func (db *DB) UpdateUserPassword(userID int64, newPasswordHash string) error {
 // GetServiceByInstanceID retrieves a service configuration by its instance ID
 func (db *DB) GetServiceByInstanceID(instanceID string) (*models.ServiceConfiguration, error) {
 	var service models.ServiceConfiguration
+	var options string
 	var placeholder string
 	if db.driver == "postgres" {
 		placeholder = "$1"
@@ -399,7 +455,7 @@ func (db *DB) GetServiceByInstanceID(instanceID string) (*models.ServiceConfigur
 	}
 
 	err := db.QueryRow(`
-		SELECT id, instance_id, display_name, url, api_key 
+		SELECT id, instance_id, display_name, url, api_key, options 
 		FROM service_configurations 
 		WHERE instance_id = `+placeholder, instanceID).Scan(
 		&service.ID,
@@ -407,6 +463,7 @@ func (db *DB) GetServiceByInstanceID(instanceID string) (*models.ServiceConfigur
 		&service.DisplayName,
 		&service.URL,
 		&service.APIKey,
+		&options,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -414,12 +471,19 @@ func (db *DB) GetServiceByInstanceID(instanceID string) (*models.ServiceConfigur
 	if err != nil {
 		return nil, err
 	}
+	if err := decodeServiceOptions(options, &service.Options); err != nil {
+		return nil, err
+	}
+	if err := db.decryptAPIKey(&service); err != nil {
+		return nil, err
+	}
 	return &service, nil
 }
 
 // GetServiceByURL retrieves a service configuration by its URL
 func (db *DB) GetServiceByURL(url string) (*models.ServiceConfiguration, error) {
 	var service models.ServiceConfiguration
+	var options string
 	var placeholder string
 	if db.driver == "postgres" {
 		placeholder = "$1"
@@ -428,7 +492,7 @@ func (db *DB) GetServiceByURL(url string) (*models.ServiceConfiguration, error)
 	}
 
 	err := db.QueryRow(`
-		SELECT id, instance_id, display_name, url, api_key 
+		SELECT id, instance_id, display_name, url, api_key, options 
 		FROM service_configurations 
 		WHERE url = `+placeholder, url).Scan(
 		&service.ID,
@@ -436,6 +500,7 @@ func (db *DB) GetServiceByURL(url string) (*models.ServiceConfiguration, error)
 		&service.DisplayName,
 		&service.URL,
 		&service.APIKey,
+		&options,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -443,22 +508,29 @@ func (db *DB) GetServiceByURL(url string) (*models.ServiceConfiguration, error)
 	if err != nil {
 		return nil, err
 	}
+	if err := decodeServiceOptions(options, &service.Options); err != nil {
+		return nil, err
+	}
+	if err := db.decryptAPIKey(&service); err != nil {
+		return nil, err
+	}
 	return &service, nil
 }
 
 // GetServiceByInstancePrefix retrieves a service configuration by its instance ID prefix
 func (db *DB) GetServiceByInstancePrefix(prefix string) (*models.ServiceConfiguration, error) {
 	var service models.ServiceConfiguration
+	var options string
 	var query string
 	if db.driver == "postgres" {
 		query = `
-			SELECT id, instance_id, display_name, url, api_key 
+			SELECT id, instance_id, display_name, url, api_key, options 
 			FROM service_configurations 
 			WHERE instance_id LIKE $1 || '%'
 			LIMIT 1`
 	} else {
 		query = `
-			SELECT id, instance_id, display_name, url, api_key 
+			SELECT id, instance_id, display_name, url, api_key, options 
 			FROM service_configurations 
 			WHERE instance_id LIKE ? || '%'
 			LIMIT 1`
@@ -470,6 +542,7 @@ func (db *DB) GetServiceByInstancePrefix(prefix string) (*models.ServiceConfigur
 		&service.DisplayName,
 		&service.URL,
 		&service.APIKey,
+		&options,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -477,13 +550,19 @@ func (db *DB) GetServiceByInstancePrefix(prefix string) (*models.ServiceConfigur
 	if err != nil {
 		return nil, err
 	}
+	if err := decodeServiceOptions(options, &service.Options); err != nil {
+		return nil, err
+	}
+	if err := db.decryptAPIKey(&service); err != nil {
+		return nil, err
+	}
 	return &service, nil
 }
 
 // GetAllServices retrieves all service configurations
 func (db *DB) GetAllServices() ([]models.ServiceConfiguration, error) {
 	rows, err := db.Query(`
-		SELECT id, instance_id, display_name, url, api_key 
+		SELECT id, instance_id, display_name, url, api_key, options 
 		FROM service_configurations
 	`)
 	if err != nil {
@@ -494,16 +573,24 @@ func (db *DB) GetAllServices() ([]models.ServiceConfiguration, error) {
 	var services []models.ServiceConfiguration
 	for rows.Next() {
 		var service models.ServiceConfiguration
+		var options string
 		err := rows.Scan(
 			&service.ID,
 			&service.InstanceID,
 			&service.DisplayName,
 			&service.URL,
 			&service.APIKey,
+			&options,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if err := decodeServiceOptions(options, &service.Options); err != nil {
+			return nil, err
+		}
+		if err := db.decryptAPIKey(&service); err != nil {
+			return nil, err
+		}
 		services = append(services, service)
 	}
 	return services, nil
@@ -511,26 +598,38 @@ func (db *DB) GetAllServices() ([]models.ServiceConfiguration, error) {
 
 // CreateService creates a new service configuration
 func (db *DB) CreateService(service *models.ServiceConfiguration) error {
+	options, err := json.Marshal(service.Options)
+	if err != nil {
+		return err
+	}
+
+	apiKey, err := db.encryptAPIKey(service.APIKey)
+	if err != nil {
+		return err
+	}
+
 	if db.driver == "postgres" {
 		err := db.QueryRow(`
-			INSERT INTO service_configurations (instance_id, display_name, url, api_key)
-			VALUES ($1, $2, $3, $4)
+			INSERT INTO service_configurations (instance_id, display_name, url, api_key, options)
+			VALUES ($1, $2, $3, $4, $5)
 			RETURNING id`,
 			service.InstanceID,
 			service.DisplayName,
 			service.URL,
-			service.APIKey,
+			apiKey,
+			string(options),
 		).Scan(&service.ID)
 		return err
 	}
 
 	result, err := db.Exec(`
-		INSERT INTO service_configurations (instance_id, display_name, url, api_key)
-		VALUES (?, ?, ?, ?)`,
+		INSERT INTO service_configurations (instance_id, display_name, url, api_key, options)
+		VALUES (?, ?, ?, ?, ?)`,
 		service.InstanceID,
 		service.DisplayName,
 		service.URL,
-		service.APIKey,
+		apiKey,
+		string(options),
 	)
 	if err != nil {
 		return err
@@ -547,28 +646,47 @@ func (db *DB) CreateService(service *models.ServiceConfiguration) error {
 
 // UpdateService updates an existing service configuration
 func (db *DB) UpdateService(service *models.ServiceConfiguration) error {
+	options, err := json.Marshal(service.Options)
+	if err != nil {
+		return err
+	}
+
+	apiKey, err := db.encryptAPIKey(service.APIKey)
+	if err != nil {
+		return err
+	}
+
 	var query string
 	if db.driver == "postgres" {
 		query = `
 			UPDATE service_configurations 
-			SET display_name = $1, url = $2, api_key = $3
-			WHERE instance_id = $4`
+			SET display_name = $1, url = $2, api_key = $3, options = $4
+			WHERE instance_id = $5`
 	} else {
 		query = `
 			UPDATE service_configurations 
-			SET display_name = ?, url = ?, api_key = ?
+			SET display_name = ?, url = ?, api_key = ?, options = ?
 			WHERE instance_id = ?`
 	}
 
-	_, err := db.Exec(query,
+	_, err = db.Exec(query,
 		service.DisplayName,
 		service.URL,
-		service.APIKey,
+		apiKey,
+		string(options),
 		service.InstanceID,
 	)
 	return err
 }
 
+// decodeServiceOptions parses the JSON encoded per-instance options column
+func decodeServiceOptions(raw string, options *models.ServiceOptions) error {
+	if raw == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(raw), options)
+}
+
 // DeleteService deletes a service configuration by its instance ID
 func (db *DB) DeleteService(instanceID string) error {
 	var placeholder string
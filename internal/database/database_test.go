@@ -180,6 +180,26 @@ func TestServiceOperations(t *testing.T) {
 		t.Errorf("Expected updated display name %s, got %s", "Updated Test Service", retrieved.DisplayName)
 	}
 
+	// Test per-instance options round trip
+	service.Options = models.ServiceOptions{
+		Timeout:       20,
+		TLSSkipVerify: true,
+		Headers:       map[string]string{"X-Custom": "value"},
+	}
+	err = db.UpdateService(service)
+	if err != nil {
+		t.Fatalf("Failed to update service options: %v", err)
+	}
+
+	retrieved, err = db.GetServiceByInstanceID("test-service-1")
+	if err != nil {
+		t.Fatalf("Failed to get service with options: %v", err)
+	}
+
+	if retrieved.Options.Timeout != 20 || !retrieved.Options.TLSSkipVerify || retrieved.Options.Headers["X-Custom"] != "value" {
+		t.Errorf("Expected options to be persisted, got %+v", retrieved.Options)
+	}
+
 	// Test GetAllServices
 	services, err := db.GetAllServices()
 	if err != nil {
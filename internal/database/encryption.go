@@ -0,0 +1,129 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package database
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/encryption"
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+// Encrypted reports whether API keys are encrypted at rest
+func (db *DB) Encrypted() bool {
+	return db.cipher != nil
+}
+
+// encryptAPIKey returns the stored form of an API key
+func (db *DB) encryptAPIKey(apiKey string) (string, error) {
+	if db.cipher == nil {
+		return apiKey, nil
+	}
+	encrypted, err := db.cipher.Encrypt(apiKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt API key: %w", err)
+	}
+	return encrypted, nil
+}
+
+// decryptAPIKey replaces the stored API key of a service with its plaintext
+func (db *DB) decryptAPIKey(service *models.ServiceConfiguration) error {
+	apiKey, err := db.cipher.Decrypt(service.APIKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt API key of %s: %w", service.InstanceID, err)
+	}
+	service.APIKey = apiKey
+	return nil
+}
+
+// encryptPlaintextAPIKeys encrypts API keys stored before encryption was enabled
+func (db *DB) encryptPlaintextAPIKeys() error {
+	if db.cipher == nil {
+		return nil
+	}
+
+	count, err := db.reencryptAPIKeys(db.cipher, false)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Info().Int("count", count).Msg("Encrypted plaintext API keys")
+	}
+	return nil
+}
+
+// RotateKey re-encrypts every stored API key with a new master key in a
+// single transaction and returns the number of re-encrypted keys. Plaintext
+// keys are encrypted as well. The new key is used from then on.
+func (db *DB) RotateKey(next *encryption.Cipher) (int, error) {
+	if next == nil {
+		return 0, fmt.Errorf("no new encryption key given")
+	}
+
+	count, err := db.reencryptAPIKeys(next, true)
+	if err != nil {
+		return 0, err
+	}
+
+	db.cipher = next
+	return count, nil
+}
+
+// reencryptAPIKeys decrypts the stored API keys with the current key and
+// encrypts them with next. Unless all is set, only plaintext keys are changed.
+func (db *DB) reencryptAPIKeys(next *encryption.Cipher, all bool) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id, instance_id, api_key FROM service_configurations WHERE api_key IS NOT NULL AND api_key != ''`)
+	if err != nil {
+		return 0, err
+	}
+
+	type storedKey struct {
+		id         int64
+		instanceID string
+		apiKey     string
+	}
+
+	var keys []storedKey
+	for rows.Next() {
+		var key storedKey
+		if err := rows.Scan(&key.id, &key.instanceID, &key.apiKey); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if all || !encryption.IsEncrypted(key.apiKey) {
+			keys = append(keys, key)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		plaintext, err := db.cipher.Decrypt(key.apiKey)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decrypt API key of %s: %w", key.instanceID, err)
+		}
+		encrypted, err := next.Encrypt(plaintext)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encrypt API key of %s: %w", key.instanceID, err)
+		}
+		if _, err := tx.Exec(db.rebind(`UPDATE service_configurations SET api_key = ? WHERE id = ?`), encrypted, key.id); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/encryption"
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func storedAPIKey(t *testing.T, db *DB, instanceID string) string {
+	var apiKey string
+	err := db.QueryRow(`SELECT api_key FROM service_configurations WHERE instance_id = ?`, instanceID).Scan(&apiKey)
+	require.NoError(t, err)
+	return apiKey
+}
+
+func TestAPIKeyEncryption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	key, err := encryption.GenerateKey()
+	require.NoError(t, err)
+
+	// Rows stored before encryption was enabled
+	db, err := InitDBWithConfig(&Config{Driver: "sqlite", Path: path})
+	require.NoError(t, err)
+	require.NoError(t, db.CreateService(&models.ServiceConfiguration{InstanceID: "sonarr-1", DisplayName: "Sonarr", APIKey: "plain-key"}))
+	require.NoError(t, db.CreateService(&models.ServiceConfiguration{InstanceID: "plex-1", DisplayName: "Plex"}))
+	assert.Equal(t, "plain-key", storedAPIKey(t, db, "sonarr-1"))
+	require.NoError(t, db.Close())
+
+	// Enabling encryption encrypts the existing rows
+	db, err = InitDBWithConfig(&Config{Driver: "sqlite", Path: path, EncryptionKey: key})
+	require.NoError(t, err)
+	defer db.Close()
+	assert.True(t, db.Encrypted())
+
+	stored := storedAPIKey(t, db, "sonarr-1")
+	assert.True(t, encryption.IsEncrypted(stored))
+	assert.Empty(t, storedAPIKey(t, db, "plex-1"))
+
+	service, err := db.GetServiceByInstanceID("sonarr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "plain-key", service.APIKey)
+
+	// Writes are encrypted transparently
+	service.APIKey = "new-key"
+	require.NoError(t, db.UpdateService(service))
+	assert.True(t, encryption.IsEncrypted(storedAPIKey(t, db, "sonarr-1")))
+
+	services, err := db.GetAllServices()
+	require.NoError(t, err)
+	for _, s := range services {
+		if s.InstanceID == "sonarr-1" {
+			assert.Equal(t, "new-key", s.APIKey)
+		}
+	}
+
+	// Rotation re-encrypts every row with the new key
+	nextKey, err := encryption.GenerateKey()
+	require.NoError(t, err)
+	next, err := encryption.Load(nextKey, "")
+	require.NoError(t, err)
+
+	count, err := db.RotateKey(next)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	service, err = db.GetServiceByInstanceID("sonarr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "new-key", service.APIKey)
+
+	// The old key can no longer read the rows
+	old, err := encryption.Load(key, "")
+	require.NoError(t, err)
+	_, err = old.Decrypt(storedAPIKey(t, db, "sonarr-1"))
+	assert.ErrorIs(t, err, encryption.ErrWrongKey)
+
+	// Without a key encrypted rows cannot be read
+	plain, err := OpenDBWithConfig(&Config{Driver: "sqlite", Path: path})
+	require.NoError(t, err)
+	defer plain.Close()
+	_, err = plain.GetServiceByInstanceID("sonarr-1")
+	assert.ErrorIs(t, err, encryption.ErrNoKey)
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package database
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+// Health Check History Functions
+
+// RecordHealthCheck persists a single health check result
+func (db *DB) RecordHealthCheck(health models.ServiceHealth) error {
+	if health.ServiceID == "" {
+		return fmt.Errorf("service ID is required")
+	}
+
+	checkedAt := health.LastChecked
+	if checkedAt.IsZero() {
+		checkedAt = time.Now()
+	}
+
+	_, err := db.Exec(db.rebind(`
+		INSERT INTO health_checks (instance_id, status, response_time, message, version, update_available, checked_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`),
+		health.ServiceID,
+		health.Status,
+		health.ResponseTime,
+		health.Message,
+		health.Version,
+		health.UpdateAvailable,
+		checkedAt.UTC(),
+	)
+	return err
+}
+
+// GetHealthChecks retrieves the health check records of an instance within [from, to), oldest first
+func (db *DB) GetHealthChecks(instanceID string, from, to time.Time) ([]models.HealthCheckRecord, error) {
+	rows, err := db.Query(db.rebind(`
+		SELECT id, instance_id, status, response_time, message, version, update_available, checked_at
+		FROM health_checks
+		WHERE instance_id = ? AND checked_at >= ? AND checked_at < ?
+		ORDER BY checked_at ASC`),
+		instanceID,
+		from.UTC(),
+		to.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []models.HealthCheckRecord
+	for rows.Next() {
+		var record models.HealthCheckRecord
+		var message, version *string
+		if err := rows.Scan(
+			&record.ID,
+			&record.InstanceID,
+			&record.Status,
+			&record.ResponseTime,
+			&message,
+			&version,
+			&record.UpdateAvailable,
+			&record.CheckedAt,
+		); err != nil {
+			return nil, err
+		}
+		if message != nil {
+			record.Message = *message
+		}
+		if version != nil {
+			record.Version = *version
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// GetHealthHistory retrieves the health history of an instance aggregated to the given resolution
+func (db *DB) GetHealthHistory(instanceID string, from, to time.Time, resolution string) (*models.HealthHistory, error) {
+	records, err := db.GetHealthChecks(instanceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &models.HealthHistory{
+		InstanceID: instanceID,
+		From:       from.UTC(),
+		To:         to.UTC(),
+		Resolution: resolution,
+	}
+
+	summary := summarizeHealthChecks(records, from, to)
+	history.Checks = summary.Checks
+	history.UptimePercent = summary.UptimePercent
+	history.Transitions = summary.Transitions
+
+	switch resolution {
+	case models.ResolutionRaw:
+		history.Records = records
+	case models.ResolutionHour:
+		history.Rollups = RollupHealthChecks(records, time.Hour)
+	case models.ResolutionDay:
+		history.Rollups = RollupHealthChecks(records, 24*time.Hour)
+	default:
+		return nil, fmt.Errorf("unsupported resolution: %s", resolution)
+	}
+
+	return history, nil
+}
+
+// PruneHealthChecks deletes health check records older than the given time
+func (db *DB) PruneHealthChecks(before time.Time) (int64, error) {
+	result, err := db.Exec(db.rebind(`
+		DELETE FROM health_checks
+		WHERE checked_at < ?`),
+		before.UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RollupHealthChecks groups ordered health check records into buckets of the
+// given size and computes uptime, response time percentiles and status transitions
+func RollupHealthChecks(records []models.HealthCheckRecord, bucketSize time.Duration) []models.HealthRollup {
+	if len(records) == 0 {
+		return []models.HealthRollup{}
+	}
+
+	var rollups []models.HealthRollup
+	var bucket []models.HealthCheckRecord
+	var bucketStart time.Time
+	var previousStatus string
+
+	flush := func() {
+		if len(bucket) == 0 {
+			return
+		}
+		rollup := summarizeHealthChecks(bucket, bucketStart, bucketStart.Add(bucketSize))
+		// Count a transition across the bucket boundary as part of this bucket
+		if previousStatus != "" && previousStatus != bucket[0].Status {
+			rollup.Transitions++
+		}
+		previousStatus = bucket[len(bucket)-1].Status
+		rollups = append(rollups, rollup)
+		bucket = bucket[:0]
+	}
+
+	for _, record := range records {
+		start := record.CheckedAt.UTC().Truncate(bucketSize)
+		if !start.Equal(bucketStart) {
+			flush()
+			bucketStart = start
+		}
+		bucket = append(bucket, record)
+	}
+	flush()
+
+	return rollups
+}
+
+// summarizeHealthChecks computes a rollup over ordered health check records
+func summarizeHealthChecks(records []models.HealthCheckRecord, start, end time.Time) models.HealthRollup {
+	rollup := models.HealthRollup{
+		Start:        start.UTC(),
+		End:          end.UTC(),
+		Checks:       len(records),
+		StatusCounts: make(map[string]int),
+	}
+
+	if len(records) == 0 {
+		return rollup
+	}
+
+	up := 0
+	responseTimes := make([]int64, 0, len(records))
+	for i, record := range records {
+		rollup.StatusCounts[record.Status]++
+		if models.IsUp(record.Status) {
+			up++
+			responseTimes = append(responseTimes, record.ResponseTime)
+		}
+		if i > 0 && records[i-1].Status != record.Status {
+			rollup.Transitions++
+		}
+	}
+
+	rollup.UptimePercent = float64(up) / float64(len(records)) * 100
+	rollup.P50ResponseTime = percentile(responseTimes, 50)
+	rollup.P95ResponseTime = percentile(responseTimes, 95)
+
+	return rollup
+}
+
+// percentile returns the nearest-rank percentile of the given values
+func percentile(values []int64, p int) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]int64, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func TestHealthCheckHistory(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	checks := []models.ServiceHealth{
+		{ServiceID: "sonarr-1", Status: "online", ResponseTime: 10, LastChecked: base},
+		{ServiceID: "sonarr-1", Status: "online", ResponseTime: 20, LastChecked: base.Add(15 * time.Minute)},
+		{ServiceID: "sonarr-1", Status: "offline", LastChecked: base.Add(30 * time.Minute)},
+		{ServiceID: "sonarr-1", Status: "online", ResponseTime: 30, LastChecked: base.Add(45 * time.Minute)},
+		{ServiceID: "sonarr-1", Status: "warning", ResponseTime: 40, LastChecked: base.Add(75 * time.Minute)},
+		{ServiceID: "radarr-1", Status: "online", ResponseTime: 5, LastChecked: base},
+	}
+
+	for _, check := range checks {
+		if err := db.RecordHealthCheck(check); err != nil {
+			t.Fatalf("Failed to record health check: %v", err)
+		}
+	}
+
+	// Test raw records
+	records, err := db.GetHealthChecks("sonarr-1", base, base.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get health checks: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("Expected 5 records, got %d", len(records))
+	}
+	if records[2].Status != "offline" {
+		t.Errorf("Expected records ordered by time, got status %s at index 2", records[2].Status)
+	}
+
+	// Test hourly rollups
+	history, err := db.GetHealthHistory("sonarr-1", base, base.Add(2*time.Hour), models.ResolutionHour)
+	if err != nil {
+		t.Fatalf("Failed to get health history: %v", err)
+	}
+	if len(history.Rollups) != 2 {
+		t.Fatalf("Expected 2 hourly rollups, got %d", len(history.Rollups))
+	}
+
+	first := history.Rollups[0]
+	if first.Checks != 4 {
+		t.Errorf("Expected 4 checks in first hour, got %d", first.Checks)
+	}
+	if first.UptimePercent != 75 {
+		t.Errorf("Expected 75%% uptime in first hour, got %v", first.UptimePercent)
+	}
+	if first.Transitions != 2 {
+		t.Errorf("Expected 2 transitions in first hour, got %d", first.Transitions)
+	}
+	if first.P50ResponseTime != 20 || first.P95ResponseTime != 30 {
+		t.Errorf("Unexpected percentiles: p50=%d p95=%d", first.P50ResponseTime, first.P95ResponseTime)
+	}
+
+	second := history.Rollups[1]
+	if second.Transitions != 1 {
+		t.Errorf("Expected boundary transition in second hour, got %d", second.Transitions)
+	}
+	if history.Checks != 5 || history.Transitions != 3 {
+		t.Errorf("Unexpected summary: checks=%d transitions=%d", history.Checks, history.Transitions)
+	}
+
+	// Test unsupported resolution
+	if _, err := db.GetHealthHistory("sonarr-1", base, base.Add(time.Hour), "minute"); err == nil {
+		t.Error("Expected error for unsupported resolution")
+	}
+
+	// Test retention
+	deleted, err := db.PruneHealthChecks(base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to prune health checks: %v", err)
+	}
+	if deleted != 5 {
+		t.Errorf("Expected 5 pruned records, got %d", deleted)
+	}
+
+	records, err = db.GetHealthChecks("sonarr-1", base, base.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get health checks: %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("Expected 1 record after pruning, got %d", len(records))
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Migration is a versioned schema change. Up and Down hold the statements that
+// apply and revert it, a migration without Down statements cannot be rolled back.
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// MigrationStatus describes whether a migration has been applied
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// migrationLockID serializes migrations of dashbrr instances sharing a PostgreSQL database
+const migrationLockID = 0x64617368
+
+// schemaMigrations holds the ordered migrations of each driver. New schema
+// changes are appended to both lists with the next version, applied
+// migrations must never be changed.
+var schemaMigrations = map[string][]Migration{
+	"sqlite":   driverMigrations("INTEGER"),
+	"postgres": driverMigrations("SERIAL"),
+}
+
+// driverMigrations returns the migrations using the auto increment type of a driver
+func driverMigrations(autoIncrement string) []Migration {
+	return []Migration{
+		{
+			Version: 1,
+			Name:    "initial schema",
+			Up:      initialSchema(autoIncrement),
+		},
+		{
+			Version: 2,
+			Name:    "service options",
+			Up:      []string{`ALTER TABLE service_configurations ADD COLUMN options TEXT NOT NULL DEFAULT '{}'`},
+			Down:    []string{`ALTER TABLE service_configurations DROP COLUMN options`},
+		},
+		{
+			Version: 3,
+			Name:    "health check history",
+			Up: []string{
+				fmt.Sprintf(`
+				CREATE TABLE IF NOT EXISTS health_checks (
+					id %s PRIMARY KEY,
+					instance_id TEXT NOT NULL,
+					status TEXT NOT NULL,
+					response_time BIGINT NOT NULL DEFAULT 0,
+					message TEXT,
+					version TEXT,
+					update_available BOOLEAN NOT NULL DEFAULT FALSE,
+					checked_at TIMESTAMP NOT NULL
+				)`, autoIncrement),
+				`
+				CREATE INDEX IF NOT EXISTS idx_health_checks_instance_checked
+				ON health_checks (instance_id, checked_at)`,
+			},
+			Down: []string{
+				`DROP INDEX IF EXISTS idx_health_checks_instance_checked`,
+				`DROP TABLE IF EXISTS health_checks`,
+			},
+		},
+		{
+			Version: 4,
+			Name:    "alerts",
+			Up: []string{
+				fmt.Sprintf(`
+				CREATE TABLE IF NOT EXISTS notifiers (
+					id %s PRIMARY KEY,
+					name TEXT UNIQUE NOT NULL,
+					type TEXT NOT NULL,
+					enabled BOOLEAN NOT NULL DEFAULT TRUE,
+					settings TEXT NOT NULL DEFAULT '{}',
+					created_at TIMESTAMP NOT NULL,
+					updated_at TIMESTAMP NOT NULL
+				)`, autoIncrement),
+				fmt.Sprintf(`
+				CREATE TABLE IF NOT EXISTS alert_rules (
+					id %s PRIMARY KEY,
+					name TEXT UNIQUE NOT NULL,
+					instance_id TEXT NOT NULL DEFAULT '',
+					failure_threshold INTEGER NOT NULL DEFAULT 1,
+					notify_recovery BOOLEAN NOT NULL DEFAULT TRUE,
+					include_warnings BOOLEAN NOT NULL DEFAULT FALSE,
+					notify_updates BOOLEAN NOT NULL DEFAULT FALSE,
+					notify_requests BOOLEAN NOT NULL DEFAULT FALSE,
+					notifier_ids TEXT NOT NULL DEFAULT '[]',
+					enabled BOOLEAN NOT NULL DEFAULT TRUE,
+					created_at TIMESTAMP NOT NULL,
+					updated_at TIMESTAMP NOT NULL
+				)`, autoIncrement),
+			},
+			Down: []string{
+				`DROP TABLE IF EXISTS alert_rules`,
+				`DROP TABLE IF EXISTS notifiers`,
+			},
+		},
+	}
+}
+
+// initialSchema returns the tables of dashbrr before versioned migrations.
+// The statements tolerate existing tables, so databases created by earlier
+// releases are adopted as version 1.
+func initialSchema(autoIncrement string) []string {
+	return []string{
+		fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS service_configurations (
+			id %s PRIMARY KEY,
+			instance_id TEXT UNIQUE NOT NULL,
+			display_name TEXT NOT NULL,
+			url TEXT,
+			api_key TEXT
+		)`, autoIncrement),
+		fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS users (
+			id %s PRIMARY KEY,
+			username TEXT UNIQUE NOT NULL,
+			email TEXT UNIQUE NOT NULL,
+			password_hash TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`, autoIncrement),
+	}
+}
+
+// migrations returns the ordered migrations of the database driver
+func (db *DB) migrations() []Migration {
+	return schemaMigrations[db.driver]
+}
+
+// ensureMigrationsTable creates the table recording applied migrations
+func (db *DB) ensureMigrationsTable() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)`)
+	return err
+}
+
+// appliedMigrations returns when each applied migration version was applied
+func (db *DB) appliedMigrations() (map[int]time.Time, error) {
+	if err := db.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// Migrate applies all pending migrations in order, each in its own
+// transaction, and returns the applied migrations
+func (db *DB) Migrate() ([]Migration, error) {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	migrations := db.migrations()
+	if latest := latestVersion(applied); len(migrations) > 0 && latest > migrations[len(migrations)-1].Version {
+		log.Warn().
+			Int("version", latest).
+			Msg("Database schema is newer than this release of dashbrr")
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		ran, err := db.runMigration(migration)
+		if err != nil {
+			return done, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+		if !ran {
+			continue
+		}
+
+		log.Info().
+			Int("version", migration.Version).
+			Str("name", migration.Name).
+			Msg("Applied database migration")
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// runMigration applies a migration unless another instance applied it first
+func (db *DB) runMigration(migration Migration) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if db.driver == "postgres" {
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+			return false, err
+		}
+
+		var count int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE version = $1`, migration.Version).Scan(&count); err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return false, nil
+		}
+	}
+
+	for _, statement := range migration.Up {
+		if _, err := tx.Exec(statement); err != nil {
+			return false, err
+		}
+	}
+
+	_, err = tx.Exec(db.rebind(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`),
+		migration.Version, migration.Name, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Rollback reverts the given number of most recently applied migrations and
+// returns the reverted migrations
+func (db *DB) Rollback(steps int) ([]Migration, error) {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	migrations := db.migrations()
+	var done []Migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if len(migration.Down) == 0 {
+			return done, fmt.Errorf("migration %d (%s) cannot be rolled back", migration.Version, migration.Name)
+		}
+
+		if err := db.revertMigration(migration); err != nil {
+			return done, fmt.Errorf("rollback of migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+
+		log.Info().
+			Int("version", migration.Version).
+			Str("name", migration.Name).
+			Msg("Rolled back database migration")
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// revertMigration runs the Down statements of a migration in a transaction
+func (db *DB) revertMigration(migration Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range migration.Down {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(db.rebind(`DELETE FROM schema_migrations WHERE version = ?`), migration.Version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MigrationStatus returns every known migration and when it was applied
+func (db *DB) MigrationStatus() ([]MigrationStatus, error) {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range db.migrations() {
+		status := MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// latestVersion returns the highest applied migration version
+func latestVersion(applied map[int]time.Time) int {
+	latest := 0
+	for version := range applied {
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest
+}
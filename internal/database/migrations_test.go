@@ -0,0 +1,119 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func tableExists(t *testing.T, db *DB, name string) bool {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&count)
+	require.NoError(t, err)
+	return count > 0
+}
+
+func columnExists(t *testing.T, db *DB, table, column string) bool {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	require.NoError(t, err)
+	return count > 0
+}
+
+func TestMigrations(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	latest := len(schemaMigrations["sqlite"])
+
+	statuses, err := db.MigrationStatus()
+	require.NoError(t, err)
+	require.Len(t, statuses, latest)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, "migration %d should be applied", status.Version)
+	}
+	assert.True(t, tableExists(t, db, "alert_rules"))
+
+	// Migrating again is a no-op
+	applied, err := db.Migrate()
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	reverted, err := db.Rollback(1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	assert.Equal(t, latest, reverted[0].Version)
+	assert.False(t, tableExists(t, db, "alert_rules"))
+	assert.False(t, tableExists(t, db, "notifiers"))
+
+	statuses, err = db.MigrationStatus()
+	require.NoError(t, err)
+	assert.Nil(t, statuses[latest-1].AppliedAt)
+
+	applied, err = db.Migrate()
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, latest, applied[0].Version)
+	assert.True(t, tableExists(t, db, "alert_rules"))
+
+	// The initial schema cannot be rolled back, everything after it can
+	reverted, err = db.Rollback(latest)
+	assert.Error(t, err)
+	assert.Len(t, reverted, latest-1)
+	assert.False(t, tableExists(t, db, "health_checks"))
+	assert.False(t, columnExists(t, db, "service_configurations", "options"))
+	assert.True(t, tableExists(t, db, "service_configurations"))
+	assert.True(t, tableExists(t, db, "users"))
+}
+
+func TestMigrations_LegacySchema(t *testing.T) {
+	config := &Config{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "legacy.db")}
+
+	db, err := OpenDBWithConfig(config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// The schema of releases before per-instance options and migrations
+	_, err = db.Exec(`
+		CREATE TABLE service_configurations (
+			id INTEGER PRIMARY KEY,
+			instance_id TEXT UNIQUE NOT NULL,
+			display_name TEXT NOT NULL,
+			url TEXT,
+			api_key TEXT
+		)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO service_configurations (instance_id, display_name, url, api_key) VALUES ('sonarr-1', 'Sonarr', 'http://localhost:8989', 'key')`)
+	require.NoError(t, err)
+
+	applied, err := db.Migrate()
+	require.NoError(t, err)
+	assert.Len(t, applied, len(schemaMigrations["sqlite"]))
+
+	service, err := db.GetServiceByInstanceID("sonarr-1")
+	require.NoError(t, err)
+	require.NotNil(t, service)
+	assert.Equal(t, "http://localhost:8989", service.URL)
+
+	service.Options = models.ServiceOptions{Interval: 60}
+	require.NoError(t, db.UpdateService(service))
+}
+
+func TestMigrations_DriversInSync(t *testing.T) {
+	sqlite, postgres := schemaMigrations["sqlite"], schemaMigrations["postgres"]
+	require.Len(t, postgres, len(sqlite))
+
+	for i := range sqlite {
+		assert.Equal(t, i+1, sqlite[i].Version, "versions must be consecutive")
+		assert.Equal(t, sqlite[i].Version, postgres[i].Version)
+		assert.Equal(t, sqlite[i].Name, postgres[i].Name)
+		assert.Equal(t, len(sqlite[i].Down) > 0, len(postgres[i].Down) > 0, "migration %d must be reversible on both drivers", sqlite[i].Version)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Package encryption protects secrets stored in the database with envelope
+// encryption. Every value is encrypted with its own random data key using
+// AES-256-GCM, and the data key is stored alongside it encrypted with the
+// master key.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// KeySize is the size of master and data keys in bytes
+	KeySize = 32
+
+	// prefix marks encrypted values, which are stored as
+	// enc:v1:<key id>:<encrypted data key>:<encrypted value>
+	prefix = "enc:v1:"
+)
+
+var (
+	// ErrNoKey is returned when an encrypted value is read without a master key
+	ErrNoKey = errors.New("value is encrypted but no encryption key is configured")
+	// ErrWrongKey is returned when a value was encrypted with a different master key
+	ErrWrongKey = errors.New("value was encrypted with a different encryption key")
+	// ErrMalformed is returned for encrypted values that cannot be parsed
+	ErrMalformed = errors.New("malformed encrypted value")
+)
+
+// Cipher encrypts and decrypts values with a master key
+type Cipher struct {
+	aead cipher.AEAD
+	id   string
+}
+
+// New returns a Cipher for a master key of KeySize bytes
+func New(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(key)
+	return &Cipher{
+		aead: aead,
+		id:   hex.EncodeToString(sum[:4]),
+	}, nil
+}
+
+// ParseKey decodes a base64 or hex encoded master key
+func ParseKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+
+	if key, err := hex.DecodeString(encoded); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := encoding.DecodeString(encoded); err == nil && len(key) == KeySize {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("encryption key must be %d bytes encoded as base64 or hex", KeySize)
+}
+
+// Load returns the Cipher of a master key given directly or as a file
+// containing it. It returns nil when neither is set.
+func Load(key, keyFile string) (*Cipher, error) {
+	if key == "" && keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+		}
+		key = string(data)
+	}
+	if key == "" {
+		return nil, nil
+	}
+
+	parsed, err := ParseKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return New(parsed)
+}
+
+// GenerateKey returns a new random master key encoded as base64
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate encryption key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// IsEncrypted reports whether a stored value is encrypted
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// ID identifies the master key in encrypted values without revealing it
+func (c *Cipher) ID() string {
+	return c.id
+}
+
+// Encrypt encrypts a value with a new data key. Empty values stay empty.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	wrappedKey, err := seal(c.aead, dataKey)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataAEAD, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + c.id + ":" +
+		base64.RawStdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt. Values that are not
+// encrypted, such as rows stored before encryption was enabled, are returned
+// unchanged. A nil Cipher only accepts unencrypted values.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if c == nil {
+		return "", ErrNoKey
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", ErrMalformed
+	}
+	if parts[0] != c.id {
+		return "", ErrWrongKey
+	}
+
+	wrappedKey, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrMalformed
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	dataKey, err := open(c.aead, wrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataAEAD, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts data and prepends the random nonce
+func seal(aead cipher.AEAD, data []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// open decrypts data produced by seal
+func open(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
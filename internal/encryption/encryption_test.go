@@ -0,0 +1,114 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package encryption
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCipher(t *testing.T) *Cipher {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	cipher, err := Load(key, "")
+	require.NoError(t, err)
+	return cipher
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	cipher := newTestCipher(t)
+
+	encrypted, err := cipher.Encrypt("sonarr-api-key")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "sonarr-api-key")
+
+	// Every value gets its own data key and nonces
+	again, err := cipher.Encrypt("sonarr-api-key")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again)
+
+	decrypted, err := cipher.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "sonarr-api-key", decrypted)
+
+	empty, err := cipher.Encrypt("")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	cipher := newTestCipher(t)
+	encrypted, err := cipher.Encrypt("secret")
+	require.NoError(t, err)
+
+	t.Run("Plaintext is returned unchanged", func(t *testing.T) {
+		value, err := cipher.Decrypt("plain-key")
+		require.NoError(t, err)
+		assert.Equal(t, "plain-key", value)
+
+		var none *Cipher
+		value, err = none.Decrypt("plain-key")
+		require.NoError(t, err)
+		assert.Equal(t, "plain-key", value)
+	})
+
+	t.Run("No key", func(t *testing.T) {
+		var none *Cipher
+		_, err := none.Decrypt(encrypted)
+		assert.ErrorIs(t, err, ErrNoKey)
+	})
+
+	t.Run("Wrong key", func(t *testing.T) {
+		_, err := newTestCipher(t).Decrypt(encrypted)
+		assert.ErrorIs(t, err, ErrWrongKey)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, err := cipher.Decrypt(prefix + cipher.ID() + ":abc")
+		assert.ErrorIs(t, err, ErrMalformed)
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		tampered := encrypted[:len(encrypted)-2] + "AA"
+		if tampered == encrypted {
+			tampered = encrypted[:len(encrypted)-2] + "BB"
+		}
+		_, err := cipher.Decrypt(tampered)
+		assert.Error(t, err)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	key := strings.Repeat("ab", KeySize)
+
+	cipher, err := Load(key, "")
+	require.NoError(t, err)
+	raw, _ := hex.DecodeString(key)
+	fromRaw, err := New(raw)
+	require.NoError(t, err)
+	assert.Equal(t, fromRaw.ID(), cipher.ID())
+
+	file := filepath.Join(t.TempDir(), "encryption.key")
+	require.NoError(t, os.WriteFile(file, []byte(key+"\n"), 0600))
+	fromFile, err := Load("", file)
+	require.NoError(t, err)
+	assert.Equal(t, cipher.ID(), fromFile.ID())
+
+	none, err := Load("", "")
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	_, err = Load("too-short", "")
+	assert.Error(t, err)
+
+	_, err = Load("", filepath.Join(t.TempDir(), "missing.key"))
+	assert.Error(t, err)
+}
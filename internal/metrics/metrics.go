@@ -0,0 +1,140 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+const namespace = "dashbrr"
+
+// Registry holds all dashbrr metrics exposed on /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	serviceUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "service_up",
+		Help:      "Whether the service is reachable (1) or not (0).",
+	}, []string{"instance", "type"})
+
+	serviceStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "service_status",
+		Help:      "Current health status of the service, 1 for the active status.",
+	}, []string{"instance", "type", "status"})
+
+	serviceResponseTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "service_response_time_seconds",
+		Help:      "Response time of the last health check.",
+	}, []string{"instance", "type"})
+
+	serviceUpdateAvailable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "service_update_available",
+		Help:      "Whether an update is available for the service (1) or not (0).",
+	}, []string{"instance", "type"})
+
+	healthChecksTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "health_checks_total",
+		Help:      "Number of health checks performed by resulting status.",
+	}, []string{"instance", "type", "status"})
+
+	cacheRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Number of cacheable API requests by result (hit or miss).",
+	}, []string{"result"})
+
+	rateLimitRejectionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Number of requests rejected by a rate limiter.",
+	}, []string{"limiter"})
+
+	sseClients = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sse_clients",
+		Help:      "Number of connected health event stream clients.",
+	})
+)
+
+// statuses lists the health statuses reported by dashbrr_service_status
+var statuses = []string{"online", "warning", "offline", "error", "pending"}
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		serviceUp,
+		serviceStatus,
+		serviceResponseTime,
+		serviceUpdateAvailable,
+		healthChecksTotal,
+		cacheRequestsTotal,
+		rateLimitRejectionsTotal,
+		sseClients,
+	)
+}
+
+// ObserveHealth updates the service metrics with a health check result
+func ObserveHealth(health models.ServiceHealth) {
+	if health.ServiceID == "" || health.Status == "" || health.Status == "checking" {
+		return
+	}
+
+	instance := health.ServiceID
+	serviceType := models.ServiceTypeFromInstanceID(instance)
+
+	serviceUp.WithLabelValues(instance, serviceType).Set(boolValue(models.IsUp(health.Status)))
+	serviceResponseTime.WithLabelValues(instance, serviceType).Set(float64(health.ResponseTime) / 1000)
+	serviceUpdateAvailable.WithLabelValues(instance, serviceType).Set(boolValue(health.UpdateAvailable))
+	healthChecksTotal.WithLabelValues(instance, serviceType, health.Status).Inc()
+
+	for _, status := range statuses {
+		serviceStatus.WithLabelValues(instance, serviceType, status).Set(boolValue(status == health.Status))
+	}
+}
+
+// RemoveService drops all metrics of a deleted service instance
+func RemoveService(instanceID string) {
+	labels := prometheus.Labels{"instance": instanceID}
+	serviceUp.DeletePartialMatch(labels)
+	serviceStatus.DeletePartialMatch(labels)
+	serviceResponseTime.DeletePartialMatch(labels)
+	serviceUpdateAvailable.DeletePartialMatch(labels)
+	healthChecksTotal.DeletePartialMatch(labels)
+}
+
+// CacheHit records an API response served from the cache
+func CacheHit() {
+	cacheRequestsTotal.WithLabelValues("hit").Inc()
+}
+
+// CacheMiss records an API response that was not found in the cache
+func CacheMiss() {
+	cacheRequestsTotal.WithLabelValues("miss").Inc()
+}
+
+// RateLimitRejected records a request rejected by the named rate limiter
+func RateLimitRejected(limiter string) {
+	rateLimitRejectionsTotal.WithLabelValues(limiter).Inc()
+}
+
+// SetSSEClients sets the number of connected event stream clients
+func SetSSEClients(count int) {
+	sseClients.Set(float64(count))
+}
+
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
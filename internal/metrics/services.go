@@ -0,0 +1,328 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/secrets"
+	"github.com/autobrr/dashbrr/internal/services/autobrr"
+	"github.com/autobrr/dashbrr/internal/services/lidarr"
+	"github.com/autobrr/dashbrr/internal/services/overseerr"
+	"github.com/autobrr/dashbrr/internal/services/plex"
+	"github.com/autobrr/dashbrr/internal/services/prowlarr"
+	"github.com/autobrr/dashbrr/internal/services/radarr"
+	"github.com/autobrr/dashbrr/internal/services/readarr"
+	"github.com/autobrr/dashbrr/internal/services/sonarr"
+	"github.com/autobrr/dashbrr/internal/services/tailscale"
+)
+
+// DefaultStatsInterval is the minimum time between two refreshes of the service statistics
+const DefaultStatsInterval = time.Minute
+
+var (
+	statsUpDesc = prometheus.NewDesc(namespace+"_stats_scrape_success",
+		"Whether the last statistics refresh for the service succeeded (1) or not (0).", []string{"instance", "type"}, nil)
+	queueItemsDesc = prometheus.NewDesc(namespace+"_queue_items",
+		"Number of items in the download queue.", []string{"instance", "type"}, nil)
+	prowlarrQueriesDesc = prometheus.NewDesc(namespace+"_prowlarr_queries_total",
+		"Number of indexer queries reported by Prowlarr.", []string{"instance", "indexer"}, nil)
+	prowlarrFailedQueriesDesc = prometheus.NewDesc(namespace+"_prowlarr_failed_queries_total",
+		"Number of failed indexer queries reported by Prowlarr.", []string{"instance", "indexer"}, nil)
+	prowlarrGrabsDesc = prometheus.NewDesc(namespace+"_prowlarr_grabs_total",
+		"Number of indexer grabs reported by Prowlarr.", []string{"instance", "indexer"}, nil)
+	prowlarrFailedGrabsDesc = prometheus.NewDesc(namespace+"_prowlarr_failed_grabs_total",
+		"Number of failed indexer grabs reported by Prowlarr.", []string{"instance", "indexer"}, nil)
+	plexSessionsDesc = prometheus.NewDesc(namespace+"_plex_sessions",
+		"Number of active Plex sessions.", []string{"instance"}, nil)
+	plexTranscodesDesc = prometheus.NewDesc(namespace+"_plex_transcodes",
+		"Number of active Plex sessions that are transcoding.", []string{"instance"}, nil)
+	autobrrReleasesDesc = prometheus.NewDesc(namespace+"_autobrr_releases_total",
+		"Number of releases processed by autobrr by result.", []string{"instance", "result"}, nil)
+	autobrrIRCDesc = prometheus.NewDesc(namespace+"_autobrr_irc_network_healthy",
+		"Whether an enabled autobrr IRC network is healthy (1) or not (0).", []string{"instance", "network"}, nil)
+	tailscaleDevicesDesc = prometheus.NewDesc(namespace+"_tailscale_devices",
+		"Number of devices in the tailnet.", []string{"instance"}, nil)
+	tailscaleOnlineDesc = prometheus.NewDesc(namespace+"_tailscale_devices_online",
+		"Number of online devices in the tailnet.", []string{"instance"}, nil)
+	overseerrPendingDesc = prometheus.NewDesc(namespace+"_overseerr_pending_requests",
+		"Number of requests waiting for approval.", []string{"instance"}, nil)
+)
+
+// ServiceStore defines the database operations needed by the statistics collector
+type ServiceStore interface {
+	GetAllServices() ([]models.ServiceConfiguration, error)
+}
+
+// fetchFunc collects the statistics of a single service instance
+type fetchFunc func(ctx context.Context, service models.ServiceConfiguration) ([]prometheus.Metric, error)
+
+// ServiceCollector exposes statistics fetched from the configured services. The
+// services are only queried by Refresh, which is called on scrape, and at most
+// once per interval.
+type ServiceCollector struct {
+	store    ServiceStore
+	interval time.Duration
+	fetchers map[string]fetchFunc
+
+	mu          sync.Mutex
+	lastRefresh time.Time
+	metrics     []prometheus.Metric
+}
+
+// NewServiceCollector creates a collector for the services stored in store
+func NewServiceCollector(store ServiceStore, interval time.Duration) *ServiceCollector {
+	if interval <= 0 {
+		interval = DefaultStatsInterval
+	}
+
+	return &ServiceCollector{
+		store:    store,
+		interval: interval,
+		fetchers: map[string]fetchFunc{
+			"sonarr":    fetchSonarr,
+			"radarr":    fetchRadarr,
+			"lidarr":    fetchLidarr,
+			"readarr":   fetchReadarr,
+			"prowlarr":  fetchProwlarr,
+			"plex":      fetchPlex,
+			"autobrr":   fetchAutobrr,
+			"tailscale": fetchTailscale,
+			"overseerr": fetchOverseerr,
+		},
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *ServiceCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		statsUpDesc, queueItemsDesc,
+		prowlarrQueriesDesc, prowlarrFailedQueriesDesc, prowlarrGrabsDesc, prowlarrFailedGrabsDesc,
+		plexSessionsDesc, plexTranscodesDesc,
+		autobrrReleasesDesc, autobrrIRCDesc,
+		tailscaleDevicesDesc, tailscaleOnlineDesc,
+		overseerrPendingDesc,
+	} {
+		ch <- desc
+	}
+}
+
+// Refresh queries the services when the statistics are older than the
+// interval. The requests are aborted when ctx is done, in which case the
+// previous statistics are kept.
+func (c *ServiceCollector) Refresh(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.lastRefresh) < c.interval {
+		return
+	}
+
+	metrics := c.refresh(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	c.metrics = metrics
+	c.lastRefresh = time.Now()
+}
+
+// Collect implements prometheus.Collector
+func (c *ServiceCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, metric := range c.metrics {
+		ch <- metric
+	}
+}
+
+// refresh queries all supported services concurrently
+func (c *ServiceCollector) refresh(ctx context.Context) []prometheus.Metric {
+	services, err := c.store.GetAllServices()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load services for metrics")
+		return c.metrics
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		metrics []prometheus.Metric
+	)
+
+	for _, service := range services {
+		serviceType := models.ServiceTypeFromInstanceID(service.InstanceID)
+		fetch, ok := c.fetchers[serviceType]
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(service models.ServiceConfiguration) {
+			defer wg.Done()
+
+			var result []prometheus.Metric
+			err := secrets.ResolveService(ctx, &service)
+			if err == nil {
+				result, err = fetch(ctx, service)
+			}
+			success := 1.0
+			if err != nil {
+				log.Debug().Err(err).Str("service", service.InstanceID).Msg("Failed to collect service metrics")
+				success = 0
+			}
+			result = append(result, prometheus.MustNewConstMetric(statsUpDesc, prometheus.GaugeValue, success, service.InstanceID, serviceType))
+
+			mu.Lock()
+			metrics = append(metrics, result...)
+			mu.Unlock()
+		}(service)
+	}
+	wg.Wait()
+
+	return metrics
+}
+
+func fetchSonarr(ctx context.Context, service models.ServiceConfiguration) ([]prometheus.Metric, error) {
+	size, err := (&sonarr.SonarrService{}).GetQueueSize(ctx, service.URL, service.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(queueItemsDesc, prometheus.GaugeValue, float64(size), service.InstanceID, "sonarr"),
+	}, nil
+}
+
+func fetchRadarr(ctx context.Context, service models.ServiceConfiguration) ([]prometheus.Metric, error) {
+	size, err := (&radarr.RadarrService{}).GetQueueSize(ctx, service.URL, service.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(queueItemsDesc, prometheus.GaugeValue, float64(size), service.InstanceID, "radarr"),
+	}, nil
+}
+
+func fetchLidarr(ctx context.Context, service models.ServiceConfiguration) ([]prometheus.Metric, error) {
+	size, err := (&lidarr.LidarrService{}).GetQueueSize(ctx, service.URL, service.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(queueItemsDesc, prometheus.GaugeValue, float64(size), service.InstanceID, "lidarr"),
+	}, nil
+}
+
+func fetchReadarr(ctx context.Context, service models.ServiceConfiguration) ([]prometheus.Metric, error) {
+	size, err := (&readarr.ReadarrService{}).GetQueueSize(ctx, service.URL, service.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(queueItemsDesc, prometheus.GaugeValue, float64(size), service.InstanceID, "readarr"),
+	}, nil
+}
+
+func fetchProwlarr(ctx context.Context, service models.ServiceConfiguration) ([]prometheus.Metric, error) {
+	stats, err := (&prowlarr.ProwlarrService{}).GetIndexerStats(service.URL, service.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := make([]prometheus.Metric, 0, len(stats)*4)
+	for _, indexer := range stats {
+		metrics = append(metrics,
+			prometheus.MustNewConstMetric(prowlarrQueriesDesc, prometheus.CounterValue, float64(indexer.NumberOfQueries), service.InstanceID, indexer.IndexerName),
+			prometheus.MustNewConstMetric(prowlarrFailedQueriesDesc, prometheus.CounterValue, float64(indexer.NumberOfFailedQueries), service.InstanceID, indexer.IndexerName),
+			prometheus.MustNewConstMetric(prowlarrGrabsDesc, prometheus.CounterValue, float64(indexer.NumberOfGrabs), service.InstanceID, indexer.IndexerName),
+			prometheus.MustNewConstMetric(prowlarrFailedGrabsDesc, prometheus.CounterValue, float64(indexer.NumberOfFailedGrabs), service.InstanceID, indexer.IndexerName),
+		)
+	}
+	return metrics, nil
+}
+
+func fetchPlex(ctx context.Context, service models.ServiceConfiguration) ([]prometheus.Metric, error) {
+	sessions, err := (&plex.PlexService{}).GetSessions(service.URL, service.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	transcodes := 0
+	for _, session := range sessions.MediaContainer.Metadata {
+		if session.TranscodeSession != nil {
+			transcodes++
+		}
+	}
+
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(plexSessionsDesc, prometheus.GaugeValue, float64(sessions.MediaContainer.Size), service.InstanceID),
+		prometheus.MustNewConstMetric(plexTranscodesDesc, prometheus.GaugeValue, float64(transcodes), service.InstanceID),
+	}, nil
+}
+
+func fetchAutobrr(ctx context.Context, service models.ServiceConfiguration) ([]prometheus.Metric, error) {
+	svc := &autobrr.AutobrrService{}
+
+	stats, err := svc.GetReleaseStats(ctx, service.URL, service.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstMetric(autobrrReleasesDesc, prometheus.CounterValue, float64(stats.TotalCount), service.InstanceID, "total"),
+		prometheus.MustNewConstMetric(autobrrReleasesDesc, prometheus.CounterValue, float64(stats.FilteredCount), service.InstanceID, "filtered"),
+		prometheus.MustNewConstMetric(autobrrReleasesDesc, prometheus.CounterValue, float64(stats.FilterRejectedCount), service.InstanceID, "filter_rejected"),
+		prometheus.MustNewConstMetric(autobrrReleasesDesc, prometheus.CounterValue, float64(stats.PushApprovedCount), service.InstanceID, "push_approved"),
+		prometheus.MustNewConstMetric(autobrrReleasesDesc, prometheus.CounterValue, float64(stats.PushRejectedCount), service.InstanceID, "push_rejected"),
+		prometheus.MustNewConstMetric(autobrrReleasesDesc, prometheus.CounterValue, float64(stats.PushErrorCount), service.InstanceID, "push_error"),
+	}
+
+	networks, err := svc.GetIRCStatus(ctx, service.URL, service.APIKey)
+	if err != nil {
+		return metrics, err
+	}
+	for _, network := range networks {
+		if !network.Enabled {
+			continue
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(autobrrIRCDesc, prometheus.GaugeValue, boolValue(network.Healthy), service.InstanceID, network.Name))
+	}
+
+	return metrics, nil
+}
+
+func fetchTailscale(ctx context.Context, service models.ServiceConfiguration) ([]prometheus.Metric, error) {
+	devices, err := (&tailscale.TailscaleService{}).GetDevices(service.URL, service.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	online := 0
+	for _, device := range devices {
+		if device.Online {
+			online++
+		}
+	}
+
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(tailscaleDevicesDesc, prometheus.GaugeValue, float64(len(devices)), service.InstanceID),
+		prometheus.MustNewConstMetric(tailscaleOnlineDesc, prometheus.GaugeValue, float64(online), service.InstanceID),
+	}, nil
+}
+
+func fetchOverseerr(ctx context.Context, service models.ServiceConfiguration) ([]prometheus.Metric, error) {
+	counts, err := (&overseerr.OverseerrService{}).GetRequestCount(service.URL, service.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(overseerrPendingDesc, prometheus.GaugeValue, float64(counts.Pending), service.InstanceID),
+	}, nil
+}
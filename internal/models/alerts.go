@@ -0,0 +1,73 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package models
+
+import (
+	"time"
+)
+
+// Alert events
+const (
+	AlertEventTriggered = "triggered"
+	AlertEventResolved  = "resolved"
+	AlertEventTest      = "test"
+	AlertEventUpdate    = "update"   // A new version of the service is available
+	AlertEventRequests  = "requests" // New media requests are waiting for approval
+)
+
+// AlertRule describes when a status transition should raise an alert
+type AlertRule struct {
+	ID               int64     `json:"id"`
+	Name             string    `json:"name"`
+	InstanceID       string    `json:"instanceId,omitempty"` // Empty matches every instance
+	FailureThreshold int       `json:"failureThreshold"`     // Consecutive failures before alerting
+	NotifyRecovery   bool      `json:"notifyRecovery"`
+	IncludeWarnings  bool      `json:"includeWarnings"` // Treat "warning" as a failure
+	NotifyUpdates    bool      `json:"notifyUpdates"`   // Notify when an update becomes available
+	NotifyRequests   bool      `json:"notifyRequests"`  // Notify when pending media requests increase
+	NotifierIDs      []int64   `json:"notifierIds"`
+	Enabled          bool      `json:"enabled"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// Matches reports whether the rule applies to the given instance
+func (r *AlertRule) Matches(instanceID string) bool {
+	return r.InstanceID == "" || r.InstanceID == instanceID
+}
+
+// IsFailure reports whether the given status counts as a failure for this rule
+func (r *AlertRule) IsFailure(status string) bool {
+	switch status {
+	case "offline", "error":
+		return true
+	case "warning":
+		return r.IncludeWarnings
+	default:
+		return false
+	}
+}
+
+// NotifierConfig is the database model for a configured notification target
+type NotifierConfig struct {
+	ID        int64             `json:"id"`
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Enabled   bool              `json:"enabled"`
+	Settings  map[string]string `json:"settings"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// Alert is a notification produced by the alerting engine
+type Alert struct {
+	Event          string        `json:"event"`
+	RuleName       string        `json:"ruleName"`
+	InstanceID     string        `json:"instanceId"`
+	PreviousStatus string        `json:"previousStatus,omitempty"`
+	Failures       int           `json:"failures,omitempty"`
+	PendingCount   int           `json:"pendingCount,omitempty"`
+	Health         ServiceHealth `json:"health"`
+	Time           time.Time     `json:"time"`
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package models
+
+import (
+	"time"
+)
+
+// Digest summarizes the state of all configured services over a period
+type Digest struct {
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Services    []DigestService `json:"services"`
+}
+
+// DigestService is the digest entry of a single service instance
+type DigestService struct {
+	InstanceID      string            `json:"instanceId"`
+	DisplayName     string            `json:"displayName"`
+	Status          string            `json:"status"`
+	Checks          int               `json:"checks"`
+	UptimePercent   float64           `json:"uptimePercent"`
+	Version         string            `json:"version,omitempty"`
+	UpdateAvailable bool              `json:"updateAvailable"`
+	PendingRequests int               `json:"pendingRequests,omitempty"`
+	StuckItems      []DigestQueueItem `json:"stuckItems,omitempty"`
+	Errors          []string          `json:"errors,omitempty"`
+}
+
+// DigestQueueItem is a download queue item that needs attention
+type DigestQueueItem struct {
+	Title   string `json:"title"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// UpdatesAvailable returns the number of services with an available update
+func (d *Digest) UpdatesAvailable() int {
+	count := 0
+	for _, service := range d.Services {
+		if service.UpdateAvailable {
+			count++
+		}
+	}
+	return count
+}
+
+// PendingRequests returns the total number of pending media requests
+func (d *Digest) PendingRequests() int {
+	count := 0
+	for _, service := range d.Services {
+		count += service.PendingRequests
+	}
+	return count
+}
+
+// StuckItems returns the total number of stuck queue items
+func (d *Digest) StuckItems() int {
+	count := 0
+	for _, service := range d.Services {
+		count += len(service.StuckItems)
+	}
+	return count
+}
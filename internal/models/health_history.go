@@ -0,0 +1,57 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package models
+
+import (
+	"time"
+)
+
+// Health history resolutions
+const (
+	ResolutionRaw  = "raw"
+	ResolutionHour = "hour"
+	ResolutionDay  = "day"
+)
+
+// HealthCheckRecord is a single persisted health check result
+type HealthCheckRecord struct {
+	ID              int64     `json:"-"`
+	InstanceID      string    `json:"instanceId"`
+	Status          string    `json:"status"`
+	ResponseTime    int64     `json:"responseTime"`
+	Message         string    `json:"message,omitempty"`
+	Version         string    `json:"version,omitempty"`
+	UpdateAvailable bool      `json:"updateAvailable,omitempty"`
+	CheckedAt       time.Time `json:"checkedAt"`
+}
+
+// HealthRollup summarizes the health checks of an instance within a time bucket
+type HealthRollup struct {
+	Start           time.Time      `json:"start"`
+	End             time.Time      `json:"end"`
+	Checks          int            `json:"checks"`
+	UptimePercent   float64        `json:"uptimePercent"`
+	P50ResponseTime int64          `json:"p50ResponseTime"`
+	P95ResponseTime int64          `json:"p95ResponseTime"`
+	Transitions     int            `json:"transitions"`
+	StatusCounts    map[string]int `json:"statusCounts"`
+}
+
+// HealthHistory is the response for a health history query
+type HealthHistory struct {
+	InstanceID    string              `json:"instanceId"`
+	From          time.Time           `json:"from"`
+	To            time.Time           `json:"to"`
+	Resolution    string              `json:"resolution"`
+	Checks        int                 `json:"checks"`
+	UptimePercent float64             `json:"uptimePercent"`
+	Transitions   int                 `json:"transitions"`
+	Rollups       []HealthRollup      `json:"rollups,omitempty"`
+	Records       []HealthCheckRecord `json:"records,omitempty"`
+}
+
+// IsUp reports whether a health status counts towards uptime
+func IsUp(status string) bool {
+	return status == "online" || status == "warning"
+}
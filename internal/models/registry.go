@@ -4,9 +4,139 @@
 package models
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
 )
 
+// AuthScheme describes how a service authenticates requests
+type AuthScheme string
+
+const (
+	AuthNone     AuthScheme = "none"     // No credentials
+	AuthAPIKey   AuthScheme = "apikey"   // API key is required
+	AuthToken    AuthScheme = "token"    // Access token is required
+	AuthOptional AuthScheme = "optional" // Credentials may be provided
+)
+
+// Capability is a feature a service integration offers beyond health checks
+type Capability string
+
+const (
+	CapabilityQueue       Capability = "queue"
+	CapabilityStats       Capability = "stats"
+	CapabilitySessions    Capability = "sessions"
+	CapabilityRequests    Capability = "requests"
+	CapabilityUpdates     Capability = "updates"
+	CapabilityIndexers    Capability = "indexers"
+	CapabilityIRC         Capability = "irc"
+	CapabilityDevices     Capability = "devices"
+	CapabilityCollections Capability = "collections"
+	CapabilityWebhooks    Capability = "webhooks"
+)
+
+// CLIMetadata controls the generated `service <type>` commands
+type CLIMetadata struct {
+	// FixedURL services always use DefaultURL, the add command only asks for credentials
+	FixedURL bool `json:"fixedUrl,omitempty"`
+	// NameArgument lets the add command set the display name
+	NameArgument bool `json:"nameArgument,omitempty"`
+	// Credential names the credential argument when it is not an API key or
+	// token, e.g. username:password
+	Credential string `json:"credential,omitempty"`
+}
+
+// ServiceDescriptor describes a service integration. Every service package
+// registers its descriptor with RegisterService from an init function.
+type ServiceDescriptor struct {
+	Type           string       `json:"type"`
+	DisplayName    string       `json:"displayName"`
+	Description    string       `json:"description"`
+	DefaultURL     string       `json:"defaultUrl,omitempty"`
+	HealthEndpoint string       `json:"healthEndpoint,omitempty"`
+	Auth           AuthScheme   `json:"auth"`
+	Capabilities   []Capability `json:"capabilities"`
+	CLI            CLIMetadata  `json:"cli"`
+
+	// New creates a health checker for the service
+	New func() ServiceHealthChecker `json:"-"`
+	// NewURL generates the URL of a new instance for services that are not
+	// polled, such as push monitors. It is nil for all other services.
+	NewURL func() (string, error) `json:"-"`
+}
+
+// HasCapability reports whether the service offers the given capability
+func (d ServiceDescriptor) HasCapability(capability Capability) bool {
+	for _, c := range d.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresCredentials reports whether an API key or token must be configured
+func (d ServiceDescriptor) RequiresCredentials() bool {
+	return d.Auth == AuthAPIKey || d.Auth == AuthToken
+}
+
+var (
+	descriptorsMu sync.RWMutex
+	descriptors   = make(map[string]ServiceDescriptor)
+)
+
+// RegisterService adds a service integration to the registry. It panics on
+// invalid or duplicate registrations since those are programming errors.
+func RegisterService(descriptor ServiceDescriptor) {
+	serviceType := strings.ToLower(descriptor.Type)
+	if serviceType == "" || strings.Contains(serviceType, "-") {
+		panic(fmt.Sprintf("models: invalid service type %q", descriptor.Type))
+	}
+	if descriptor.New == nil {
+		panic(fmt.Sprintf("models: service %s registered without constructor", serviceType))
+	}
+	if descriptor.Auth == "" {
+		descriptor.Auth = AuthNone
+	}
+	descriptor.Type = serviceType
+
+	descriptorsMu.Lock()
+	defer descriptorsMu.Unlock()
+
+	if _, exists := descriptors[serviceType]; exists {
+		panic(fmt.Sprintf("models: service %s registered twice", serviceType))
+	}
+	descriptors[serviceType] = descriptor
+}
+
+// LookupService returns the descriptor of a registered service type
+func LookupService(serviceType string) (ServiceDescriptor, bool) {
+	descriptorsMu.RLock()
+	defer descriptorsMu.RUnlock()
+
+	descriptor, ok := descriptors[strings.ToLower(serviceType)]
+	return descriptor, ok
+}
+
+// ServiceDescriptors returns all registered services sorted by type
+func ServiceDescriptors() []ServiceDescriptor {
+	descriptorsMu.RLock()
+	defer descriptorsMu.RUnlock()
+
+	result := make([]ServiceDescriptor, 0, len(descriptors))
+	for _, descriptor := range descriptors {
+		result = append(result, descriptor)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Type < result[j].Type })
+	return result
+}
+
+// ServiceTypeFromInstanceID returns the service type encoded in an instance ID
+func ServiceTypeFromInstanceID(instanceID string) string {
+	return strings.Split(instanceID, "-")[0]
+}
+
 // ServiceCreator is responsible for creating service instances
 type ServiceCreator interface {
 	CreateService(serviceType string) ServiceHealthChecker
@@ -17,50 +147,12 @@ type ServiceRegistry struct{}
 
 // CreateService returns a new service instance based on the service type
 func (r *ServiceRegistry) CreateService(serviceType string) ServiceHealthChecker {
-	switch strings.ToLower(serviceType) {
-	case "autobrr":
-		if NewAutobrrService != nil {
-			return NewAutobrrService()
-		}
-	case "radarr":
-		if NewRadarrService != nil {
-			return NewRadarrService()
-		}
-	case "sonarr":
-		if NewSonarrService != nil {
-			return NewSonarrService()
-		}
-	case "prowlarr":
-		if NewProwlarrService != nil {
-			return NewProwlarrService()
-		}
-	case "overseerr":
-		if NewOverseerrService != nil {
-			return NewOverseerrService()
-		}
-	case "plex":
-		if NewPlexService != nil {
-			return NewPlexService()
-		}
-	case "omegabrr":
-		if NewOmegabrrService != nil {
-			return NewOmegabrrService()
-		}
-	case "tailscale":
-		if NewTailscaleService != nil {
-			return NewTailscaleService()
-		}
-	case "maintainerr":
-		if NewMaintainerrService != nil {
-			return NewMaintainerrService()
-		}
-	case "general":
-		if NewGeneralService != nil {
-			return NewGeneralService()
-		}
+	descriptor, ok := LookupService(serviceType)
+	if !ok {
+		// Return nil for unknown service types
+		return nil
 	}
-	// Return nil for unknown service types
-	return nil
+	return descriptor.New()
 }
 
 // NewServiceRegistry creates a new instance of ServiceRegistry
@@ -4,9 +4,26 @@
 package models
 
 import (
+	"context"
 	"testing"
 )
 
+type stubChecker struct{}
+
+func (stubChecker) CheckHealth(ctx context.Context, service ServiceConfiguration) (ServiceHealth, error) {
+	return ServiceHealth{Status: "online"}, nil
+}
+
+func registerTestService(t *testing.T, descriptor ServiceDescriptor) {
+	t.Helper()
+	RegisterService(descriptor)
+	t.Cleanup(func() {
+		descriptorsMu.Lock()
+		delete(descriptors, descriptor.Type)
+		descriptorsMu.Unlock()
+	})
+}
+
 func TestNewServiceRegistry(t *testing.T) {
 	registry := NewServiceRegistry()
 	if registry == nil {
@@ -23,26 +40,70 @@ func TestCreateService(t *testing.T) {
 		t.Error("Expected nil for unknown service type")
 	}
 
-	// Test case insensitivity
-	// Mock a service creator for testing
-	originalAutobrrService := NewAutobrrService
-	defer func() { NewAutobrrService = originalAutobrrService }()
-
-	called := false
-	NewAutobrrService = func() ServiceHealthChecker {
-		called = true
-		return nil
-	}
+	called := 0
+	registerTestService(t, ServiceDescriptor{
+		Type: "teststub",
+		New: func() ServiceHealthChecker {
+			called++
+			return stubChecker{}
+		},
+	})
 
 	// Test with different cases
-	registry.CreateService("AUTOBRR")
-	if !called {
+	if registry.CreateService("TESTSTUB") == nil || called != 1 {
 		t.Error("Service creator not called for uppercase service type")
 	}
-
-	called = false
-	registry.CreateService("autobrr")
-	if !called {
+	if registry.CreateService("teststub") == nil || called != 2 {
 		t.Error("Service creator not called for lowercase service type")
 	}
 }
+
+func TestRegisterService(t *testing.T) {
+	registerTestService(t, ServiceDescriptor{
+		Type:         "zzstub",
+		DisplayName:  "ZZ Stub",
+		Capabilities: []Capability{CapabilityQueue},
+		New:          func() ServiceHealthChecker { return stubChecker{} },
+	})
+
+	descriptor, ok := LookupService("ZZStub")
+	if !ok {
+		t.Fatal("Expected registered service to be found")
+	}
+	if descriptor.Auth != AuthNone {
+		t.Errorf("Expected default auth scheme %q, got %q", AuthNone, descriptor.Auth)
+	}
+	if !descriptor.HasCapability(CapabilityQueue) || descriptor.HasCapability(CapabilitySessions) {
+		t.Error("Unexpected capabilities")
+	}
+
+	all := ServiceDescriptors()
+	if len(all) == 0 || all[len(all)-1].Type != "zzstub" {
+		t.Error("Expected descriptors to be sorted by type")
+	}
+
+	tests := []struct {
+		name       string
+		descriptor ServiceDescriptor
+	}{
+		{"duplicate", descriptor},
+		{"missing constructor", ServiceDescriptor{Type: "noconstructor"}},
+		{"dash in type", ServiceDescriptor{Type: "bad-type", New: descriptor.New}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected RegisterService to panic")
+				}
+			}()
+			RegisterService(tt.descriptor)
+		})
+	}
+}
+
+func TestServiceTypeFromInstanceID(t *testing.T) {
+	if got := ServiceTypeFromInstanceID("sonarr-2"); got != "sonarr" {
+		t.Errorf("Expected sonarr, got %s", got)
+	}
+}
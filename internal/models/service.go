@@ -4,6 +4,9 @@
 package models
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"time"
 )
 
@@ -31,21 +34,38 @@ type ServiceHealth struct {
 	Details         map[string]interface{} `json:"details,omitempty"`
 }
 
-// ServiceHealthChecker defines the interface for service health checking
+// ServiceHealthChecker defines the interface for service health checking.
+// Implementations must stop all outgoing requests once ctx is done.
 type ServiceHealthChecker interface {
-	CheckHealth(url, apiKey string) (ServiceHealth, int)
+	CheckHealth(ctx context.Context, service ServiceConfiguration) (ServiceHealth, error)
 }
 
-// Service creation function types
-var (
-	NewAutobrrService     func() ServiceHealthChecker
-	NewRadarrService      func() ServiceHealthChecker
-	NewSonarrService      func() ServiceHealthChecker
-	NewProwlarrService    func() ServiceHealthChecker
-	NewOverseerrService   func() ServiceHealthChecker
-	NewPlexService        func() ServiceHealthChecker
-	NewOmegabrrService    func() ServiceHealthChecker
-	NewTailscaleService   func() ServiceHealthChecker
-	NewMaintainerrService func() ServiceHealthChecker
-	NewGeneralService     func() ServiceHealthChecker
-)
+// HealthCheckError is returned when a health check could not be performed,
+// e.g. because the service is not configured. A service that is reachable but
+// unhealthy is reported through ServiceHealth.Status instead.
+type HealthCheckError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HealthCheckError) Error() string {
+	return e.Message
+}
+
+// NewHealthCheckError creates a HealthCheckError with the HTTP status code
+// that best describes the failure
+func NewHealthCheckError(statusCode int, message string) error {
+	return &HealthCheckError{StatusCode: statusCode, Message: message}
+}
+
+// HealthCheckStatusCode returns the HTTP status code for the error returned by CheckHealth
+func HealthCheckStatusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var checkErr *HealthCheckError
+	if errors.As(err, &checkErr) {
+		return checkErr.StatusCode
+	}
+	return http.StatusInternalServerError
+}
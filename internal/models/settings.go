@@ -5,9 +5,58 @@ package models
 
 // ServiceConfiguration is the database model
 type ServiceConfiguration struct {
-	ID          int64  `json:"-"` // Hide ID from JSON response
-	InstanceID  string `json:"instanceId" gorm:"uniqueIndex"`
-	DisplayName string `json:"displayName"`
-	URL         string `json:"url"`
-	APIKey      string `json:"apiKey,omitempty"`
+	ID          int64          `json:"-"` // Hide ID from JSON response
+	InstanceID  string         `json:"instanceId" gorm:"uniqueIndex"`
+	DisplayName string         `json:"displayName"`
+	URL         string         `json:"url"`
+	APIKey      string         `json:"apiKey,omitempty"`
+	Options     ServiceOptions `json:"options"`
+}
+
+// ServiceOptions holds per-instance connection settings
+type ServiceOptions struct {
+	// Interval between scheduled health checks in seconds, 0 uses the default
+	Interval int `json:"interval,omitempty" yaml:"interval,omitempty"`
+	// Timeout of a health check in seconds, 0 uses the default
+	Timeout int `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Jitter delays every scheduled check by a random amount of up to this many seconds
+	Jitter int `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+	// TLSSkipVerify accepts self-signed or otherwise invalid certificates
+	TLSSkipVerify bool `json:"tlsSkipVerify,omitempty" yaml:"tlsSkipVerify,omitempty"`
+	// Headers are sent with every request to the service
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// GracePeriod is how many seconds a push service may go without a
+	// heartbeat before it is offline, 0 uses the default
+	GracePeriod int `json:"gracePeriod,omitempty" yaml:"gracePeriod,omitempty"`
+	// Check configures an advanced health check of a general service
+	Check *HTTPCheck `json:"check,omitempty" yaml:"check,omitempty"`
+}
+
+// HTTPCheck configures an advanced health check of a general service. When
+// set, the response is judged by these rules only instead of by its status
+// field. Per-instance headers are sent as request headers.
+type HTTPCheck struct {
+	// Method is the request method, defaults to GET
+	Method string `json:"method,omitempty" yaml:"method,omitempty"`
+	// Body is sent with the request, as JSON unless a Content-Type header is configured
+	Body string `json:"body,omitempty" yaml:"body,omitempty"`
+	// ExpectedStatus lists the accepted status codes, defaults to any 2xx code
+	ExpectedStatus []int `json:"expectedStatus,omitempty" yaml:"expectedStatus,omitempty"`
+	// JSONPath selects a value of a JSON response, such as $.status or
+	// $.checks[0].healthy, which must equal JSONValue or be truthy if JSONValue is empty
+	JSONPath  string `json:"jsonPath,omitempty" yaml:"jsonPath,omitempty"`
+	JSONValue string `json:"jsonValue,omitempty" yaml:"jsonValue,omitempty"`
+	// Regex must match the response body
+	Regex string `json:"regex,omitempty" yaml:"regex,omitempty"`
+	// Keyword must be present in the response body
+	Keyword string `json:"keyword,omitempty" yaml:"keyword,omitempty"`
+	// AbsentKeyword must not be present in the response body
+	AbsentKeyword string `json:"absentKeyword,omitempty" yaml:"absentKeyword,omitempty"`
+	// CertExpiryDays warns when the TLS certificate expires within this many
+	// days, 0 uses the default of 14 days and a negative value disables the warning
+	CertExpiryDays int `json:"certExpiryDays,omitempty" yaml:"certExpiryDays,omitempty"`
+	// VersionJSONPath or VersionRegex extract the version from the response.
+	// VersionRegex uses its first capture group, or the whole match without one.
+	VersionJSONPath string `json:"versionJsonPath,omitempty" yaml:"versionJsonPath,omitempty"`
+	VersionRegex    string `json:"versionRegex,omitempty" yaml:"versionRegex,omitempty"`
 }
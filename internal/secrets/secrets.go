@@ -0,0 +1,225 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Package secrets resolves references to credentials kept outside of the
+// dashbrr database. A service API key can be stored as a reference such as
+// env:SONARR_KEY, file:/run/secrets/sonarr or vault:kv/data/arr#sonarr, which
+// is resolved every time the key is used, so the secret can be rotated
+// without touching dashbrr.
+//
+// A reference is a registered scheme followed by a name that contains no
+// further colon, so credentials such as user:password are never mistaken for
+// one.
+//
+// Resolved keys are sent to the service URL, which any user can edit, so
+// references are limited to secrets meant for services: variables configuring
+// dashbrr itself, files outside the secret directories and Vault paths outside
+// the allowed prefixes are rejected.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+// DefaultSecretsDir is the directory Docker and Kubernetes mount secrets in
+const DefaultSecretsDir = "/run/secrets"
+
+// ErrNotAllowed is returned for references to secrets dashbrr must not hand out
+var ErrNotAllowed = errors.New("secret reference is not allowed")
+
+// reservedEnvPrefixes are the environment variables configuring dashbrr and
+// its own credentials
+var reservedEnvPrefixes = []string{"DASHBRR__", "OIDC_", "VAULT_", "REDIS_"}
+
+// SecretResolver resolves the references of one scheme. The reference is
+// passed without its scheme prefix.
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// Validator is implemented by resolvers that restrict which references can be used
+type Validator interface {
+	Validate(ref string) error
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]SecretResolver{
+		"env":   EnvResolver{},
+		"file":  FileResolver{},
+		"vault": NewVaultResolverFromEnv(),
+	}
+)
+
+// Register makes a resolver available for references of the given scheme,
+// replacing any resolver registered for it before
+func Register(scheme string, resolver SecretResolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = resolver
+}
+
+// lookup returns the resolver of a value's scheme and the reference without it
+func lookup(value string) (SecretResolver, string, bool) {
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok || ref == "" || strings.Contains(ref, ":") {
+		return nil, "", false
+	}
+
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	resolver, ok := resolvers[scheme]
+	return resolver, ref, ok
+}
+
+// IsReference reports whether a value is a reference of a registered scheme
+func IsReference(value string) bool {
+	_, _, ok := lookup(value)
+	return ok
+}
+
+// Validate checks that a reference may be stored, so a forbidden reference is
+// rejected when a service is saved. Values that are not references are valid.
+func Validate(value string) error {
+	resolver, ref, ok := lookup(value)
+	if !ok {
+		return nil
+	}
+
+	if validator, ok := resolver.(Validator); ok {
+		if err := validator.Validate(ref); err != nil {
+			scheme, _, _ := strings.Cut(value, ":")
+			return fmt.Errorf("invalid %s secret: %w", scheme, err)
+		}
+	}
+	return nil
+}
+
+// Resolve returns the secret a reference points to. Values that are not
+// references are returned unchanged.
+func Resolve(ctx context.Context, value string) (string, error) {
+	resolver, ref, ok := lookup(value)
+	if !ok {
+		return value, nil
+	}
+
+	if validator, ok := resolver.(Validator); ok {
+		if err := validator.Validate(ref); err != nil {
+			scheme, _, _ := strings.Cut(value, ":")
+			return "", fmt.Errorf("failed to resolve %s secret: %w", scheme, err)
+		}
+	}
+
+	secret, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		scheme, _, _ := strings.Cut(value, ":")
+		return "", fmt.Errorf("failed to resolve %s secret: %w", scheme, err)
+	}
+	return secret, nil
+}
+
+// ResolveService replaces a reference in the API key of a service with the secret
+func ResolveService(ctx context.Context, service *models.ServiceConfiguration) error {
+	apiKey, err := Resolve(ctx, service.APIKey)
+	if err != nil {
+		return err
+	}
+	service.APIKey = apiKey
+	return nil
+}
+
+// EnvResolver resolves env:NAME references to environment variables. The
+// variables of dashbrr itself cannot be referenced, and when
+// DASHBRR__SECRETS_ENV_PREFIX is set only variables with that prefix can.
+type EnvResolver struct{}
+
+func (EnvResolver) Validate(name string) error {
+	for _, prefix := range reservedEnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return fmt.Errorf("%w: environment variable %s is reserved for dashbrr", ErrNotAllowed, name)
+		}
+	}
+
+	if prefix := os.Getenv("DASHBRR__SECRETS_ENV_PREFIX"); prefix != "" && !strings.HasPrefix(name, prefix) {
+		return fmt.Errorf("%w: environment variable %s does not start with %s", ErrNotAllowed, name, prefix)
+	}
+	return nil
+}
+
+func (r EnvResolver) Resolve(_ context.Context, name string) (string, error) {
+	if err := r.Validate(name); err != nil {
+		return "", err
+	}
+
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// FileResolver resolves file:/path references to the content of a file, such
+// as a Docker or Kubernetes secret. Surrounding whitespace is removed. Only
+// files in the secret directories can be referenced, which are /run/secrets
+// or the directories listed in DASHBRR__SECRETS_DIR.
+type FileResolver struct{}
+
+func (FileResolver) Validate(path string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("%w: file %s is not an absolute path", ErrNotAllowed, path)
+	}
+
+	path = filepath.Clean(path)
+	dirs := secretsDirs()
+	for _, dir := range dirs {
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != "." && filepath.IsLocal(rel) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: file %s is outside of %s", ErrNotAllowed, path, strings.Join(dirs, ", "))
+}
+
+func (r FileResolver) Resolve(_ context.Context, path string) (string, error) {
+	if err := r.Validate(path); err != nil {
+		return "", err
+	}
+	return readFile(filepath.Clean(path))
+}
+
+// readFile returns the trimmed content of a secret file
+func readFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("file %s is empty", path)
+	}
+	return value, nil
+}
+
+// secretsDirs returns the directories file references can point into
+func secretsDirs() []string {
+	value := os.Getenv("DASHBRR__SECRETS_DIR")
+	if value == "" {
+		return []string{DefaultSecretsDir}
+	}
+
+	var dirs []string
+	for _, dir := range filepath.SplitList(value) {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, filepath.Clean(dir))
+		}
+	}
+	return dirs
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+type staticResolver map[string]string
+
+func (r staticResolver) Resolve(_ context.Context, ref string) (string, error) {
+	return r[ref], nil
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv("DASHBRR_TEST_SECRET", "from-env")
+	t.Setenv("DASHBRR__TEST_SECRET", "reserved")
+
+	dir := t.TempDir()
+	t.Setenv("DASHBRR__SECRETS_DIR", dir)
+
+	path := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0600))
+
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("\n"), 0600))
+
+	outside := filepath.Join(t.TempDir(), "outside")
+	require.NoError(t, os.WriteFile(outside, []byte("outside"), 0600))
+
+	Register("static", staticResolver{"key": "from-static"})
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr string
+	}{
+		{name: "plain value", value: "abc123", want: "abc123"},
+		{name: "empty value", value: "", want: ""},
+		{name: "unknown scheme", value: "https://example.com", want: "https://example.com"},
+		{name: "credential with scheme as user", value: "file:hunter2:more", want: "file:hunter2:more"},
+		{name: "env", value: "env:DASHBRR_TEST_SECRET", want: "from-env"},
+		{name: "unset env", value: "env:DASHBRR_TEST_UNSET", wantErr: "failed to resolve env secret"},
+		{name: "file", value: "file:" + path, want: "from-file"},
+		{name: "empty file", value: "file:" + empty, wantErr: "is empty"},
+		{name: "missing file", value: "file:" + path + ".missing", wantErr: "failed to resolve file secret"},
+		{name: "reserved env", value: "env:DASHBRR__TEST_SECRET", wantErr: "reserved for dashbrr"},
+		{name: "file outside secret dirs", value: "file:" + outside, wantErr: "is outside of"},
+		{name: "file escaping secret dir", value: "file:" + dir + "/../" + filepath.Base(outside), wantErr: "is outside of"},
+		{name: "registered resolver", value: "static:key", want: "from-static"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(context.Background(), tt.value)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Setenv("DASHBRR__SECRETS_DIR", "/run/secrets"+string(filepath.ListSeparator)+"/etc/dashbrr/secrets")
+	t.Setenv("DASHBRR__SECRETS_VAULT_PREFIX", "secret/data/arr, kv/")
+
+	tests := []struct {
+		name    string
+		value   string
+		allowed bool
+	}{
+		{name: "plain value", value: "abc123", allowed: true},
+		{name: "env", value: "env:SONARR_API_KEY", allowed: true},
+		{name: "dashbrr env", value: "env:DASHBRR__ENCRYPTION_KEY"},
+		{name: "oidc env", value: "env:OIDC_CLIENT_SECRET"},
+		{name: "vault env", value: "env:VAULT_TOKEN"},
+		{name: "file in secret dir", value: "file:/run/secrets/sonarr", allowed: true},
+		{name: "file in configured dir", value: "file:/etc/dashbrr/secrets/radarr", allowed: true},
+		{name: "secret dir itself", value: "file:/run/secrets"},
+		{name: "file outside secret dirs", value: "file:/etc/shadow"},
+		{name: "file escaping secret dir", value: "file:/run/secrets/../../etc/shadow"},
+		{name: "relative file", value: "file:secrets/sonarr"},
+		{name: "vault", value: "vault:secret/data/arr#sonarr", allowed: true},
+		{name: "vault below prefix", value: "vault:kv/plex#token", allowed: true},
+		{name: "vault outside prefixes", value: "vault:secret/data/other#password"},
+		{name: "vault token lookup", value: "vault:auth/token/lookup-self#id"},
+		{name: "vault escaping prefix", value: "vault:kv/../auth/token/lookup-self#id"},
+		{name: "vault prefix without separator", value: "vault:secret/data/arrs#sonarr"},
+		{name: "vault query", value: "vault:kv/plex?list=true#token"},
+		{name: "credential with scheme as user", value: "env:user:password", allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.value)
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrNotAllowed)
+		})
+	}
+
+	t.Run("env prefix", func(t *testing.T) {
+		t.Setenv("DASHBRR__SECRETS_ENV_PREFIX", "ARR_")
+		assert.NoError(t, Validate("env:ARR_SONARR_KEY"))
+		assert.ErrorIs(t, Validate("env:HOME"), ErrNotAllowed)
+	})
+
+	t.Run("no vault prefix", func(t *testing.T) {
+		t.Setenv("DASHBRR__SECRETS_VAULT_PREFIX", "")
+		assert.ErrorIs(t, Validate("vault:secret/data/arr#sonarr"), ErrNotAllowed)
+	})
+
+	t.Run("default secret dir", func(t *testing.T) {
+		t.Setenv("DASHBRR__SECRETS_DIR", "")
+		assert.NoError(t, Validate("file:/run/secrets/sonarr"))
+		assert.ErrorIs(t, Validate("file:/etc/dashbrr/secrets/radarr"), ErrNotAllowed)
+	})
+}
+
+func TestResolveService(t *testing.T) {
+	t.Setenv("DASHBRR_TEST_SECRET", "from-env")
+
+	service := &models.ServiceConfiguration{InstanceID: "sonarr-1", APIKey: "env:DASHBRR_TEST_SECRET"}
+	require.NoError(t, ResolveService(context.Background(), service))
+	assert.Equal(t, "from-env", service.APIKey)
+
+	service = &models.ServiceConfiguration{InstanceID: "sonarr-1", APIKey: "env:DASHBRR_TEST_UNSET"}
+	require.Error(t, ResolveService(context.Background(), service))
+	assert.Equal(t, "env:DASHBRR_TEST_UNSET", service.APIKey)
+}
+
+// newFakeVault serves secrets by path the way the Vault HTTP API does
+func newFakeVault(t *testing.T, secrets map[string]interface{}, reads *int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(reads, 1)
+
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		secret, ok := secrets[strings.TrimPrefix(r.URL.Path, "/v1/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": secret})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultResolver(t *testing.T) {
+	var reads int32
+	server := newFakeVault(t, map[string]interface{}{
+		"secret/data/arr": map[string]interface{}{
+			"data":     map[string]interface{}{"sonarr": "kv2-key", "value": "kv2-default"},
+			"metadata": map[string]interface{}{"version": 3},
+		},
+		"kv/plex": map[string]interface{}{"token": "kv1-token"},
+	}, &reads)
+
+	t.Setenv("DASHBRR__SECRETS_VAULT_PREFIX", "secret,kv")
+	resolver := NewVaultResolver(server.URL, "test-token")
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{name: "kv v2 field", ref: "secret/data/arr#sonarr", want: "kv2-key"},
+		{name: "kv v2 default field", ref: "secret/data/arr", want: "kv2-default"},
+		{name: "kv v1 field", ref: "kv/plex#token", want: "kv1-token"},
+		{name: "missing field", ref: "kv/plex#password", wantErr: "field password not found"},
+		{name: "missing secret", ref: "kv/unknown#token", wantErr: "status 404"},
+		{name: "no path", ref: "#token", wantErr: "has no path"},
+		{name: "outside prefixes", ref: "auth/token/lookup-self#id", wantErr: "is outside of"},
+		{name: "escaping prefix", ref: "kv/../auth/token/lookup-self#id", wantErr: "is not a clean path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.Resolve(context.Background(), tt.ref)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("cached reads", func(t *testing.T) {
+		atomic.StoreInt32(&reads, 0)
+		resolver := NewVaultResolver(server.URL, "test-token")
+
+		for i := 0; i < 3; i++ {
+			_, err := resolver.Resolve(context.Background(), "kv/plex#token")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&reads))
+
+		resolver.CacheTTL = 0
+		resolver.cache = nil
+		for i := 0; i < 2; i++ {
+			_, err := resolver.Resolve(context.Background(), "kv/plex#token")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(3), atomic.LoadInt32(&reads))
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		_, err := NewVaultResolver(server.URL, "other").Resolve(context.Background(), "kv/plex#token")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "status 403")
+	})
+
+	t.Run("token file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(path, []byte("test-token\n"), 0600))
+		t.Setenv("VAULT_TOKEN_FILE", path)
+
+		got, err := NewVaultResolver(server.URL, "").Resolve(context.Background(), "kv/plex#token")
+		require.NoError(t, err)
+		assert.Equal(t, "kv1-token", got)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		_, err := NewVaultResolver("", "test-token").Resolve(context.Background(), "kv/plex#token")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no Vault address")
+	})
+
+	t.Run("registered scheme", func(t *testing.T) {
+		Register("vault", resolver)
+		t.Cleanup(func() { Register("vault", NewVaultResolverFromEnv()) })
+
+		got, err := Resolve(context.Background(), "vault:secret/data/arr#sonarr")
+		require.NoError(t, err)
+		assert.Equal(t, "kv2-key", got)
+	})
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultVaultCacheTTL is how long a secret read from Vault is reused
+	DefaultVaultCacheTTL = time.Minute
+
+	// defaultVaultField is read when a reference does not name a field
+	defaultVaultField = "value"
+)
+
+// VaultResolver resolves vault:<path>#<field> references by reading the
+// secret at path from HashiCorp Vault. KV version 1 and 2 engines are
+// supported, for version 2 the path includes the data segment, such as
+// vault:kv/data/arr#sonarr. The field defaults to value. Only paths below the
+// prefixes listed in DASHBRR__SECRETS_VAULT_PREFIX can be read, no path is
+// allowed when it is not set.
+type VaultResolver struct {
+	Address   string
+	Token     string
+	Namespace string
+	// CacheTTL limits how often the same secret is read, 0 disables caching
+	CacheTTL time.Duration
+	Client   *http.Client
+
+	mu    sync.Mutex
+	cache map[string]vaultCacheEntry
+}
+
+type vaultCacheEntry struct {
+	data      map[string]interface{}
+	expiresAt time.Time
+}
+
+// NewVaultResolver returns a resolver for the Vault server at address
+func NewVaultResolver(address, token string) *VaultResolver {
+	return &VaultResolver{
+		Address:  strings.TrimRight(address, "/"),
+		Token:    token,
+		CacheTTL: DefaultVaultCacheTTL,
+		Client:   &http.Client{Timeout: 10 * time.Second},
+		cache:    make(map[string]vaultCacheEntry),
+	}
+}
+
+// NewVaultResolverFromEnv returns a resolver configured by the standard
+// VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE environment variables. The
+// token can also be read from the file in VAULT_TOKEN_FILE.
+func NewVaultResolverFromEnv() *VaultResolver {
+	resolver := NewVaultResolver(os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN"))
+	resolver.Namespace = os.Getenv("VAULT_NAMESPACE")
+	return resolver
+}
+
+// token returns the configured token, falling back to VAULT_TOKEN_FILE so a
+// renewed token file is picked up
+func (v *VaultResolver) token() (string, error) {
+	if v.Token != "" {
+		return v.Token, nil
+	}
+	if path := os.Getenv("VAULT_TOKEN_FILE"); path != "" {
+		return readFile(path)
+	}
+	return "", fmt.Errorf("no Vault token configured")
+}
+
+// Validate checks that a reference points below an allowed path prefix, so
+// other secrets and endpoints such as auth/token/lookup-self cannot be read
+func (v *VaultResolver) Validate(ref string) error {
+	path, _, _ := strings.Cut(ref, "#")
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return fmt.Errorf("reference %q has no path", ref)
+	}
+	if strings.ContainsAny(path, "?%") {
+		return fmt.Errorf("%w: Vault path %s contains a query or escape", ErrNotAllowed, path)
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("%w: Vault path %s is not a clean path", ErrNotAllowed, path)
+		}
+	}
+
+	prefixes := vaultPrefixes()
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return nil
+		}
+	}
+	if len(prefixes) == 0 {
+		return fmt.Errorf("%w: no Vault path prefix is allowed, set DASHBRR__SECRETS_VAULT_PREFIX", ErrNotAllowed)
+	}
+	return fmt.Errorf("%w: Vault path %s is outside of %s", ErrNotAllowed, path, strings.Join(prefixes, ", "))
+}
+
+func (v *VaultResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	if err := v.Validate(ref); err != nil {
+		return "", err
+	}
+	if v.Address == "" {
+		return "", fmt.Errorf("no Vault address configured")
+	}
+
+	path, field, _ := strings.Cut(ref, "#")
+	path = strings.Trim(path, "/")
+	if field == "" {
+		field = defaultVaultField
+	}
+
+	data, err := v.read(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := data[field].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("field %s not found in %s", field, path)
+	}
+	return value, nil
+}
+
+// vaultPrefixes returns the path prefixes listed in DASHBRR__SECRETS_VAULT_PREFIX
+func vaultPrefixes() []string {
+	var prefixes []string
+	for _, prefix := range strings.Split(os.Getenv("DASHBRR__SECRETS_VAULT_PREFIX"), ",") {
+		if prefix = strings.Trim(strings.TrimSpace(prefix), "/"); prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// read returns the fields of the secret at path
+func (v *VaultResolver) read(ctx context.Context, path string) (map[string]interface{}, error) {
+	v.mu.Lock()
+	if entry, ok := v.cache[path]; ok && time.Now().Before(entry.expiresAt) {
+		v.mu.Unlock()
+		return entry.data, nil
+	}
+	v.mu.Unlock()
+
+	token, err := v.token()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.Address+"/v1/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d for %s", resp.StatusCode, path)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	// KV version 2 nests the fields with the metadata of the version
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+
+	if v.CacheTTL > 0 {
+		v.mu.Lock()
+		if v.cache == nil {
+			v.cache = make(map[string]vaultCacheEntry)
+		}
+		v.cache[path] = vaultCacheEntry{data: data, expiresAt: time.Now().Add(v.CacheTTL)}
+		v.mu.Unlock()
+	}
+
+	return data, nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func init() {
+	RegisterNotifier("discord", NewDiscordNotifier)
+}
+
+// DiscordNotifier posts alerts to a Discord webhook
+type DiscordNotifier struct {
+	webhookURL string
+	username   string
+	avatarURL  string
+}
+
+type discordPayload struct {
+	Username  string         `json:"username,omitempty"`
+	AvatarURL string         `json:"avatar_url,omitempty"`
+	Embeds    []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// NewDiscordNotifier creates a Discord notifier from the webhook_url, username and avatar_url settings
+func NewDiscordNotifier(settings map[string]string) (Notifier, error) {
+	if settings["webhook_url"] == "" {
+		return nil, fmt.Errorf("discord notifier requires a webhook_url")
+	}
+
+	username := settings["username"]
+	if username == "" {
+		username = "dashbrr"
+	}
+
+	return &DiscordNotifier{
+		webhookURL: settings["webhook_url"],
+		username:   username,
+		avatarURL:  settings["avatar_url"],
+	}, nil
+}
+
+func (n *DiscordNotifier) Send(ctx context.Context, alert models.Alert) error {
+	embed := discordEmbed{
+		Title:       alertTitle(alert),
+		Description: alertDescription(alert),
+		Color:       alertColor(alert),
+		Timestamp:   alert.Time.UTC().Format(time.RFC3339),
+	}
+	for _, field := range alertFields(alert) {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: field.Name, Value: field.Value, Inline: true})
+	}
+
+	return postJSON(ctx, n.webhookURL, discordPayload{
+		Username:  n.username,
+		AvatarURL: n.avatarURL,
+		Embeds:    []discordEmbed{embed},
+	})
+}
@@ -0,0 +1,401 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+// Email delivery modes
+const (
+	EmailModeImmediate = "immediate"
+	EmailModeDigest    = "digest"
+)
+
+// Connection security options
+const (
+	emailSecurityStartTLS = "starttls"
+	emailSecurityTLS      = "tls"
+	emailSecurityNone     = "none"
+)
+
+const defaultDigestTime = "08:00"
+
+// ErrNotDigest is returned by NewDigestNotifier for notifiers that do not send digests
+var ErrNotDigest = errors.New("notifier is not an email digest")
+
+func init() {
+	RegisterNotifier("email", NewEmailNotifier)
+}
+
+// ErrNotifierSMTP is returned when the mail server rejects a command
+type ErrNotifierSMTP struct {
+	Code    int
+	Message string
+}
+
+func (e *ErrNotifierSMTP) Error() string {
+	return fmt.Sprintf("mail server returned %d: %s", e.Code, e.Message)
+}
+
+// Retryable reports whether the mail server reported a temporary failure
+func (e *ErrNotifierSMTP) Retryable() bool {
+	return e.Code < 500
+}
+
+// EmailNotifier sends alerts and daily digests over SMTP
+type EmailNotifier struct {
+	host       string
+	port       int
+	username   string
+	password   string
+	from       string // Header value, may include a display name
+	sender     string // Envelope address
+	to         []string
+	security   string
+	skipVerify bool
+	mode       string
+	digestHour int
+	digestMin  int
+	click      string
+}
+
+type emailAlertData struct {
+	TemplateData
+	Color string
+	URL   string
+}
+
+type emailDigestData struct {
+	*models.Digest
+	URL string
+}
+
+// NewEmailNotifier creates an email notifier from the host, port, username, password,
+// from, to, security, skip_verify, mode, digest_time and click_url settings
+func NewEmailNotifier(settings map[string]string) (Notifier, error) {
+	n := &EmailNotifier{
+		host:     settings["host"],
+		username: settings["username"],
+		password: settings["password"],
+		from:     settings["from"],
+		security: strings.ToLower(settings["security"]),
+		mode:     strings.ToLower(settings["mode"]),
+		click:    clickURL(settings),
+	}
+
+	if n.host == "" {
+		return nil, fmt.Errorf("email notifier requires a host")
+	}
+	if n.from == "" {
+		return nil, fmt.Errorf("email notifier requires a from address")
+	}
+	sender, err := mail.ParseAddress(n.from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+	n.sender = sender.Address
+
+	for _, address := range strings.Split(settings["to"], ",") {
+		if address = strings.TrimSpace(address); address == "" {
+			continue
+		}
+		recipient, err := mail.ParseAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", address, err)
+		}
+		n.to = append(n.to, recipient.Address)
+	}
+	if len(n.to) == 0 {
+		return nil, fmt.Errorf("email notifier requires at least one recipient in to")
+	}
+
+	switch n.security {
+	case "":
+		n.security = emailSecurityStartTLS
+	case emailSecurityStartTLS, emailSecurityTLS, emailSecurityNone:
+	default:
+		return nil, fmt.Errorf("unsupported email security: %s", settings["security"])
+	}
+
+	switch n.mode {
+	case "":
+		n.mode = EmailModeImmediate
+	case EmailModeImmediate, EmailModeDigest:
+	default:
+		return nil, fmt.Errorf("unsupported email mode: %s", settings["mode"])
+	}
+
+	n.port = defaultEmailPort(n.security)
+	if value := settings["port"]; value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid email port: %s", value)
+		}
+		n.port = port
+	}
+
+	if value := settings["skip_verify"]; value != "" {
+		skipVerify, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid skip_verify: %s", value)
+		}
+		n.skipVerify = skipVerify
+	}
+
+	digestTime := settings["digest_time"]
+	if digestTime == "" {
+		digestTime = defaultDigestTime
+	}
+	at, err := time.Parse("15:04", digestTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest_time, expected HH:MM: %s", digestTime)
+	}
+	n.digestHour, n.digestMin = at.Hour(), at.Minute()
+
+	return n, nil
+}
+
+// NewDigestNotifier builds an email notifier configured for digest mode.
+// ErrNotDigest is returned for any other notifier configuration.
+func NewDigestNotifier(config models.NotifierConfig) (*EmailNotifier, error) {
+	if strings.ToLower(config.Type) != "email" {
+		return nil, ErrNotDigest
+	}
+
+	notifier, err := NewEmailNotifier(config.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	email := notifier.(*EmailNotifier)
+	if email.mode != EmailModeDigest {
+		return nil, ErrNotDigest
+	}
+	return email, nil
+}
+
+func defaultEmailPort(security string) int {
+	switch security {
+	case emailSecurityTLS:
+		return 465
+	case emailSecurityNone:
+		return 25
+	default:
+		return 587
+	}
+}
+
+// Send delivers an alert immediately. Notifiers in digest mode only send test alerts,
+// everything else is summarized in the next digest.
+func (n *EmailNotifier) Send(ctx context.Context, alert models.Alert) error {
+	if n.mode == EmailModeDigest && alert.Event != models.AlertEventTest {
+		return nil
+	}
+
+	data := emailAlertData{
+		TemplateData: newTemplateData(alert),
+		Color:        fmt.Sprintf("#%06X", alertColor(alert)),
+		URL:          n.click,
+	}
+
+	text, err := renderText("alert.txt", data)
+	if err != nil {
+		return err
+	}
+	html, err := renderHTML("alert.html", data)
+	if err != nil {
+		return err
+	}
+
+	return n.deliver(ctx, "[dashbrr] "+alertTitle(alert), text, html)
+}
+
+// SendDigest delivers a digest summarizing all services
+func (n *EmailNotifier) SendDigest(ctx context.Context, digest *models.Digest) error {
+	data := emailDigestData{Digest: digest, URL: n.click}
+
+	text, err := renderText("digest.txt", data)
+	if err != nil {
+		return err
+	}
+	html, err := renderHTML("digest.html", data)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("[dashbrr] Daily digest for %s", digest.To.Local().Format("2006-01-02"))
+	return n.deliver(ctx, subject, text, html)
+}
+
+// NextDigest returns the first scheduled digest time after the given time
+func (n *EmailNotifier) NextDigest(after time.Time) time.Time {
+	next := time.Date(after.Year(), after.Month(), after.Day(), n.digestHour, n.digestMin, 0, 0, after.Location())
+	if !next.After(after) {
+		next = time.Date(after.Year(), after.Month(), after.Day()+1, n.digestHour, n.digestMin, 0, 0, after.Location())
+	}
+	return next
+}
+
+// deliver sends a multipart message with plaintext and HTML alternatives
+func (n *EmailNotifier) deliver(ctx context.Context, subject, text, html string) error {
+	message, err := n.buildMessage(subject, text, html)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(n.host, strconv.Itoa(n.port))
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	tlsConfig := &tls.Config{ServerName: n.host, InsecureSkipVerify: n.skipVerify}
+
+	var conn net.Conn
+	if n.security == emailSecurityTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to mail server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return smtpError("connect", err)
+	}
+	defer client.Close()
+
+	if n.security == emailSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("mail server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return smtpError("starttls", err)
+		}
+	}
+
+	if n.username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("mail server does not support authentication")
+		}
+		if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return smtpError("auth", err)
+		}
+	}
+
+	if err := client.Mail(n.sender); err != nil {
+		return smtpError("mail", err)
+	}
+	for _, recipient := range n.to {
+		if err := client.Rcpt(recipient); err != nil {
+			return smtpError("rcpt", err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return smtpError("data", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return smtpError("data", err)
+	}
+	if err := w.Close(); err != nil {
+		return smtpError("data", err)
+	}
+
+	return client.Quit()
+}
+
+func (n *EmailNotifier) buildMessage(subject, text, html string) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	}
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build message: %w", err)
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to build message: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("failed to build message: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build message: %w", err)
+	}
+
+	var message bytes.Buffer
+	headers := [][2]string{
+		{"From", n.from},
+		{"To", strings.Join(n.to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID(n.sender)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + writer.Boundary()},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&message, "%s: %s\r\n", header[0], header[1])
+	}
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
+
+// messageID generates a unique Message-ID using the domain of the sender address
+func messageID(sender string) string {
+	domain := "dashbrr"
+	if at := strings.LastIndex(sender, "@"); at >= 0 {
+		domain = sender[at+1:]
+	}
+
+	random := make([]byte, 8)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
+
+// smtpError converts protocol errors into ErrNotifierSMTP so permanent failures are not retried
+func smtpError(op string, err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return &ErrNotifierSMTP{Code: protoErr.Code, Message: fmt.Sprintf("%s: %s", op, protoErr.Msg)}
+	}
+	return fmt.Errorf("smtp %s failed: %w", op, err)
+}
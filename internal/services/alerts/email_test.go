@@ -0,0 +1,288 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+// fakeSMTP is a minimal SMTP server that records delivered messages
+type fakeSMTP struct {
+	listener net.Listener
+	rejectTo string // Recipient rejected with a permanent error
+
+	mu       sync.Mutex
+	auth     []string
+	from     string
+	rcpt     []string
+	messages []string
+	attempts int
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTP{listener: listener}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return server
+}
+
+func (s *fakeSMTP) port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = io.WriteString(conn, line+"\r\n")
+	}
+
+	reply("220 localhost fake SMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(command, "AUTH PLAIN"):
+			s.mu.Lock()
+			s.auth = append(s.auth, strings.TrimSpace(line[len("AUTH PLAIN"):]))
+			s.mu.Unlock()
+			reply("235 Authentication successful")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.mu.Lock()
+			s.attempts++
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			s.rcpt = nil
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			recipient := strings.Trim(line[len("RCPT TO:"):], "<>")
+			if recipient == s.rejectTo {
+				reply("550 No such user")
+				continue
+			}
+			s.mu.Lock()
+			s.rcpt = append(s.rcpt, recipient)
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// lastMessage parses the last delivered message and returns its subject, plaintext and HTML parts
+func (s *fakeSMTP) lastMessage(t *testing.T) (string, string, string) {
+	t.Helper()
+
+	s.mu.Lock()
+	require.NotEmpty(t, s.messages, "no message delivered")
+	raw := s.messages[len(s.messages)-1]
+	s.mu.Unlock()
+
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(part) // quoted-printable is decoded by the multipart reader
+		require.NoError(t, err)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(content)
+	}
+
+	return subject, parts["text/plain"], parts["text/html"]
+}
+
+func emailSettings(server *fakeSMTP) map[string]string {
+	return map[string]string{
+		"host":        "127.0.0.1",
+		"port":        server.port(),
+		"security":    "none",
+		"username":    "dashbrr",
+		"password":    "secret",
+		"from":        "dashbrr <dashbrr@example.com>",
+		"to":          "ops@example.com, admin@example.com",
+		"retry_delay": "1ms",
+		"click_url":   "https://dashbrr.example.com",
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	server := newFakeSMTP(t)
+
+	notifier, err := NewNotifier(models.NotifierConfig{Type: "email", Settings: emailSettings(server)})
+	require.NoError(t, err)
+
+	alert := testAlert()
+	alert.Health.Message = "<script>alert(1)</script>"
+	require.NoError(t, notifier.Send(context.Background(), alert))
+
+	server.mu.Lock()
+	assert.Equal(t, "dashbrr@example.com", server.from)
+	assert.Equal(t, []string{"ops@example.com", "admin@example.com"}, server.rcpt)
+	assert.Len(t, server.auth, 1, "credentials should be sent with AUTH PLAIN")
+	server.mu.Unlock()
+
+	subject, text, html := server.lastMessage(t)
+	assert.Equal(t, "[dashbrr] sonarr-1 is offline", subject)
+	assert.Contains(t, text, "Status:   offline")
+	assert.Contains(t, text, "https://dashbrr.example.com")
+	assert.Contains(t, html, "#E74C3C")
+	assert.NotContains(t, html, "<script>", "HTML output must be escaped")
+}
+
+func TestEmailNotifier_PermanentFailure(t *testing.T) {
+	server := newFakeSMTP(t)
+	server.rejectTo = "admin@example.com"
+
+	notifier, err := NewNotifier(models.NotifierConfig{Type: "email", Settings: emailSettings(server)})
+	require.NoError(t, err)
+
+	err = notifier.Send(context.Background(), testAlert())
+	var smtpErr *ErrNotifierSMTP
+	require.ErrorAs(t, err, &smtpErr)
+	assert.Equal(t, 550, smtpErr.Code)
+
+	server.mu.Lock()
+	assert.Equal(t, 1, server.attempts, "permanent failures must not be retried")
+	server.mu.Unlock()
+}
+
+func TestEmailNotifier_Digest(t *testing.T) {
+	server := newFakeSMTP(t)
+
+	settings := emailSettings(server)
+	settings["mode"] = EmailModeDigest
+	settings["digest_time"] = "07:30"
+	config := models.NotifierConfig{Type: "email", Settings: settings}
+
+	// Regular alerts are held back for the digest
+	notifier, err := NewNotifier(config)
+	require.NoError(t, err)
+	require.NoError(t, notifier.Send(context.Background(), testAlert()))
+	server.mu.Lock()
+	assert.Empty(t, server.messages)
+	server.mu.Unlock()
+
+	digestNotifier, err := NewDigestNotifier(config)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2024, 3, 11, 7, 30, 0, 0, time.Local), digestNotifier.NextDigest(now))
+	assert.Equal(t, time.Date(2024, 3, 10, 7, 30, 0, 0, time.Local), digestNotifier.NextDigest(now.Add(-2*time.Hour)))
+
+	digest := &models.Digest{
+		From: now.Add(-24 * time.Hour),
+		To:   now,
+		Services: []models.DigestService{
+			{InstanceID: "radarr-1", DisplayName: "Radarr", Status: "online", Checks: 4, UptimePercent: 75, Version: "5.0.0", UpdateAvailable: true,
+				StuckItems: []models.DigestQueueItem{{Title: "Movie.2024.1080p", Status: "importBlocked"}}},
+			{InstanceID: "overseerr-1", DisplayName: "Overseerr", Status: "online", Checks: 4, UptimePercent: 100, PendingRequests: 2},
+		},
+	}
+	require.NoError(t, digestNotifier.SendDigest(context.Background(), digest))
+
+	subject, text, html := server.lastMessage(t)
+	assert.Equal(t, "[dashbrr] Daily digest for 2024-03-10", subject)
+	assert.Contains(t, text, "2 service(s), 1 update(s) available, 2 pending request(s), 1 stuck queue item(s)")
+	assert.Contains(t, text, "Uptime:  75.00% over 4 check(s)")
+	assert.Contains(t, text, "Stuck: Movie.2024.1080p [importBlocked]")
+	assert.Contains(t, html, "Overseerr")
+
+	_, err = NewDigestNotifier(models.NotifierConfig{Type: "email", Settings: emailSettings(server)})
+	assert.ErrorIs(t, err, ErrNotDigest)
+}
+
+func TestEmailNotifier_TemplateOverride(t *testing.T) {
+	server := newFakeSMTP(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "alert.txt"), []byte("custom {{.InstanceID}} {{.Status}}"), 0o644))
+	SetTemplateDir(dir)
+	t.Cleanup(func() { SetTemplateDir("") })
+
+	notifier, err := NewNotifier(models.NotifierConfig{Type: "email", Settings: emailSettings(server)})
+	require.NoError(t, err)
+	require.NoError(t, notifier.Send(context.Background(), testAlert()))
+
+	_, text, html := server.lastMessage(t)
+	assert.Equal(t, "custom sonarr-1 offline", text)
+	assert.Contains(t, html, "sonarr-1 is offline", "templates without override use the built-in version")
+}
@@ -0,0 +1,351 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+const (
+	defaultReloadInterval = 30 * time.Second
+	defaultSendTimeout    = 30 * time.Second
+)
+
+// Store defines the database operations needed by the alerting engine
+type Store interface {
+	GetAlertRules() ([]models.AlertRule, error)
+	GetNotifiers() ([]models.NotifierConfig, error)
+}
+
+// ActiveAlert describes a rule that is currently firing for an instance
+type ActiveAlert struct {
+	RuleID     int64     `json:"ruleId"`
+	RuleName   string    `json:"ruleName"`
+	InstanceID string    `json:"instanceId"`
+	Status     string    `json:"status"`
+	Failures   int       `json:"failures"`
+	Since      time.Time `json:"since"`
+}
+
+type ruleKey struct {
+	ruleID     int64
+	instanceID string
+}
+
+type ruleState struct {
+	failures int
+	active   bool
+	since    time.Time
+	status   string
+
+	updateNotified bool
+	requestsSeen   bool
+	pendingCount   int
+}
+
+type dispatch struct {
+	rule  models.AlertRule
+	alert models.Alert
+}
+
+// Engine detects status transitions per service and dispatches alerts
+type Engine struct {
+	store Store
+
+	mu         sync.Mutex
+	rules      []models.AlertRule
+	notifiers  map[int64]models.NotifierConfig
+	loadedAt   time.Time
+	lastStatus map[string]string
+	states     map[ruleKey]*ruleState
+
+	reloadInterval time.Duration
+	sendTimeout    time.Duration
+	wg             sync.WaitGroup
+}
+
+// NewEngine creates a new alerting engine backed by the given store
+func NewEngine(store Store) *Engine {
+	return &Engine{
+		store:          store,
+		notifiers:      make(map[int64]models.NotifierConfig),
+		lastStatus:     make(map[string]string),
+		states:         make(map[ruleKey]*ruleState),
+		reloadInterval: defaultReloadInterval,
+		sendTimeout:    defaultSendTimeout,
+	}
+}
+
+// Invalidate forces rules and notifiers to be reloaded on the next check
+func (e *Engine) Invalidate() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.loadedAt = time.Time{}
+}
+
+// reload refreshes the cached rules and notifiers, the caller must hold e.mu
+func (e *Engine) reload() {
+	if !e.loadedAt.IsZero() && time.Since(e.loadedAt) < e.reloadInterval {
+		return
+	}
+
+	rules, err := e.store.GetAlertRules()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load alert rules")
+		return
+	}
+
+	notifiers, err := e.store.GetNotifiers()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load notifiers")
+		return
+	}
+
+	e.rules = rules
+	e.notifiers = make(map[int64]models.NotifierConfig, len(notifiers))
+	for _, notifier := range notifiers {
+		e.notifiers[notifier.ID] = notifier
+	}
+
+	// Drop state belonging to rules that no longer exist
+	ruleIDs := make(map[int64]bool, len(rules))
+	for _, rule := range rules {
+		ruleIDs[rule.ID] = true
+	}
+	for key := range e.states {
+		if !ruleIDs[key.ruleID] {
+			delete(e.states, key)
+		}
+	}
+
+	e.loadedAt = time.Now()
+}
+
+// Process evaluates a health check result against all alert rules
+func (e *Engine) Process(health models.ServiceHealth) {
+	if health.ServiceID == "" || health.Status == "" || health.Status == "checking" {
+		return
+	}
+
+	e.mu.Lock()
+	e.reload()
+
+	previousStatus := e.lastStatus[health.ServiceID]
+	e.lastStatus[health.ServiceID] = health.Status
+
+	if previousStatus != "" && previousStatus != health.Status {
+		log.Debug().
+			Str("service", health.ServiceID).
+			Str("from", previousStatus).
+			Str("to", health.Status).
+			Msg("Service status changed")
+	}
+
+	var pending []dispatch
+	now := time.Now()
+
+	for _, rule := range e.rules {
+		if !rule.Enabled || !rule.Matches(health.ServiceID) {
+			continue
+		}
+
+		key := ruleKey{ruleID: rule.ID, instanceID: health.ServiceID}
+		state, ok := e.states[key]
+		if !ok {
+			state = &ruleState{}
+			e.states[key] = state
+		}
+
+		threshold := rule.FailureThreshold
+		if threshold < 1 {
+			threshold = 1
+		}
+
+		alert := models.Alert{
+			RuleName:       rule.Name,
+			InstanceID:     health.ServiceID,
+			PreviousStatus: previousStatus,
+			Health:         health,
+			Time:           now,
+		}
+
+		// Offline checks carry no version information, so only reachable
+		// services may raise or clear an update notification
+		if rule.NotifyUpdates && models.IsUp(health.Status) {
+			if health.UpdateAvailable && !state.updateNotified {
+				update := alert
+				update.Event = models.AlertEventUpdate
+				pending = append(pending, dispatch{rule: rule, alert: update})
+			}
+			state.updateNotified = health.UpdateAvailable
+		}
+
+		if rule.IsFailure(health.Status) {
+			state.failures++
+			state.status = health.Status
+			if !state.active && state.failures >= threshold {
+				state.active = true
+				state.since = now
+				alert.Event = models.AlertEventTriggered
+				alert.Failures = state.failures
+				pending = append(pending, dispatch{rule: rule, alert: alert})
+			}
+			continue
+		}
+
+		if !models.IsUp(health.Status) {
+			// Statuses like "unknown" neither trigger nor resolve an alert
+			continue
+		}
+
+		if state.active && rule.NotifyRecovery {
+			alert.Event = models.AlertEventResolved
+			alert.Failures = state.failures
+			pending = append(pending, dispatch{rule: rule, alert: alert})
+		}
+		state.active = false
+		state.failures = 0
+		state.status = health.Status
+	}
+
+	notifiers := e.notifiers
+	e.mu.Unlock()
+
+	for _, d := range pending {
+		e.dispatch(d, notifiers)
+	}
+}
+
+// ProcessRequests evaluates the number of pending media requests of an instance.
+// The first count seen for an instance is used as a baseline, afterwards every
+// increase raises a notification for rules with NotifyRequests enabled.
+func (e *Engine) ProcessRequests(instanceID string, pendingCount int) {
+	if instanceID == "" {
+		return
+	}
+
+	e.mu.Lock()
+	e.reload()
+
+	var pending []dispatch
+	now := time.Now()
+
+	for _, rule := range e.rules {
+		if !rule.Enabled || !rule.NotifyRequests || !rule.Matches(instanceID) {
+			continue
+		}
+
+		key := ruleKey{ruleID: rule.ID, instanceID: instanceID}
+		state, ok := e.states[key]
+		if !ok {
+			state = &ruleState{}
+			e.states[key] = state
+		}
+
+		if state.requestsSeen && pendingCount > state.pendingCount {
+			pending = append(pending, dispatch{rule: rule, alert: models.Alert{
+				Event:        models.AlertEventRequests,
+				RuleName:     rule.Name,
+				InstanceID:   instanceID,
+				PendingCount: pendingCount,
+				Health: models.ServiceHealth{
+					ServiceID:   instanceID,
+					Status:      e.lastStatus[instanceID],
+					LastChecked: now,
+				},
+				Time: now,
+			}})
+		}
+		state.requestsSeen = true
+		state.pendingCount = pendingCount
+	}
+
+	notifiers := e.notifiers
+	e.mu.Unlock()
+
+	for _, d := range pending {
+		e.dispatch(d, notifiers)
+	}
+}
+
+// dispatch sends an alert to every enabled notifier of its rule
+func (e *Engine) dispatch(d dispatch, notifiers map[int64]models.NotifierConfig) {
+	log.Info().
+		Str("event", d.alert.Event).
+		Str("rule", d.rule.Name).
+		Str("service", d.alert.InstanceID).
+		Str("status", d.alert.Health.Status).
+		Msg("Dispatching alert")
+
+	for _, id := range d.rule.NotifierIDs {
+		config, ok := notifiers[id]
+		if !ok {
+			log.Warn().Int64("notifier_id", id).Str("rule", d.rule.Name).Msg("Alert rule references unknown notifier")
+			continue
+		}
+		if !config.Enabled {
+			continue
+		}
+
+		notifier, err := NewNotifier(config)
+		if err != nil {
+			log.Error().Err(err).Str("notifier", config.Name).Msg("Failed to create notifier")
+			continue
+		}
+
+		e.wg.Add(1)
+		go func(name string, notifier Notifier, alert models.Alert) {
+			defer e.wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), e.sendTimeout)
+			defer cancel()
+
+			if err := notifier.Send(ctx, alert); err != nil {
+				log.Error().
+					Err(err).
+					Str("notifier", name).
+					Str("service", alert.InstanceID).
+					Msg("Failed to send alert")
+			}
+		}(config.Name, notifier, d.alert)
+	}
+}
+
+// Wait blocks until all in-flight notifications have been sent
+func (e *Engine) Wait() {
+	e.wg.Wait()
+}
+
+// ActiveAlerts returns the alerts that are currently firing
+func (e *Engine) ActiveAlerts() []ActiveAlert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ruleNames := make(map[int64]string, len(e.rules))
+	for _, rule := range e.rules {
+		ruleNames[rule.ID] = rule.Name
+	}
+
+	active := []ActiveAlert{}
+	for key, state := range e.states {
+		if !state.active {
+			continue
+		}
+		active = append(active, ActiveAlert{
+			RuleID:     key.ruleID,
+			RuleName:   ruleNames[key.ruleID],
+			InstanceID: key.instanceID,
+			Status:     state.status,
+			Failures:   state.failures,
+			Since:      state.since,
+		})
+	}
+	return active
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+type mockStore struct {
+	rules     []models.AlertRule
+	notifiers []models.NotifierConfig
+}
+
+func (m *mockStore) GetAlertRules() ([]models.AlertRule, error) {
+	return m.rules, nil
+}
+
+func (m *mockStore) GetNotifiers() ([]models.NotifierConfig, error) {
+	return m.notifiers, nil
+}
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []models.Alert
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, alert models.Alert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func (n *recordingNotifier) events() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	events := make([]string, 0, len(n.alerts))
+	for _, alert := range n.alerts {
+		events = append(events, alert.Event)
+	}
+	return events
+}
+
+func setupEngine(t *testing.T, rule models.AlertRule) (*Engine, *recordingNotifier) {
+	t.Helper()
+
+	recorder := &recordingNotifier{}
+	RegisterNotifier("recorder", func(settings map[string]string) (Notifier, error) {
+		return recorder, nil
+	})
+
+	rule.ID = 1
+	rule.Enabled = true
+	rule.NotifierIDs = []int64{1}
+
+	store := &mockStore{
+		rules:     []models.AlertRule{rule},
+		notifiers: []models.NotifierConfig{{ID: 1, Name: "recorder", Type: "recorder", Enabled: true}},
+	}
+	return NewEngine(store), recorder
+}
+
+func process(engine *Engine, instanceID string, statuses ...string) {
+	for _, status := range statuses {
+		engine.Process(models.ServiceHealth{ServiceID: instanceID, Status: status})
+	}
+	engine.Wait()
+}
+
+func TestEngine_Debounce(t *testing.T) {
+	engine, recorder := setupEngine(t, models.AlertRule{Name: "debounce", FailureThreshold: 3, NotifyRecovery: true})
+
+	process(engine, "sonarr-1", "online", "offline", "offline")
+	assert.Empty(t, recorder.events(), "should not alert before threshold")
+
+	process(engine, "sonarr-1", "online", "offline", "offline")
+	assert.Empty(t, recorder.events(), "recovery should reset the failure count")
+
+	process(engine, "sonarr-1", "error")
+	assert.Equal(t, []string{models.AlertEventTriggered}, recorder.events())
+
+	process(engine, "sonarr-1", "offline", "offline")
+	assert.Len(t, recorder.events(), 1, "should not alert again while already firing")
+	assert.Len(t, engine.ActiveAlerts(), 1)
+
+	process(engine, "sonarr-1", "online")
+	assert.Equal(t, []string{models.AlertEventTriggered, models.AlertEventResolved}, recorder.events())
+	assert.Empty(t, engine.ActiveAlerts())
+}
+
+func TestEngine_Transitions(t *testing.T) {
+	engine, recorder := setupEngine(t, models.AlertRule{Name: "warnings", FailureThreshold: 1, IncludeWarnings: true})
+
+	process(engine, "radarr-1", "online", "warning")
+	if assert.Len(t, recorder.alerts, 1) {
+		assert.Equal(t, "online", recorder.alerts[0].PreviousStatus)
+		assert.Equal(t, "warning", recorder.alerts[0].Health.Status)
+		assert.Equal(t, "warnings", recorder.alerts[0].RuleName)
+	}
+
+	// Recovery notifications are disabled for this rule
+	process(engine, "radarr-1", "online")
+	assert.Len(t, recorder.events(), 1)
+
+	// Unknown statuses neither trigger nor resolve alerts
+	process(engine, "radarr-1", "unknown", "checking")
+	assert.Len(t, recorder.events(), 1)
+}
+
+func TestEngine_InstanceFilter(t *testing.T) {
+	engine, recorder := setupEngine(t, models.AlertRule{Name: "plex", InstanceID: "plex-1", FailureThreshold: 1})
+
+	process(engine, "sonarr-1", "offline")
+	assert.Empty(t, recorder.events())
+
+	process(engine, "plex-1", "offline")
+	assert.Equal(t, []string{models.AlertEventTriggered}, recorder.events())
+}
+
+func TestEngine_Updates(t *testing.T) {
+	engine, recorder := setupEngine(t, models.AlertRule{Name: "updates", FailureThreshold: 1, NotifyUpdates: true})
+
+	check := func(status string, updateAvailable bool) {
+		engine.Process(models.ServiceHealth{ServiceID: "radarr-1", Status: status, UpdateAvailable: updateAvailable})
+		engine.Wait()
+	}
+
+	check("online", false)
+	check("online", true)
+	check("online", true)
+	assert.Equal(t, []string{models.AlertEventUpdate}, recorder.events(), "should notify once per available update")
+
+	// Offline checks carry no update information and must not reset the state
+	check("offline", false)
+	check("online", true)
+	assert.Equal(t, []string{models.AlertEventUpdate, models.AlertEventTriggered}, recorder.events())
+
+	check("online", false)
+	check("online", true)
+	assert.Equal(t, models.AlertEventUpdate, recorder.events()[len(recorder.events())-1])
+}
+
+func TestEngine_Requests(t *testing.T) {
+	engine, recorder := setupEngine(t, models.AlertRule{Name: "requests", FailureThreshold: 1, NotifyRequests: true})
+
+	engine.ProcessRequests("overseerr-1", 2)
+	engine.ProcessRequests("overseerr-1", 2)
+	engine.ProcessRequests("overseerr-1", 1)
+	engine.Wait()
+	assert.Empty(t, recorder.events(), "the first count is a baseline and decreases are ignored")
+
+	engine.ProcessRequests("overseerr-1", 3)
+	engine.Wait()
+	if assert.Len(t, recorder.alerts, 1) {
+		assert.Equal(t, models.AlertEventRequests, recorder.alerts[0].Event)
+		assert.Equal(t, 3, recorder.alerts[0].PendingCount)
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	notifiers := []models.NotifierConfig{{ID: 1, Name: "log", Type: "log"}}
+
+	assert.NoError(t, ValidateRule(models.AlertRule{Name: "ok", FailureThreshold: 1, NotifierIDs: []int64{1}}, notifiers))
+	assert.Error(t, ValidateRule(models.AlertRule{Name: "", FailureThreshold: 1}, notifiers))
+	assert.Error(t, ValidateRule(models.AlertRule{Name: "zero", FailureThreshold: 0}, notifiers))
+	assert.Error(t, ValidateRule(models.AlertRule{Name: "missing", FailureThreshold: 1, NotifierIDs: []int64{2}}, notifiers))
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+// Embed colors used by chat notifiers
+const (
+	colorTriggered = 0xE74C3C
+	colorWarning   = 0xF1C40F
+	colorResolved  = 0x2ECC71
+	colorTest      = 0x3498DB
+	colorInfo      = 0x9B59B6
+)
+
+// priority is a provider independent urgency level, push notifiers map it to
+// their own scale
+type priority int
+
+const (
+	priorityLow priority = iota
+	priorityDefault
+	priorityHigh
+	priorityUrgent
+)
+
+// alertTitle returns a short human readable summary of an alert
+func alertTitle(alert models.Alert) string {
+	switch alert.Event {
+	case models.AlertEventTriggered:
+		return fmt.Sprintf("%s is %s", alert.InstanceID, alert.Health.Status)
+	case models.AlertEventResolved:
+		return fmt.Sprintf("%s has recovered", alert.InstanceID)
+	case models.AlertEventUpdate:
+		return fmt.Sprintf("Update available for %s", alert.InstanceID)
+	case models.AlertEventRequests:
+		return fmt.Sprintf("%s has pending requests", alert.InstanceID)
+	case models.AlertEventTest:
+		return "dashbrr test notification"
+	default:
+		return fmt.Sprintf("%s: %s", alert.InstanceID, alert.Health.Status)
+	}
+}
+
+// alertDescription returns the body text of an alert
+func alertDescription(alert models.Alert) string {
+	message := alert.Health.Message
+	if message == "" {
+		message = "No message provided"
+	}
+
+	switch alert.Event {
+	case models.AlertEventTriggered:
+		if alert.PreviousStatus != "" && alert.PreviousStatus != alert.Health.Status {
+			return fmt.Sprintf("Status changed from %s to %s after %d failed check(s).\n%s",
+				alert.PreviousStatus, alert.Health.Status, alert.Failures, message)
+		}
+		return fmt.Sprintf("Status is %s after %d failed check(s).\n%s", alert.Health.Status, alert.Failures, message)
+	case models.AlertEventResolved:
+		return fmt.Sprintf("Status is back to %s.", alert.Health.Status)
+	case models.AlertEventUpdate:
+		if alert.Health.Version != "" {
+			return fmt.Sprintf("A new version is available, currently running %s.", alert.Health.Version)
+		}
+		return "A new version is available."
+	case models.AlertEventRequests:
+		return fmt.Sprintf("%d request(s) waiting for approval.", alert.PendingCount)
+	default:
+		return message
+	}
+}
+
+// alertColor returns the embed color for an alert
+func alertColor(alert models.Alert) int {
+	switch alert.Event {
+	case models.AlertEventTriggered:
+		if alert.Health.Status == "warning" {
+			return colorWarning
+		}
+		return colorTriggered
+	case models.AlertEventResolved:
+		return colorResolved
+	case models.AlertEventUpdate, models.AlertEventRequests:
+		return colorInfo
+	default:
+		return colorTest
+	}
+}
+
+// alertPriority maps an alert to an urgency level based on the event and health status
+func alertPriority(alert models.Alert) priority {
+	switch alert.Event {
+	case models.AlertEventTriggered:
+		switch alert.Health.Status {
+		case "offline":
+			return priorityUrgent
+		case "error":
+			return priorityHigh
+		default:
+			return priorityDefault
+		}
+	case models.AlertEventUpdate:
+		return priorityLow
+	default:
+		return priorityDefault
+	}
+}
+
+// clickURL returns the dashbrr URL opened when a push notification is tapped.
+// The click_url setting takes precedence over DASHBRR__PUBLIC_URL.
+func clickURL(settings map[string]string) string {
+	url := settings["click_url"]
+	if url == "" {
+		url = os.Getenv("DASHBRR__PUBLIC_URL")
+	}
+	return strings.TrimRight(url, "/")
+}
+
+// alertField is a labelled value shown by chat notifiers
+type alertField struct {
+	Name  string
+	Value string
+}
+
+// alertFields returns the labelled values describing the service health
+func alertFields(alert models.Alert) []alertField {
+	var fields []alertField
+	if alert.Health.Status != "" {
+		fields = append(fields, alertField{Name: "Status", Value: alert.Health.Status})
+	}
+	if alert.Health.ResponseTime > 0 {
+		fields = append(fields, alertField{Name: "Response time", Value: fmt.Sprintf("%dms", alert.Health.ResponseTime)})
+	}
+	if alert.Health.Version != "" {
+		fields = append(fields, alertField{Name: "Version", Value: alert.Health.Version})
+	}
+	if alert.Health.UpdateAvailable {
+		fields = append(fields, alertField{Name: "Update", Value: "Available"})
+	}
+	if alert.PendingCount > 0 {
+		fields = append(fields, alertField{Name: "Pending requests", Value: fmt.Sprintf("%d", alert.PendingCount)})
+	}
+	if alert.RuleName != "" && alert.Event != models.AlertEventTest {
+		fields = append(fields, alertField{Name: "Rule", Value: alert.RuleName})
+	}
+	return fields
+}
+
+// TemplateData is the data passed to webhook and email templates. The embedded
+// ServiceHealth exposes fields such as .Status, .Message, .Version and .ResponseTime.
+type TemplateData struct {
+	models.ServiceHealth
+	Event          string
+	RuleName       string
+	InstanceID     string
+	PreviousStatus string
+	Failures       int
+	PendingCount   int
+	Time           time.Time
+	Title          string
+	Description    string
+}
+
+func newTemplateData(alert models.Alert) TemplateData {
+	return TemplateData{
+		ServiceHealth:  alert.Health,
+		Event:          alert.Event,
+		RuleName:       alert.RuleName,
+		InstanceID:     alert.InstanceID,
+		PreviousStatus: alert.PreviousStatus,
+		Failures:       alert.Failures,
+		PendingCount:   alert.PendingCount,
+		Time:           alert.Time,
+		Title:          alertTitle(alert),
+		Description:    alertDescription(alert),
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func init() {
+	RegisterNotifier("gotify", NewGotifyNotifier)
+}
+
+// GotifyNotifier sends alerts to a Gotify server using an application token
+type GotifyNotifier struct {
+	server string
+	token  string
+	click  string
+}
+
+type gotifyMessage struct {
+	Title    string                 `json:"title"`
+	Message  string                 `json:"message"`
+	Priority int                    `json:"priority"`
+	Extras   map[string]interface{} `json:"extras,omitempty"`
+}
+
+// NewGotifyNotifier creates a Gotify notifier from the url, token and click_url settings
+func NewGotifyNotifier(settings map[string]string) (Notifier, error) {
+	if settings["url"] == "" {
+		return nil, fmt.Errorf("gotify notifier requires a url")
+	}
+	if settings["token"] == "" {
+		return nil, fmt.Errorf("gotify notifier requires an application token")
+	}
+
+	return &GotifyNotifier{
+		server: strings.TrimRight(settings["url"], "/"),
+		token:  settings["token"],
+		click:  clickURL(settings),
+	}, nil
+}
+
+func (n *GotifyNotifier) Send(ctx context.Context, alert models.Alert) error {
+	message := gotifyMessage{
+		Title:    alertTitle(alert),
+		Message:  alertDescription(alert),
+		Priority: gotifyPriority(alertPriority(alert)),
+	}
+	if n.click != "" {
+		message.Extras = map[string]interface{}{
+			"client::notification": map[string]interface{}{
+				"click": map[string]string{"url": n.click},
+			},
+		}
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	headers := map[string]string{"X-Gotify-Key": n.token}
+	return sendRequest(ctx, http.MethodPost, n.server+"/message", "application/json", body, headers)
+}
+
+// gotifyPriority maps to Gotify priorities 0 to 10, the Android app only
+// shows a heads-up notification from 8 upwards
+func gotifyPriority(p priority) int {
+	switch p {
+	case priorityLow:
+		return 2
+	case priorityHigh:
+		return 8
+	case priorityUrgent:
+		return 10
+	default:
+		return 5
+	}
+}
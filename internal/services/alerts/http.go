@@ -0,0 +1,154 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/autobrr/dashbrr/internal/buildinfo"
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultRetryDelay = 2 * time.Second
+)
+
+var notifierHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// ErrNotifierHTTP is returned when a notification target responds with a non-2xx status
+type ErrNotifierHTTP struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ErrNotifierHTTP) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("notification target returned %s (%d): %s", http.StatusText(e.StatusCode), e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("notification target returned %s (%d)", http.StatusText(e.StatusCode), e.StatusCode)
+}
+
+// retryableError is implemented by delivery errors that know whether sending again can succeed
+type retryableError interface {
+	error
+	Retryable() bool
+}
+
+// Retryable reports whether the request may succeed when sent again
+func (e *ErrNotifierHTTP) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// sendRequest sends a request body to a notification target and checks the response status
+func sendRequest(ctx context.Context, method, url, contentType string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	buildinfo.AttachUserAgentHeader(req)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := notifierHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &ErrNotifierHTTP{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(respBody))}
+	}
+
+	return nil
+}
+
+// postJSON sends a JSON payload to a notification target
+func postJSON(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	return sendRequest(ctx, http.MethodPost, url, "application/json", body, nil)
+}
+
+// retryNotifier retries failed deliveries with exponential backoff
+type retryNotifier struct {
+	Notifier
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// withRetry wraps a notifier using the max_retries and retry_delay settings
+func withRetry(notifier Notifier, settings map[string]string) (Notifier, error) {
+	maxRetries := defaultMaxRetries
+	if value := settings["max_retries"]; value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid max_retries: %s", value)
+		}
+		maxRetries = parsed
+	}
+
+	retryDelay := defaultRetryDelay
+	if value := settings["retry_delay"]; value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid retry_delay: %s", value)
+		}
+		retryDelay = parsed
+	}
+
+	if maxRetries == 0 {
+		return notifier, nil
+	}
+
+	return &retryNotifier{
+		Notifier:   notifier,
+		maxRetries: maxRetries,
+		retryDelay: retryDelay,
+	}, nil
+}
+
+func (n *retryNotifier) Send(ctx context.Context, alert models.Alert) error {
+	var err error
+	delay := n.retryDelay
+
+	for attempt := 0; attempt <= n.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		err = n.Notifier.Send(ctx, alert)
+		if err == nil {
+			return nil
+		}
+
+		var retryErr retryableError
+		if errors.As(err, &retryErr) && !retryErr.Retryable() {
+			return err
+		}
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", n.maxRetries+1, err)
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+// Notifier delivers alerts to an external target
+type Notifier interface {
+	// Send delivers a single alert
+	Send(ctx context.Context, alert models.Alert) error
+}
+
+// NotifierFactory builds a notifier from its stored settings
+type NotifierFactory func(settings map[string]string) (Notifier, error)
+
+var (
+	notifierFactories   = make(map[string]NotifierFactory)
+	notifierFactoriesMu sync.RWMutex
+)
+
+func init() {
+	RegisterNotifier("log", NewLogNotifier)
+}
+
+// RegisterNotifier makes a notifier type available to the alerting engine
+func RegisterNotifier(notifierType string, factory NotifierFactory) {
+	notifierFactoriesMu.Lock()
+	defer notifierFactoriesMu.Unlock()
+	notifierFactories[strings.ToLower(notifierType)] = factory
+}
+
+// NotifierTypes returns the sorted list of registered notifier types
+func NotifierTypes() []string {
+	notifierFactoriesMu.RLock()
+	defer notifierFactoriesMu.RUnlock()
+
+	types := make([]string, 0, len(notifierFactories))
+	for notifierType := range notifierFactories {
+		types = append(types, notifierType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// NewNotifier builds a notifier from a stored configuration
+func NewNotifier(config models.NotifierConfig) (Notifier, error) {
+	notifierFactoriesMu.RLock()
+	factory, ok := notifierFactories[strings.ToLower(config.Type)]
+	notifierFactoriesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported notifier type: %s", config.Type)
+	}
+
+	settings := config.Settings
+	if settings == nil {
+		settings = map[string]string{}
+	}
+
+	notifier, err := factory(settings)
+	if err != nil {
+		return nil, err
+	}
+	return withRetry(notifier, settings)
+}
+
+// SendTestNotification sends a test alert through the given notifier configuration
+func SendTestNotification(ctx context.Context, config models.NotifierConfig) error {
+	notifier, err := NewNotifier(config)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return notifier.Send(ctx, models.Alert{
+		Event:      models.AlertEventTest,
+		RuleName:   "test",
+		InstanceID: "dashbrr-test",
+		Health: models.ServiceHealth{
+			ServiceID:   "dashbrr-test",
+			Status:      "online",
+			Message:     "This is a test notification from dashbrr",
+			LastChecked: now,
+		},
+		Time: now,
+	})
+}
+
+// LogNotifier writes alerts to the application log
+type LogNotifier struct{}
+
+// NewLogNotifier creates a notifier that only logs alerts
+func NewLogNotifier(settings map[string]string) (Notifier, error) {
+	return &LogNotifier{}, nil
+}
+
+func (n *LogNotifier) Send(ctx context.Context, alert models.Alert) error {
+	log.Info().
+		Str("event", alert.Event).
+		Str("rule", alert.RuleName).
+		Str("service", alert.InstanceID).
+		Str("previous_status", alert.PreviousStatus).
+		Str("status", alert.Health.Status).
+		Str("message", alert.Health.Message).
+		Msg("Service alert")
+	return nil
+}
+
+// ValidateNotifier checks that a notifier configuration can be built
+func ValidateNotifier(config models.NotifierConfig) error {
+	if strings.TrimSpace(config.Name) == "" {
+		return fmt.Errorf("notifier name is required")
+	}
+	if config.Type == "" {
+		return fmt.Errorf("notifier type is required")
+	}
+	_, err := NewNotifier(config)
+	return err
+}
+
+// ValidateRule checks that an alert rule is well formed and references existing notifiers
+func ValidateRule(rule models.AlertRule, notifiers []models.NotifierConfig) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return fmt.Errorf("rule name is required")
+	}
+	if rule.FailureThreshold < 1 {
+		return fmt.Errorf("failure threshold must be at least 1")
+	}
+
+	known := make(map[int64]bool, len(notifiers))
+	for _, notifier := range notifiers {
+		known[notifier.ID] = true
+	}
+	for _, id := range rule.NotifierIDs {
+		if !known[id] {
+			return fmt.Errorf("unknown notifier ID: %d", id)
+		}
+	}
+	return nil
+}
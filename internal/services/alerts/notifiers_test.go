@@ -0,0 +1,317 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func testAlert() models.Alert {
+	return models.Alert{
+		Event:          models.AlertEventTriggered,
+		RuleName:       "sonarr-down",
+		InstanceID:     "sonarr-1",
+		PreviousStatus: "online",
+		Failures:       3,
+		Health: models.ServiceHealth{
+			ServiceID:    "sonarr-1",
+			Status:       "offline",
+			Message:      "Connection refused",
+			ResponseTime: 250,
+			Version:      "4.0.0",
+		},
+		Time: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// captureServer records the last request body and answers with the given status codes in order
+func captureServer(t *testing.T, statuses ...int) (*httptest.Server, *[]byte, *int32) {
+	t.Helper()
+
+	var body []byte
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(&calls, 1)) - 1
+		body, _ = io.ReadAll(r.Body)
+		status := http.StatusOK
+		if call < len(statuses) {
+			status = statuses[call]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &body, &calls
+}
+
+func TestDiscordNotifier(t *testing.T) {
+	server, body, _ := captureServer(t)
+
+	notifier, err := NewNotifier(models.NotifierConfig{
+		Type:     "discord",
+		Settings: map[string]string{"webhook_url": server.URL},
+	})
+	require.NoError(t, err)
+	require.NoError(t, notifier.Send(context.Background(), testAlert()))
+
+	var payload discordPayload
+	require.NoError(t, json.Unmarshal(*body, &payload))
+	require.Len(t, payload.Embeds, 1)
+	assert.Equal(t, "dashbrr", payload.Username)
+	assert.Equal(t, "sonarr-1 is offline", payload.Embeds[0].Title)
+	assert.Equal(t, colorTriggered, payload.Embeds[0].Color)
+	assert.Contains(t, payload.Embeds[0].Description, "Connection refused")
+	assert.Equal(t, "2024-01-01T12:00:00Z", payload.Embeds[0].Timestamp)
+
+	_, err = NewNotifier(models.NotifierConfig{Type: "discord"})
+	assert.Error(t, err, "missing webhook_url should be rejected")
+}
+
+func TestSlackNotifier(t *testing.T) {
+	server, body, _ := captureServer(t)
+
+	notifier, err := NewNotifier(models.NotifierConfig{
+		Type:     "slack",
+		Settings: map[string]string{"webhook_url": server.URL, "channel": "#alerts"},
+	})
+	require.NoError(t, err)
+
+	alert := testAlert()
+	alert.Event = models.AlertEventResolved
+	alert.Health.Status = "online"
+	require.NoError(t, notifier.Send(context.Background(), alert))
+
+	var payload slackPayload
+	require.NoError(t, json.Unmarshal(*body, &payload))
+	assert.Equal(t, "#alerts", payload.Channel)
+	assert.Equal(t, "sonarr-1 has recovered", payload.Text)
+	require.Len(t, payload.Attachments, 1)
+	assert.Equal(t, "#2ECC71", payload.Attachments[0].Color)
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		check    func(t *testing.T, body map[string]interface{})
+	}{
+		{
+			name: "default payload",
+			check: func(t *testing.T, body map[string]interface{}) {
+				assert.Equal(t, "triggered", body["event"])
+				assert.Equal(t, "sonarr-1", body["instanceId"])
+			},
+		},
+		{
+			name:     "custom template",
+			template: `{"service": {{json .InstanceID}}, "status": {{json .Status}}, "message": {{json .Message}}, "version": {{json .Version}}, "ms": {{.ResponseTime}}}`,
+			check: func(t *testing.T, body map[string]interface{}) {
+				assert.Equal(t, "sonarr-1", body["service"])
+				assert.Equal(t, "offline", body["status"])
+				assert.Equal(t, "Connection refused", body["message"])
+				assert.Equal(t, "4.0.0", body["version"])
+				assert.Equal(t, float64(250), body["ms"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, body, _ := captureServer(t)
+
+			notifier, err := NewNotifier(models.NotifierConfig{
+				Type: "webhook",
+				Settings: map[string]string{
+					"url":      server.URL,
+					"template": tt.template,
+					"headers":  "X-Token: secret",
+				},
+			})
+			require.NoError(t, err)
+			require.NoError(t, notifier.Send(context.Background(), testAlert()))
+
+			var decoded map[string]interface{}
+			require.NoError(t, json.Unmarshal(*body, &decoded))
+			tt.check(t, decoded)
+		})
+	}
+
+	_, err := NewNotifier(models.NotifierConfig{
+		Type:     "webhook",
+		Settings: map[string]string{"url": "http://localhost", "template": `{"status": {{.Status}}}`},
+	})
+	assert.Error(t, err, "template producing invalid JSON should be rejected")
+}
+
+func TestNotifierRetry(t *testing.T) {
+	t.Run("retries server errors", func(t *testing.T) {
+		server, _, calls := captureServer(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK)
+
+		notifier, err := NewNotifier(models.NotifierConfig{
+			Type:     "discord",
+			Settings: map[string]string{"webhook_url": server.URL, "retry_delay": "1ms"},
+		})
+		require.NoError(t, err)
+
+		assert.NoError(t, notifier.Send(context.Background(), testAlert()))
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		server, _, calls := captureServer(t, 500, 500, 500, 500)
+
+		notifier, err := NewNotifier(models.NotifierConfig{
+			Type:     "slack",
+			Settings: map[string]string{"webhook_url": server.URL, "retry_delay": "1ms", "max_retries": "2"},
+		})
+		require.NoError(t, err)
+
+		assert.Error(t, notifier.Send(context.Background(), testAlert()))
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		server, _, calls := captureServer(t, http.StatusBadRequest)
+
+		notifier, err := NewNotifier(models.NotifierConfig{
+			Type:     "webhook",
+			Settings: map[string]string{"url": server.URL, "retry_delay": "1ms"},
+		})
+		require.NoError(t, err)
+
+		err = notifier.Send(context.Background(), testAlert())
+		var httpErr *ErrNotifierHTTP
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+}
+
+func TestSendTestNotification(t *testing.T) {
+	server, body, _ := captureServer(t)
+
+	err := SendTestNotification(context.Background(), models.NotifierConfig{
+		Type:     "discord",
+		Settings: map[string]string{"webhook_url": server.URL},
+	})
+	require.NoError(t, err)
+
+	var payload discordPayload
+	require.NoError(t, json.Unmarshal(*body, &payload))
+	require.Len(t, payload.Embeds, 1)
+	assert.Equal(t, "dashbrr test notification", payload.Embeds[0].Title)
+	assert.Equal(t, colorTest, payload.Embeds[0].Color)
+}
+
+// requestServer records the path, headers and JSON body of the last request
+func requestServer(t *testing.T) (*httptest.Server, *http.Request, map[string]interface{}) {
+	t.Helper()
+
+	last := &http.Request{}
+	body := map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*last = *r.Clone(context.Background())
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, last, body
+}
+
+func TestPushNotifiers(t *testing.T) {
+	alert := testAlert()
+
+	t.Run("ntfy", func(t *testing.T) {
+		server, req, body := requestServer(t)
+
+		notifier, err := NewNotifier(models.NotifierConfig{
+			Type:     "ntfy",
+			Settings: map[string]string{"server": server.URL + "/", "topic": "homelab", "token": "tk_secret", "click_url": "https://dashbrr.example.com/"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, notifier.Send(context.Background(), alert))
+
+		assert.Equal(t, "/", req.URL.Path)
+		assert.Equal(t, "Bearer tk_secret", req.Header.Get("Authorization"))
+		assert.Equal(t, "homelab", body["topic"])
+		assert.Equal(t, float64(5), body["priority"], "offline maps to max priority")
+		assert.Equal(t, "https://dashbrr.example.com", body["click"])
+
+		_, err = NewNotifier(models.NotifierConfig{Type: "ntfy"})
+		assert.Error(t, err, "missing topic should be rejected")
+	})
+
+	t.Run("gotify", func(t *testing.T) {
+		server, req, body := requestServer(t)
+
+		notifier, err := NewNotifier(models.NotifierConfig{
+			Type:     "gotify",
+			Settings: map[string]string{"url": server.URL, "token": "app-token", "click_url": "https://dashbrr.example.com"},
+		})
+		require.NoError(t, err)
+
+		update := alert
+		update.Event = models.AlertEventUpdate
+		update.Health.Status = "online"
+		require.NoError(t, notifier.Send(context.Background(), update))
+
+		assert.Equal(t, "/message", req.URL.Path)
+		assert.Equal(t, "app-token", req.Header.Get("X-Gotify-Key"))
+		assert.Equal(t, "Update available for sonarr-1", body["title"])
+		assert.Equal(t, float64(2), body["priority"], "updates map to low priority")
+		assert.Contains(t, body, "extras")
+	})
+
+	t.Run("pushover", func(t *testing.T) {
+		server, _, body := requestServer(t)
+
+		notifier, err := NewNotifier(models.NotifierConfig{
+			Type:     "pushover",
+			Settings: map[string]string{"api_url": server.URL, "token": "app", "user": "user-key"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, notifier.Send(context.Background(), alert))
+
+		assert.Equal(t, "app", body["token"])
+		assert.Equal(t, "user-key", body["user"])
+		assert.Equal(t, float64(2), body["priority"])
+		assert.Equal(t, float64(pushoverEmergencyRetry), body["retry"], "emergency messages require retry and expire")
+		assert.NotContains(t, body, "url")
+
+		_, err = NewNotifier(models.NotifierConfig{Type: "pushover", Settings: map[string]string{"token": "app"}})
+		assert.Error(t, err, "missing user key should be rejected")
+	})
+}
+
+func TestAlertPriority(t *testing.T) {
+	tests := []struct {
+		event  string
+		status string
+		want   priority
+	}{
+		{models.AlertEventTriggered, "offline", priorityUrgent},
+		{models.AlertEventTriggered, "error", priorityHigh},
+		{models.AlertEventTriggered, "warning", priorityDefault},
+		{models.AlertEventResolved, "online", priorityDefault},
+		{models.AlertEventUpdate, "online", priorityLow},
+		{models.AlertEventRequests, "online", priorityDefault},
+	}
+
+	for _, tt := range tests {
+		alert := models.Alert{Event: tt.event, Health: models.ServiceHealth{Status: tt.status}}
+		assert.Equal(t, tt.want, alertPriority(alert), "%s/%s", tt.event, tt.status)
+	}
+}
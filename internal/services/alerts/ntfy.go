@@ -0,0 +1,121 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+const defaultNtfyServer = "https://ntfy.sh"
+
+func init() {
+	RegisterNotifier("ntfy", NewNtfyNotifier)
+}
+
+// NtfyNotifier publishes alerts to an ntfy topic
+type NtfyNotifier struct {
+	server   string
+	topic    string
+	token    string
+	username string
+	password string
+	click    string
+}
+
+type ntfyMessage struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags,omitempty"`
+	Click    string   `json:"click,omitempty"`
+}
+
+// NewNtfyNotifier creates an ntfy notifier from the server, topic, token,
+// username, password and click_url settings
+func NewNtfyNotifier(settings map[string]string) (Notifier, error) {
+	if settings["topic"] == "" {
+		return nil, fmt.Errorf("ntfy notifier requires a topic")
+	}
+
+	server := strings.TrimRight(settings["server"], "/")
+	if server == "" {
+		server = defaultNtfyServer
+	}
+
+	return &NtfyNotifier{
+		server:   server,
+		topic:    settings["topic"],
+		token:    settings["token"],
+		username: settings["username"],
+		password: settings["password"],
+		click:    clickURL(settings),
+	}, nil
+}
+
+func (n *NtfyNotifier) Send(ctx context.Context, alert models.Alert) error {
+	body, err := json.Marshal(ntfyMessage{
+		Topic:    n.topic,
+		Title:    alertTitle(alert),
+		Message:  alertDescription(alert),
+		Priority: ntfyPriority(alertPriority(alert)),
+		Tags:     ntfyTags(alert),
+		Click:    n.click,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	headers := map[string]string{}
+	switch {
+	case n.token != "":
+		headers["Authorization"] = "Bearer " + n.token
+	case n.username != "":
+		credentials := base64.StdEncoding.EncodeToString([]byte(n.username + ":" + n.password))
+		headers["Authorization"] = "Basic " + credentials
+	}
+
+	// JSON messages are published to the server root, the topic is part of the body
+	return sendRequest(ctx, http.MethodPost, n.server, "application/json", body, headers)
+}
+
+// ntfyPriority maps to ntfy priorities 1 (min) to 5 (max)
+func ntfyPriority(p priority) int {
+	switch p {
+	case priorityLow:
+		return 2
+	case priorityHigh:
+		return 4
+	case priorityUrgent:
+		return 5
+	default:
+		return 3
+	}
+}
+
+// ntfyTags returns emoji shortcodes shown next to the notification title
+func ntfyTags(alert models.Alert) []string {
+	switch alert.Event {
+	case models.AlertEventTriggered:
+		if alert.Health.Status == "warning" {
+			return []string{"warning"}
+		}
+		return []string{"rotating_light"}
+	case models.AlertEventResolved:
+		return []string{"white_check_mark"}
+	case models.AlertEventUpdate:
+		return []string{"arrow_up"}
+	case models.AlertEventRequests:
+		return []string{"inbox_tray"}
+	default:
+		return []string{"bell"}
+	}
+}
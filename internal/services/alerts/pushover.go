@@ -0,0 +1,110 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+const defaultPushoverAPI = "https://api.pushover.net/1/messages.json"
+
+// Emergency priority messages repeat every retry seconds until acknowledged or expired
+const (
+	pushoverEmergencyRetry  = 60
+	pushoverEmergencyExpire = 3600
+)
+
+func init() {
+	RegisterNotifier("pushover", NewPushoverNotifier)
+}
+
+// PushoverNotifier sends alerts through the Pushover API
+type PushoverNotifier struct {
+	apiURL string
+	token  string
+	user   string
+	device string
+	sound  string
+	click  string
+}
+
+type pushoverMessage struct {
+	Token     string `json:"token"`
+	User      string `json:"user"`
+	Title     string `json:"title"`
+	Message   string `json:"message"`
+	Priority  int    `json:"priority"`
+	Retry     int    `json:"retry,omitempty"`
+	Expire    int    `json:"expire,omitempty"`
+	Device    string `json:"device,omitempty"`
+	Sound     string `json:"sound,omitempty"`
+	URL       string `json:"url,omitempty"`
+	URLTitle  string `json:"url_title,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+}
+
+// NewPushoverNotifier creates a Pushover notifier from the token, user, device,
+// sound, click_url and api_url settings
+func NewPushoverNotifier(settings map[string]string) (Notifier, error) {
+	if settings["token"] == "" {
+		return nil, fmt.Errorf("pushover notifier requires an application token")
+	}
+	if settings["user"] == "" {
+		return nil, fmt.Errorf("pushover notifier requires a user key")
+	}
+
+	apiURL := settings["api_url"]
+	if apiURL == "" {
+		apiURL = defaultPushoverAPI
+	}
+
+	return &PushoverNotifier{
+		apiURL: apiURL,
+		token:  settings["token"],
+		user:   settings["user"],
+		device: settings["device"],
+		sound:  settings["sound"],
+		click:  clickURL(settings),
+	}, nil
+}
+
+func (n *PushoverNotifier) Send(ctx context.Context, alert models.Alert) error {
+	message := pushoverMessage{
+		Token:     n.token,
+		User:      n.user,
+		Title:     alertTitle(alert),
+		Message:   alertDescription(alert),
+		Priority:  pushoverPriority(alertPriority(alert)),
+		Device:    n.device,
+		Sound:     n.sound,
+		Timestamp: alert.Time.Unix(),
+	}
+	if message.Priority == 2 {
+		message.Retry = pushoverEmergencyRetry
+		message.Expire = pushoverEmergencyExpire
+	}
+	if n.click != "" {
+		message.URL = n.click
+		message.URLTitle = "Open dashbrr"
+	}
+
+	return postJSON(ctx, n.apiURL, message)
+}
+
+// pushoverPriority maps to Pushover priorities -2 (lowest) to 2 (emergency)
+func pushoverPriority(p priority) int {
+	switch p {
+	case priorityLow:
+		return -1
+	case priorityHigh:
+		return 1
+	case priorityUrgent:
+		return 2
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func init() {
+	RegisterNotifier("slack", NewSlackNotifier)
+}
+
+// SlackNotifier posts alerts to a Slack incoming webhook
+type SlackNotifier struct {
+	webhookURL string
+	channel    string
+	username   string
+}
+
+type slackPayload struct {
+	Channel     string            `json:"channel,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Title  string       `json:"title"`
+	Text   string       `json:"text,omitempty"`
+	Fields []slackField `json:"fields,omitempty"`
+	Ts     int64        `json:"ts,omitempty"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// NewSlackNotifier creates a Slack notifier from the webhook_url, channel and username settings
+func NewSlackNotifier(settings map[string]string) (Notifier, error) {
+	if settings["webhook_url"] == "" {
+		return nil, fmt.Errorf("slack notifier requires a webhook_url")
+	}
+
+	return &SlackNotifier{
+		webhookURL: settings["webhook_url"],
+		channel:    settings["channel"],
+		username:   settings["username"],
+	}, nil
+}
+
+func (n *SlackNotifier) Send(ctx context.Context, alert models.Alert) error {
+	title := alertTitle(alert)
+	attachment := slackAttachment{
+		Color: fmt.Sprintf("#%06X", alertColor(alert)),
+		Title: title,
+		Text:  alertDescription(alert),
+		Ts:    alert.Time.Unix(),
+	}
+	for _, field := range alertFields(alert) {
+		attachment.Fields = append(attachment.Fields, slackField{Title: field.Name, Value: field.Value, Short: true})
+	}
+
+	return postJSON(ctx, n.webhookURL, slackPayload{
+		Channel:     n.channel,
+		Username:    n.username,
+		Text:        title,
+		Attachments: []slackAttachment{attachment},
+	})
+}
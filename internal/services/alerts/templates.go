@@ -0,0 +1,103 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"sync"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*
+var defaultTemplates embed.FS
+
+var (
+	templateDir   string
+	templateDirMu sync.RWMutex
+)
+
+var templateFuncs = map[string]interface{}{
+	"datetime": func(t time.Time) string {
+		return t.Local().Format("2006-01-02 15:04 MST")
+	},
+	"percent": func(value float64) string {
+		return fmt.Sprintf("%.2f%%", value)
+	},
+}
+
+// SetTemplateDir sets the directory searched for template overrides. A file
+// in this directory replaces the built-in template with the same name.
+func SetTemplateDir(dir string) {
+	templateDirMu.Lock()
+	defer templateDirMu.Unlock()
+	templateDir = dir
+}
+
+// readTemplate returns the override for a template if present, otherwise the built-in one
+func readTemplate(name string) (string, error) {
+	templateDirMu.RLock()
+	dir := templateDir
+	templateDirMu.RUnlock()
+
+	if dir != "" {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return string(content), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read template override %s: %w", name, err)
+		}
+	}
+
+	content, err := defaultTemplates.ReadFile("templates/" + name)
+	if err != nil {
+		return "", fmt.Errorf("unknown template %s: %w", name, err)
+	}
+	return string(content), nil
+}
+
+// renderText renders a plaintext template. Templates are read on every call
+// so overrides can be changed without restarting dashbrr.
+func renderText(name string, data interface{}) (string, error) {
+	content, err := readTemplate(name)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// renderHTML renders an HTML template with contextual escaping
+func renderHTML(name string, data interface{}) (string, error) {
+	content, err := readTemplate(name)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := htmltemplate.New(name).Funcs(templateFuncs).Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func init() {
+	RegisterNotifier("webhook", NewWebhookNotifier)
+}
+
+// WebhookNotifier sends alerts as JSON to an arbitrary HTTP endpoint
+type WebhookNotifier struct {
+	url      string
+	method   string
+	headers  map[string]string
+	template *template.Template
+}
+
+var webhookFuncs = template.FuncMap{
+	// json encodes a value so it can be embedded in a JSON template
+	"json": func(v interface{}) (string, error) {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	},
+}
+
+// NewWebhookNotifier creates a webhook notifier from the url, method, headers and template settings.
+// Headers are newline separated "Key: Value" pairs.
+func NewWebhookNotifier(settings map[string]string) (Notifier, error) {
+	if settings["url"] == "" {
+		return nil, fmt.Errorf("webhook notifier requires a url")
+	}
+
+	method := strings.ToUpper(settings["method"])
+	switch method {
+	case "":
+		method = http.MethodPost
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return nil, fmt.Errorf("unsupported webhook method: %s", settings["method"])
+	}
+
+	headers, err := parseHeaders(settings["headers"])
+	if err != nil {
+		return nil, err
+	}
+
+	notifier := &WebhookNotifier{
+		url:     settings["url"],
+		method:  method,
+		headers: headers,
+	}
+
+	if text := settings["template"]; text != "" {
+		tmpl, err := template.New("webhook").Funcs(webhookFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook template: %w", err)
+		}
+		notifier.template = tmpl
+
+		// Render a sample alert so broken templates are rejected up front
+		if _, err := notifier.render(sampleAlert()); err != nil {
+			return nil, err
+		}
+	}
+
+	return notifier, nil
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, alert models.Alert) error {
+	body, err := n.render(alert)
+	if err != nil {
+		return err
+	}
+	return sendRequest(ctx, n.method, n.url, "application/json", body, n.headers)
+}
+
+// render builds the request body for an alert
+func (n *WebhookNotifier) render(alert models.Alert) ([]byte, error) {
+	if n.template == nil {
+		body, err := json.Marshal(alert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode payload: %w", err)
+		}
+		return body, nil
+	}
+
+	var buf bytes.Buffer
+	if err := n.template.Execute(&buf, newTemplateData(alert)); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook template did not produce valid JSON")
+	}
+	return buf.Bytes(), nil
+}
+
+// parseHeaders parses newline separated "Key: Value" pairs
+func parseHeaders(raw string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid webhook header: %s", line)
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+func sampleAlert() models.Alert {
+	now := time.Now()
+	return models.Alert{
+		Event:          models.AlertEventTriggered,
+		RuleName:       "sample",
+		InstanceID:     "sample-1",
+		PreviousStatus: "online",
+		Failures:       1,
+		Health: models.ServiceHealth{
+			ServiceID:    "sample-1",
+			Status:       "offline",
+			Message:      "Sample \"message\"",
+			ResponseTime: 120,
+			Version:      "1.0.0",
+			LastChecked:  now,
+		},
+		Time: now,
+	}
+}
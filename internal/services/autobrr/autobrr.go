@@ -39,17 +39,20 @@ type VersionResponse struct {
 }
 
 func init() {
-	models.NewAutobrrService = NewAutobrrService
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "autobrr",
+		DisplayName:    "Autobrr",
+		Description:    "Monitor and manage your Autobrr instance",
+		DefaultURL:     "http://localhost:7474",
+		HealthEndpoint: "/api/healthz/liveness",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityStats, models.CapabilityIRC, models.CapabilityUpdates},
+		New:            NewAutobrrService,
+	})
 }
 
 func NewAutobrrService() models.ServiceHealthChecker {
-	service := &AutobrrService{}
-	service.Type = "autobrr"
-	service.DisplayName = "Autobrr"
-	service.Description = "Monitor and manage your Autobrr instance"
-	service.DefaultURL = "http://localhost:7474"
-	service.HealthEndpoint = "/api/healthz/liveness"
-	return service
+	return &AutobrrService{ServiceCore: core.NewServiceCore("autobrr")}
 }
 
 func (s *AutobrrService) getEndpoint(baseURL, path string) string {
@@ -57,12 +60,12 @@ func (s *AutobrrService) getEndpoint(baseURL, path string) string {
 	return fmt.Sprintf("%s%s", baseURL, path)
 }
 
-func (s *AutobrrService) GetReleaseStats(url, apiKey string) (AutobrrStats, error) {
+func (s *AutobrrService) GetReleaseStats(ctx context.Context, url, apiKey string) (AutobrrStats, error) {
 	if url == "" || apiKey == "" {
 		return AutobrrStats{}, fmt.Errorf("service not configured: missing URL or API key")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
 	statsURL := s.getEndpoint(url, "/api/release/stats")
@@ -107,7 +110,7 @@ func (s *AutobrrService) CacheIRCStatus(url, status string) error {
 	return s.CacheVersion(url+"_irc", status, 5*time.Minute)
 }
 
-func (s *AutobrrService) GetIRCStatus(url, apiKey string) ([]IRCStatus, error) {
+func (s *AutobrrService) GetIRCStatus(ctx context.Context, url, apiKey string) ([]IRCStatus, error) {
 	if url == "" || apiKey == "" {
 		return nil, fmt.Errorf("service not configured: missing URL or API key")
 	}
@@ -120,7 +123,7 @@ func (s *AutobrrService) GetIRCStatus(url, apiKey string) ([]IRCStatus, error) {
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
 	ircURL := s.getEndpoint(url, "/api/irc")
@@ -186,13 +189,13 @@ func (s *AutobrrService) GetIRCStatus(url, apiKey string) ([]IRCStatus, error) {
 	return []IRCStatus{{Name: "IRC", Healthy: false}}, fmt.Errorf("failed to decode response: %s", string(body))
 }
 
-func (s *AutobrrService) GetVersion(url, apiKey string) (string, error) {
+func (s *AutobrrService) GetVersion(ctx context.Context, url, apiKey string) (string, error) {
 	// Check cache first
 	if version := s.GetVersionFromCache(url); version != "" {
 		return version, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	versionURL := s.getEndpoint(url, "/api/config")
@@ -243,13 +246,13 @@ func (s *AutobrrService) CacheUpdate(url, status string, ttl time.Duration) erro
 	return s.CacheVersion(url+"_update", status, ttl)
 }
 
-func (s *AutobrrService) CheckUpdate(url, apiKey string) (bool, error) {
+func (s *AutobrrService) CheckUpdate(ctx context.Context, url, apiKey string) (bool, error) {
 	// Check cache first
 	if status := s.GetUpdateFromCache(url); status != "" {
 		return status == "true", nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	updateURL := s.getEndpoint(url, "/api/updates/latest")
@@ -280,21 +283,22 @@ func (s *AutobrrService) CheckUpdate(url, apiKey string) (bool, error) {
 	return hasUpdate, nil
 }
 
-func (s *AutobrrService) CheckHealth(url string, apiKey string) (models.ServiceHealth, int) {
+func (s *AutobrrService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
 	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
 
 	if url == "" || apiKey == "" {
-		return s.CreateHealthResponse(startTime, "pending", "Autobrr not configured"), http.StatusOK
+		return s.CreateHealthResponse(startTime, "pending", "Autobrr not configured"), nil
 	}
 
 	// Create a context with timeout for the entire health check
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := core.HealthCheckContext(ctx, service, 15*time.Second)
 	defer cancel()
 
 	// Start version check in background
 	versionChan := make(chan string, 1)
 	go func() {
-		version, err := s.GetVersion(url, apiKey)
+		version, err := s.GetVersion(ctx, url, apiKey)
 		if err != nil {
 			versionChan <- ""
 			return
@@ -305,7 +309,7 @@ func (s *AutobrrService) CheckHealth(url string, apiKey string) (models.ServiceH
 	// Start update check in background
 	updateChan := make(chan bool, 1)
 	go func() {
-		hasUpdate, err := s.CheckUpdate(url, apiKey)
+		hasUpdate, err := s.CheckUpdate(ctx, url, apiKey)
 		if err != nil {
 			updateChan <- false
 			return
@@ -314,7 +318,7 @@ func (s *AutobrrService) CheckHealth(url string, apiKey string) (models.ServiceH
 	}()
 
 	// Get release stats
-	stats, err := s.GetReleaseStats(url, apiKey)
+	stats, err := s.GetReleaseStats(ctx, url, apiKey)
 	if err != nil {
 		fmt.Printf("Failed to get release stats: %v\n", err)
 		// Continue without stats, don't fail the health check
@@ -329,7 +333,7 @@ func (s *AutobrrService) CheckHealth(url string, apiKey string) (models.ServiceH
 
 	resp, err := s.MakeRequestWithContext(ctx, livenessURL, apiKey, headers)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
 	}
 	defer resp.Body.Close()
 
@@ -337,19 +341,19 @@ func (s *AutobrrService) CheckHealth(url string, apiKey string) (models.ServiceH
 	responseTime, _ := time.ParseDuration(resp.Header.Get("X-Response-Time") + "ms")
 
 	if resp.StatusCode != http.StatusOK {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Unexpected status code: %d", resp.StatusCode)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Unexpected status code: %d", resp.StatusCode)), nil
 	}
 
 	body, err := s.ReadBody(resp)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), nil
 	}
 
 	trimmedBody := strings.TrimSpace(string(body))
 	trimmedBody = strings.Trim(trimmedBody, "\"")
 
 	if trimmedBody != "healthy" && trimmedBody != "OK" {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Autobrr reported unhealthy status: %s", trimmedBody)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Autobrr reported unhealthy status: %s", trimmedBody)), nil
 	}
 
 	// Wait for version and update status with timeout
@@ -370,7 +374,7 @@ func (s *AutobrrService) CheckHealth(url string, apiKey string) (models.ServiceH
 	}
 
 	// Get IRC status
-	ircStatus, err := s.GetIRCStatus(url, apiKey)
+	ircStatus, err := s.GetIRCStatus(ctx, url, apiKey)
 	if err != nil {
 		return s.CreateHealthResponse(startTime, "warning", fmt.Sprintf("Autobrr is running but IRC status check failed: %v", err), map[string]interface{}{
 			"version":         version,
@@ -384,7 +388,7 @@ func (s *AutobrrService) CheckHealth(url string, apiKey string) (models.ServiceH
 			"stats": map[string]interface{}{
 				"autobrr": stats,
 			},
-		}), http.StatusOK
+		}), nil
 	}
 
 	// Check if any IRC connections are healthy
@@ -418,8 +422,8 @@ func (s *AutobrrService) CheckHealth(url string, apiKey string) (models.ServiceH
 				"irc": ircStatus,
 			},
 		}
-		return s.CreateHealthResponse(startTime, "warning", "Autobrr is running but reports unhealthy IRC connections", extras), http.StatusOK
+		return s.CreateHealthResponse(startTime, "warning", "Autobrr is running but reports unhealthy IRC connections", extras), nil
 	}
 
-	return s.CreateHealthResponse(startTime, "online", "Autobrr is running", extras), http.StatusOK
+	return s.CreateHealthResponse(startTime, "online", "Autobrr is running", extras), nil
 }
@@ -3,6 +3,8 @@ package autobrr
 import (
 	"net/http"
 	"time"
+
+	"github.com/autobrr/dashbrr/internal/tracing"
 )
 
 // Client represents an Autobrr service client
@@ -24,7 +26,8 @@ func NewClient(baseURL, apiKey string) *Client {
 		BaseURL: baseURL,
 		APIKey:  apiKey,
 		http: &http.Client{
-			Timeout: 10 * time.Second,
+			Transport: tracing.Transport(nil),
+			Timeout:   10 * time.Second,
 		},
 	}
 }
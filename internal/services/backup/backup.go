@@ -0,0 +1,554 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Package backup creates and restores archives of the dashbrr state: the
+// database, the sessions persisted by the memory cache and the config file.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/autobrr/dashbrr/internal/buildinfo"
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/secrets"
+)
+
+const (
+	// FormatVersion is the version of the archive layout
+	FormatVersion = 1
+
+	filePrefix       = "dashbrr-backup-"
+	archiveExtension = ".tar.gz"
+	timeLayout       = "20060102-150405"
+
+	manifestEntry = "manifest.json"
+	databaseEntry = "dashbrr.db"
+	sessionsEntry = "sessions.json"
+	configDir     = "config/"
+
+	// maxEntrySize bounds the size of a single file extracted from an archive
+	maxEntrySize = 1 << 30
+)
+
+// Manifest describes the content of a backup archive
+type Manifest struct {
+	Format        int       `json:"format"`
+	Version       string    `json:"version"`
+	CreatedAt     time.Time `json:"createdAt"`
+	Driver        string    `json:"driver"`
+	Method        string    `json:"method"`
+	SchemaVersion int       `json:"schemaVersion"`
+	Files         []string  `json:"files"`
+	ConfigFile    string    `json:"configFile,omitempty"`
+}
+
+// Options select the state included in a backup and how it is protected
+type Options struct {
+	// ConfigPath is the active config file, included when it exists
+	ConfigPath string
+	// SessionsDir holds the sessions.json persisted by the memory cache
+	SessionsDir string
+	// Passphrase encrypts the archive when set, it can be a secret reference
+	Passphrase string
+}
+
+// RestoreOptions control which parts of a backup are restored
+type RestoreOptions struct {
+	Options
+	// SkipConfig keeps the current config file
+	SkipConfig bool
+}
+
+// RestoreResult describes a restored backup
+type RestoreResult struct {
+	Manifest *Manifest
+	Tables   []database.TableCount
+	Files    []string
+}
+
+// Archive is a backup archive stored in a directory
+type Archive struct {
+	Name      string
+	Path      string
+	Size      int64
+	CreatedAt time.Time
+	Encrypted bool
+}
+
+// FileName returns the name of an archive created at the given time
+func FileName(createdAt time.Time, encrypted bool) string {
+	name := filePrefix + createdAt.UTC().Format(timeLayout) + archiveExtension
+	if encrypted {
+		name += encryptedExtension
+	}
+	return name
+}
+
+// Snapshot is a copy of the dashbrr state taken for a backup, which is
+// streamed as an archive by Write. Close removes the copy.
+type Snapshot struct {
+	Manifest *Manifest
+
+	tmpDir     string
+	files      map[string]string
+	passphrase string
+}
+
+// NewSnapshot copies the database and collects the files in opts. Everything
+// that can fail before the archive is written, including resolving the
+// passphrase, happens here.
+func NewSnapshot(db *database.DB, opts Options) (*Snapshot, error) {
+	var passphrase string
+	if opts.Passphrase != "" {
+		var err error
+		if passphrase, err = secrets.Resolve(context.Background(), opts.Passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "dashbrr-backup-")
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{tmpDir: tmpDir, passphrase: passphrase}
+
+	dbPath := filepath.Join(tmpDir, databaseEntry)
+	if err := db.BackupTo(dbPath); err != nil {
+		snapshot.Close()
+		return nil, err
+	}
+
+	schemaVersion, err := db.SchemaVersion()
+	if err != nil {
+		snapshot.Close()
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	manifest := &Manifest{
+		Format:        FormatVersion,
+		Version:       buildinfo.Version,
+		CreatedAt:     time.Now().UTC(),
+		Driver:        db.Driver(),
+		Method:        "sqlite-online-backup",
+		SchemaVersion: schemaVersion,
+	}
+	if db.Driver() == "postgres" {
+		manifest.Method = "logical-dump"
+	}
+
+	files := map[string]string{databaseEntry: dbPath}
+	if opts.SessionsDir != "" {
+		path := filepath.Join(opts.SessionsDir, sessionsEntry)
+		if fileExists(path) {
+			files[sessionsEntry] = path
+		}
+	}
+	if opts.ConfigPath != "" && fileExists(opts.ConfigPath) {
+		files[configDir+filepath.Base(opts.ConfigPath)] = opts.ConfigPath
+		manifest.ConfigFile = opts.ConfigPath
+	}
+
+	for name := range files {
+		manifest.Files = append(manifest.Files, name)
+	}
+	sort.Strings(manifest.Files)
+
+	snapshot.Manifest = manifest
+	snapshot.files = files
+	return snapshot, nil
+}
+
+// Encrypted reports whether the archive is encrypted
+func (s *Snapshot) Encrypted() bool {
+	return s.passphrase != ""
+}
+
+// Write streams the archive to w, encrypted when a passphrase is set
+func (s *Snapshot) Write(w io.Writer) error {
+	if !s.Encrypted() {
+		return writeArchive(w, s.Manifest, s.files)
+	}
+
+	ew, err := newEncryptWriter(w, s.passphrase)
+	if err != nil {
+		return err
+	}
+	if err := writeArchive(ew, s.Manifest, s.files); err != nil {
+		return err
+	}
+	return ew.Close()
+}
+
+// Close removes the copy of the database
+func (s *Snapshot) Close() error {
+	return os.RemoveAll(s.tmpDir)
+}
+
+// Create writes a backup archive of the database and the files in opts to w
+func Create(db *database.DB, w io.Writer, opts Options) (*Manifest, error) {
+	snapshot, err := NewSnapshot(db, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer snapshot.Close()
+
+	if err := snapshot.Write(w); err != nil {
+		return nil, err
+	}
+	return snapshot.Manifest, nil
+}
+
+// writeArchive writes the manifest and files to a gzip compressed tar archive
+func writeArchive(w io.Writer, manifest *Manifest, files map[string]string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeEntry(tw, manifestEntry, manifestData, manifest.CreatedAt); err != nil {
+		return err
+	}
+
+	for _, name := range manifest.Files {
+		if err := copyEntry(tw, name, files[name], manifest.CreatedAt); err != nil {
+			return fmt.Errorf("failed to add %s: %w", files[name], err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// copyEntry streams a file into the archive
+func copyEntry(tw *tar.Writer, name, path string, modTime time.Time) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    info.Size(),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.CopyN(tw, file, info.Size())
+	return err
+}
+
+// Restore replaces the database with the one in a backup archive and writes
+// back the sessions and config file it contains. Encrypted archives need the
+// passphrase they were created with.
+func Restore(db *database.DB, r io.Reader, opts RestoreOptions) (*RestoreResult, error) {
+	br := bufio.NewReader(r)
+	var archive io.Reader = br
+
+	if magic, _ := br.Peek(len(encryptedMagic)); IsEncrypted(magic) {
+		if opts.Passphrase == "" {
+			return nil, ErrPassphraseRequired
+		}
+		passphrase, err := secrets.Resolve(context.Background(), opts.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		if archive, err = newDecryptReader(br, passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "dashbrr-restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest, entries, err := readArchive(archive, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	// Read to the end, so a truncated encrypted archive is detected
+	if _, err := io.Copy(io.Discard, archive); err != nil {
+		return nil, err
+	}
+	if manifest.Format > FormatVersion {
+		return nil, fmt.Errorf("backup format %d is newer than supported format %d", manifest.Format, FormatVersion)
+	}
+
+	dbPath := filepath.Join(tmpDir, databaseEntry)
+	if !fileExists(dbPath) {
+		return nil, fmt.Errorf("backup contains no database")
+	}
+
+	tables, err := db.RestoreFrom(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore database: %w", err)
+	}
+
+	result := &RestoreResult{Manifest: manifest, Tables: tables, Files: []string{databaseEntry}}
+
+	if sessions, ok := entries[sessionsEntry]; ok && opts.SessionsDir != "" {
+		path := filepath.Join(opts.SessionsDir, sessionsEntry)
+		if err := writeFile(path, sessions); err != nil {
+			return result, fmt.Errorf("failed to restore sessions: %w", err)
+		}
+		result.Files = append(result.Files, path)
+	}
+
+	if !opts.SkipConfig && opts.ConfigPath != "" {
+		for name, content := range entries {
+			if !strings.HasPrefix(name, configDir) {
+				continue
+			}
+			if err := writeFile(opts.ConfigPath, content); err != nil {
+				return result, fmt.Errorf("failed to restore config file: %w", err)
+			}
+			result.Files = append(result.Files, opts.ConfigPath)
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// readArchive returns the manifest and the known entries of an archive. The
+// database is extracted to dir instead of being returned.
+func readArchive(r io.Reader, dir string) (*Manifest, map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a dashbrr backup: %w", err)
+	}
+	defer gz.Close()
+
+	entries := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || !knownEntry(header.Name) {
+			continue
+		}
+
+		if header.Name == databaseEntry {
+			if err := writeFileFrom(filepath.Join(dir, databaseEntry), func(w io.Writer) error {
+				return extractEntry(w, tr, header.Name)
+			}); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := extractEntry(&buf, tr, header.Name); err != nil {
+			return nil, nil, err
+		}
+		entries[header.Name] = buf.Bytes()
+	}
+
+	manifestData, ok := entries[manifestEntry]
+	if !ok {
+		return nil, nil, fmt.Errorf("not a dashbrr backup: missing %s", manifestEntry)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	return &manifest, entries, nil
+}
+
+// extractEntry copies the current archive entry to w, up to maxEntrySize
+func extractEntry(w io.Writer, tr *tar.Reader, name string) error {
+	n, err := io.Copy(w, io.LimitReader(tr, maxEntrySize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read %s from backup: %w", name, err)
+	}
+	if n > maxEntrySize {
+		return fmt.Errorf("%s in backup is too large", name)
+	}
+	return nil
+}
+
+// knownEntry reports whether an archive entry is one Restore uses. Config
+// files are accepted by base name only, so entries cannot escape the target.
+func knownEntry(name string) bool {
+	switch name {
+	case manifestEntry, databaseEntry, sessionsEntry:
+		return true
+	}
+	rest, ok := strings.CutPrefix(name, configDir)
+	return ok && rest != "" && !strings.ContainsAny(rest, `/\`) && rest != ".."
+}
+
+// WriteFile creates a backup archive in dir and returns its path
+func WriteFile(db *database.DB, dir string, opts Options) (string, *Manifest, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", nil, err
+	}
+
+	snapshot, err := NewSnapshot(db, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	defer snapshot.Close()
+
+	path := filepath.Join(dir, FileName(snapshot.Manifest.CreatedAt, snapshot.Encrypted()))
+	if err := writeFileFrom(path, snapshot.Write); err != nil {
+		return "", nil, err
+	}
+	return path, snapshot.Manifest, nil
+}
+
+// List returns the backup archives in dir, newest first
+func List(dir string) ([]Archive, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var archives []Archive
+	for _, entry := range dirEntries {
+		archive, ok := parseFileName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		archive.Path = filepath.Join(dir, entry.Name())
+		archive.Size = info.Size()
+		archives = append(archives, archive)
+	}
+
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].CreatedAt.After(archives[j].CreatedAt)
+	})
+	return archives, nil
+}
+
+// Prune deletes all but the newest keep archives in dir and returns the
+// paths of the deleted archives
+func Prune(dir string, keep int) ([]string, error) {
+	archives, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	if keep < 0 {
+		keep = 0
+	}
+
+	var deleted []string
+	for i := keep; i < len(archives); i++ {
+		if err := os.Remove(archives[i].Path); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, archives[i].Path)
+	}
+	return deleted, nil
+}
+
+// parseFileName returns the archive described by a file name created by FileName
+func parseFileName(name string) (Archive, bool) {
+	rest, ok := strings.CutPrefix(name, filePrefix)
+	if !ok {
+		return Archive{}, false
+	}
+
+	archive := Archive{Name: name}
+	if trimmed, ok := strings.CutSuffix(rest, encryptedExtension); ok {
+		rest = trimmed
+		archive.Encrypted = true
+	}
+	rest, ok = strings.CutSuffix(rest, archiveExtension)
+	if !ok {
+		return Archive{}, false
+	}
+
+	createdAt, err := time.Parse(timeLayout, rest)
+	if err != nil {
+		return Archive{}, false
+	}
+	archive.CreatedAt = createdAt
+	return archive, true
+}
+
+// writeFile atomically replaces a file readable only by its owner
+func writeFile(path string, data []byte) error {
+	return writeFileFrom(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeFileFrom atomically replaces a file readable only by its owner with
+// the content written by write
+func writeFileFrom(path string, write func(w io.Writer) error) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
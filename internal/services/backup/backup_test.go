@@ -0,0 +1,204 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func setupTestDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	db, err := database.InitDBWithConfig(&database.Config{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "dashbrr.db")})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.CreateService(&models.ServiceConfiguration{
+		InstanceID:  "sonarr-1",
+		DisplayName: "Sonarr",
+		URL:         "http://localhost:8989",
+		APIKey:      "secret",
+	}))
+
+	stateDir := t.TempDir()
+	configPath := filepath.Join(stateDir, "config.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte("[server]\nlisten_addr = \":8080\"\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "sessions.json"), []byte(`{"session:abc":{}}`), 0600))
+
+	opts := Options{ConfigPath: configPath, SessionsDir: stateDir}
+
+	var archive bytes.Buffer
+	manifest, err := Create(db, &archive, opts)
+	require.NoError(t, err)
+	assert.Equal(t, FormatVersion, manifest.Format)
+	assert.Equal(t, "sqlite", manifest.Driver)
+	assert.Equal(t, "sqlite-online-backup", manifest.Method)
+	assert.Equal(t, []string{"config/config.toml", "dashbrr.db", "sessions.json"}, manifest.Files)
+	assert.Positive(t, manifest.SchemaVersion)
+	assert.False(t, IsEncrypted(archive.Bytes()))
+
+	// Change the state after the backup
+	require.NoError(t, db.DeleteService("sonarr-1"))
+	require.NoError(t, db.CreateService(&models.ServiceConfiguration{InstanceID: "radarr-1", DisplayName: "Radarr"}))
+	require.NoError(t, os.WriteFile(configPath, []byte("changed"), 0600))
+	require.NoError(t, os.Remove(filepath.Join(stateDir, "sessions.json")))
+
+	result, err := Restore(db, bytes.NewReader(archive.Bytes()), RestoreOptions{Options: opts})
+	require.NoError(t, err)
+	assert.Equal(t, manifest.CreatedAt, result.Manifest.CreatedAt)
+	assert.Contains(t, result.Files, configPath)
+
+	services, err := db.GetAllServices()
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "sonarr-1", services[0].InstanceID)
+	assert.Equal(t, "secret", services[0].APIKey)
+
+	config, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(config), "listen_addr")
+
+	sessions, err := os.ReadFile(filepath.Join(stateDir, "sessions.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"session:abc":{}}`, string(sessions))
+
+	t.Run("skip config", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(configPath, []byte("kept"), 0600))
+
+		_, err := Restore(db, bytes.NewReader(archive.Bytes()), RestoreOptions{Options: opts, SkipConfig: true})
+		require.NoError(t, err)
+
+		config, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, "kept", string(config))
+	})
+}
+
+func TestCreateAndRestore_Encrypted(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.CreateService(&models.ServiceConfiguration{InstanceID: "sonarr-1", DisplayName: "Sonarr"}))
+
+	var archive bytes.Buffer
+	_, err := Create(db, &archive, Options{Passphrase: "correct horse"})
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(archive.Bytes()))
+
+	_, err = Restore(db, bytes.NewReader(archive.Bytes()), RestoreOptions{})
+	assert.ErrorIs(t, err, ErrPassphraseRequired)
+
+	_, err = Restore(db, bytes.NewReader(archive.Bytes()), RestoreOptions{Options: Options{Passphrase: "battery staple"}})
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+
+	t.Setenv("DASHBRR_TEST_PASSPHRASE", "correct horse")
+	result, err := Restore(db, bytes.NewReader(archive.Bytes()), RestoreOptions{Options: Options{Passphrase: "env:DASHBRR_TEST_PASSPHRASE"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dashbrr.db"}, result.Files)
+}
+
+func TestRestore_InvalidArchive(t *testing.T) {
+	db := setupTestDB(t)
+
+	_, err := Restore(db, bytes.NewReader([]byte("not an archive")), RestoreOptions{})
+	assert.ErrorContains(t, err, "not a dashbrr backup")
+
+	// An archive without a manifest, with an entry escaping the config directory
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, writeEntry(tw, "config/../../etc/passwd", []byte("x"), time.Now()))
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	_, err = Restore(db, &buf, RestoreOptions{})
+	assert.ErrorContains(t, err, "missing manifest.json")
+}
+
+func TestKnownEntry(t *testing.T) {
+	assert.True(t, knownEntry("manifest.json"))
+	assert.True(t, knownEntry("dashbrr.db"))
+	assert.True(t, knownEntry("config/config.toml"))
+	assert.False(t, knownEntry("config/"))
+	assert.False(t, knownEntry("config/../config.toml"))
+	assert.False(t, knownEntry("config/.."))
+	assert.False(t, knownEntry("../dashbrr.db"))
+	assert.False(t, knownEntry("other.txt"))
+}
+
+func TestListAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 4; i++ {
+		name := FileName(base.Add(time.Duration(i)*24*time.Hour), i == 3)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("archive"), 0600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0600))
+
+	archives, err := List(dir)
+	require.NoError(t, err)
+	require.Len(t, archives, 4)
+	assert.Equal(t, "dashbrr-backup-20240104-030000.tar.gz.enc", archives[0].Name)
+	assert.True(t, archives[0].Encrypted)
+	assert.Equal(t, base, archives[3].CreatedAt)
+	assert.Equal(t, int64(7), archives[3].Size)
+
+	deleted, err := Prune(dir, 2)
+	require.NoError(t, err)
+	assert.Len(t, deleted, 2)
+
+	archives, err = List(dir)
+	require.NoError(t, err)
+	require.Len(t, archives, 2)
+	assert.Equal(t, base.Add(48*time.Hour), archives[1].CreatedAt)
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+
+	archives, err = List(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, archives)
+}
+
+func TestSchedulerTick(t *testing.T) {
+	db := setupTestDB(t)
+	dir := t.TempDir()
+
+	scheduler := NewScheduler(db, Config{Dir: dir, Interval: time.Hour, Retention: 2})
+
+	now := time.Now()
+	scheduler.tick(now)
+	archives, err := List(dir)
+	require.NoError(t, err)
+	require.Len(t, archives, 1)
+
+	// Not due before the interval has passed
+	scheduler.tick(now.Add(30 * time.Minute))
+	archives, err = List(dir)
+	require.NoError(t, err)
+	assert.Len(t, archives, 1)
+
+	// Older archives are pruned down to the retention
+	for i := 1; i <= 3; i++ {
+		name := FileName(now.Add(-time.Duration(i)*24*time.Hour), false)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("archive"), 0600))
+	}
+	scheduler.tick(now.Add(2 * time.Hour))
+	archives, err = List(dir)
+	require.NoError(t, err)
+	assert.Len(t, archives, 2)
+}
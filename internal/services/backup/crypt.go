@@ -0,0 +1,205 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	encryptedExtension = ".enc"
+
+	// encryptedMagic starts encrypted archives, which continue with the salt
+	// and the archive sealed with AES-256-GCM in chunks of chunkSize. The nonce
+	// of a chunk is its counter and a flag marking the last chunk, so reordered
+	// or truncated archives fail to decrypt. The key is unique per archive.
+	encryptedMagic = "DASHBRR-BACKUP-ENC1\n"
+	saltSize       = 16
+	chunkSize      = 64 * 1024
+)
+
+var (
+	// ErrPassphraseRequired is returned when an encrypted backup is restored without a passphrase
+	ErrPassphraseRequired = errors.New("backup is encrypted, a passphrase is required")
+	// ErrWrongPassphrase is returned when an encrypted backup cannot be decrypted
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted backup")
+)
+
+// IsEncrypted reports whether an archive is encrypted
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedMagic))
+}
+
+// passphraseAEAD stretches a passphrase into an AES-256-GCM key with Argon2id
+func passphraseAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), salt, 1, 64*1024, 4, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of the chunk with the given counter
+func chunkNonce(aead cipher.AEAD, counter uint64, last bool) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-9:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+// encryptWriter encrypts everything written to it chunk by chunk
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+}
+
+func newEncryptWriter(w io.Writer, passphrase string) (*encryptWriter, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aead, err := passphraseAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(append([]byte(encryptedMagic), salt...)); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data follows, the last chunk
+		// is sealed by Close
+		if len(e.buf) == chunkSize {
+			if err := e.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(e.buf[len(e.buf):chunkSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close seals the last chunk. The underlying writer is not closed.
+func (e *encryptWriter) Close() error {
+	return e.seal(true)
+}
+
+func (e *encryptWriter) seal(last bool) error {
+	sealed := e.aead.Seal(nil, chunkNonce(e.aead, e.counter, last), e.buf, []byte(encryptedMagic))
+	e.counter++
+	e.buf = e.buf[:0]
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+// decryptReader decrypts an archive written by encryptWriter
+type decryptReader struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	chunk   []byte
+	plain   []byte
+	buf     []byte
+	counter uint64
+	last    bool
+}
+
+func newDecryptReader(r io.Reader, passphrase string) (*decryptReader, error) {
+	header := make([]byte, len(encryptedMagic)+saltSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if !IsEncrypted(header) {
+		return nil, fmt.Errorf("backup is not encrypted")
+	}
+
+	aead, err := passphraseAEAD(passphrase, header[len(encryptedMagic):])
+	if err != nil {
+		return nil, err
+	}
+	d := &decryptReader{
+		r:     r,
+		aead:  aead,
+		chunk: make([]byte, chunkSize+aead.Overhead()),
+		plain: make([]byte, 0, chunkSize),
+	}
+
+	// A wrong passphrase is reported before anything reads the archive
+	if err := d.open(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.last {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// open decrypts the next chunk
+func (d *decryptReader) open() error {
+	n, err := io.ReadFull(d.r, d.chunk)
+	switch {
+	case errors.Is(err, io.EOF):
+		// The last chunk is missing
+		return ErrWrongPassphrase
+	case err != nil && !errors.Is(err, io.ErrUnexpectedEOF):
+		return err
+	}
+	sealed := d.chunk[:n]
+	full := err == nil
+
+	if full {
+		if plain, err := d.aead.Open(d.plain[:0], chunkNonce(d.aead, d.counter, false), sealed, []byte(encryptedMagic)); err == nil {
+			d.counter++
+			d.buf = plain
+			return nil
+		}
+	}
+
+	plain, err := d.aead.Open(d.plain[:0], chunkNonce(d.aead, d.counter, true), sealed, []byte(encryptedMagic))
+	if err != nil {
+		return ErrWrongPassphrase
+	}
+	// Nothing may follow the last chunk
+	if full {
+		if _, err := io.ReadFull(d.r, make([]byte, 1)); !errors.Is(err, io.EOF) {
+			return ErrWrongPassphrase
+		}
+	}
+	d.last = true
+	d.buf = plain
+	return nil
+}
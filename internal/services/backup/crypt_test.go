@@ -0,0 +1,80 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encryptBytes(t *testing.T, data []byte, passphrase string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	ew, err := newEncryptWriter(&buf, passphrase)
+	require.NoError(t, err)
+	_, err = ew.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, ew.Close())
+	return buf.Bytes()
+}
+
+func decryptBytes(data []byte, passphrase string) ([]byte, error) {
+	dr, err := newDecryptReader(bytes.NewReader(data), passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(dr)
+}
+
+func TestEncryption(t *testing.T) {
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3 * chunkSize} {
+		data := make([]byte, size)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+
+		encrypted := encryptBytes(t, data, "secret")
+		assert.True(t, IsEncrypted(encrypted))
+
+		decrypted, err := decryptBytes(encrypted, "secret")
+		require.NoError(t, err, "size %d", size)
+		assert.Equal(t, data, decrypted, "size %d", size)
+	}
+}
+
+func TestEncryption_Tampering(t *testing.T) {
+	data := bytes.Repeat([]byte("dashbrr"), chunkSize)
+	encrypted := encryptBytes(t, data, "secret")
+	header := len(encryptedMagic) + saltSize
+	sealedChunk := chunkSize + 16
+
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase string
+	}{
+		{name: "wrong passphrase", data: encrypted, passphrase: "other"},
+		{name: "truncated at a chunk", data: encrypted[:header+2*sealedChunk]},
+		{name: "truncated within a chunk", data: encrypted[:len(encrypted)-1]},
+		{name: "trailing data", data: append(bytes.Clone(encrypted), 0)},
+		{name: "chunks swapped", data: append(append(bytes.Clone(encrypted[:header]),
+			encrypted[header+sealedChunk:header+2*sealedChunk]...), encrypted[header:header+sealedChunk]...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passphrase := tt.passphrase
+			if passphrase == "" {
+				passphrase = "secret"
+			}
+			_, err := decryptBytes(tt.data, passphrase)
+			assert.ErrorIs(t, err, ErrWrongPassphrase)
+		})
+	}
+}
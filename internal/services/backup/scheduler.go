@@ -0,0 +1,139 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+)
+
+const (
+	// DefaultRetention is the number of scheduled backups kept
+	DefaultRetention = 7
+
+	schedulerInterval = time.Minute
+)
+
+// Config configures where backups are stored and how often they are created
+type Config struct {
+	Options
+	Dir string
+	// Interval between scheduled backups, 0 disables them
+	Interval time.Duration
+	// Retention is the number of archives kept in Dir by scheduled backups
+	Retention int
+}
+
+// NewConfig returns the backup configuration of a database and config file
+// from the DASHBRR__BACKUP_* environment variables
+func NewConfig(db *database.DB, configPath string) Config {
+	// The memory cache persists sessions next to DASHBRR__DB_PATH
+	sessionsDir := filepath.Dir(os.Getenv("DASHBRR__DB_PATH"))
+
+	dataDir := sessionsDir
+	if db.Path() != "" {
+		dataDir = filepath.Dir(db.Path())
+	}
+
+	config := Config{
+		Options: Options{
+			ConfigPath:  configPath,
+			SessionsDir: sessionsDir,
+			Passphrase:  os.Getenv("DASHBRR__BACKUP_PASSPHRASE"),
+		},
+		Dir:       filepath.Join(dataDir, "backups"),
+		Retention: DefaultRetention,
+	}
+
+	if env := os.Getenv("DASHBRR__BACKUP_DIR"); env != "" {
+		config.Dir = env
+	}
+	if env := os.Getenv("DASHBRR__BACKUP_INTERVAL"); env != "" {
+		interval, err := time.ParseDuration(env)
+		if err != nil || interval < 0 {
+			log.Warn().Str("value", env).Msg("Invalid DASHBRR__BACKUP_INTERVAL, scheduled backups disabled")
+		} else {
+			config.Interval = interval
+		}
+	}
+	if env := os.Getenv("DASHBRR__BACKUP_RETENTION"); env != "" {
+		retention, err := strconv.Atoi(env)
+		if err != nil || retention < 1 {
+			log.Warn().Str("value", env).Int("default", DefaultRetention).Msg("Invalid DASHBRR__BACKUP_RETENTION, using default")
+		} else {
+			config.Retention = retention
+		}
+	}
+
+	return config
+}
+
+// Scheduler creates backups at a fixed interval and prunes old ones
+type Scheduler struct {
+	db     *database.DB
+	config Config
+}
+
+// NewScheduler creates a backup scheduler for the given database
+func NewScheduler(db *database.DB, config Config) *Scheduler {
+	return &Scheduler{db: db, config: config}
+}
+
+// Start checks every minute whether a backup is due. It does nothing when
+// scheduled backups are disabled.
+func (s *Scheduler) Start() {
+	if s.config.Interval <= 0 {
+		return
+	}
+
+	log.Info().
+		Str("dir", s.config.Dir).
+		Dur("interval", s.config.Interval).
+		Int("retention", s.config.Retention).
+		Msg("Scheduled backups enabled")
+
+	go func() {
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+
+		s.tick(time.Now())
+		for now := range ticker.C {
+			s.tick(now)
+		}
+	}()
+}
+
+// tick creates a backup when the newest archive is older than the interval,
+// so restarts do not create additional backups
+func (s *Scheduler) tick(now time.Time) {
+	archives, err := List(s.config.Dir)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list backups")
+		return
+	}
+	if len(archives) > 0 && now.Before(archives[0].CreatedAt.Add(s.config.Interval)) {
+		return
+	}
+
+	path, _, err := WriteFile(s.db, s.config.Dir, s.config.Options)
+	if err != nil {
+		log.Error().Err(err).Msg("Scheduled backup failed")
+		return
+	}
+	log.Info().Str("path", path).Msg("Created scheduled backup")
+
+	deleted, err := Prune(s.config.Dir, s.config.Retention)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to prune old backups")
+	}
+	for _, path := range deleted {
+		log.Debug().Str("path", path).Msg("Deleted old backup")
+	}
+}
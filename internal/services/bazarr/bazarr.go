@@ -0,0 +1,258 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package bazarr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/core"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+// Custom error types for better error handling
+type ErrBazarr struct {
+	Op       string // Operation that failed
+	Err      error  // Underlying error
+	HttpCode int    // HTTP status code if applicable
+}
+
+func (e *ErrBazarr) Error() string {
+	if e.HttpCode > 0 {
+		return fmt.Sprintf("bazarr %s: server returned %s (%d)", e.Op, http.StatusText(e.HttpCode), e.HttpCode)
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("bazarr %s: %v", e.Op, e.Err)
+	}
+	return fmt.Sprintf("bazarr %s", e.Op)
+}
+
+func (e *ErrBazarr) Unwrap() error {
+	return e.Err
+}
+
+type BazarrService struct {
+	core.ServiceCore
+}
+
+type systemStatusResponse struct {
+	Data struct {
+		BazarrVersion string `json:"bazarr_version"`
+	} `json:"data"`
+}
+
+type healthResponse struct {
+	Data []types.BazarrHealthIssue `json:"data"`
+}
+
+type wantedResponse struct {
+	Total int `json:"total"`
+}
+
+type providersResponse struct {
+	Data []types.BazarrProviderStatus `json:"data"`
+}
+
+func init() {
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "bazarr",
+		DisplayName:    "Bazarr",
+		Description:    "Monitor subtitle health of your Bazarr instance",
+		DefaultURL:     "http://localhost:6767",
+		HealthEndpoint: "/api/system/health",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityStats},
+		New:            NewBazarrService,
+	})
+}
+
+func NewBazarrService() models.ServiceHealthChecker {
+	return &BazarrService{ServiceCore: core.NewServiceCore("bazarr")}
+}
+
+// getJSON fetches a Bazarr API path and decodes the response into v
+func (s *BazarrService) getJSON(ctx context.Context, op, baseURL, apiKey, path string, v interface{}) error {
+	if baseURL == "" {
+		return &ErrBazarr{Op: op, Err: fmt.Errorf("URL is required")}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+path, nil)
+	if err != nil {
+		return &ErrBazarr{Op: op, Err: fmt.Errorf("failed to create request: %w", err)}
+	}
+	req.Header.Set("X-API-KEY", apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := core.Do(req)
+	if err != nil {
+		return &ErrBazarr{Op: op, Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &ErrBazarr{Op: op, HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return &ErrBazarr{Op: op, Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return &ErrBazarr{Op: op, Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	return nil
+}
+
+// GetVersion returns the Bazarr version, cached for an hour
+func (s *BazarrService) GetVersion(ctx context.Context, baseURL, apiKey string) (string, error) {
+	if version := s.GetVersionFromCache(baseURL); version != "" {
+		return version, nil
+	}
+
+	var status systemStatusResponse
+	if err := s.getJSON(ctx, "get_version", baseURL, apiKey, "/api/system/status", &status); err != nil {
+		return "", err
+	}
+
+	if err := s.CacheVersion(baseURL, status.Data.BazarrVersion, time.Hour); err != nil {
+		log.Warn().Err(err).Msg("Failed to cache Bazarr version")
+	}
+
+	return status.Data.BazarrVersion, nil
+}
+
+// GetHealthIssues returns the issues reported by Bazarr's own health checks
+func (s *BazarrService) GetHealthIssues(ctx context.Context, baseURL, apiKey string) ([]types.BazarrHealthIssue, error) {
+	var health healthResponse
+	if err := s.getJSON(ctx, "get_health", baseURL, apiKey, "/api/system/health", &health); err != nil {
+		return nil, err
+	}
+	return health.Data, nil
+}
+
+// GetWantedCount returns the number of episodes or movies with missing subtitles.
+// kind is either "episodes" or "movies".
+func (s *BazarrService) GetWantedCount(ctx context.Context, baseURL, apiKey, kind string) (int, error) {
+	var wanted wantedResponse
+	path := fmt.Sprintf("/api/%s/wanted?start=0&length=1", kind)
+	if err := s.getJSON(ctx, "get_wanted_"+kind, baseURL, apiKey, path, &wanted); err != nil {
+		return 0, err
+	}
+	return wanted.Total, nil
+}
+
+// GetThrottledProviders returns the subtitle providers Bazarr has throttled
+func (s *BazarrService) GetThrottledProviders(ctx context.Context, baseURL, apiKey string) ([]types.BazarrProviderStatus, error) {
+	var providers providersResponse
+	if err := s.getJSON(ctx, "get_providers", baseURL, apiKey, "/api/providers", &providers); err != nil {
+		return nil, err
+	}
+	if providers.Data == nil {
+		return []types.BazarrProviderStatus{}, nil
+	}
+	return providers.Data, nil
+}
+
+// GetStats collects wanted subtitle counts, throttled providers and health issues
+func (s *BazarrService) GetStats(ctx context.Context, baseURL, apiKey string) (*types.BazarrStatsResponse, error) {
+	if baseURL == "" {
+		return nil, &ErrBazarr{Op: "get_stats", Err: fmt.Errorf("URL is required")}
+	}
+
+	if apiKey == "" {
+		return nil, &ErrBazarr{Op: "get_stats", Err: fmt.Errorf("API key is required")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	stats := &types.BazarrStatsResponse{}
+	var err error
+
+	if stats.WantedEpisodes, err = s.GetWantedCount(ctx, baseURL, apiKey, "episodes"); err != nil {
+		return nil, err
+	}
+	if stats.WantedMovies, err = s.GetWantedCount(ctx, baseURL, apiKey, "movies"); err != nil {
+		return nil, err
+	}
+	if stats.Providers, err = s.GetThrottledProviders(ctx, baseURL, apiKey); err != nil {
+		return nil, err
+	}
+	stats.ThrottledProviders = len(stats.Providers)
+
+	// Version and health issues are informational, the stats are still useful without them
+	if version, err := s.GetVersion(ctx, baseURL, apiKey); err == nil {
+		stats.Version = version
+	}
+	if issues, err := s.GetHealthIssues(ctx, baseURL, apiKey); err == nil {
+		stats.HealthIssues = issues
+	}
+
+	return stats, nil
+}
+
+func (s *BazarrService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
+	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
+
+	if url == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
+	}
+
+	ctx, cancel := core.HealthCheckContext(ctx, service, 5*time.Second)
+	defer cancel()
+
+	// The status endpoint doubles as connectivity check and version source
+	version, err := s.GetVersion(ctx, url, apiKey)
+	if err != nil {
+		if bazarrErr, ok := err.(*ErrBazarr); ok && bazarrErr.HttpCode > 0 {
+			message := fmt.Sprintf("Server returned %s (%d)", http.StatusText(bazarrErr.HttpCode), bazarrErr.HttpCode)
+			if bazarrErr.HttpCode == http.StatusUnauthorized {
+				message = "Invalid API key"
+			}
+			return s.CreateHealthResponse(startTime, "error", message), nil
+		}
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
+	}
+
+	extras := map[string]interface{}{}
+	if version != "" {
+		extras["version"] = version
+	}
+
+	issues, err := s.GetHealthIssues(ctx, url, apiKey)
+	if err != nil {
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to get health status: %v", err), extras), nil
+	}
+
+	var warnings []string
+	for _, issue := range issues {
+		if issue.Object != "" {
+			warnings = append(warnings, fmt.Sprintf("[%s] %s", issue.Object, issue.Issue))
+		} else {
+			warnings = append(warnings, issue.Issue)
+		}
+	}
+
+	if providers, err := s.GetThrottledProviders(ctx, url, apiKey); err == nil {
+		for _, provider := range providers {
+			warnings = append(warnings, fmt.Sprintf("Provider %s is throttled: %s (retry %s)", provider.Name, provider.Status, provider.Retry))
+		}
+	}
+
+	if len(warnings) > 0 {
+		return s.CreateHealthResponse(startTime, "warning", strings.Join(warnings, "\n\n"), extras), nil
+	}
+
+	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), nil
+}
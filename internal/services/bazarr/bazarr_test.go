@@ -0,0 +1,99 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package bazarr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func newBazarrServer(t *testing.T, providers string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-KEY") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/system/status":
+			w.Write([]byte(`{"data": {"bazarr_version": "1.4.5"}}`))
+		case "/api/system/health":
+			w.Write([]byte(`{"data": []}`))
+		case "/api/episodes/wanted":
+			w.Write([]byte(`{"data": [], "total": 12}`))
+		case "/api/movies/wanted":
+			w.Write([]byte(`{"data": [], "total": 3}`))
+		case "/api/providers":
+			w.Write([]byte(providers))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGetStats(t *testing.T) {
+	server := newBazarrServer(t, `{"data": [{"name": "opensubtitles", "status": "TooManyRequests", "retry": "0:59:00"}]}`)
+	defer server.Close()
+
+	stats, err := (&BazarrService{}).GetStats(context.Background(), server.URL, "test-key")
+	require.NoError(t, err)
+
+	assert.Equal(t, 12, stats.WantedEpisodes)
+	assert.Equal(t, 3, stats.WantedMovies)
+	assert.Equal(t, 1, stats.ThrottledProviders)
+	assert.Equal(t, "opensubtitles", stats.Providers[0].Name)
+}
+
+func TestCheckHealth(t *testing.T) {
+	tests := []struct {
+		name      string
+		apiKey    string
+		providers string
+		status    string
+		message   string
+	}{
+		{
+			name:      "Healthy",
+			apiKey:    "test-key",
+			providers: `{"data": []}`,
+			status:    "online",
+			message:   "Healthy",
+		},
+		{
+			name:      "Throttled provider",
+			apiKey:    "test-key",
+			providers: `{"data": [{"name": "opensubtitles", "status": "TooManyRequests", "retry": "0:59:00"}]}`,
+			status:    "warning",
+			message:   "Provider opensubtitles is throttled: TooManyRequests (retry 0:59:00)",
+		},
+		{
+			name:      "Invalid API key",
+			apiKey:    "wrong-key",
+			providers: `{"data": []}`,
+			status:    "error",
+			message:   "Invalid API key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newBazarrServer(t, tt.providers)
+			defer server.Close()
+
+			service := NewBazarrService().(*BazarrService)
+			health, err := service.CheckHealth(context.Background(), models.ServiceConfiguration{URL: server.URL, APIKey: tt.apiKey})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, health.Status)
+			assert.Equal(t, tt.message, health.Message)
+		})
+	}
+}
@@ -89,6 +89,11 @@ func getCacheType() CacheType {
 // InitCache initializes a cache instance based on configuration.
 // It always returns a valid cache store, falling back to memory cache if Redis fails.
 func InitCache(cfg Config) (Store, error) {
+	store, err := initStore(cfg)
+	return withTracing(store), err
+}
+
+func initStore(cfg Config) (Store, error) {
 	cacheType := getCacheType()
 
 	switch cacheType {
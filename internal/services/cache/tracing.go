@@ -0,0 +1,98 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/autobrr/dashbrr/internal/tracing"
+)
+
+// tracedStore records a span for every cache operation
+type tracedStore struct {
+	Store
+	backend string
+}
+
+// withTracing wraps a store so its operations show up in request traces
+func withTracing(store Store) Store {
+	backend := string(CacheTypeMemory)
+	if _, ok := store.(*RedisStore); ok {
+		backend = string(CacheTypeRedis)
+	}
+	return &tracedStore{Store: store, backend: backend}
+}
+
+func (s *tracedStore) start(ctx context.Context, op, key string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "cache."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("cache.backend", s.backend),
+			attribute.String("cache.key", key),
+		))
+}
+
+func end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (s *tracedStore) Get(ctx context.Context, key string, value interface{}) error {
+	ctx, span := s.start(ctx, "get", key)
+	err := s.Store.Get(ctx, key, value)
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil))
+	end(span, err)
+	return err
+}
+
+func (s *tracedStore) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	ctx, span := s.start(ctx, "set", key)
+	err := s.Store.Set(ctx, key, value, expiration)
+	end(span, err)
+	return err
+}
+
+func (s *tracedStore) Delete(ctx context.Context, key string) error {
+	ctx, span := s.start(ctx, "delete", key)
+	err := s.Store.Delete(ctx, key)
+	end(span, err)
+	return err
+}
+
+func (s *tracedStore) Increment(ctx context.Context, key string, timestamp int64) error {
+	ctx, span := s.start(ctx, "increment", key)
+	err := s.Store.Increment(ctx, key, timestamp)
+	end(span, err)
+	return err
+}
+
+func (s *tracedStore) CleanAndCount(ctx context.Context, key string, windowStart int64) error {
+	ctx, span := s.start(ctx, "clean_and_count", key)
+	err := s.Store.CleanAndCount(ctx, key, windowStart)
+	end(span, err)
+	return err
+}
+
+func (s *tracedStore) GetCount(ctx context.Context, key string) (int64, error) {
+	ctx, span := s.start(ctx, "get_count", key)
+	count, err := s.Store.GetCount(ctx, key)
+	end(span, err)
+	return count, err
+}
+
+func (s *tracedStore) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	ctx, span := s.start(ctx, "expire", key)
+	err := s.Store.Expire(ctx, key, expiration)
+	end(span, err)
+	return err
+}
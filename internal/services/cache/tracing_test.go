@@ -0,0 +1,71 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedStore(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	store := withTracing(NewMemoryStore(t.TempDir()))
+	defer store.Close()
+
+	ctx := context.Background()
+	var value string
+	if err := store.Get(ctx, "missing", &value); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got %v", err)
+	}
+	if err := store.Set(ctx, "key", "value", time.Minute); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := store.Get(ctx, "key", &value); err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+
+	expected := []struct {
+		name string
+		hit  bool
+	}{
+		{"cache.get", false},
+		{"cache.set", false},
+		{"cache.get", true},
+	}
+	for i, span := range spans {
+		if span.Name() != expected[i].name {
+			t.Errorf("Span %d: expected name %s, got %s", i, expected[i].name, span.Name())
+		}
+		if span.Status().Code == codes.Error {
+			t.Errorf("Span %d: cache misses must not be reported as errors", i)
+		}
+
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, attr := range span.Attributes() {
+			attrs[attr.Key] = attr.Value
+		}
+		if attrs["cache.backend"].AsString() != "memory" {
+			t.Errorf("Span %d: expected memory backend, got %q", i, attrs["cache.backend"].AsString())
+		}
+		if hit, ok := attrs["cache.hit"]; ok && hit.AsBool() != expected[i].hit {
+			t.Errorf("Span %d: expected cache.hit=%v", i, expected[i].hit)
+		}
+	}
+}
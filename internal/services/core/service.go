@@ -5,6 +5,7 @@ package core
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"io"
 	"net/http"
@@ -18,6 +19,7 @@ import (
 	"github.com/autobrr/dashbrr/internal/buildinfo"
 	"github.com/autobrr/dashbrr/internal/models"
 	"github.com/autobrr/dashbrr/internal/services/cache"
+	"github.com/autobrr/dashbrr/internal/tracing"
 )
 
 var (
@@ -39,27 +41,77 @@ type ServiceCore struct {
 	cache          cache.Store
 }
 
-// getHTTPClient returns a client with the specified timeout
-func getHTTPClient(timeout time.Duration) *http.Client {
-	// Use the timeout as the key
-	if client, ok := httpClients.Load(timeout); ok {
+// NewServiceCore returns a ServiceCore populated from the registered
+// descriptor of the given service type
+func NewServiceCore(serviceType string) ServiceCore {
+	descriptor, _ := models.LookupService(serviceType)
+	return ServiceCore{
+		Type:           serviceType,
+		DisplayName:    descriptor.DisplayName,
+		Description:    descriptor.Description,
+		DefaultURL:     descriptor.DefaultURL,
+		HealthEndpoint: descriptor.HealthEndpoint,
+	}
+}
+
+type clientKey struct {
+	timeout       time.Duration
+	tlsSkipVerify bool
+}
+
+type optionsKey struct{}
+
+// HealthCheckContext derives the context for a health check of service. It
+// carries the per-instance options, which MakeRequestWithContext and Do apply
+// to every request, and is cancelled once the configured timeout expires.
+// defaultTimeout is used when the instance does not configure one.
+func HealthCheckContext(ctx context.Context, service models.ServiceConfiguration, defaultTimeout time.Duration) (context.Context, context.CancelFunc) {
+	timeout := defaultTimeout
+	if service.Options.Timeout > 0 {
+		timeout = time.Duration(service.Options.Timeout) * time.Second
+	}
+	return context.WithTimeout(WithOptions(ctx, service.Options), timeout)
+}
+
+// WithOptions returns a copy of ctx carrying the per-instance options, which
+// MakeRequestWithContext and Do apply to every request made with it.
+func WithOptions(ctx context.Context, options models.ServiceOptions) context.Context {
+	return context.WithValue(ctx, optionsKey{}, options)
+}
+
+// optionsFromContext returns the per-instance options stored by WithOptions
+func optionsFromContext(ctx context.Context) models.ServiceOptions {
+	options, _ := ctx.Value(optionsKey{}).(models.ServiceOptions)
+	return options
+}
+
+// getHTTPClient returns a client with the specified timeout and TLS settings
+func getHTTPClient(timeout time.Duration, tlsSkipVerify bool) *http.Client {
+	key := clientKey{timeout: timeout, tlsSkipVerify: tlsSkipVerify}
+	if client, ok := httpClients.Load(key); ok {
 		return client.(*http.Client)
 	}
 
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+		DisableKeepAlives:   false,
+	}
+	if tlsSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
 	// Create new client if not found
 	client := &http.Client{
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     90 * time.Second,
-			DisableKeepAlives:   false,
-		},
-		Timeout: timeout,
+		Transport: tracing.Transport(transport),
+		Timeout:   timeout,
 	}
 
 	// Store in pool
-	httpClients.Store(timeout, client)
-	return client
+	actual, _ := httpClients.LoadOrStore(key, client)
+	return actual.(*http.Client)
 }
 
 func (s *ServiceCore) initCache() error {
@@ -109,11 +161,12 @@ func (s *ServiceCore) MakeRequestWithContext(ctx context.Context, url string, ap
 		return nil, ErrServiceNotConfigured
 	}
 
-	// Default timeout of 15 seconds if not specified in context
+	// Default timeout of 15 seconds if the context has no deadline of its own
 	timeout := 15 * time.Second
-	if deadline, ok := ctx.Deadline(); ok {
-		timeout = time.Until(deadline)
+	if _, ok := ctx.Deadline(); ok {
+		timeout = 0
 	}
+	options := optionsFromContext(ctx)
 
 	// Get method from headers if provided, default to GET
 	method := http.MethodGet
@@ -133,6 +186,11 @@ func (s *ServiceCore) MakeRequestWithContext(ctx context.Context, url string, ap
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Connection", "keep-alive")
 
+	// Per-instance headers, e.g. for an authenticating reverse proxy
+	for headerKey, headerValue := range options.Headers {
+		req.Header.Set(headerKey, headerValue)
+	}
+
 	if headers != nil {
 		// Handle auth header first if present
 		if authHeader, ok := headers["auth_header"]; ok {
@@ -152,7 +210,7 @@ func (s *ServiceCore) MakeRequestWithContext(ctx context.Context, url string, ap
 	start := time.Now()
 
 	// Get client with appropriate timeout
-	client := getHTTPClient(timeout)
+	client := getHTTPClient(timeout, options.TLSSkipVerify)
 	resp, err := client.Do(req)
 	if err != nil {
 		log.Error().Err(err).Str("url", url).Msg("Request failed")
@@ -178,6 +236,19 @@ func (s *ServiceCore) MakeRequestWithContext(ctx context.Context, url string, ap
 	return resp, nil
 }
 
+// Do sends a prepared request using the shared client pool, applying the
+// per-instance options carried by the request context. Services that build
+// their own requests use it instead of a dedicated http.Client.
+func Do(req *http.Request) (*http.Response, error) {
+	options := optionsFromContext(req.Context())
+	for headerKey, headerValue := range options.Headers {
+		if req.Header.Get(headerKey) == "" {
+			req.Header.Set(headerKey, headerValue)
+		}
+	}
+	return getHTTPClient(0, options.TLSSkipVerify).Do(req)
+}
+
 func (s *ServiceCore) MakeRequest(url string, apiKey string, headers map[string]string) (*http.Response, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
@@ -290,6 +361,12 @@ func (s *ServiceCore) CreateHealthResponse(lastChecked time.Time, status string,
 	return response
 }
 
+// CreateHealthError creates a health response for a check that could not be
+// performed, together with the error describing why
+func (s *ServiceCore) CreateHealthError(lastChecked time.Time, statusCode int, status string, message string) (models.ServiceHealth, error) {
+	return s.CreateHealthResponse(lastChecked, status, message), models.NewHealthCheckError(statusCode, message)
+}
+
 // GetCachedVersion attempts to get version from cache or fetches it if not found
 func (s *ServiceCore) GetCachedVersion(ctx context.Context, baseURL, apiKey string, fetchVersion func(string, string) (string, error)) (string, error) {
 	if err := s.initCache(); err != nil {
@@ -0,0 +1,355 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package deluge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/core"
+	"github.com/autobrr/dashbrr/internal/services/downloadclient"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+const clientType = "deluge"
+
+var (
+	// sessions holds the Web UI session cookie per instance
+	sessions sync.Map
+	// requestID numbers the JSON-RPC requests
+	requestID atomic.Int64
+)
+
+type DelugeService struct {
+	core.ServiceCore
+}
+
+type rpcRequest struct {
+	ID     int64         `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+// errNotAuthenticated is the JSON-RPC error code of an expired or missing session
+const errNotAuthenticated = 1
+
+type updateUIResponse struct {
+	Stats struct {
+		DownloadRate float64 `json:"download_rate"`
+		UploadRate   float64 `json:"upload_rate"`
+		FreeSpace    int64   `json:"free_space"`
+	} `json:"stats"`
+	Torrents map[string]struct {
+		State               string  `json:"state"`
+		DownloadPayloadRate float64 `json:"download_payload_rate"`
+		NumPeers            int     `json:"num_peers"`
+	} `json:"torrents"`
+}
+
+func init() {
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           clientType,
+		DisplayName:    "Deluge",
+		Description:    "Monitor transfers and torrents of your Deluge client",
+		DefaultURL:     "http://localhost:8112",
+		HealthEndpoint: "/json",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityStats},
+		CLI:            models.CLIMetadata{Credential: "password"},
+		New:            NewDelugeService,
+	})
+}
+
+func NewDelugeService() models.ServiceHealthChecker {
+	return &DelugeService{ServiceCore: core.NewServiceCore(clientType)}
+}
+
+func newError(op string, err error) error {
+	return &downloadclient.ErrDownloadClient{Client: clientType, Op: op, Err: err}
+}
+
+// post sends a JSON-RPC request to the Web UI and returns the raw result
+// together with the response cookies
+func (s *DelugeService) post(ctx context.Context, baseURL, cookie, method string, params ...interface{}) (json.RawMessage, []*http.Cookie, error) {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	payload, err := json.Marshal(rpcRequest{ID: requestID.Add(1), Method: method, Params: params})
+	if err != nil {
+		return nil, nil, newError(method, fmt.Errorf("failed to encode request: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, newError(method, fmt.Errorf("failed to create request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if cookie != "" {
+		req.Header.Set("Cookie", cookie)
+	}
+
+	resp, err := core.Do(req)
+	if err != nil {
+		return nil, nil, newError(method, fmt.Errorf("failed to make request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, &downloadclient.ErrDownloadClient{Client: clientType, Op: method, HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return nil, nil, newError(method, fmt.Errorf("failed to read response: %w", err))
+	}
+
+	var response rpcResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, nil, newError(method, fmt.Errorf("failed to parse response: %w", err))
+	}
+	if response.Error != nil {
+		if response.Error.Code == errNotAuthenticated {
+			return nil, nil, &downloadclient.ErrDownloadClient{Client: clientType, Op: method, HttpCode: http.StatusUnauthorized}
+		}
+		return nil, nil, newError(method, fmt.Errorf("%s", response.Error.Message))
+	}
+
+	return response.Result, resp.Cookies(), nil
+}
+
+// login authenticates against the Web UI, makes sure it is connected to a
+// daemon and returns the session cookie
+func (s *DelugeService) login(ctx context.Context, baseURL, password string) (string, error) {
+	sessionKey := baseURL + "|" + password
+	if cookie, ok := sessions.Load(sessionKey); ok {
+		return cookie.(string), nil
+	}
+
+	result, cookies, err := s.post(ctx, baseURL, "", "auth.login", password)
+	if err != nil {
+		return "", err
+	}
+
+	var ok bool
+	if err := json.Unmarshal(result, &ok); err != nil || !ok {
+		return "", &downloadclient.ErrDownloadClient{Client: clientType, Op: "auth.login", HttpCode: http.StatusUnauthorized}
+	}
+
+	var cookie string
+	for _, c := range cookies {
+		if c.Name == "_session_id" {
+			cookie = c.Name + "=" + c.Value
+		}
+	}
+	if cookie == "" {
+		return "", newError("auth.login", fmt.Errorf("no session cookie in login response"))
+	}
+
+	if err := s.connect(ctx, baseURL, cookie); err != nil {
+		return "", err
+	}
+
+	sessions.Store(sessionKey, cookie)
+	return cookie, nil
+}
+
+// connect connects the Web UI to the first known daemon unless it already is
+func (s *DelugeService) connect(ctx context.Context, baseURL, cookie string) error {
+	result, _, err := s.post(ctx, baseURL, cookie, "web.connected")
+	if err != nil {
+		return err
+	}
+
+	var connected bool
+	if err := json.Unmarshal(result, &connected); err == nil && connected {
+		return nil
+	}
+
+	result, _, err = s.post(ctx, baseURL, cookie, "web.get_hosts")
+	if err != nil {
+		return err
+	}
+
+	// Each host is a [id, host, port, status] tuple
+	var hosts [][]interface{}
+	if err := json.Unmarshal(result, &hosts); err != nil || len(hosts) == 0 || len(hosts[0]) == 0 {
+		return newError("web.connect", fmt.Errorf("web UI is not connected to a daemon and has no hosts configured"))
+	}
+
+	_, _, err = s.post(ctx, baseURL, cookie, "web.connect", hosts[0][0])
+	return err
+}
+
+// call runs an authenticated JSON-RPC method and decodes the result into v,
+// logging in again once if the session expired
+func (s *DelugeService) call(ctx context.Context, baseURL, apiKey string, v interface{}, method string, params ...interface{}) error {
+	baseURL = strings.TrimRight(baseURL, "/")
+	if baseURL == "" {
+		return newError(method, fmt.Errorf("URL is required"))
+	}
+
+	for attempt := 0; ; attempt++ {
+		cookie, err := s.login(ctx, baseURL, apiKey)
+		if err != nil {
+			return err
+		}
+
+		result, _, err := s.post(ctx, baseURL, cookie, method, params...)
+		if clientErr, ok := err.(*downloadclient.ErrDownloadClient); ok && clientErr.HttpCode == http.StatusUnauthorized && attempt == 0 {
+			sessions.Delete(baseURL + "|" + apiKey)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(result, v); err != nil {
+			return newError(method, fmt.Errorf("failed to parse result: %w", err))
+		}
+		return nil
+	}
+}
+
+// GetVersion returns the Deluge version, cached for an hour
+func (s *DelugeService) GetVersion(ctx context.Context, baseURL, apiKey string) (string, error) {
+	if version := s.GetVersionFromCache(baseURL); version != "" {
+		return version, nil
+	}
+
+	var version string
+	if err := s.call(ctx, baseURL, apiKey, &version, "daemon.info"); err != nil {
+		return "", err
+	}
+
+	if err := s.CacheVersion(baseURL, version, time.Hour); err != nil {
+		log.Warn().Err(err).Msg("Failed to cache Deluge version")
+	}
+
+	return version, nil
+}
+
+func (s *DelugeService) GetStats(ctx context.Context, baseURL, apiKey string) (*types.DownloadClientStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	version, err := s.GetVersion(ctx, baseURL, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var update updateUIResponse
+	fields := []string{"state", "download_payload_rate", "num_peers"}
+	if err := s.call(ctx, baseURL, apiKey, &update, "web.update_ui", fields, map[string]interface{}{}); err != nil {
+		return nil, err
+	}
+
+	stats := &types.DownloadClientStats{
+		ClientType:    clientType,
+		Version:       version,
+		DownloadSpeed: int64(update.Stats.DownloadRate),
+		UploadSpeed:   int64(update.Stats.UploadRate),
+		Total:         len(update.Torrents),
+		FreeSpace:     update.Stats.FreeSpace,
+	}
+
+	for _, torrent := range update.Torrents {
+		switch torrent.State {
+		case "Downloading":
+			// Deluge has no stalled state, a download without peers or progress is stalled
+			if torrent.NumPeers == 0 && torrent.DownloadPayloadRate == 0 {
+				stats.Stalled++
+				continue
+			}
+			stats.Downloading++
+			stats.Active++
+		case "Seeding":
+			stats.Seeding++
+			stats.Active++
+		case "Paused":
+			stats.Paused++
+		case "Error":
+			stats.Errored++
+		}
+	}
+
+	return stats, nil
+}
+
+// torrentAction calls method for the torrents with the given hashes. Without
+// hashes the ids of all torrents are looked up first.
+func (s *DelugeService) torrentAction(ctx context.Context, baseURL, apiKey, method string, hashes []string) error {
+	if len(hashes) == 0 {
+		var torrents map[string]interface{}
+		if err := s.call(ctx, baseURL, apiKey, &torrents, "core.get_torrents_status", map[string]interface{}{}, []string{"state"}); err != nil {
+			return err
+		}
+		for hash := range torrents {
+			hashes = append(hashes, hash)
+		}
+	}
+	return s.call(ctx, baseURL, apiKey, nil, method, hashes)
+}
+
+func (s *DelugeService) PauseTorrents(ctx context.Context, baseURL, apiKey string, hashes []string) error {
+	return s.torrentAction(ctx, baseURL, apiKey, "core.pause_torrents", hashes)
+}
+
+func (s *DelugeService) ResumeTorrents(ctx context.Context, baseURL, apiKey string, hashes []string) error {
+	return s.torrentAction(ctx, baseURL, apiKey, "core.resume_torrents", hashes)
+}
+
+func (s *DelugeService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
+	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
+
+	if url == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
+	}
+
+	if apiKey == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "Password is required")
+	}
+
+	ctx, cancel := core.HealthCheckContext(ctx, service, 10*time.Second)
+	defer cancel()
+
+	stats, err := s.GetStats(ctx, url, apiKey)
+	if err != nil {
+		if clientErr, ok := err.(*downloadclient.ErrDownloadClient); ok && clientErr.HttpCode > 0 {
+			message := fmt.Sprintf("Server returned %s (%d)", http.StatusText(clientErr.HttpCode), clientErr.HttpCode)
+			if clientErr.HttpCode == http.StatusUnauthorized {
+				message = "Invalid password"
+			}
+			return s.CreateHealthResponse(startTime, "error", message), nil
+		}
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
+	}
+
+	status, message, extras := downloadclient.HealthResponse(stats)
+	return s.CreateHealthResponse(startTime, status, message, extras), nil
+}
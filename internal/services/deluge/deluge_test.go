@@ -0,0 +1,83 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package deluge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStats_ConnectsToDaemon(t *testing.T) {
+	connected := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		if req.Method == "auth.login" {
+			if req.Params[0] != "deluge" {
+				w.Write([]byte(`{"id": 1, "result": false, "error": null}`))
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "_session_id", Value: "session"})
+			w.Write([]byte(`{"id": 1, "result": true, "error": null}`))
+			return
+		}
+
+		if cookie, err := r.Cookie("_session_id"); err != nil || cookie.Value != "session" {
+			w.Write([]byte(`{"id": 1, "result": null, "error": {"message": "Not authenticated", "code": 1}}`))
+			return
+		}
+
+		switch req.Method {
+		case "web.connected":
+			w.Write([]byte(`{"id": 1, "result": false, "error": null}`))
+		case "web.get_hosts":
+			w.Write([]byte(`{"id": 1, "result": [["host-id", "127.0.0.1", 58846, "Offline"]], "error": null}`))
+		case "web.connect":
+			assert.Equal(t, "host-id", req.Params[0])
+			connected = true
+			w.Write([]byte(`{"id": 1, "result": [], "error": null}`))
+		case "daemon.info":
+			w.Write([]byte(`{"id": 1, "result": "2.1.1", "error": null}`))
+		case "web.update_ui":
+			w.Write([]byte(`{"id": 1, "error": null, "result": {
+				"stats": {"download_rate": 4096.5, "upload_rate": 1024.0, "free_space": 9000},
+				"torrents": {
+					"a": {"state": "Downloading", "download_payload_rate": 4096.5, "num_peers": 3},
+					"b": {"state": "Downloading", "download_payload_rate": 0, "num_peers": 0},
+					"c": {"state": "Seeding"},
+					"d": {"state": "Paused"},
+					"e": {"state": "Error"}
+				}
+			}}`))
+		default:
+			w.Write([]byte(`{"id": 1, "result": null, "error": {"message": "Unknown method", "code": 2}}`))
+		}
+	}))
+	defer server.Close()
+
+	service := &DelugeService{}
+	stats, err := service.GetStats(context.Background(), server.URL, "deluge")
+	require.NoError(t, err)
+
+	assert.True(t, connected)
+	assert.Equal(t, "2.1.1", stats.Version)
+	assert.Equal(t, int64(4096), stats.DownloadSpeed)
+	assert.Equal(t, int64(9000), stats.FreeSpace)
+	assert.Equal(t, 5, stats.Total)
+	assert.Equal(t, 2, stats.Active)
+	assert.Equal(t, 1, stats.Stalled)
+	assert.Equal(t, 1, stats.Paused)
+	assert.Equal(t, 1, stats.Errored)
+
+	_, err = service.GetStats(context.Background(), server.URL, "wrong")
+	assert.Error(t, err)
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package digest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/secrets"
+	"github.com/autobrr/dashbrr/internal/services/lidarr"
+	"github.com/autobrr/dashbrr/internal/services/overseerr"
+	"github.com/autobrr/dashbrr/internal/services/radarr"
+	"github.com/autobrr/dashbrr/internal/services/readarr"
+	"github.com/autobrr/dashbrr/internal/services/sonarr"
+)
+
+// Period is the time span covered by a digest
+const Period = 24 * time.Hour
+
+// Store defines the database operations needed to build a digest
+type Store interface {
+	GetAllServices() ([]models.ServiceConfiguration, error)
+	GetHealthChecks(instanceID string, from, to time.Time) ([]models.HealthCheckRecord, error)
+}
+
+// Sources fetches live information from the configured services
+type Sources interface {
+	CheckForUpdates(service models.ServiceConfiguration) (bool, error)
+	PendingRequests(service models.ServiceConfiguration) (int, error)
+	StuckQueueItems(service models.ServiceConfiguration) ([]models.DigestQueueItem, error)
+}
+
+// Builder assembles digests from health history and live service data
+type Builder struct {
+	store   Store
+	sources Sources
+}
+
+// NewBuilder creates a digest builder that queries the services configured in db
+func NewBuilder(db *database.DB) *Builder {
+	return &Builder{
+		store:   db,
+		sources: &serviceSources{db: db},
+	}
+}
+
+// Build creates a digest for the period ending at the given time
+func (b *Builder) Build(to time.Time) (*models.Digest, error) {
+	services, err := b.store.GetAllServices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load services: %w", err)
+	}
+
+	from := to.Add(-Period)
+	digest := &models.Digest{
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now(),
+		Services:    make([]models.DigestService, 0, len(services)),
+	}
+
+	for _, service := range services {
+		if err := secrets.ResolveService(context.Background(), &service); err != nil {
+			log.Warn().Err(err).Str("instanceId", service.InstanceID).Msg("Failed to resolve API key for digest")
+		}
+		digest.Services = append(digest.Services, b.buildService(service, from, to))
+	}
+
+	return digest, nil
+}
+
+func (b *Builder) buildService(service models.ServiceConfiguration, from, to time.Time) models.DigestService {
+	entry := models.DigestService{
+		InstanceID:  service.InstanceID,
+		DisplayName: service.DisplayName,
+	}
+	if entry.DisplayName == "" {
+		entry.DisplayName = service.InstanceID
+	}
+
+	records, err := b.store.GetHealthChecks(service.InstanceID, from, to)
+	if err != nil {
+		log.Error().Err(err).Str("service", service.InstanceID).Msg("Failed to load health history for digest")
+		entry.Errors = append(entry.Errors, "Health history unavailable")
+	}
+
+	up := 0
+	for _, record := range records {
+		if models.IsUp(record.Status) {
+			up++
+		}
+	}
+	entry.Checks = len(records)
+	if entry.Checks > 0 {
+		entry.UptimePercent = float64(up) / float64(entry.Checks) * 100
+
+		latest := records[len(records)-1]
+		entry.Status = latest.Status
+		entry.Version = latest.Version
+		entry.UpdateAvailable = latest.UpdateAvailable
+	}
+
+	switch models.ServiceTypeFromInstanceID(service.InstanceID) {
+	case "sonarr", "radarr", "lidarr", "readarr":
+		if updateAvailable, err := b.sources.CheckForUpdates(service); err != nil {
+			entry.Errors = append(entry.Errors, fmt.Sprintf("Update check failed: %v", err))
+		} else {
+			entry.UpdateAvailable = updateAvailable
+		}
+
+		items, err := b.sources.StuckQueueItems(service)
+		if err != nil {
+			entry.Errors = append(entry.Errors, fmt.Sprintf("Queue check failed: %v", err))
+		}
+		entry.StuckItems = items
+	case "overseerr", "jellyseerr":
+		pending, err := b.sources.PendingRequests(service)
+		if err != nil {
+			entry.Errors = append(entry.Errors, fmt.Sprintf("Request check failed: %v", err))
+		}
+		entry.PendingRequests = pending
+	}
+
+	return entry
+}
+
+// serviceSources queries the real service APIs
+type serviceSources struct {
+	db *database.DB
+}
+
+func (s *serviceSources) CheckForUpdates(service models.ServiceConfiguration) (bool, error) {
+	switch models.ServiceTypeFromInstanceID(service.InstanceID) {
+	case "sonarr":
+		return (&sonarr.SonarrService{}).CheckForUpdates(context.Background(), service.URL, service.APIKey)
+	case "radarr":
+		return (&radarr.RadarrService{}).CheckForUpdates(context.Background(), service.URL, service.APIKey)
+	case "lidarr":
+		return (&lidarr.LidarrService{}).CheckForUpdates(context.Background(), service.URL, service.APIKey)
+	case "readarr":
+		return (&readarr.ReadarrService{}).CheckForUpdates(context.Background(), service.URL, service.APIKey)
+	}
+	return false, nil
+}
+
+func (s *serviceSources) PendingRequests(service models.ServiceConfiguration) (int, error) {
+	svc := overseerr.NewService(overseerr.Variant(models.ServiceTypeFromInstanceID(service.InstanceID)))
+	svc.SetDB(s.db)
+
+	stats, err := svc.GetRequests(service.URL, service.APIKey)
+	if err != nil {
+		return 0, err
+	}
+	return stats.PendingCount, nil
+}
+
+func (s *serviceSources) StuckQueueItems(service models.ServiceConfiguration) ([]models.DigestQueueItem, error) {
+	var items []models.DigestQueueItem
+
+	switch models.ServiceTypeFromInstanceID(service.InstanceID) {
+	case "sonarr":
+		records, err := (&sonarr.SonarrService{}).GetQueue(context.Background(), service.URL, service.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			var messages []string
+			for _, status := range record.StatusMessages {
+				messages = append(messages, status.Messages...)
+			}
+			if item, ok := stuckItem(record.Title, record.TrackedDownloadStatus, record.TrackedDownloadState, record.ErrorMessage, messages); ok {
+				items = append(items, item)
+			}
+		}
+	case "radarr":
+		records, err := (&radarr.RadarrService{}).GetQueue(context.Background(), service.URL, service.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			var messages []string
+			for _, status := range record.StatusMessages {
+				messages = append(messages, status.Messages...)
+			}
+			if item, ok := stuckItem(record.Title, record.TrackedDownloadStatus, record.TrackedDownloadState, record.ErrorMessage, messages); ok {
+				items = append(items, item)
+			}
+		}
+	case "lidarr":
+		queue, err := (&lidarr.LidarrService{}).GetQueue(context.Background(), service.URL, service.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range queue.Records {
+			var messages []string
+			for _, status := range record.StatusMessages {
+				messages = append(messages, status.Messages...)
+			}
+			if item, ok := stuckItem(record.Title, record.TrackedDownloadStatus, record.TrackedDownloadState, record.ErrorMessage, messages); ok {
+				items = append(items, item)
+			}
+		}
+	case "readarr":
+		queue, err := (&readarr.ReadarrService{}).GetQueue(context.Background(), service.URL, service.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range queue.Records {
+			title := record.Title
+			if record.AuthorName != "" && record.BookTitle != "" {
+				title = record.AuthorName + " - " + record.BookTitle
+			}
+
+			var messages []string
+			for _, status := range record.StatusMessages {
+				messages = append(messages, status.Messages...)
+			}
+			if item, ok := stuckItem(title, record.TrackedDownloadStatus, record.TrackedDownloadState, record.ErrorMessage, messages); ok {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// stuckItem reports whether a queue record needs manual attention, such as a
+// blocked import or a download client error
+func stuckItem(title, trackedStatus, trackedState, errorMessage string, messages []string) (models.DigestQueueItem, bool) {
+	stuck := trackedStatus == "warning" || trackedStatus == "error"
+	switch trackedState {
+	case "importBlocked", "failedPending":
+		stuck = true
+	}
+	if !stuck {
+		return models.DigestQueueItem{}, false
+	}
+
+	status := trackedState
+	if status == "" {
+		status = trackedStatus
+	}
+
+	message := errorMessage
+	if message == "" && len(messages) > 0 {
+		message = messages[0]
+	}
+
+	return models.DigestQueueItem{Title: title, Status: status, Message: message}, true
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package digest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+type mockStore struct {
+	services []models.ServiceConfiguration
+	checks   map[string][]models.HealthCheckRecord
+}
+
+func (m *mockStore) GetAllServices() ([]models.ServiceConfiguration, error) {
+	return m.services, nil
+}
+
+func (m *mockStore) GetHealthChecks(instanceID string, from, to time.Time) ([]models.HealthCheckRecord, error) {
+	return m.checks[instanceID], nil
+}
+
+type mockSources struct{}
+
+func (m *mockSources) CheckForUpdates(service models.ServiceConfiguration) (bool, error) {
+	if service.InstanceID == "sonarr-1" {
+		return false, fmt.Errorf("connection refused")
+	}
+	return true, nil
+}
+
+func (m *mockSources) PendingRequests(service models.ServiceConfiguration) (int, error) {
+	return 3, nil
+}
+
+func (m *mockSources) StuckQueueItems(service models.ServiceConfiguration) ([]models.DigestQueueItem, error) {
+	return []models.DigestQueueItem{{Title: "Movie.2024", Status: "importBlocked"}}, nil
+}
+
+func TestBuilder_Build(t *testing.T) {
+	now := time.Now()
+	store := &mockStore{
+		services: []models.ServiceConfiguration{
+			{InstanceID: "radarr-1", DisplayName: "Radarr"},
+			{InstanceID: "sonarr-1"},
+			{InstanceID: "overseerr-1", DisplayName: "Overseerr"},
+		},
+		checks: map[string][]models.HealthCheckRecord{
+			"radarr-1": {
+				{Status: "online", CheckedAt: now.Add(-3 * time.Hour)},
+				{Status: "offline", CheckedAt: now.Add(-2 * time.Hour)},
+				{Status: "warning", CheckedAt: now.Add(-1 * time.Hour), Version: "5.0.0"},
+				{Status: "online", CheckedAt: now, Version: "5.1.0"},
+			},
+			"sonarr-1": {
+				{Status: "online", CheckedAt: now, UpdateAvailable: true},
+			},
+		},
+	}
+
+	builder := &Builder{store: store, sources: &mockSources{}}
+	digest, err := builder.Build(now)
+	require.NoError(t, err)
+	require.Len(t, digest.Services, 3)
+
+	radarr := digest.Services[0]
+	assert.Equal(t, 75.0, radarr.UptimePercent)
+	assert.Equal(t, 4, radarr.Checks)
+	assert.Equal(t, "5.1.0", radarr.Version, "the latest check provides the version")
+	assert.True(t, radarr.UpdateAvailable)
+	assert.Len(t, radarr.StuckItems, 1)
+
+	sonarr := digest.Services[1]
+	assert.Equal(t, "sonarr-1", sonarr.DisplayName)
+	assert.True(t, sonarr.UpdateAvailable, "failed update checks fall back to the health history")
+	assert.Len(t, sonarr.Errors, 1)
+
+	overseerr := digest.Services[2]
+	assert.Equal(t, 3, overseerr.PendingRequests)
+	assert.Zero(t, overseerr.Checks)
+	assert.Empty(t, overseerr.StuckItems)
+
+	assert.Equal(t, 2, digest.UpdatesAvailable())
+	assert.Equal(t, 3, digest.PendingRequests())
+	assert.Equal(t, 2, digest.StuckItems())
+}
+
+func TestStuckItem(t *testing.T) {
+	_, stuck := stuckItem("Downloading", "ok", "downloading", "", nil)
+	assert.False(t, stuck)
+
+	item, stuck := stuckItem("Blocked", "warning", "importBlocked", "", []string{"No files found"})
+	assert.True(t, stuck)
+	assert.Equal(t, "importBlocked", item.Status)
+	assert.Equal(t, "No files found", item.Message)
+
+	item, stuck = stuckItem("Failed", "error", "", "Download client unavailable", nil)
+	assert.True(t, stuck)
+	assert.Equal(t, "error", item.Status)
+	assert.Equal(t, "Download client unavailable", item.Message)
+}
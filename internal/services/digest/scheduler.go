@@ -0,0 +1,147 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/database"
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/alerts"
+)
+
+const (
+	schedulerInterval = time.Minute
+	sendTimeout       = 2 * time.Minute
+)
+
+// NotifierStore defines the database operations needed by the scheduler
+type NotifierStore interface {
+	GetNotifiers() ([]models.NotifierConfig, error)
+}
+
+// Scheduler sends digests to email notifiers configured in digest mode
+type Scheduler struct {
+	builder   *Builder
+	notifiers NotifierStore
+
+	mu   sync.Mutex
+	next map[int64]time.Time
+}
+
+// NewScheduler creates a digest scheduler backed by the given database
+func NewScheduler(db *database.DB) *Scheduler {
+	return &Scheduler{
+		builder:   NewBuilder(db),
+		notifiers: db,
+		next:      make(map[int64]time.Time),
+	}
+}
+
+// Start checks every minute whether a digest is due
+func (s *Scheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+
+		s.tick(time.Now())
+		for now := range ticker.C {
+			s.tick(now)
+		}
+	}()
+}
+
+// tick sends every digest that is due. The first time a notifier is seen only
+// its next run is scheduled, so restarts do not send a second digest.
+func (s *Scheduler) tick(now time.Time) {
+	configs, err := s.notifiers.GetNotifiers()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load notifiers for digest")
+		return
+	}
+
+	var due []*alerts.EmailNotifier
+	var dueConfigs []models.NotifierConfig
+
+	s.mu.Lock()
+	seen := make(map[int64]bool, len(configs))
+	for _, config := range configs {
+		if !config.Enabled {
+			continue
+		}
+
+		notifier, err := alerts.NewDigestNotifier(config)
+		if errors.Is(err, alerts.ErrNotDigest) {
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Str("notifier", config.Name).Msg("Invalid digest notifier configuration")
+			continue
+		}
+		seen[config.ID] = true
+
+		next, ok := s.next[config.ID]
+		if !ok || next.After(notifier.NextDigest(now)) {
+			// New notifier or an earlier digest time was configured
+			s.next[config.ID] = notifier.NextDigest(now)
+			continue
+		}
+		if now.Before(next) {
+			continue
+		}
+
+		s.next[config.ID] = notifier.NextDigest(now)
+		due = append(due, notifier)
+		dueConfigs = append(dueConfigs, config)
+	}
+	for id := range s.next {
+		if !seen[id] {
+			delete(s.next, id)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(due) == 0 {
+		return
+	}
+
+	digest, err := s.builder.Build(now)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to build digest")
+		return
+	}
+
+	for i, notifier := range due {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		if err := notifier.SendDigest(ctx, digest); err != nil {
+			log.Error().Err(err).Str("notifier", dueConfigs[i].Name).Msg("Failed to send digest")
+		} else {
+			log.Info().Str("notifier", dueConfigs[i].Name).Int("services", len(digest.Services)).Msg("Digest sent")
+		}
+		cancel()
+	}
+}
+
+// SendNow builds a digest and sends it through the given notifier immediately
+func SendNow(ctx context.Context, db *database.DB, config models.NotifierConfig) error {
+	notifier, err := alerts.NewDigestNotifier(config)
+	if errors.Is(err, alerts.ErrNotDigest) {
+		return fmt.Errorf("notifier %s is not an email notifier in digest mode", config.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	digest, err := NewBuilder(db).Build(time.Now())
+	if err != nil {
+		return err
+	}
+	return notifier.SendDigest(ctx, digest)
+}
@@ -10,6 +10,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/secrets"
 )
 
 // ConfigFile represents the structure of the external configuration file
@@ -19,10 +20,11 @@ type ConfigFile struct {
 
 // ServiceConfig represents a service configuration in the external file
 type ServiceConfig struct {
-	URL         string            `json:"url" yaml:"url"`
-	APIKey      string            `json:"apikey" yaml:"apikey"`
-	DisplayName string            `json:"name,omitempty" yaml:"name,omitempty"`
-	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	URL         string                `json:"url" yaml:"url"`
+	APIKey      string                `json:"apikey" yaml:"apikey"`
+	DisplayName string                `json:"name,omitempty" yaml:"name,omitempty"`
+	Labels      map[string]string     `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Options     models.ServiceOptions `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 // ImportConfig imports service configurations from a file
@@ -77,6 +79,7 @@ func ImportConfig(path string) ([]models.ServiceConfiguration, error) {
 				DisplayName: displayName,
 				URL:         cfg.URL,
 				APIKey:      apiKey,
+				Options:     cfg.Options,
 			})
 		}
 	}
@@ -100,10 +103,11 @@ func ExportConfig(services []models.ServiceConfiguration, path string, maskSecre
 		config := ServiceConfig{
 			URL:         service.URL,
 			DisplayName: service.DisplayName,
+			Options:     service.Options,
 		}
 
-		// Handle API key masking
-		if maskSecrets {
+		// Handle API key masking, secret references reveal nothing and are kept
+		if maskSecrets && !secrets.IsReference(service.APIKey) {
 			config.APIKey = "${DASHBRR_" + strings.ToUpper(serviceType) + "_API_KEY}"
 		} else {
 			config.APIKey = service.APIKey
@@ -0,0 +1,113 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Package downloadclient holds what the torrent (qBittorrent, Transmission,
+// Deluge) and usenet (SABnzbd, NZBGet) client integrations have in common.
+package downloadclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+// Custom error types for better error handling
+type ErrDownloadClient struct {
+	Client   string // Client type, e.g. qbittorrent
+	Op       string // Operation that failed
+	Err      error  // Underlying error
+	HttpCode int    // HTTP status code if applicable
+}
+
+func (e *ErrDownloadClient) Error() string {
+	if e.HttpCode > 0 {
+		return fmt.Sprintf("%s %s: server returned %s (%d)", e.Client, e.Op, http.StatusText(e.HttpCode), e.HttpCode)
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("%s %s: %v", e.Client, e.Op, e.Err)
+	}
+	return fmt.Sprintf("%s %s", e.Client, e.Op)
+}
+
+func (e *ErrDownloadClient) Unwrap() error {
+	return e.Err
+}
+
+// Client is implemented by every download client service
+type Client interface {
+	GetStats(ctx context.Context, baseURL, apiKey string) (*types.DownloadClientStats, error)
+	// PauseTorrents pauses the torrents with the given hashes, or all torrents when hashes is empty
+	PauseTorrents(ctx context.Context, baseURL, apiKey string, hashes []string) error
+	// ResumeTorrents resumes the torrents with the given hashes, or all torrents when hashes is empty
+	ResumeTorrents(ctx context.Context, baseURL, apiKey string, hashes []string) error
+}
+
+// UsenetClient is implemented by every usenet client service
+type UsenetClient interface {
+	GetQueue(ctx context.Context, baseURL, apiKey string) (*types.UsenetQueue, error)
+	// GetFailedHistory returns up to limit of the most recent failed downloads
+	GetFailedHistory(ctx context.Context, baseURL, apiKey string, limit int) ([]types.UsenetHistoryItem, error)
+	GetWarnings(ctx context.Context, baseURL, apiKey string) ([]types.UsenetWarning, error)
+	PauseQueue(ctx context.Context, baseURL, apiKey string) error
+	ResumeQueue(ctx context.Context, baseURL, apiKey string) error
+	// RetryItem downloads a failed history item again
+	RetryItem(ctx context.Context, baseURL, apiKey, id string) error
+}
+
+// SplitCredentials splits credentials stored as username:password
+func SplitCredentials(apiKey string) (username, password string) {
+	username, password, _ = strings.Cut(apiKey, ":")
+	return username, password
+}
+
+// HealthResponse returns the status, message and extras of a health check
+// whose stats could be retrieved
+func HealthResponse(stats *types.DownloadClientStats) (string, string, map[string]interface{}) {
+	extras := map[string]interface{}{
+		"version": stats.Version,
+		"stats": map[string]interface{}{
+			stats.ClientType: stats,
+		},
+	}
+
+	if stats.Errored > 0 {
+		return "warning", fmt.Sprintf("%d torrent(s) in error state", stats.Errored), extras
+	}
+	return "online", "Healthy", extras
+}
+
+// maxHealthWarnings limits how many usenet client warnings a health message lists
+const maxHealthWarnings = 3
+
+// UsenetHealthResponse returns the status, message and extras of a health
+// check whose queue could be retrieved. The most recent warnings are listed.
+func UsenetHealthResponse(clientType, version string, queue *types.UsenetQueue, warnings []types.UsenetWarning) (string, string, map[string]interface{}) {
+	extras := map[string]interface{}{
+		"version": version,
+		"stats": map[string]interface{}{
+			clientType: map[string]interface{}{
+				"paused":   queue.Paused,
+				"speed":    queue.Speed,
+				"sizeLeft": queue.SizeLeft,
+				"items":    len(queue.Items),
+				"warnings": len(warnings),
+			},
+		},
+	}
+
+	if len(warnings) > 0 {
+		var messages []string
+		for i := len(warnings) - 1; i >= 0 && len(messages) < maxHealthWarnings; i-- {
+			messages = append(messages, warnings[i].Text)
+		}
+		return "warning", strings.Join(messages, "\n\n"), extras
+	}
+
+	if queue.Paused {
+		return "online", "Healthy - Queue paused", extras
+	}
+	return "online", "Healthy", extras
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package emby
+
+import (
+	"net/http"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/mediaserver"
+)
+
+type EmbyService struct {
+	mediaserver.Service
+}
+
+func init() {
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "emby",
+		DisplayName:    "Emby",
+		Description:    "Monitor streams and libraries of your Emby server",
+		DefaultURL:     "http://localhost:8096",
+		HealthEndpoint: "/emby/System/Info",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilitySessions, models.CapabilityStats, models.CapabilityUpdates},
+		New:            NewEmbyService,
+	})
+}
+
+func NewEmbyService() models.ServiceHealthChecker {
+	return &EmbyService{Service: mediaserver.NewService("emby", "/emby", authorize)}
+}
+
+func authorize(req *http.Request, apiKey string) {
+	req.Header.Set("X-Emby-Token", apiKey)
+}
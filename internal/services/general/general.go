@@ -9,22 +9,37 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/autobrr/dashbrr/internal/buildinfo"
 	"github.com/autobrr/dashbrr/internal/models"
 	"github.com/autobrr/dashbrr/internal/services/core"
 )
 
+// defaultCertExpiryDays is how many days before its expiry a TLS certificate
+// causes a warning
+const defaultCertExpiryDays = 14
+
+// maxBodySize limits how much of the response is read for the body rules
+const maxBodySize = 1 << 20
+
 func init() {
-	models.NewGeneralService = NewGeneralService
+	models.RegisterService(models.ServiceDescriptor{
+		Type:        "general",
+		DisplayName: "General",
+		Description: "Generic health check service for any URL endpoint",
+		Auth:        models.AuthOptional,
+		CLI:         models.CLIMetadata{NameArgument: true},
+		New:         NewGeneralService,
+	})
 }
 
 func NewGeneralService() models.ServiceHealthChecker {
-	service := &GeneralService{}
-	service.Type = "general"
+	service := &GeneralService{ServiceCore: core.NewServiceCore("general")}
 	service.DisplayName = "" // Allow display name to be set via configuration
-	service.Description = "Generic health check service for any URL endpoint"
 	return service
 }
 
@@ -32,16 +47,21 @@ type GeneralService struct {
 	core.ServiceCore
 }
 
-func (s *GeneralService) CheckHealth(url, apiKey string) (models.ServiceHealth, int) {
+func (s *GeneralService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
 	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
 
 	if url == "" {
-		return s.CreateHealthResponse(startTime, "error", "URL is required"), http.StatusBadRequest
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := core.HealthCheckContext(ctx, service, 10*time.Second)
 	defer cancel()
 
+	if service.Options.Check != nil {
+		return s.checkRules(ctx, startTime, service, *service.Options.Check)
+	}
+
 	headers := make(map[string]string)
 	if apiKey != "" {
 		headers["Authorization"] = fmt.Sprintf("Bearer %s", apiKey)
@@ -49,15 +69,15 @@ func (s *GeneralService) CheckHealth(url, apiKey string) (models.ServiceHealth,
 
 	resp, err := s.MakeRequestWithContext(ctx, url, apiKey, headers)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), http.StatusServiceUnavailable
+		return s.CreateHealthError(startTime, http.StatusServiceUnavailable, "offline", fmt.Sprintf("Failed to connect: %v", err))
 	}
 	defer resp.Body.Close()
 
 	responseTime, _ := time.ParseDuration(resp.Header.Get("X-Response-Time") + "ms")
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), http.StatusInternalServerError
+		return s.CreateHealthError(startTime, http.StatusInternalServerError, "error", fmt.Sprintf("Failed to read response: %v", err))
 	}
 
 	// Try to parse as JSON first
@@ -88,7 +108,7 @@ func (s *GeneralService) CheckHealth(url, apiKey string) (models.ServiceHealth,
 			"responseTime": responseTime.Milliseconds(),
 		}
 
-		return s.CreateHealthResponse(startTime, status, message, extras), resp.StatusCode
+		return s.CreateHealthResponse(startTime, status, message, extras), upstreamError(resp.StatusCode)
 	}
 
 	// If JSON parsing fails, treat as plain text
@@ -98,10 +118,211 @@ func (s *GeneralService) CheckHealth(url, apiKey string) (models.ServiceHealth,
 	}
 
 	if strings.EqualFold(textResponse, "ok") {
-		return s.CreateHealthResponse(startTime, "online", "", extras), resp.StatusCode
+		return s.CreateHealthResponse(startTime, "online", "", extras), upstreamError(resp.StatusCode)
+	}
+
+	return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Unexpected response: %s", textResponse), extras), upstreamError(resp.StatusCode)
+}
+
+// checkRules runs an advanced health check, in which the configured rules
+// alone decide the status
+func (s *GeneralService) checkRules(ctx context.Context, startTime time.Time, service models.ServiceConfiguration, check models.HTTPCheck) (models.ServiceHealth, error) {
+	method := http.MethodGet
+	if check.Method != "" {
+		method = strings.ToUpper(check.Method)
+	}
+
+	var requestBody io.Reader
+	if check.Body != "" {
+		requestBody = strings.NewReader(check.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, service.URL, requestBody)
+	if err != nil {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", fmt.Sprintf("Invalid request: %v", err))
+	}
+	buildinfo.AttachUserAgentHeader(req)
+	if service.APIKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", service.APIKey))
+	}
+	if check.Body != "" && !hasHeader(service.Options.Headers, "Content-Type") {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	requestStart := time.Now()
+	resp, err := core.Do(req)
+	if err != nil {
+		return s.CreateHealthError(startTime, http.StatusServiceUnavailable, "offline", fmt.Sprintf("Failed to connect: %v", err))
+	}
+	defer resp.Body.Close()
+	responseTime := time.Since(requestStart)
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return s.CreateHealthError(startTime, http.StatusInternalServerError, "error", fmt.Sprintf("Failed to read response: %v", err))
+	}
+
+	details := map[string]interface{}{
+		"statusCode": resp.StatusCode,
+	}
+	extras := map[string]interface{}{
+		"responseTime": responseTime.Milliseconds(),
+		"details":      details,
+	}
+
+	// A JSON body is decoded once for all JSONPath rules
+	var document interface{}
+	isJSON := json.Unmarshal(body, &document) == nil
+
+	version, err := extractVersion(check, body, document, isJSON)
+	if err != nil {
+		return s.CreateHealthResponse(startTime, "error", err.Error(), extras), nil
+	}
+	if version != "" {
+		extras["version"] = version
 	}
 
-	return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Unexpected response: %s", textResponse), extras), resp.StatusCode
+	if !expectedStatus(check.ExpectedStatus, resp.StatusCode) {
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Unexpected status code: %d", resp.StatusCode), extras), nil
+	}
+
+	if message, err := assertBody(check, body, document, isJSON); err != nil {
+		return s.CreateHealthResponse(startTime, "error", err.Error(), extras), nil
+	} else if message != "" {
+		return s.CreateHealthResponse(startTime, "error", message, extras), nil
+	}
+
+	if status, message := certificateStatus(check, resp, details); status != "online" {
+		return s.CreateHealthResponse(startTime, status, message, extras), nil
+	}
+
+	return s.CreateHealthResponse(startTime, "online", "", extras), nil
+}
+
+// expectedStatus reports whether a status code is accepted, any 2xx code by default
+func expectedStatus(accepted []int, statusCode int) bool {
+	if len(accepted) == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+	return slices.Contains(accepted, statusCode)
+}
+
+// assertBody checks the response body against the configured assertions. It
+// returns the message of the first failed assertion, or an error when an
+// assertion is misconfigured.
+func assertBody(check models.HTTPCheck, body []byte, document interface{}, isJSON bool) (string, error) {
+	text := string(body)
+
+	if check.Keyword != "" && !strings.Contains(text, check.Keyword) {
+		return fmt.Sprintf("Keyword %q not found", check.Keyword), nil
+	}
+	if check.AbsentKeyword != "" && strings.Contains(text, check.AbsentKeyword) {
+		return fmt.Sprintf("Keyword %q found", check.AbsentKeyword), nil
+	}
+
+	if check.Regex != "" {
+		re, err := regexp.Compile(check.Regex)
+		if err != nil {
+			return "", fmt.Errorf("invalid regex: %w", err)
+		}
+		if !re.Match(body) {
+			return fmt.Sprintf("Response does not match %s", check.Regex), nil
+		}
+	}
+
+	if check.JSONPath != "" {
+		if !isJSON {
+			return "Response is not JSON", nil
+		}
+		value, found, err := lookupJSONPath(document, check.JSONPath)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return fmt.Sprintf("%s not found", check.JSONPath), nil
+		}
+		if check.JSONValue == "" {
+			if !truthy(value) {
+				return fmt.Sprintf("%s is %s", check.JSONPath, formatJSONValue(value)), nil
+			}
+		} else if actual := formatJSONValue(value); actual != check.JSONValue {
+			return fmt.Sprintf("%s is %s, expected %s", check.JSONPath, actual, check.JSONValue), nil
+		}
+	}
+
+	return "", nil
+}
+
+// extractVersion applies the configured version rule to the response
+func extractVersion(check models.HTTPCheck, body []byte, document interface{}, isJSON bool) (string, error) {
+	if check.VersionJSONPath != "" && isJSON {
+		value, found, err := lookupJSONPath(document, check.VersionJSONPath)
+		if err != nil {
+			return "", err
+		}
+		if found && value != nil {
+			return formatJSONValue(value), nil
+		}
+	}
+
+	if check.VersionRegex != "" {
+		re, err := regexp.Compile(check.VersionRegex)
+		if err != nil {
+			return "", fmt.Errorf("invalid version regex: %w", err)
+		}
+		match := re.FindSubmatch(body)
+		switch {
+		case len(match) > 1:
+			return string(match[1]), nil
+		case len(match) == 1:
+			return string(match[0]), nil
+		}
+	}
+
+	return "", nil
+}
+
+// certificateStatus checks the expiry of the TLS certificate served by the
+// service and adds it to the details
+func certificateStatus(check models.HTTPCheck, resp *http.Response, details map[string]interface{}) (string, string) {
+	if check.CertExpiryDays < 0 || resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return "online", ""
+	}
+
+	expiry := resp.TLS.PeerCertificates[0].NotAfter
+	details["certificateExpiry"] = expiry
+
+	warnDays := defaultCertExpiryDays
+	if check.CertExpiryDays > 0 {
+		warnDays = check.CertExpiryDays
+	}
+
+	remaining := time.Until(expiry)
+	switch {
+	case remaining <= 0:
+		return "error", fmt.Sprintf("TLS certificate expired on %s", expiry.Format(time.DateOnly))
+	case remaining < time.Duration(warnDays)*24*time.Hour:
+		return "warning", fmt.Sprintf("TLS certificate expires in %d days", int(remaining.Hours()/24))
+	}
+	return "online", ""
+}
+
+// hasHeader reports whether a header is configured, ignoring its case
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// upstreamError passes a non-200 status code of the checked endpoint on to the caller
+func upstreamError(statusCode int) error {
+	if statusCode == http.StatusOK {
+		return nil
+	}
+	return models.NewHealthCheckError(statusCode, fmt.Sprintf("Unexpected status code: %d", statusCode))
 }
 
 func (s *GeneralService) GetVersion(url, apiKey string) (string, error) {
@@ -0,0 +1,237 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package general
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func TestCheckHealth_Rules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/post":
+			body, _ := io.ReadAll(r.Body)
+			if r.Method != http.MethodPost || r.Header.Get("X-Token") != "secret" || string(body) != `{"ping":true}` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"app":{"version":"2.4.1"},"checks":[{"name":"db","healthy":true},{"name":"queue","healthy":false}]}`))
+		case "/large":
+			_, _ = w.Write(bytes.Repeat([]byte("x"), maxBodySize))
+			_, _ = w.Write([]byte("end-marker"))
+		case "/html":
+			_, _ = w.Write([]byte(`<html><title>My App</title><footer>v1.9.0</footer></html>`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("maintenance"))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		path            string
+		check           models.HTTPCheck
+		headers         map[string]string
+		expectedStatus  string
+		expectedMessage string
+		expectedVersion string
+	}{
+		{
+			name:           "Method, headers and body",
+			path:           "/post",
+			check:          models.HTTPCheck{Method: "post", Body: `{"ping":true}`, ExpectedStatus: []int{202}},
+			headers:        map[string]string{"X-Token": "secret"},
+			expectedStatus: "online",
+		},
+		{
+			name:           "Any 2xx by default",
+			path:           "/html",
+			expectedStatus: "online",
+		},
+		{
+			name:            "Unexpected status",
+			path:            "/down",
+			expectedStatus:  "error",
+			expectedMessage: "Unexpected status code: 503",
+		},
+		{
+			name:           "Accepted error status",
+			path:           "/down",
+			check:          models.HTTPCheck{ExpectedStatus: []int{503}, Keyword: "maintenance"},
+			expectedStatus: "online",
+		},
+		{
+			name:            "JSONPath truthy",
+			path:            "/json",
+			check:           models.HTTPCheck{JSONPath: "$.checks[0].healthy", VersionJSONPath: "$.app.version"},
+			expectedStatus:  "online",
+			expectedVersion: "2.4.1",
+		},
+		{
+			name:            "JSONPath falsy",
+			path:            "/json",
+			check:           models.HTTPCheck{JSONPath: "$.checks[1].healthy"},
+			expectedStatus:  "error",
+			expectedMessage: "$.checks[1].healthy is false",
+		},
+		{
+			name:            "JSONPath value",
+			path:            "/json",
+			check:           models.HTTPCheck{JSONPath: "$['checks'][1].name", JSONValue: "db"},
+			expectedStatus:  "error",
+			expectedMessage: "$['checks'][1].name is queue, expected db",
+		},
+		{
+			name:            "JSONPath missing",
+			path:            "/json",
+			check:           models.HTTPCheck{JSONPath: "status"},
+			expectedStatus:  "error",
+			expectedMessage: "status not found",
+		},
+		{
+			name:            "Regex and version regex",
+			path:            "/html",
+			check:           models.HTTPCheck{Regex: `<title>My \w+</title>`, VersionRegex: `v(\d+\.\d+\.\d+)`},
+			expectedStatus:  "online",
+			expectedVersion: "1.9.0",
+		},
+		{
+			name:            "Keyword missing",
+			path:            "/html",
+			check:           models.HTTPCheck{Keyword: "Dashboard"},
+			expectedStatus:  "error",
+			expectedMessage: `Keyword "Dashboard" not found`,
+		},
+		{
+			name:            "Absent keyword found",
+			path:            "/html",
+			check:           models.HTTPCheck{AbsentKeyword: "My App"},
+			expectedStatus:  "error",
+			expectedMessage: `Keyword "My App" found`,
+		},
+		{
+			name:            "Invalid regex",
+			path:            "/html",
+			check:           models.HTTPCheck{Regex: "("},
+			expectedStatus:  "error",
+			expectedMessage: "invalid regex: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:            "Keyword beyond the read limit",
+			path:            "/large",
+			check:           models.HTTPCheck{Keyword: "end-marker"},
+			expectedStatus:  "error",
+			expectedMessage: `Keyword "end-marker" not found`,
+		},
+	}
+
+	service := NewGeneralService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := tt.check
+			health, err := service.CheckHealth(context.Background(), models.ServiceConfiguration{
+				InstanceID: "general-1",
+				URL:        server.URL + tt.path,
+				Options:    models.ServiceOptions{Headers: tt.headers, Check: &check},
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, health.Status)
+			assert.Equal(t, tt.expectedMessage, health.Message)
+			assert.Equal(t, tt.expectedVersion, health.Version)
+		})
+	}
+}
+
+func TestCheckHealth_CertificateExpiry(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	check := func(days int) models.ServiceHealth {
+		health, err := NewGeneralService().CheckHealth(context.Background(), models.ServiceConfiguration{
+			InstanceID: "general-1",
+			URL:        server.URL,
+			Options: models.ServiceOptions{
+				TLSSkipVerify: true,
+				Check:         &models.HTTPCheck{CertExpiryDays: days},
+			},
+		})
+		require.NoError(t, err)
+		return health
+	}
+
+	health := check(0)
+	assert.Equal(t, "online", health.Status)
+	assert.Contains(t, health.Details, "certificateExpiry")
+
+	// The test certificate is valid for decades, which is within a century
+	health = check(36500)
+	assert.Equal(t, "warning", health.Status)
+	assert.Contains(t, health.Message, "TLS certificate expires in")
+
+	health = check(-1)
+	assert.Equal(t, "online", health.Status)
+	assert.NotContains(t, health.Details, "certificateExpiry")
+}
+
+func TestCheckHealth_WithoutRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"warning","message":"Disk almost full"}`))
+	}))
+	defer server.Close()
+
+	health, err := NewGeneralService().CheckHealth(context.Background(), models.ServiceConfiguration{
+		InstanceID: "general-1",
+		URL:        server.URL,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "warning", health.Status)
+	assert.Equal(t, "Disk almost full", health.Message)
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	document := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b.c":  "dotted",
+			"list": []interface{}{1.0, "two"},
+		},
+	}
+
+	value, found, err := lookupJSONPath(document, "$.a.list[1]")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "two", value)
+
+	value, found, err = lookupJSONPath(document, "a['b.c']")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "dotted", value)
+
+	_, found, err = lookupJSONPath(document, "$.a.list[5]")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, _, err = lookupJSONPath(document, "$.a[*]")
+	assert.Error(t, err)
+
+	_, _, err = lookupJSONPath(document, "$..a")
+	assert.Error(t, err)
+}
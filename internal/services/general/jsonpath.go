@@ -0,0 +1,115 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package general
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lookupJSONPath returns the value selected by a JSONPath expression from a
+// decoded JSON document. Only the child subset of JSONPath is supported:
+// $.name, $['name'] and $.list[0], optionally without the leading $.
+func lookupJSONPath(document interface{}, path string) (interface{}, bool, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	value := document
+	for _, step := range steps {
+		switch current := value.(type) {
+		case map[string]interface{}:
+			child, ok := current[step]
+			if !ok {
+				return nil, false, nil
+			}
+			value = child
+		case []interface{}:
+			index, err := strconv.Atoi(step)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, false, nil
+			}
+			value = current[index]
+		default:
+			return nil, false, nil
+		}
+	}
+	return value, true, nil
+}
+
+// parseJSONPath splits a JSONPath expression into its keys and indexes
+func parseJSONPath(path string) ([]string, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		rest = "." + rest
+	}
+
+	var steps []string
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty key", path)
+			}
+			steps = append(steps, rest[:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid JSONPath %q: missing ]", path)
+			}
+			step := strings.TrimSpace(rest[1:end])
+			if unquoted, err := strconv.Unquote(strings.ReplaceAll(step, "'", `"`)); err == nil {
+				step = unquoted
+			} else if _, err := strconv.Atoi(step); err != nil {
+				return nil, fmt.Errorf("invalid JSONPath %q: unsupported selector [%s]", path, step)
+			}
+			steps = append(steps, step)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q", path)
+		}
+	}
+	return steps, nil
+}
+
+// formatJSONValue returns the text form of a decoded JSON value, used to
+// compare it with the configured expectation
+func formatJSONValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// truthy reports whether a decoded JSON value counts as a passed assertion
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	default:
+		return true
+	}
+}
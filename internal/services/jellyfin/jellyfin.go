@@ -0,0 +1,39 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package jellyfin
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/mediaserver"
+)
+
+type JellyfinService struct {
+	mediaserver.Service
+}
+
+func init() {
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "jellyfin",
+		DisplayName:    "Jellyfin",
+		Description:    "Monitor streams and libraries of your Jellyfin server",
+		DefaultURL:     "http://localhost:8096",
+		HealthEndpoint: "/System/Info",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilitySessions, models.CapabilityStats, models.CapabilityUpdates},
+		New:            NewJellyfinService,
+	})
+}
+
+func NewJellyfinService() models.ServiceHealthChecker {
+	return &JellyfinService{Service: mediaserver.NewService("jellyfin", "", authorize)}
+}
+
+// authorize uses the Authorization header, newer Jellyfin releases no longer
+// accept the legacy X-Emby-Token header by default
+func authorize(req *http.Request, apiKey string) {
+	req.Header.Set("Authorization", fmt.Sprintf("MediaBrowser Token=%q", apiKey))
+}
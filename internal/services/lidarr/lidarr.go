@@ -0,0 +1,480 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package lidarr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/core"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+// Custom error types for better error handling
+type ErrLidarr struct {
+	Op       string // Operation that failed
+	Err      error  // Underlying error
+	HttpCode int    // HTTP status code if applicable
+}
+
+func (e *ErrLidarr) Error() string {
+	if e.HttpCode > 0 {
+		return fmt.Sprintf("lidarr %s: server returned %s (%d)", e.Op, http.StatusText(e.HttpCode), e.HttpCode)
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("lidarr %s: %v", e.Op, e.Err)
+	}
+	return fmt.Sprintf("lidarr %s", e.Op)
+}
+
+func (e *ErrLidarr) Unwrap() error {
+	return e.Err
+}
+
+type LidarrService struct {
+	core.ServiceCore
+}
+
+type HealthResponse struct {
+	Source  string `json:"source"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	WikiURL string `json:"wikiUrl"`
+}
+
+type SystemStatusResponse struct {
+	Version string `json:"version"`
+}
+
+func init() {
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "lidarr",
+		DisplayName:    "Lidarr",
+		Description:    "Monitor and manage your Lidarr instance",
+		DefaultURL:     "http://localhost:8686",
+		HealthEndpoint: "/api/v1/health",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityQueue, models.CapabilityStats, models.CapabilityUpdates},
+		New:            NewLidarrService,
+	})
+}
+
+func NewLidarrService() models.ServiceHealthChecker {
+	return &LidarrService{ServiceCore: core.NewServiceCore("lidarr")}
+}
+
+func (s *LidarrService) GetHealthEndpoint(baseURL string) string {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return fmt.Sprintf("%s/api/v1/health", baseURL)
+}
+
+// makeRequest is a helper function to make requests with proper headers
+func (s *LidarrService) makeRequest(ctx context.Context, method, url, apiKey string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Api-Key", apiKey)
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Content-Type", "application/json")
+
+	return core.Do(req)
+}
+
+// DeleteQueueItem deletes a queue item with the specified options
+func (s *LidarrService) DeleteQueueItem(ctx context.Context, baseURL, apiKey string, queueId string, options types.LidarrQueueDeleteOptions) error {
+	if baseURL == "" {
+		return &ErrLidarr{Op: "delete_queue", Err: fmt.Errorf("URL is required")}
+	}
+
+	if apiKey == "" {
+		return &ErrLidarr{Op: "delete_queue", Err: fmt.Errorf("API key is required")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	deleteURL := fmt.Sprintf("%s/api/v1/queue/%s?removeFromClient=%t&blocklist=%t&skipRedownload=%t",
+		strings.TrimRight(baseURL, "/"),
+		queueId,
+		options.RemoveFromClient,
+		options.Blocklist,
+		options.SkipRedownload)
+
+	if options.ChangeCategory {
+		deleteURL += "&changeCategory=true"
+	}
+
+	log.Info().
+		Str("url", deleteURL).
+		Str("queueId", queueId).
+		Bool("removeFromClient", options.RemoveFromClient).
+		Bool("blocklist", options.Blocklist).
+		Bool("skipRedownload", options.SkipRedownload).
+		Bool("changeCategory", options.ChangeCategory).
+		Msg("Attempting to delete queue item")
+
+	resp, err := s.makeRequest(ctx, http.MethodDelete, deleteURL, apiKey, nil)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("url", deleteURL).
+			Str("queueId", queueId).
+			Msg("Failed to execute delete request")
+		return &ErrLidarr{Op: "delete_queue", Err: fmt.Errorf("failed to execute request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := s.ReadBody(resp)
+		log.Error().
+			Int("statusCode", resp.StatusCode).
+			Str("url", deleteURL).
+			Str("queueId", queueId).
+			Str("response", string(body)).
+			Msg("Delete request failed")
+
+		var errorResponse struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Message != "" {
+			return &ErrLidarr{Op: "delete_queue", Err: fmt.Errorf("%s", errorResponse.Message), HttpCode: resp.StatusCode}
+		}
+		return &ErrLidarr{Op: "delete_queue", HttpCode: resp.StatusCode}
+	}
+
+	log.Info().
+		Str("queueId", queueId).
+		Msg("Successfully deleted queue item")
+
+	return nil
+}
+
+// getQueuePage fetches a single page of the Lidarr queue
+func (s *LidarrService) getQueuePage(ctx context.Context, op, url, apiKey string, pageSize int) (*types.LidarrQueueResponse, error) {
+	if url == "" {
+		return nil, &ErrLidarr{Op: op, Err: fmt.Errorf("URL is required")}
+	}
+
+	if apiKey == "" {
+		return nil, &ErrLidarr{Op: op, Err: fmt.Errorf("API key is required")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	queueURL := fmt.Sprintf("%s/api/v1/queue?page=1&pageSize=%d&includeUnknownArtistItems=false&includeArtist=false&includeAlbum=false",
+		strings.TrimRight(url, "/"), pageSize)
+
+	resp, err := s.makeRequest(ctx, http.MethodGet, queueURL, apiKey, nil)
+	if err != nil {
+		return nil, &ErrLidarr{Op: op, Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ErrLidarr{Op: op, HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return nil, &ErrLidarr{Op: op, Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	var queue types.LidarrQueueResponse
+	if err := json.Unmarshal(body, &queue); err != nil {
+		return nil, &ErrLidarr{Op: op, Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	return &queue, nil
+}
+
+// GetQueueSize returns the total number of items in the Lidarr queue
+func (s *LidarrService) GetQueueSize(ctx context.Context, url, apiKey string) (int, error) {
+	queue, err := s.getQueuePage(ctx, "get_queue_size", url, apiKey, 1)
+	if err != nil {
+		return 0, err
+	}
+	return queue.TotalRecords, nil
+}
+
+// GetQueue fetches the current queue from Lidarr
+func (s *LidarrService) GetQueue(ctx context.Context, url, apiKey string) (*types.LidarrQueueResponse, error) {
+	return s.getQueuePage(ctx, "get_queue", url, apiKey, 10)
+}
+
+// GetStats summarizes the Lidarr library from the artist list
+func (s *LidarrService) GetStats(ctx context.Context, url, apiKey string) (*types.LidarrStatsResponse, error) {
+	if url == "" {
+		return nil, &ErrLidarr{Op: "get_stats", Err: fmt.Errorf("URL is required")}
+	}
+
+	if apiKey == "" {
+		return nil, &ErrLidarr{Op: "get_stats", Err: fmt.Errorf("API key is required")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	artistURL := fmt.Sprintf("%s/api/v1/artist", strings.TrimRight(url, "/"))
+
+	resp, err := s.makeRequest(ctx, http.MethodGet, artistURL, apiKey, nil)
+	if err != nil {
+		return nil, &ErrLidarr{Op: "get_stats", Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ErrLidarr{Op: "get_stats", HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return nil, &ErrLidarr{Op: "get_stats", Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	var artists []types.LidarrArtist
+	if err := json.Unmarshal(body, &artists); err != nil {
+		return nil, &ErrLidarr{Op: "get_stats", Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	stats := &types.LidarrStatsResponse{ArtistCount: len(artists)}
+	for _, artist := range artists {
+		if artist.Monitored {
+			stats.Monitored++
+		} else {
+			stats.Unmonitored++
+		}
+		stats.AlbumCount += artist.Statistics.AlbumCount
+		stats.TrackCount += artist.Statistics.TrackCount
+		stats.TrackFileCount += artist.Statistics.TrackFileCount
+		stats.SizeOnDisk += artist.Statistics.SizeOnDisk
+		if missing := artist.Statistics.TrackCount - artist.Statistics.TrackFileCount; missing > 0 {
+			stats.MissingCount += missing
+		}
+	}
+
+	return stats, nil
+}
+
+func (s *LidarrService) getSystemStatus(ctx context.Context, baseURL, apiKey string) (string, error) {
+	if baseURL == "" {
+		return "", &ErrLidarr{Op: "get_system_status", Err: fmt.Errorf("URL is required")}
+	}
+
+	// Check cache first
+	if version := s.GetVersionFromCache(baseURL); version != "" {
+		return version, nil
+	}
+
+	statusURL := fmt.Sprintf("%s/api/v1/system/status", strings.TrimRight(baseURL, "/"))
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	resp, err := s.makeRequest(ctx, http.MethodGet, statusURL, apiKey, nil)
+	if err != nil {
+		return "", &ErrLidarr{Op: "get_system_status", Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &ErrLidarr{Op: "get_system_status", HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return "", &ErrLidarr{Op: "get_system_status", Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	var status SystemStatusResponse
+	if err := json.Unmarshal(body, &status); err != nil {
+		return "", &ErrLidarr{Op: "get_system_status", Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	// Cache version for 1 hour
+	if err := s.CacheVersion(baseURL, status.Version, time.Hour); err != nil {
+		log.Warn().Err(err).Msg("Failed to cache Lidarr version")
+	}
+
+	return status.Version, nil
+}
+
+// CheckForUpdates checks if there are any updates available for Lidarr
+func (s *LidarrService) CheckForUpdates(ctx context.Context, baseURL, apiKey string) (bool, error) {
+	if baseURL == "" {
+		return false, &ErrLidarr{Op: "check_for_updates", Err: fmt.Errorf("URL is required")}
+	}
+
+	updateURL := fmt.Sprintf("%s/api/v1/update", strings.TrimRight(baseURL, "/"))
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	resp, err := s.makeRequest(ctx, http.MethodGet, updateURL, apiKey, nil)
+	if err != nil {
+		return false, &ErrLidarr{Op: "check_for_updates", Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, &ErrLidarr{Op: "check_for_updates", HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return false, &ErrLidarr{Op: "check_for_updates", Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	var updates []types.UpdateResponse
+	if err := json.Unmarshal(body, &updates); err != nil {
+		return false, &ErrLidarr{Op: "check_for_updates", Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	for _, update := range updates {
+		if !update.Installed && update.Installable {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *LidarrService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
+	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
+
+	if url == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
+	}
+
+	// Create a context with timeout for the entire health check
+	ctx, cancel := core.HealthCheckContext(ctx, service, 3*time.Second)
+	defer cancel()
+
+	// Start version check in background
+	versionChan := make(chan string, 1)
+	versionErrChan := make(chan error, 1)
+	go func() {
+		version, err := s.getSystemStatus(ctx, url, apiKey)
+		versionChan <- version
+		versionErrChan <- err
+	}()
+
+	// Start update check in background
+	updateChan := make(chan bool, 1)
+	updateErrChan := make(chan error, 1)
+	go func() {
+		hasUpdate, err := s.CheckForUpdates(ctx, url, apiKey)
+		updateChan <- hasUpdate
+		updateErrChan <- err
+	}()
+
+	// Perform health check
+	resp, err := s.makeRequest(ctx, http.MethodGet, s.GetHealthEndpoint(url), apiKey, nil)
+	if err != nil {
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
+	}
+	defer resp.Body.Close()
+
+	responseTime, _ := time.ParseDuration(resp.Header.Get("X-Response-Time") + "ms")
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), nil
+	}
+
+	if resp.StatusCode >= 400 {
+		statusText := http.StatusText(resp.StatusCode)
+		message := fmt.Sprintf("Server returned %s (%d)", statusText, resp.StatusCode)
+
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			message = fmt.Sprintf("Service is temporarily unavailable (%d %s)", resp.StatusCode, statusText)
+		case http.StatusUnauthorized:
+			message = "Invalid API key"
+		case http.StatusForbidden:
+			message = "Access forbidden"
+		case http.StatusNotFound:
+			message = "Service endpoint not found"
+		}
+
+		return s.CreateHealthResponse(startTime, "error", message), nil
+	}
+
+	var healthIssues []HealthResponse
+	if err := json.Unmarshal(body, &healthIssues); err != nil {
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to parse response: %v", err)), nil
+	}
+
+	// Wait for version with timeout
+	var version string
+	var versionErr error
+	select {
+	case version = <-versionChan:
+		versionErr = <-versionErrChan
+	case <-time.After(500 * time.Millisecond):
+		// Continue without version if it takes too long
+	}
+
+	// Wait for update check with timeout
+	var updateAvailable bool
+	var updateErr error
+	select {
+	case updateAvailable = <-updateChan:
+		updateErr = <-updateErrChan
+	case <-time.After(500 * time.Millisecond):
+		// Continue without update check if it takes too long
+	}
+
+	extras := map[string]interface{}{
+		"responseTime": responseTime.Milliseconds(),
+	}
+
+	if version != "" {
+		extras["version"] = version
+	}
+	if versionErr != nil {
+		extras["versionError"] = versionErr.Error()
+	}
+
+	if updateAvailable {
+		extras["updateAvailable"] = true
+	}
+	if updateErr != nil {
+		extras["updateError"] = updateErr.Error()
+	}
+
+	var allWarnings []string
+	for _, issue := range healthIssues {
+		if issue.Type != "warning" && issue.Type != "error" {
+			continue
+		}
+
+		warning := issue.Message
+		if issue.WikiURL != "" {
+			warning += fmt.Sprintf("\nWiki: %s", issue.WikiURL)
+		}
+		if issue.Source != "" &&
+			issue.Source != "IndexerLongTermStatusCheck" &&
+			issue.Source != "NotificationStatusCheck" {
+			warning = fmt.Sprintf("[%s] %s", issue.Source, warning)
+		}
+
+		allWarnings = append(allWarnings, warning)
+	}
+
+	if len(allWarnings) > 0 {
+		return s.CreateHealthResponse(startTime, "warning", strings.Join(allWarnings, "\n\n"), extras), nil
+	}
+
+	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), nil
+}
@@ -75,17 +75,20 @@ type Collection struct {
 }
 
 func init() {
-	models.NewMaintainerrService = NewMaintainerrService
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "maintainerr",
+		DisplayName:    "Maintainerr",
+		Description:    "Monitor and manage your Maintainerr instance",
+		DefaultURL:     "http://localhost:6246",
+		HealthEndpoint: "/api/app/status",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityCollections},
+		New:            NewMaintainerrService,
+	})
 }
 
 func NewMaintainerrService() models.ServiceHealthChecker {
-	service := &MaintainerrService{}
-	service.Type = "maintainerr"
-	service.DisplayName = "Maintainerr"
-	service.Description = "Monitor and manage your Maintainerr instance"
-	service.DefaultURL = "http://localhost:6246"
-	service.HealthEndpoint = "/api/app/status"
-	return service
+	return &MaintainerrService{ServiceCore: core.NewServiceCore("maintainerr")}
 }
 
 func (s *MaintainerrService) GetHealthEndpoint(baseURL string) string {
@@ -123,14 +126,16 @@ func (s *MaintainerrService) getVersion(ctx context.Context, url string) (string
 	return statusResponse.Version, nil
 }
 
-func (s *MaintainerrService) CheckHealth(url, apiKey string) (models.ServiceHealth, int) {
+func (s *MaintainerrService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
 	startTime := time.Now()
+	url := service.URL
 
 	if url == "" {
-		return s.CreateHealthResponse(startTime, "error", "URL is required"), http.StatusBadRequest
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
 	}
 
-	ctx := context.Background()
+	ctx, cancel := core.HealthCheckContext(ctx, service, 15*time.Second)
+	defer cancel()
 
 	versionChan := make(chan string, 1)
 	errChan := make(chan error, 1)
@@ -149,7 +154,7 @@ func (s *MaintainerrService) CheckHealth(url, apiKey string) (models.ServiceHeal
 	healthEndpoint := s.GetHealthEndpoint(url)
 	resp, err := s.MakeRequestWithContext(ctx, healthEndpoint, "", nil)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
 	}
 	defer resp.Body.Close()
 
@@ -157,7 +162,7 @@ func (s *MaintainerrService) CheckHealth(url, apiKey string) (models.ServiceHeal
 
 	body, err := s.ReadBody(resp)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), nil
 	}
 
 	if resp.StatusCode >= 400 {
@@ -177,12 +182,12 @@ func (s *MaintainerrService) CheckHealth(url, apiKey string) (models.ServiceHeal
 			message = "Service endpoint not found"
 		}
 
-		return s.CreateHealthResponse(startTime, status, message), http.StatusOK
+		return s.CreateHealthResponse(startTime, status, message), nil
 	}
 
 	var statusResponse StatusResponse
 	if err := json.Unmarshal(body, &statusResponse); err != nil {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to parse status response: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to parse status response: %v", err)), nil
 	}
 
 	var version string
@@ -204,7 +209,7 @@ func (s *MaintainerrService) CheckHealth(url, apiKey string) (models.ServiceHeal
 		extras["versionError"] = versionErr.Error()
 	}
 
-	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), http.StatusOK
+	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), nil
 }
 
 func (s *MaintainerrService) GetCollections(url, apiKey string) ([]Collection, error) {
@@ -0,0 +1,395 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Package mediaserver implements the API shared by Jellyfin and Emby, which
+// Jellyfin inherited when it forked from Emby. The jellyfin and emby packages
+// register the service types and only differ in how requests authenticate.
+package mediaserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/core"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+// ticksPerMillisecond converts the .NET ticks used for positions and runtimes
+const ticksPerMillisecond = 10000
+
+// Custom error types for better error handling
+type ErrMediaServer struct {
+	Server   string // Server type, jellyfin or emby
+	Op       string // Operation that failed
+	Err      error  // Underlying error
+	HttpCode int    // HTTP status code if applicable
+}
+
+func (e *ErrMediaServer) Error() string {
+	if e.HttpCode > 0 {
+		return fmt.Sprintf("%s %s: server returned %s (%d)", e.Server, e.Op, http.StatusText(e.HttpCode), e.HttpCode)
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("%s %s: %v", e.Server, e.Op, e.Err)
+	}
+	return fmt.Sprintf("%s %s", e.Server, e.Op)
+}
+
+func (e *ErrMediaServer) Unwrap() error {
+	return e.Err
+}
+
+// AuthFunc adds the API key to a request
+type AuthFunc func(req *http.Request, apiKey string)
+
+// Server is implemented by the Jellyfin and Emby services
+type Server interface {
+	GetSessions(ctx context.Context, baseURL, apiKey string) (*types.MediaSessionsResponse, error)
+	GetStats(ctx context.Context, baseURL, apiKey string) (*types.MediaServerStats, error)
+}
+
+type Service struct {
+	core.ServiceCore
+	pathPrefix string
+	authorize  AuthFunc
+}
+
+// NewService returns the shared implementation for serverType. pathPrefix is
+// prepended to every API path.
+func NewService(serverType, pathPrefix string, authorize AuthFunc) Service {
+	return Service{
+		ServiceCore: core.NewServiceCore(serverType),
+		pathPrefix:  pathPrefix,
+		authorize:   authorize,
+	}
+}
+
+type systemInfoResponse struct {
+	ServerName         string `json:"ServerName"`
+	Version            string `json:"Version"`
+	OperatingSystem    string `json:"OperatingSystem"`
+	HasPendingRestart  bool   `json:"HasPendingRestart"`
+	HasUpdateAvailable bool   `json:"HasUpdateAvailable"`
+}
+
+type sessionInfo struct {
+	ID                 string `json:"Id"`
+	UserName           string `json:"UserName"`
+	Client             string `json:"Client"`
+	DeviceName         string `json:"DeviceName"`
+	ApplicationVersion string `json:"ApplicationVersion"`
+	RemoteEndPoint     string `json:"RemoteEndPoint"`
+	NowPlayingItem     *struct {
+		Name              string `json:"Name"`
+		Type              string `json:"Type"`
+		SeriesName        string `json:"SeriesName"`
+		AlbumArtist       string `json:"AlbumArtist"`
+		ParentIndexNumber int    `json:"ParentIndexNumber"`
+		IndexNumber       int    `json:"IndexNumber"`
+		RunTimeTicks      int64  `json:"RunTimeTicks"`
+	} `json:"NowPlayingItem"`
+	PlayState struct {
+		PositionTicks int64  `json:"PositionTicks"`
+		IsPaused      bool   `json:"IsPaused"`
+		PlayMethod    string `json:"PlayMethod"`
+	} `json:"PlayState"`
+	TranscodingInfo *struct {
+		VideoCodec       string   `json:"VideoCodec"`
+		AudioCodec       string   `json:"AudioCodec"`
+		Bitrate          int      `json:"Bitrate"`
+		IsVideoDirect    bool     `json:"IsVideoDirect"`
+		IsAudioDirect    bool     `json:"IsAudioDirect"`
+		TranscodeReasons []string `json:"TranscodeReasons"`
+	} `json:"TranscodingInfo"`
+}
+
+type scheduledTask struct {
+	ID                        string  `json:"Id"`
+	Name                      string  `json:"Name"`
+	Category                  string  `json:"Category"`
+	State                     string  `json:"State"`
+	CurrentProgressPercentage float64 `json:"CurrentProgressPercentage"`
+	LastExecutionResult       *struct {
+		EndTimeUtc   time.Time `json:"EndTimeUtc"`
+		Status       string    `json:"Status"`
+		ErrorMessage string    `json:"ErrorMessage"`
+	} `json:"LastExecutionResult"`
+}
+
+// getJSON fetches an API path and decodes the response into v
+func (s *Service) getJSON(ctx context.Context, op, baseURL, apiKey, path string, v interface{}) error {
+	if baseURL == "" {
+		return &ErrMediaServer{Server: s.Type, Op: op, Err: fmt.Errorf("URL is required")}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+s.pathPrefix+path, nil)
+	if err != nil {
+		return &ErrMediaServer{Server: s.Type, Op: op, Err: fmt.Errorf("failed to create request: %w", err)}
+	}
+	req.Header.Set("Accept", "application/json")
+	if apiKey != "" && s.authorize != nil {
+		s.authorize(req, apiKey)
+	}
+
+	resp, err := core.Do(req)
+	if err != nil {
+		return &ErrMediaServer{Server: s.Type, Op: op, Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &ErrMediaServer{Server: s.Type, Op: op, HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return &ErrMediaServer{Server: s.Type, Op: op, Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return &ErrMediaServer{Server: s.Type, Op: op, Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	return nil
+}
+
+func (s *Service) getSystemInfo(ctx context.Context, baseURL, apiKey string) (*systemInfoResponse, error) {
+	var info systemInfoResponse
+	if err := s.getJSON(ctx, "get_system_info", baseURL, apiKey, "/System/Info", &info); err != nil {
+		return nil, err
+	}
+
+	if info.Version != "" {
+		if err := s.CacheVersion(baseURL, info.Version, time.Hour); err != nil {
+			log.Warn().Err(err).Str("type", s.Type).Msg("Failed to cache media server version")
+		}
+	}
+
+	return &info, nil
+}
+
+// GetSessions returns the sessions that are currently playing something
+func (s *Service) GetSessions(ctx context.Context, baseURL, apiKey string) (*types.MediaSessionsResponse, error) {
+	if apiKey == "" {
+		return nil, &ErrMediaServer{Server: s.Type, Op: "get_sessions", Err: fmt.Errorf("API key is required")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var sessions []sessionInfo
+	if err := s.getJSON(ctx, "get_sessions", baseURL, apiKey, "/Sessions?activeWithinSeconds=960", &sessions); err != nil {
+		return nil, err
+	}
+
+	response := &types.MediaSessionsResponse{
+		ServerType: s.Type,
+		Sessions:   []types.MediaSession{},
+	}
+	for _, session := range sessions {
+		// Idle clients are listed as sessions too, only streams are of interest
+		if session.NowPlayingItem == nil {
+			continue
+		}
+		response.Sessions = append(response.Sessions, normalizeSession(session))
+	}
+	response.Size = len(response.Sessions)
+
+	return response, nil
+}
+
+// normalizeSession converts a session into the shared session model
+func normalizeSession(session sessionInfo) types.MediaSession {
+	item := session.NowPlayingItem
+
+	normalized := types.MediaSession{
+		SessionKey:    session.ID,
+		User:          session.UserName,
+		Client:        session.Client,
+		Device:        session.DeviceName,
+		ClientVersion: session.ApplicationVersion,
+		RemoteAddress: session.RemoteEndPoint,
+		Type:          strings.ToLower(item.Type),
+		Title:         item.Name,
+		State:         "playing",
+		ViewOffset:    session.PlayState.PositionTicks / ticksPerMillisecond,
+		Duration:      item.RunTimeTicks / ticksPerMillisecond,
+		PlayMethod:    session.PlayState.PlayMethod,
+	}
+
+	switch normalized.Type {
+	case "episode":
+		normalized.GrandparentTitle = item.SeriesName
+		normalized.ParentIndex = item.ParentIndexNumber
+		normalized.Index = item.IndexNumber
+	case "audio":
+		normalized.Type = "track"
+		normalized.GrandparentTitle = item.AlbumArtist
+		normalized.Index = item.IndexNumber
+	}
+
+	if session.PlayState.IsPaused {
+		normalized.State = "paused"
+	}
+
+	if transcoding := session.TranscodingInfo; transcoding != nil {
+		normalized.VideoCodec = transcoding.VideoCodec
+		normalized.AudioCodec = transcoding.AudioCodec
+		normalized.Bitrate = transcoding.Bitrate
+		normalized.TranscodeReasons = transcoding.TranscodeReasons
+		normalized.Transcoding = normalized.PlayMethod == "Transcode" && !(transcoding.IsVideoDirect && transcoding.IsAudioDirect)
+	}
+
+	return normalized
+}
+
+// GetLibraryCounts returns the number of items per library type
+func (s *Service) GetLibraryCounts(ctx context.Context, baseURL, apiKey string) (types.MediaLibraryCounts, error) {
+	var counts types.MediaLibraryCounts
+	err := s.getJSON(ctx, "get_library_counts", baseURL, apiKey, "/Items/Counts", &counts)
+	return counts, err
+}
+
+// GetScheduledTasks returns the visible scheduled tasks and their last result
+func (s *Service) GetScheduledTasks(ctx context.Context, baseURL, apiKey string) ([]types.MediaScheduledTask, error) {
+	var tasks []scheduledTask
+	if err := s.getJSON(ctx, "get_scheduled_tasks", baseURL, apiKey, "/ScheduledTasks?isHidden=false", &tasks); err != nil {
+		return nil, err
+	}
+
+	result := make([]types.MediaScheduledTask, 0, len(tasks))
+	for _, task := range tasks {
+		converted := types.MediaScheduledTask{
+			ID:       task.ID,
+			Name:     task.Name,
+			Category: task.Category,
+			State:    task.State,
+			Progress: task.CurrentProgressPercentage,
+		}
+		if last := task.LastExecutionResult; last != nil {
+			converted.LastStatus = last.Status
+			converted.LastError = last.ErrorMessage
+			if !last.EndTimeUtc.IsZero() {
+				lastRun := last.EndTimeUtc
+				converted.LastRun = &lastRun
+			}
+		}
+		result = append(result, converted)
+	}
+
+	return result, nil
+}
+
+// GetStats collects server info, library counts and scheduled task status
+func (s *Service) GetStats(ctx context.Context, baseURL, apiKey string) (*types.MediaServerStats, error) {
+	if apiKey == "" {
+		return nil, &ErrMediaServer{Server: s.Type, Op: "get_stats", Err: fmt.Errorf("API key is required")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	info, err := s.getSystemInfo(ctx, baseURL, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.GetLibraryCounts(ctx, baseURL, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.GetScheduledTasks(ctx, baseURL, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &types.MediaServerStats{
+		ServerType: s.Type,
+		ServerName: info.ServerName,
+		Version:    info.Version,
+		Libraries:  counts,
+		Tasks:      tasks,
+	}
+	for _, task := range tasks {
+		if task.State == "Running" {
+			stats.RunningTasks++
+		}
+		if task.LastStatus == "Failed" {
+			stats.FailedTasks++
+		}
+	}
+
+	return stats, nil
+}
+
+func (s *Service) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
+	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
+
+	if url == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
+	}
+
+	if apiKey == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "API key is required")
+	}
+
+	ctx, cancel := core.HealthCheckContext(ctx, service, 10*time.Second)
+	defer cancel()
+
+	info, err := s.getSystemInfo(ctx, url, apiKey)
+	if err != nil {
+		if serverErr, ok := err.(*ErrMediaServer); ok && serverErr.HttpCode > 0 {
+			message := fmt.Sprintf("Server returned %s (%d)", http.StatusText(serverErr.HttpCode), serverErr.HttpCode)
+			if serverErr.HttpCode == http.StatusUnauthorized || serverErr.HttpCode == http.StatusForbidden {
+				message = "Invalid API key"
+			}
+			return s.CreateHealthResponse(startTime, "error", message), nil
+		}
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
+	}
+
+	extras := map[string]interface{}{
+		"version":         info.Version,
+		"updateAvailable": info.HasUpdateAvailable,
+		"responseTime":    time.Since(startTime).Milliseconds(),
+	}
+
+	var warnings []string
+	if info.HasPendingRestart {
+		warnings = append(warnings, "Server restart is pending")
+	}
+
+	// Scheduled tasks are informational, a failure to list them does not affect health
+	if tasks, err := s.GetScheduledTasks(ctx, url, apiKey); err == nil {
+		for _, task := range tasks {
+			if task.LastStatus != "Failed" {
+				continue
+			}
+			if task.LastError != "" {
+				warnings = append(warnings, fmt.Sprintf("Scheduled task %s failed: %s", task.Name, task.LastError))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("Scheduled task %s failed", task.Name))
+			}
+		}
+	}
+
+	if len(warnings) > 0 {
+		return s.CreateHealthResponse(startTime, "warning", strings.Join(warnings, "\n\n"), extras), nil
+	}
+
+	message := "Healthy"
+	if info.OperatingSystem != "" {
+		message = fmt.Sprintf("Healthy - Running on %s", info.OperatingSystem)
+	}
+
+	return s.CreateHealthResponse(startTime, "online", message, extras), nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package mediaserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func testService() Service {
+	return NewService("jellyfin", "", func(req *http.Request, apiKey string) {
+		req.Header.Set("X-Test-Token", apiKey)
+	})
+}
+
+func TestGetSessions_NormalizesActiveStreams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("X-Test-Token"))
+		assert.Equal(t, "/Sessions", r.URL.Path)
+
+		w.Write([]byte(`[
+			{"Id": "idle", "UserName": "bob", "Client": "Jellyfin Web"},
+			{"Id": "s1", "UserName": "alice", "Client": "Jellyfin Android", "DeviceName": "Pixel",
+			 "NowPlayingItem": {"Name": "Pilot", "Type": "Episode", "SeriesName": "Severance",
+			                    "ParentIndexNumber": 1, "IndexNumber": 1, "RunTimeTicks": 34800000000},
+			 "PlayState": {"PositionTicks": 6000000000, "IsPaused": true, "PlayMethod": "Transcode"},
+			 "TranscodingInfo": {"VideoCodec": "h264", "AudioCodec": "aac", "Bitrate": 8000000,
+			                     "TranscodeReasons": ["VideoCodecNotSupported"]}},
+			{"Id": "s2", "UserName": "carol", "Client": "Infuse",
+			 "NowPlayingItem": {"Name": "Dune", "Type": "Movie", "RunTimeTicks": 93600000000},
+			 "PlayState": {"PositionTicks": 0, "PlayMethod": "DirectPlay"}}
+		]`))
+	}))
+	defer server.Close()
+
+	service := testService()
+	sessions, err := service.GetSessions(context.Background(), server.URL, "test-key")
+	require.NoError(t, err)
+	require.Equal(t, 2, sessions.Size)
+	assert.Equal(t, "jellyfin", sessions.ServerType)
+
+	episode := sessions.Sessions[0]
+	assert.Equal(t, "alice", episode.User)
+	assert.Equal(t, "episode", episode.Type)
+	assert.Equal(t, "Severance", episode.GrandparentTitle)
+	assert.Equal(t, "paused", episode.State)
+	assert.Equal(t, int64(600000), episode.ViewOffset)
+	assert.Equal(t, int64(3480000), episode.Duration)
+	assert.True(t, episode.Transcoding)
+	assert.Equal(t, []string{"VideoCodecNotSupported"}, episode.TranscodeReasons)
+
+	movie := sessions.Sessions[1]
+	assert.Equal(t, "playing", movie.State)
+	assert.Equal(t, "DirectPlay", movie.PlayMethod)
+	assert.False(t, movie.Transcoding)
+}
+
+func TestCheckHealth(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiKey  string
+		tasks   string
+		status  string
+		message string
+	}{
+		{
+			name:    "Healthy",
+			apiKey:  "test-key",
+			tasks:   `[{"Name": "Scan Media Library", "State": "Idle", "LastExecutionResult": {"Status": "Completed"}}]`,
+			status:  "online",
+			message: "Healthy - Running on Linux",
+		},
+		{
+			name:    "Failed scheduled task",
+			apiKey:  "test-key",
+			tasks:   `[{"Name": "Extract Chapter Images", "State": "Idle", "LastExecutionResult": {"Status": "Failed", "ErrorMessage": "ffmpeg exited"}}]`,
+			status:  "warning",
+			message: "Scheduled task Extract Chapter Images failed: ffmpeg exited",
+		},
+		{
+			name:    "Invalid API key",
+			apiKey:  "wrong-key",
+			tasks:   `[]`,
+			status:  "error",
+			message: "Invalid API key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Test-Token") != "test-key" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				switch r.URL.Path {
+				case "/System/Info":
+					w.Write([]byte(`{"ServerName": "media", "Version": "10.10.3", "OperatingSystem": "Linux"}`))
+				case "/ScheduledTasks":
+					w.Write([]byte(tt.tasks))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			service := testService()
+			health, err := service.CheckHealth(context.Background(), models.ServiceConfiguration{URL: server.URL, APIKey: tt.apiKey})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, health.Status)
+			assert.Equal(t, tt.message, health.Message)
+		})
+	}
+}
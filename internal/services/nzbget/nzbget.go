@@ -0,0 +1,352 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package nzbget
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/core"
+	"github.com/autobrr/dashbrr/internal/services/downloadclient"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+const (
+	clientType = "nzbget"
+	mb         = 1024 * 1024
+	// logEntries is how many log entries are searched for warnings
+	logEntries = 100
+)
+
+type NzbgetService struct {
+	core.ServiceCore
+}
+
+type rpcRequest struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Name    string `json:"name"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type statusResponse struct {
+	DownloadRate    int64 `json:"DownloadRate"`
+	RemainingSizeMB int64 `json:"RemainingSizeMB"`
+	DownloadPaused  bool  `json:"DownloadPaused"`
+}
+
+type group struct {
+	NZBID           int    `json:"NZBID"`
+	NZBName         string `json:"NZBName"`
+	Category        string `json:"Category"`
+	Status          string `json:"Status"`
+	FileSizeMB      int64  `json:"FileSizeMB"`
+	RemainingSizeMB int64  `json:"RemainingSizeMB"`
+}
+
+type historyItem struct {
+	NZBID       int    `json:"NZBID"`
+	Name        string `json:"Name"`
+	Category    string `json:"Category"`
+	Status      string `json:"Status"`
+	FileSizeMB  int64  `json:"FileSizeMB"`
+	HistoryTime int64  `json:"HistoryTime"`
+}
+
+type logEntry struct {
+	Kind string `json:"Kind"`
+	Time int64  `json:"Time"`
+	Text string `json:"Text"`
+}
+
+func init() {
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           clientType,
+		DisplayName:    "NZBGet",
+		Description:    "Monitor the download queue of your NZBGet client",
+		DefaultURL:     "http://localhost:6789",
+		HealthEndpoint: "/jsonrpc",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityQueue},
+		CLI:            models.CLIMetadata{Credential: "username:password"},
+		New:            NewNzbgetService,
+	})
+}
+
+func NewNzbgetService() models.ServiceHealthChecker {
+	return &NzbgetService{ServiceCore: core.NewServiceCore(clientType)}
+}
+
+// call runs a JSON-RPC method and decodes its result into v. The control
+// username and password are sent with basic authentication.
+func (s *NzbgetService) call(ctx context.Context, baseURL, apiKey string, v interface{}, method string, params ...interface{}) error {
+	if baseURL == "" {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("URL is required")}
+	}
+
+	if params == nil {
+		params = []interface{}{}
+	}
+	payload, err := json.Marshal(rpcRequest{Method: method, Params: params})
+	if err != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("failed to encode request: %w", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(baseURL, "/")+"/jsonrpc", bytes.NewReader(payload))
+	if err != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("failed to create request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.SetBasicAuth(downloadclient.SplitCredentials(apiKey))
+	}
+
+	resp, err := core.Do(req)
+	if err != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	var response rpcResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+	if response.Error != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("%s", response.Error.Message)}
+	}
+
+	if v == nil {
+		return nil
+	}
+	if err := json.Unmarshal(response.Result, v); err != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("failed to parse result: %w", err)}
+	}
+	return nil
+}
+
+// GetVersion returns the NZBGet version, cached for an hour
+func (s *NzbgetService) GetVersion(ctx context.Context, baseURL, apiKey string) (string, error) {
+	if version := s.GetVersionFromCache(baseURL); version != "" {
+		return version, nil
+	}
+
+	var version string
+	if err := s.call(ctx, baseURL, apiKey, &version, "version"); err != nil {
+		return "", err
+	}
+
+	if err := s.CacheVersion(baseURL, version, time.Hour); err != nil {
+		log.Warn().Err(err).Msg("Failed to cache NZBGet version")
+	}
+
+	return version, nil
+}
+
+// timeLeft estimates the seconds needed to download size bytes at rate bytes per second
+func timeLeft(size, rate int64) int64 {
+	if rate <= 0 {
+		return 0
+	}
+	return size / rate
+}
+
+func (s *NzbgetService) GetQueue(ctx context.Context, baseURL, apiKey string) (*types.UsenetQueue, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var status statusResponse
+	if err := s.call(ctx, baseURL, apiKey, &status, "status"); err != nil {
+		return nil, err
+	}
+
+	var groups []group
+	if err := s.call(ctx, baseURL, apiKey, &groups, "listgroups", 0); err != nil {
+		return nil, err
+	}
+
+	queue := &types.UsenetQueue{
+		ClientType: clientType,
+		Paused:     status.DownloadPaused,
+		Speed:      status.DownloadRate,
+		SizeLeft:   status.RemainingSizeMB * mb,
+		Items:      make([]types.UsenetQueueItem, 0, len(groups)),
+	}
+	if !queue.Paused {
+		queue.TimeLeft = timeLeft(queue.SizeLeft, queue.Speed)
+	}
+
+	// Items are downloaded in order, like SABnzbd the time left of an item
+	// includes the items ahead of it
+	var sizeAhead int64
+	for _, g := range groups {
+		item := types.UsenetQueueItem{
+			ID:       strconv.Itoa(g.NZBID),
+			Name:     g.NZBName,
+			Category: g.Category,
+			Status:   g.Status,
+			Size:     g.FileSizeMB * mb,
+			SizeLeft: g.RemainingSizeMB * mb,
+			Paused:   g.Status == "PAUSED",
+		}
+		if g.FileSizeMB > 0 {
+			item.Percentage = int((g.FileSizeMB - g.RemainingSizeMB) * 100 / g.FileSizeMB)
+		}
+		if !queue.Paused && !item.Paused {
+			sizeAhead += item.SizeLeft
+			item.TimeLeft = timeLeft(sizeAhead, queue.Speed)
+		}
+		queue.Items = append(queue.Items, item)
+	}
+
+	return queue, nil
+}
+
+func (s *NzbgetService) GetFailedHistory(ctx context.Context, baseURL, apiKey string, limit int) ([]types.UsenetHistoryItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var items []historyItem
+	if err := s.call(ctx, baseURL, apiKey, &items, "history", false); err != nil {
+		return nil, err
+	}
+
+	// History is sorted newest first, statuses look like FAILURE/PAR
+	history := []types.UsenetHistoryItem{}
+	for _, item := range items {
+		if !strings.HasPrefix(item.Status, "FAILURE") {
+			continue
+		}
+		history = append(history, types.UsenetHistoryItem{
+			ID:          strconv.Itoa(item.NZBID),
+			Name:        item.Name,
+			Category:    item.Category,
+			Status:      item.Status,
+			FailMessage: strings.TrimPrefix(item.Status, "FAILURE/"),
+			Size:        item.FileSizeMB * mb,
+			CompletedAt: time.Unix(item.HistoryTime, 0).UTC(),
+		})
+		if limit > 0 && len(history) >= limit {
+			break
+		}
+	}
+
+	return history, nil
+}
+
+// GetWarnings returns the warnings and errors among the most recent log entries
+func (s *NzbgetService) GetWarnings(ctx context.Context, baseURL, apiKey string) ([]types.UsenetWarning, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var entries []logEntry
+	if err := s.call(ctx, baseURL, apiKey, &entries, "log", 0, logEntries); err != nil {
+		return nil, err
+	}
+
+	warnings := []types.UsenetWarning{}
+	for _, entry := range entries {
+		if entry.Kind != "WARNING" && entry.Kind != "ERROR" {
+			continue
+		}
+		warnings = append(warnings, types.UsenetWarning{
+			Type: entry.Kind,
+			Text: entry.Text,
+			Time: time.Unix(entry.Time, 0).UTC(),
+		})
+	}
+
+	return warnings, nil
+}
+
+// boolCall runs a method that reports success as a boolean result
+func (s *NzbgetService) boolCall(ctx context.Context, baseURL, apiKey, method string, params ...interface{}) error {
+	var ok bool
+	if err := s.call(ctx, baseURL, apiKey, &ok, method, params...); err != nil {
+		return err
+	}
+	if !ok {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("request was rejected")}
+	}
+	return nil
+}
+
+func (s *NzbgetService) PauseQueue(ctx context.Context, baseURL, apiKey string) error {
+	return s.boolCall(ctx, baseURL, apiKey, "pausedownload")
+}
+
+func (s *NzbgetService) ResumeQueue(ctx context.Context, baseURL, apiKey string) error {
+	return s.boolCall(ctx, baseURL, apiKey, "resumedownload")
+}
+
+func (s *NzbgetService) RetryItem(ctx context.Context, baseURL, apiKey, id string) error {
+	nzbID, err := strconv.Atoi(id)
+	if err != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: "retry", Err: fmt.Errorf("invalid item id %q", id)}
+	}
+	return s.boolCall(ctx, baseURL, apiKey, "editqueue", "HistoryRedownload", "", []int{nzbID})
+}
+
+func (s *NzbgetService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
+	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
+
+	if url == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
+	}
+
+	ctx, cancel := core.HealthCheckContext(ctx, service, 10*time.Second)
+	defer cancel()
+
+	version, err := s.GetVersion(ctx, url, apiKey)
+	if err != nil {
+		return s.errorResponse(startTime, err), nil
+	}
+
+	queue, err := s.GetQueue(ctx, url, apiKey)
+	if err != nil {
+		return s.errorResponse(startTime, err), nil
+	}
+
+	// Warnings are informational, a failure to list them does not affect health
+	warnings, _ := s.GetWarnings(ctx, url, apiKey)
+
+	status, message, extras := downloadclient.UsenetHealthResponse(clientType, version, queue, warnings)
+	return s.CreateHealthResponse(startTime, status, message, extras), nil
+}
+
+// errorResponse converts a failed request into a health response
+func (s *NzbgetService) errorResponse(startTime time.Time, err error) models.ServiceHealth {
+	if clientErr, ok := err.(*downloadclient.ErrDownloadClient); ok && clientErr.HttpCode > 0 {
+		message := fmt.Sprintf("Server returned %s (%d)", http.StatusText(clientErr.HttpCode), clientErr.HttpCode)
+		if clientErr.HttpCode == http.StatusUnauthorized || clientErr.HttpCode == http.StatusForbidden {
+			message = "Invalid username or password"
+		}
+		return s.CreateHealthResponse(startTime, "error", message)
+	}
+	return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err))
+}
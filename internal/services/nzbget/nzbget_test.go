@@ -0,0 +1,104 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package nzbget
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, results map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/jsonrpc", r.URL.Path)
+
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "nzbget" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		result, ok := results[req.Method]
+		if !ok {
+			w.Write([]byte(`{"error": {"name": "JsonRpcError", "message": "Invalid procedure"}}`))
+			return
+		}
+		w.Write([]byte(`{"result": ` + result + `}`))
+	}))
+}
+
+func TestGetQueue(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"status": `{"DownloadRate": 1048576, "RemainingSizeMB": 30, "DownloadPaused": false}`,
+		"listgroups": `[
+			{"NZBID": 1, "NZBName": "First", "Category": "tv", "Status": "DOWNLOADING", "FileSizeMB": 20, "RemainingSizeMB": 10},
+			{"NZBID": 2, "NZBName": "Second", "Category": "movies", "Status": "QUEUED", "FileSizeMB": 20, "RemainingSizeMB": 20}
+		]`,
+	})
+	defer server.Close()
+
+	queue, err := (&NzbgetService{}).GetQueue(context.Background(), server.URL, "nzbget:secret")
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1048576), queue.Speed)
+	assert.Equal(t, int64(30), queue.TimeLeft)
+	require.Len(t, queue.Items, 2)
+	assert.Equal(t, "1", queue.Items[0].ID)
+	assert.Equal(t, 50, queue.Items[0].Percentage)
+	assert.Equal(t, int64(10), queue.Items[0].TimeLeft)
+	// The second item waits for the first one
+	assert.Equal(t, int64(30), queue.Items[1].TimeLeft)
+}
+
+func TestGetQueue_Unauthorized(t *testing.T) {
+	server := newTestServer(t, nil)
+	defer server.Close()
+
+	_, err := (&NzbgetService{}).GetQueue(context.Background(), server.URL, "nzbget:wrong")
+	assert.Error(t, err)
+}
+
+func TestGetFailedHistory(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"history": `[
+			{"NZBID": 3, "Name": "Broken", "Category": "tv", "Status": "FAILURE/PAR", "FileSizeMB": 5, "HistoryTime": 1700000000},
+			{"NZBID": 2, "Name": "Fine", "Category": "tv", "Status": "SUCCESS/ALL", "FileSizeMB": 5, "HistoryTime": 1690000000},
+			{"NZBID": 1, "Name": "Also broken", "Category": "movies", "Status": "FAILURE/UNPACK", "FileSizeMB": 5, "HistoryTime": 1680000000}
+		]`,
+	})
+	defer server.Close()
+
+	history, err := (&NzbgetService{}).GetFailedHistory(context.Background(), server.URL, "nzbget:secret", 1)
+	require.NoError(t, err)
+
+	require.Len(t, history, 1)
+	assert.Equal(t, "3", history[0].ID)
+	assert.Equal(t, "PAR", history[0].FailMessage)
+}
+
+func TestGetWarnings(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"log": `[
+			{"Kind": "INFO", "Time": 1700000000, "Text": "Download started"},
+			{"Kind": "WARNING", "Time": 1700000001, "Text": "Connection lost"},
+			{"Kind": "ERROR", "Time": 1700000002, "Text": "Disk full"}
+		]`,
+	})
+	defer server.Close()
+
+	warnings, err := (&NzbgetService{}).GetWarnings(context.Background(), server.URL, "nzbget:secret")
+	require.NoError(t, err)
+
+	require.Len(t, warnings, 2)
+	assert.Equal(t, "WARNING", warnings[0].Type)
+	assert.Equal(t, "Disk full", warnings[1].Text)
+}
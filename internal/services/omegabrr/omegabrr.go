@@ -24,19 +24,20 @@ type VersionResponse struct {
 }
 
 func init() {
-	models.NewOmegabrrService = func() models.ServiceHealthChecker {
-		return &OmegabrrService{}
-	}
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "omegabrr",
+		DisplayName:    "Omegabrr",
+		Description:    "Monitor and manage your Omegabrr instance",
+		DefaultURL:     "http://localhost:7474",
+		HealthEndpoint: "/api/healthz/liveness",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityWebhooks},
+		New:            NewOmegabrrService,
+	})
 }
 
 func NewOmegabrrService() models.ServiceHealthChecker {
-	service := &OmegabrrService{}
-	service.Type = "omegabrr"
-	service.DisplayName = "Omegabrr"
-	service.Description = "Monitor and manage your Omegabrr instance"
-	service.DefaultURL = "http://localhost:7474"
-	service.HealthEndpoint = "/api/healthz/liveness"
-	return service
+	return &OmegabrrService{ServiceCore: core.NewServiceCore("omegabrr")}
 }
 
 func (s *OmegabrrService) GetHealthEndpoint(baseURL string) string {
@@ -86,14 +87,16 @@ func (s *OmegabrrService) getVersion(ctx context.Context, url, apiKey string) (s
 	return version.Version, nil
 }
 
-func (s *OmegabrrService) CheckHealth(url, apiKey string) (models.ServiceHealth, int) {
+func (s *OmegabrrService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
 	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
 
 	if url == "" {
-		return s.CreateHealthResponse(startTime, "error", "URL is required"), http.StatusBadRequest
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
 	}
 
-	ctx := context.Background()
+	ctx, cancel := core.HealthCheckContext(ctx, service, 15*time.Second)
+	defer cancel()
 
 	// Start version check in background
 	versionChan := make(chan string, 1)
@@ -115,7 +118,7 @@ func (s *OmegabrrService) CheckHealth(url, apiKey string) (models.ServiceHealth,
 
 	resp, err := s.MakeRequestWithContext(ctx, healthEndpoint, "", headers)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "offline", "Failed to connect: "+err.Error()), http.StatusOK
+		return s.CreateHealthResponse(startTime, "offline", "Failed to connect: "+err.Error()), nil
 	}
 	defer resp.Body.Close()
 
@@ -124,15 +127,15 @@ func (s *OmegabrrService) CheckHealth(url, apiKey string) (models.ServiceHealth,
 
 	body, err := s.ReadBody(resp)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "warning", "Failed to read response: "+err.Error()), http.StatusOK
+		return s.CreateHealthResponse(startTime, "warning", "Failed to read response: "+err.Error()), nil
 	}
 
 	if resp.StatusCode >= 400 {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Server returned error: %d", resp.StatusCode)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Server returned error: %d", resp.StatusCode)), nil
 	}
 
 	if strings.TrimSpace(string(body)) != "OK" {
-		return s.CreateHealthResponse(startTime, "warning", "Unexpected response from server"), http.StatusOK
+		return s.CreateHealthResponse(startTime, "warning", "Unexpected response from server"), nil
 	}
 
 	// Wait for version with timeout
@@ -149,7 +152,7 @@ func (s *OmegabrrService) CheckHealth(url, apiKey string) (models.ServiceHealth,
 		"responseTime": responseTime.Milliseconds(),
 	}
 
-	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), http.StatusOK
+	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), nil
 }
 
 // TriggerARRsWebhook triggers the ARRs webhook
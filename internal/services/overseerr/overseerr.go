@@ -16,9 +16,11 @@ import (
 
 	"github.com/autobrr/dashbrr/internal/database"
 	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/secrets"
 	"github.com/autobrr/dashbrr/internal/services/core"
 	"github.com/autobrr/dashbrr/internal/services/radarr"
 	"github.com/autobrr/dashbrr/internal/services/sonarr"
+	"github.com/autobrr/dashbrr/internal/tracing"
 	"github.com/autobrr/dashbrr/internal/types"
 )
 
@@ -38,23 +40,91 @@ func (e *ErrOverseerr) Error() string {
 	return fmt.Sprintf("%s:\n• %s", e.Message, errorList)
 }
 
+// Variant identifies which fork of the request API a service speaks
+type Variant string
+
+const (
+	VariantOverseerr  Variant = "overseerr"
+	VariantJellyseerr Variant = "jellyseerr"
+)
+
+// DisplayName returns the product name of the variant
+func (v Variant) DisplayName() string {
+	if v == VariantJellyseerr {
+		return "Jellyseerr"
+	}
+	return "Overseerr"
+}
+
+// username returns the name shown for a request user. Overseerr links users
+// to Plex accounts while Jellyseerr links them to Jellyfin or Emby accounts.
+func (v Variant) username(user types.RequestUser) string {
+	if user.Username != "" {
+		return user.Username
+	}
+
+	linked := user.PlexUsername
+	if v == VariantJellyseerr {
+		linked = user.JellyfinUsername
+	}
+	if linked != "" {
+		return linked
+	}
+	return user.DisplayName
+}
+
 type OverseerrService struct {
 	core.ServiceCore
-	db *database.DB
+	db      *database.DB
+	variant Variant
 }
 
 func init() {
-	models.NewOverseerrService = NewOverseerrService
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           string(VariantOverseerr),
+		DisplayName:    "Overseerr",
+		Description:    "Monitor and manage your Overseerr instance",
+		DefaultURL:     "http://localhost:5055",
+		HealthEndpoint: "/api/v1/status",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityRequests},
+		New:            NewOverseerrService,
+	})
+
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           string(VariantJellyseerr),
+		DisplayName:    "Jellyseerr",
+		Description:    "Monitor and manage your Jellyseerr instance",
+		DefaultURL:     "http://localhost:5055",
+		HealthEndpoint: "/api/v1/status",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityRequests},
+		New:            NewJellyseerrService,
+	})
 }
 
 func NewOverseerrService() models.ServiceHealthChecker {
-	service := &OverseerrService{}
-	service.Type = "overseerr"
-	service.DisplayName = "Overseerr"
-	service.Description = "Monitor and manage your Overseerr instance"
-	service.DefaultURL = "http://localhost:5055"
-	service.HealthEndpoint = "/api/v1/status"
-	return service
+	return NewService(VariantOverseerr)
+}
+
+func NewJellyseerrService() models.ServiceHealthChecker {
+	return NewService(VariantJellyseerr)
+}
+
+// NewService creates a client for the given variant
+func NewService(variant Variant) *OverseerrService {
+	return &OverseerrService{
+		ServiceCore: core.NewServiceCore(string(variant)),
+		variant:     variant,
+	}
+}
+
+// Variant returns the variant of the service, a zero value service is Overseerr
+func (s *OverseerrService) Variant() Variant {
+	if s.variant == "" {
+		return VariantOverseerr
+	}
+	return s.variant
 }
 
 func (s *OverseerrService) GetHealthEndpoint(baseURL string) string {
@@ -94,7 +164,7 @@ func (s *OverseerrService) UpdateRequestStatus(url, apiKey string, requestID int
 	req.Header.Set("X-Api-Key", apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := &http.Client{Transport: tracing.Transport(nil)}
 	resp, err := client.Do(req)
 	if err != nil {
 		log.Error().
@@ -102,7 +172,8 @@ func (s *OverseerrService) UpdateRequestStatus(url, apiKey string, requestID int
 			Str("url", url).
 			Int("requestID", requestID).
 			Str("status", status).
-			Msg("Failed to update Overseerr request status")
+			Str("variant", string(s.Variant())).
+			Msg("Failed to update request status")
 		return &ErrOverseerr{Message: "Connection error", Errors: []string{err.Error()}}
 	}
 	defer resp.Body.Close()
@@ -136,6 +207,9 @@ func (s *OverseerrService) fetchMediaTitle(request types.MediaRequest) (string,
 		if service == nil {
 			return "", fmt.Errorf("no Radarr service found")
 		}
+		if err := secrets.ResolveService(context.Background(), service); err != nil {
+			return "", err
+		}
 
 		radarrService := &radarr.RadarrService{}
 		// Use TmdbID for movie lookups
@@ -154,6 +228,9 @@ func (s *OverseerrService) fetchMediaTitle(request types.MediaRequest) (string,
 		if service == nil {
 			return "", fmt.Errorf("no Sonarr service found")
 		}
+		if err := secrets.ResolveService(context.Background(), service); err != nil {
+			return "", err
+		}
 
 		sonarrService := &sonarr.SonarrService{}
 		// Use TvdbID for TV show lookups
@@ -182,7 +259,8 @@ func (s *OverseerrService) GetRequests(url, apiKey string) (*types.RequestsStats
 	log.Debug().
 		Str("url", url).
 		Str("endpoint", requestEndpoint).
-		Msg("Fetching Overseerr requests")
+		Str("variant", string(s.Variant())).
+		Msg("Fetching requests")
 
 	headers := map[string]string{
 		"X-Api-Key": apiKey,
@@ -193,7 +271,8 @@ func (s *OverseerrService) GetRequests(url, apiKey string) (*types.RequestsStats
 		log.Error().
 			Err(err).
 			Str("url", url).
-			Msg("Failed to connect to Overseerr")
+			Str("variant", string(s.Variant())).
+			Msg("Failed to connect to service")
 		return nil, &ErrOverseerr{Message: "Connection error", Errors: []string{err.Error()}}
 	}
 	defer resp.Body.Close()
@@ -203,7 +282,8 @@ func (s *OverseerrService) GetRequests(url, apiKey string) (*types.RequestsStats
 		log.Error().
 			Err(err).
 			Str("url", url).
-			Msg("Failed to read Overseerr response body")
+			Str("variant", string(s.Variant())).
+			Msg("Failed to read response body")
 		return nil, &ErrOverseerr{Message: "Service error", Errors: []string{err.Error()}}
 	}
 
@@ -213,7 +293,8 @@ func (s *OverseerrService) GetRequests(url, apiKey string) (*types.RequestsStats
 			Err(err).
 			Str("url", url).
 			Str("body", string(body)).
-			Msg("Failed to parse Overseerr response")
+			Str("variant", string(s.Variant())).
+			Msg("Failed to parse requests response")
 		return nil, &ErrOverseerr{Message: "Response error", Errors: []string{"Failed to parse requests response"}}
 	}
 
@@ -244,6 +325,9 @@ func (s *OverseerrService) GetRequests(url, apiKey string) (*types.RequestsStats
 			pendingCount++
 		}
 
+		mediaRequest.RequestedBy.Username = s.Variant().username(mediaRequest.RequestedBy)
+		mediaRequest.ModifiedBy.Username = s.Variant().username(mediaRequest.ModifiedBy)
+
 		// Try to fetch the title using the appropriate lookup method
 		title, err := s.fetchMediaTitle(mediaRequest)
 		if err != nil {
@@ -263,7 +347,8 @@ func (s *OverseerrService) GetRequests(url, apiKey string) (*types.RequestsStats
 	log.Debug().
 		Int("totalRequests", len(mediaRequests)).
 		Int("pendingCount", pendingCount).
-		Msg("Successfully processed Overseerr requests")
+		Str("variant", string(s.Variant())).
+		Msg("Successfully processed requests")
 
 	return &types.RequestsStats{
 		PendingCount: pendingCount,
@@ -271,18 +356,56 @@ func (s *OverseerrService) GetRequests(url, apiKey string) (*types.RequestsStats
 	}, nil
 }
 
-func (s *OverseerrService) CheckHealth(url, apiKey string) (models.ServiceHealth, int) {
+// GetRequestCount fetches the number of requests per status
+func (s *OverseerrService) GetRequestCount(url, apiKey string) (*types.RequestCountResponse, error) {
+	if url == "" {
+		return nil, &ErrOverseerr{Message: "Configuration error", Errors: []string{"URL is required"}}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	countEndpoint := fmt.Sprintf("%s/api/v1/request/count", strings.TrimRight(url, "/"))
+	headers := map[string]string{
+		"X-Api-Key": apiKey,
+	}
+
+	resp, err := s.MakeRequestWithContext(ctx, countEndpoint, "", headers)
+	if err != nil {
+		return nil, &ErrOverseerr{Message: "Connection error", Errors: []string{err.Error()}}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ErrOverseerr{Message: "Service error", Errors: []string{fmt.Sprintf("unexpected status code: %d", resp.StatusCode)}}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return nil, &ErrOverseerr{Message: "Service error", Errors: []string{err.Error()}}
+	}
+
+	var count types.RequestCountResponse
+	if err := json.Unmarshal(body, &count); err != nil {
+		return nil, &ErrOverseerr{Message: "Response error", Errors: []string{"Failed to parse request count response"}}
+	}
+
+	return &count, nil
+}
+
+func (s *OverseerrService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
 	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
 
 	if url == "" {
-		return s.CreateHealthResponse(startTime, "error", (&ErrOverseerr{
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", (&ErrOverseerr{
 			Message: "Configuration error",
 			Errors:  []string{"URL is required"},
-		}).Error()), http.StatusBadRequest
+		}).Error())
 	}
 
 	// Create a context with timeout for the health check
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := core.HealthCheckContext(ctx, service, 3*time.Second)
 	defer cancel()
 
 	healthEndpoint := s.GetHealthEndpoint(url)
@@ -296,11 +419,12 @@ func (s *OverseerrService) CheckHealth(url, apiKey string) (models.ServiceHealth
 		log.Error().
 			Err(err).
 			Str("url", url).
-			Msg("Failed to connect to Overseerr health endpoint")
+			Str("variant", string(s.Variant())).
+			Msg("Failed to connect to health endpoint")
 		return s.CreateHealthResponse(startTime, "offline", (&ErrOverseerr{
 			Message: "Connection error",
 			Errors:  []string{err.Error()},
-		}).Error()), http.StatusOK
+		}).Error()), nil
 	}
 	defer resp.Body.Close()
 
@@ -317,13 +441,14 @@ func (s *OverseerrService) CheckHealth(url, apiKey string) (models.ServiceHealth
 		log.Error().
 			Err(err).
 			Str("url", url).
-			Msg("Failed to read Overseerr health response")
+			Str("variant", string(s.Variant())).
+			Msg("Failed to read health response")
 
 		// Align error status with request failures
 		if resp.StatusCode >= 500 {
-			return s.CreateHealthResponse(startTime, "error", errMsg), http.StatusOK
+			return s.CreateHealthResponse(startTime, "error", errMsg), nil
 		}
-		return s.CreateHealthResponse(startTime, "warning", errMsg), http.StatusOK
+		return s.CreateHealthResponse(startTime, "warning", errMsg), nil
 	}
 
 	// Parse the response
@@ -333,11 +458,12 @@ func (s *OverseerrService) CheckHealth(url, apiKey string) (models.ServiceHealth
 			Err(err).
 			Str("url", url).
 			Str("body", string(body)).
-			Msg("Failed to parse Overseerr health response")
+			Str("variant", string(s.Variant())).
+			Msg("Failed to parse health response")
 		return s.CreateHealthResponse(startTime, "warning", (&ErrOverseerr{
 			Message: "Response error",
 			Errors:  []string{"Failed to parse status response"},
-		}).Error()), http.StatusOK
+		}).Error()), nil
 	}
 
 	// Create response with version, update information, and response time
@@ -366,8 +492,8 @@ func (s *OverseerrService) CheckHealth(url, apiKey string) (models.ServiceHealth
 			Err(err).
 			Str("url", url).
 			Str("version", statusResponse.Version).
-			Msg("Failed to cache Overseerr version")
+			Msg("Failed to cache version")
 	}
 
-	return s.CreateHealthResponse(startTime, status, message, extras), http.StatusOK
+	return s.CreateHealthResponse(startTime, status, message, extras), nil
 }
@@ -0,0 +1,102 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package overseerr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+func TestVariantUsername(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant Variant
+		user    types.RequestUser
+		want    string
+	}{
+		{
+			name:    "Username wins",
+			variant: VariantJellyseerr,
+			user:    types.RequestUser{Username: "alice", JellyfinUsername: "alice-jf"},
+			want:    "alice",
+		},
+		{
+			name:    "Overseerr falls back to Plex username",
+			variant: VariantOverseerr,
+			user:    types.RequestUser{PlexUsername: "bob-plex", JellyfinUsername: "bob-jf"},
+			want:    "bob-plex",
+		},
+		{
+			name:    "Jellyseerr falls back to Jellyfin username",
+			variant: VariantJellyseerr,
+			user:    types.RequestUser{PlexUsername: "bob-plex", JellyfinUsername: "bob-jf"},
+			want:    "bob-jf",
+		},
+		{
+			name:    "Display name is the last resort",
+			variant: VariantJellyseerr,
+			user:    types.RequestUser{DisplayName: "Carol"},
+			want:    "Carol",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.variant.username(tt.user))
+		})
+	}
+}
+
+func TestVariant_ZeroValueIsOverseerr(t *testing.T) {
+	assert.Equal(t, VariantOverseerr, (&OverseerrService{}).Variant())
+	assert.Equal(t, VariantJellyseerr, NewService(VariantJellyseerr).Variant())
+	assert.Equal(t, "Jellyseerr", VariantJellyseerr.DisplayName())
+}
+
+func TestGetRequests_Jellyseerr(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/request", r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("X-Api-Key"))
+
+		w.Write([]byte(`{"pageInfo": {"pages": 1, "pageSize": 10, "results": 2, "page": 1}, "results": [
+			{"id": 1, "status": 1, "media": {"tmdbId": 603, "mediaType": "movie"},
+			 "requestedBy": {"id": 2, "jellyfinUsername": "dave", "displayName": "Dave"}},
+			{"id": 2, "status": 2, "media": {"tvdbId": 81189, "mediaType": "tv"},
+			 "requestedBy": {"id": 3, "displayName": "Erin"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	stats, err := NewService(VariantJellyseerr).GetRequests(server.URL, "test-key")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, stats.PendingCount)
+	require.Len(t, stats.Requests, 2)
+	assert.Equal(t, "dave", stats.Requests[0].RequestedBy.Username)
+	assert.Equal(t, "Erin", stats.Requests[1].RequestedBy.Username)
+}
+
+func TestUpdateRequestStatus(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		path = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	service := NewService(VariantJellyseerr)
+
+	require.NoError(t, service.UpdateRequestStatus(server.URL, "test-key", 7, true))
+	assert.Equal(t, "/api/v1/request/7/approve", path)
+
+	require.NoError(t, service.UpdateRequestStatus(server.URL, "test-key", 7, false))
+	assert.Equal(t, "/api/v1/request/7/decline", path)
+}
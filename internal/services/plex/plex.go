@@ -22,17 +22,20 @@ type PlexService struct {
 }
 
 func init() {
-	models.NewPlexService = NewPlexService
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "plex",
+		DisplayName:    "Plex",
+		Description:    "Monitor and manage your Plex Media Server",
+		DefaultURL:     "http://localhost:32400",
+		HealthEndpoint: "/identity",
+		Auth:           models.AuthToken,
+		Capabilities:   []models.Capability{models.CapabilitySessions},
+		New:            NewPlexService,
+	})
 }
 
 func NewPlexService() models.ServiceHealthChecker {
-	service := &PlexService{}
-	service.Type = "plex"
-	service.DisplayName = "Plex"
-	service.Description = "Monitor and manage your Plex Media Server"
-	service.DefaultURL = "http://localhost:32400"
-	service.HealthEndpoint = "/identity"
-	return service
+	return &PlexService{ServiceCore: core.NewServiceCore("plex")}
 }
 
 func (s *PlexService) GetHealthEndpoint(baseURL string) string {
@@ -113,14 +116,15 @@ func (s *PlexService) GetSessions(url, apiKey string) (*types.PlexSessionsRespon
 	return &sessionsResponse, nil
 }
 
-func (s *PlexService) CheckHealth(url, apiKey string) (models.ServiceHealth, int) {
+func (s *PlexService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
 	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
 
 	if url == "" {
-		return s.CreateHealthResponse(startTime, "error", "URL is required"), http.StatusBadRequest
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := core.HealthCheckContext(ctx, service, 10*time.Second)
 	defer cancel()
 
 	healthEndpoint := s.GetHealthEndpoint(url)
@@ -128,7 +132,7 @@ func (s *PlexService) CheckHealth(url, apiKey string) (models.ServiceHealth, int
 
 	resp, err := s.MakeRequestWithContext(ctx, healthEndpoint, "", headers)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
 	}
 	defer resp.Body.Close()
 
@@ -136,18 +140,18 @@ func (s *PlexService) CheckHealth(url, apiKey string) (models.ServiceHealth, int
 
 	body, err := s.ReadBody(resp)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "warning", fmt.Sprintf("Failed to read response: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "warning", fmt.Sprintf("Failed to read response: %v", err)), nil
 	}
 
 	if resp.StatusCode >= 400 {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Server returned error: %d", resp.StatusCode)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Server returned error: %d", resp.StatusCode)), nil
 	}
 
 	var plexResponse types.PlexResponse
 	if err := json.Unmarshal(body, &plexResponse); err != nil {
 		var mediaContainer types.MediaContainer
 		if xmlErr := xml.Unmarshal(body, &mediaContainer); xmlErr != nil {
-			return s.CreateHealthResponse(startTime, "warning", "Failed to parse server response"), http.StatusOK
+			return s.CreateHealthResponse(startTime, "warning", "Failed to parse server response"), nil
 		}
 		plexResponse.MediaContainer = mediaContainer
 	}
@@ -163,5 +167,5 @@ func (s *PlexService) CheckHealth(url, apiKey string) (models.ServiceHealth, int
 		message = fmt.Sprintf("Healthy - Running on %s", plexResponse.MediaContainer.Platform)
 	}
 
-	return s.CreateHealthResponse(startTime, "online", message, extras), http.StatusOK
+	return s.CreateHealthResponse(startTime, "online", message, extras), nil
 }
@@ -13,6 +13,7 @@ import (
 
 	"github.com/autobrr/dashbrr/internal/models"
 	"github.com/autobrr/dashbrr/internal/services/core"
+	"github.com/autobrr/dashbrr/internal/types"
 )
 
 // Custom error types for better error handling
@@ -52,17 +53,20 @@ type SystemStatusResponse struct {
 }
 
 func init() {
-	models.NewProwlarrService = NewProwlarrService
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "prowlarr",
+		DisplayName:    "Prowlarr",
+		Description:    "Monitor and manage your Prowlarr instance",
+		DefaultURL:     "http://localhost:9696",
+		HealthEndpoint: "/api/v1/health",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityStats, models.CapabilityIndexers},
+		New:            NewProwlarrService,
+	})
 }
 
 func NewProwlarrService() models.ServiceHealthChecker {
-	service := &ProwlarrService{}
-	service.Type = "prowlarr"
-	service.DisplayName = "Prowlarr"
-	service.Description = "Monitor and manage your Prowlarr instance"
-	service.DefaultURL = "http://localhost:9696"
-	service.HealthEndpoint = "/api/v1/health"
-	return service
+	return &ProwlarrService{ServiceCore: core.NewServiceCore("prowlarr")}
 }
 
 func (s *ProwlarrService) GetHealthEndpoint(baseURL string) string {
@@ -81,12 +85,11 @@ func (s *ProwlarrService) makeRequest(ctx context.Context, method, url, apiKey s
 	req.Header.Set("X-Api-Key", apiKey)
 	req.Header.Set("Accept", "*/*")
 
-	client := &http.Client{}
-	return client.Do(req)
+	return core.Do(req)
 }
 
 // GetSystemStatus fetches the system status from Prowlarr
-func (s *ProwlarrService) GetSystemStatus(baseURL, apiKey string) (string, error) {
+func (s *ProwlarrService) GetSystemStatus(ctx context.Context, baseURL, apiKey string) (string, error) {
 	if baseURL == "" {
 		return "", &ErrProwlarr{Op: "get_system_status", Err: fmt.Errorf("URL is required")}
 	}
@@ -97,7 +100,7 @@ func (s *ProwlarrService) GetSystemStatus(baseURL, apiKey string) (string, error
 	}
 
 	statusURL := fmt.Sprintf("%s/api/v1/system/status", strings.TrimRight(baseURL, "/"))
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
 	resp, err := s.makeRequest(ctx, http.MethodGet, statusURL, apiKey)
@@ -129,22 +132,56 @@ func (s *ProwlarrService) GetSystemStatus(baseURL, apiKey string) (string, error
 	return status.Version, nil
 }
 
-func (s *ProwlarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, int) {
+// GetIndexerStats fetches the query and grab statistics of all indexers
+func (s *ProwlarrService) GetIndexerStats(baseURL, apiKey string) ([]types.ProwlarrIndexerStats, error) {
+	if baseURL == "" {
+		return nil, &ErrProwlarr{Op: "get_indexer_stats", Err: fmt.Errorf("URL is required")}
+	}
+
+	statsURL := fmt.Sprintf("%s/api/v1/indexerstats", strings.TrimRight(baseURL, "/"))
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	resp, err := s.makeRequest(ctx, http.MethodGet, statsURL, apiKey)
+	if err != nil {
+		return nil, &ErrProwlarr{Op: "get_indexer_stats", Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ErrProwlarr{Op: "get_indexer_stats", HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return nil, &ErrProwlarr{Op: "get_indexer_stats", Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	var stats types.ProwlarrIndexerStatsResponse
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return nil, &ErrProwlarr{Op: "get_indexer_stats", Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	return stats.Indexers, nil
+}
+
+func (s *ProwlarrService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
 	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
 
 	if url == "" {
-		return s.CreateHealthResponse(startTime, "error", "URL is required"), http.StatusBadRequest
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
 	}
 
 	// Create a context with timeout for the entire health check
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := core.HealthCheckContext(ctx, service, 3*time.Second)
 	defer cancel()
 
 	// Start version check in background
 	versionChan := make(chan string, 1)
 	versionErrChan := make(chan error, 1)
 	go func() {
-		version, err := s.GetSystemStatus(url, apiKey)
+		version, err := s.GetSystemStatus(ctx, url, apiKey)
 		versionChan <- version
 		versionErrChan <- err
 	}()
@@ -153,7 +190,7 @@ func (s *ProwlarrService) CheckHealth(url, apiKey string) (models.ServiceHealth,
 	healthEndpoint := s.GetHealthEndpoint(url)
 	resp, err := s.makeRequest(ctx, http.MethodGet, healthEndpoint, apiKey)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
 	}
 	defer resp.Body.Close()
 
@@ -162,7 +199,7 @@ func (s *ProwlarrService) CheckHealth(url, apiKey string) (models.ServiceHealth,
 
 	body, err := s.ReadBody(resp)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), nil
 	}
 
 	if resp.StatusCode >= 400 {
@@ -182,12 +219,12 @@ func (s *ProwlarrService) CheckHealth(url, apiKey string) (models.ServiceHealth,
 			message = "Service endpoint not found"
 		}
 
-		return s.CreateHealthResponse(startTime, status, message), http.StatusOK
+		return s.CreateHealthResponse(startTime, status, message), nil
 	}
 
 	var healthIssues []HealthResponse
 	if err := json.Unmarshal(body, &healthIssues); err != nil {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to parse response: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to parse response: %v", err)), nil
 	}
 
 	// Wait for version with timeout
@@ -259,9 +296,9 @@ func (s *ProwlarrService) CheckHealth(url, apiKey string) (models.ServiceHealth,
 
 	// If there are any warnings, return them all
 	if len(allWarnings) > 0 {
-		return s.CreateHealthResponse(startTime, "warning", strings.Join(allWarnings, "\n\n"), extras), http.StatusOK
+		return s.CreateHealthResponse(startTime, "warning", strings.Join(allWarnings, "\n\n"), extras), nil
 	}
 
 	// If no warnings, the service is healthy
-	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), http.StatusOK
+	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), nil
 }
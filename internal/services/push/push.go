@@ -0,0 +1,201 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// Package push monitors services that report their own health. Instead of
+// being polled, a push service calls its unique heartbeat URL and is marked
+// offline when no heartbeat arrives within the grace period.
+package push
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/core"
+)
+
+const (
+	// PathPrefix is the path of the heartbeat endpoint, followed by the token
+	PathPrefix = "/api/push/"
+
+	// DefaultGracePeriod is used for instances without a configured grace period
+	DefaultGracePeriod = 5 * time.Minute
+
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Heartbeat is a health report sent by a push service
+type Heartbeat struct {
+	ReceivedAt time.Time
+	// Status is StatusUp or StatusDown
+	Status  string
+	Message string
+	// Ping is the response time reported by the service in milliseconds
+	Ping int64
+}
+
+var (
+	heartbeatsMu sync.RWMutex
+	heartbeats   = make(map[string]Heartbeat)
+	// waiting holds when an instance without heartbeat was first checked.
+	// Heartbeats are not persisted, so this opens the grace period of new
+	// instances and of all instances after dashbrr restarted.
+	waiting = make(map[string]time.Time)
+)
+
+type PushService struct {
+	core.ServiceCore
+}
+
+func init() {
+	models.RegisterService(models.ServiceDescriptor{
+		Type:        "push",
+		DisplayName: "Push Monitor",
+		Description: "Monitor services that send heartbeats to dashbrr",
+		Auth:        models.AuthNone,
+		CLI:         models.CLIMetadata{NameArgument: true},
+		NewURL:      NewURL,
+		New:         NewPushService,
+	})
+}
+
+func NewPushService() models.ServiceHealthChecker {
+	return &PushService{ServiceCore: core.NewServiceCore("push")}
+}
+
+// NewURL returns a heartbeat path with a new random token
+func NewURL() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate push token: %w", err)
+	}
+	return PathPrefix + hex.EncodeToString(token), nil
+}
+
+// TokenFromURL returns the token of a heartbeat path, or an empty string
+func TokenFromURL(url string) string {
+	token, ok := strings.CutPrefix(url, PathPrefix)
+	if !ok || strings.Contains(token, "/") {
+		return ""
+	}
+	return token
+}
+
+// RecordHeartbeat stores the latest heartbeat of a token
+func RecordHeartbeat(token string, heartbeat Heartbeat) {
+	if heartbeat.ReceivedAt.IsZero() {
+		heartbeat.ReceivedAt = time.Now()
+	}
+
+	heartbeatsMu.Lock()
+	heartbeats[token] = heartbeat
+	delete(waiting, token)
+	heartbeatsMu.Unlock()
+}
+
+// LastHeartbeat returns the latest heartbeat of a token
+func LastHeartbeat(token string) (Heartbeat, bool) {
+	heartbeatsMu.RLock()
+	defer heartbeatsMu.RUnlock()
+
+	heartbeat, ok := heartbeats[token]
+	return heartbeat, ok
+}
+
+// waitingSince returns when a token without heartbeat was first checked
+func waitingSince(token string) time.Time {
+	heartbeatsMu.Lock()
+	defer heartbeatsMu.Unlock()
+
+	since, ok := waiting[token]
+	if !ok {
+		since = time.Now()
+		waiting[token] = since
+	}
+	return since
+}
+
+// Prune drops the heartbeats of tokens that none of services uses, so a
+// deleted monitor or a changed token does not leave a heartbeat behind for a
+// later monitor with the same token
+func Prune(services []models.ServiceConfiguration) {
+	tokens := make(map[string]bool)
+	for _, service := range services {
+		if token := TokenFromURL(service.URL); token != "" {
+			tokens[token] = true
+		}
+	}
+
+	heartbeatsMu.Lock()
+	defer heartbeatsMu.Unlock()
+
+	for token := range heartbeats {
+		if !tokens[token] {
+			delete(heartbeats, token)
+		}
+	}
+	for token := range waiting {
+		if !tokens[token] {
+			delete(waiting, token)
+		}
+	}
+}
+
+// GracePeriod returns the configured grace period of a service
+func GracePeriod(service models.ServiceConfiguration) time.Duration {
+	if service.Options.GracePeriod > 0 {
+		return time.Duration(service.Options.GracePeriod) * time.Second
+	}
+	return DefaultGracePeriod
+}
+
+func (s *PushService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
+	startTime := time.Now()
+
+	token := TokenFromURL(service.URL)
+	if token == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "Push URL is invalid")
+	}
+
+	grace := GracePeriod(service)
+	heartbeat, ok := LastHeartbeat(token)
+	if !ok {
+		if time.Since(waitingSince(token)) < grace {
+			return s.CreateHealthResponse(startTime, "pending", "Waiting for the first heartbeat"), nil
+		}
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("No heartbeat received within %s", grace)), nil
+	}
+
+	extras := map[string]interface{}{
+		"responseTime": heartbeat.Ping,
+		"details": map[string]interface{}{
+			"lastHeartbeat": heartbeat.ReceivedAt,
+			"gracePeriod":   int64(grace.Seconds()),
+		},
+	}
+
+	if since := time.Since(heartbeat.ReceivedAt); since > grace {
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("No heartbeat for %s", since.Truncate(time.Second)), extras), nil
+	}
+
+	if heartbeat.Status == StatusDown {
+		message := heartbeat.Message
+		if message == "" {
+			message = "Service reported down"
+		}
+		return s.CreateHealthResponse(startTime, "error", message, extras), nil
+	}
+
+	message := heartbeat.Message
+	if message == "" {
+		message = "Heartbeat received"
+	}
+	return s.CreateHealthResponse(startTime, "online", message, extras), nil
+}
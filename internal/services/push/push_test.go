@@ -0,0 +1,108 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package push
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func TestNewURL(t *testing.T) {
+	first, err := NewURL()
+	require.NoError(t, err)
+	second, err := NewURL()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.Len(t, TokenFromURL(first), 32)
+}
+
+func TestTokenFromURL(t *testing.T) {
+	assert.Equal(t, "abc", TokenFromURL("/api/push/abc"))
+	assert.Empty(t, TokenFromURL("http://localhost/api/push/abc"))
+	assert.Empty(t, TokenFromURL("/api/push/abc/def"))
+	assert.Empty(t, TokenFromURL("/api/push/"))
+}
+
+func TestPrune(t *testing.T) {
+	RecordHeartbeat("kept", Heartbeat{Status: StatusUp})
+	RecordHeartbeat("deleted", Heartbeat{Status: StatusUp})
+	waitingSince("kept-waiting")
+	waitingSince("deleted-waiting")
+
+	Prune([]models.ServiceConfiguration{
+		{InstanceID: "push-1", URL: "/api/push/kept"},
+		{InstanceID: "push-2", URL: "/api/push/kept-waiting"},
+		{InstanceID: "sonarr-1", URL: "http://localhost:8989"},
+	})
+
+	_, ok := LastHeartbeat("kept")
+	assert.True(t, ok)
+	_, ok = LastHeartbeat("deleted")
+	assert.False(t, ok)
+
+	heartbeatsMu.RLock()
+	defer heartbeatsMu.RUnlock()
+	assert.Contains(t, waiting, "kept-waiting")
+	assert.NotContains(t, waiting, "deleted-waiting")
+}
+
+func TestCheckHealth(t *testing.T) {
+	service := NewPushService()
+
+	check := func(url string, gracePeriod int) models.ServiceHealth {
+		health, err := service.CheckHealth(context.Background(), models.ServiceConfiguration{
+			InstanceID: "push-1",
+			URL:        url,
+			Options:    models.ServiceOptions{GracePeriod: gracePeriod},
+		})
+		require.NoError(t, err)
+		return health
+	}
+
+	t.Run("Waiting for the first heartbeat", func(t *testing.T) {
+		assert.Equal(t, "pending", check("/api/push/waiting", 0).Status)
+	})
+
+	t.Run("No heartbeat within the grace period", func(t *testing.T) {
+		heartbeatsMu.Lock()
+		waiting["silent"] = time.Now().Add(-2 * time.Minute)
+		heartbeatsMu.Unlock()
+
+		assert.Equal(t, "offline", check("/api/push/silent", 60).Status)
+	})
+
+	t.Run("Heartbeat up", func(t *testing.T) {
+		RecordHeartbeat("up", Heartbeat{Status: StatusUp, Ping: 42})
+
+		health := check("/api/push/up", 0)
+		assert.Equal(t, "online", health.Status)
+		assert.Equal(t, int64(42), health.ResponseTime)
+	})
+
+	t.Run("Heartbeat down", func(t *testing.T) {
+		RecordHeartbeat("down", Heartbeat{Status: StatusDown, Message: "backup failed"})
+
+		health := check("/api/push/down", 0)
+		assert.Equal(t, "error", health.Status)
+		assert.Equal(t, "backup failed", health.Message)
+	})
+
+	t.Run("Heartbeat expired", func(t *testing.T) {
+		RecordHeartbeat("expired", Heartbeat{Status: StatusUp, ReceivedAt: time.Now().Add(-2 * time.Minute)})
+
+		assert.Equal(t, "offline", check("/api/push/expired", 60).Status)
+	})
+
+	t.Run("Invalid URL", func(t *testing.T) {
+		_, err := service.CheckHealth(context.Background(), models.ServiceConfiguration{URL: "http://localhost"})
+		assert.Equal(t, 400, models.HealthCheckStatusCode(err))
+	})
+}
@@ -0,0 +1,294 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/core"
+	"github.com/autobrr/dashbrr/internal/services/downloadclient"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+const clientType = "qbittorrent"
+
+// sessions holds the WebUI session cookie per instance
+var sessions sync.Map
+
+type QbittorrentService struct {
+	core.ServiceCore
+}
+
+type mainDataResponse struct {
+	ServerState struct {
+		DownloadSpeed    int64  `json:"dl_info_speed"`
+		UploadSpeed      int64  `json:"up_info_speed"`
+		FreeSpaceOnDisk  int64  `json:"free_space_on_disk"`
+		ConnectionStatus string `json:"connection_status"`
+	} `json:"server_state"`
+	Torrents map[string]struct {
+		State string `json:"state"`
+	} `json:"torrents"`
+}
+
+func init() {
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           clientType,
+		DisplayName:    "qBittorrent",
+		Description:    "Monitor transfers and torrents of your qBittorrent client",
+		DefaultURL:     "http://localhost:8080",
+		HealthEndpoint: "/api/v2/app/version",
+		Auth:           models.AuthOptional,
+		Capabilities:   []models.Capability{models.CapabilityStats},
+		CLI:            models.CLIMetadata{Credential: "username:password"},
+		New:            NewQbittorrentService,
+	})
+}
+
+func NewQbittorrentService() models.ServiceHealthChecker {
+	return &QbittorrentService{ServiceCore: core.NewServiceCore(clientType)}
+}
+
+func newError(op string, err error) error {
+	return &downloadclient.ErrDownloadClient{Client: clientType, Op: op, Err: err}
+}
+
+// login authenticates against the WebUI and returns the session cookie. An
+// empty apiKey relies on the WebUI's authentication bypass for trusted networks.
+func (s *QbittorrentService) login(ctx context.Context, baseURL, apiKey string) (string, error) {
+	if apiKey == "" {
+		return "", nil
+	}
+
+	sessionKey := baseURL + "|" + apiKey
+	if cookie, ok := sessions.Load(sessionKey); ok {
+		return cookie.(string), nil
+	}
+
+	username, password := downloadclient.SplitCredentials(apiKey)
+	form := neturl.Values{"username": {username}, "password": {password}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/api/v2/auth/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", newError("login", fmt.Errorf("failed to create request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// The WebUI rejects requests whose Referer does not match its own origin
+	req.Header.Set("Referer", baseURL)
+
+	resp, err := core.Do(req)
+	if err != nil {
+		return "", newError("login", fmt.Errorf("failed to make request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &downloadclient.ErrDownloadClient{Client: clientType, Op: "login", HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return "", newError("login", fmt.Errorf("failed to read response: %w", err))
+	}
+	if strings.TrimSpace(string(body)) != "Ok." {
+		return "", &downloadclient.ErrDownloadClient{Client: clientType, Op: "login", HttpCode: http.StatusUnauthorized}
+	}
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "SID" {
+			value := cookie.Name + "=" + cookie.Value
+			sessions.Store(sessionKey, value)
+			return value, nil
+		}
+	}
+
+	return "", newError("login", fmt.Errorf("no session cookie in login response"))
+}
+
+// do sends an authenticated request, logging in again once if the session expired
+func (s *QbittorrentService) do(ctx context.Context, op, method, baseURL, apiKey, path string, form neturl.Values) ([]byte, error) {
+	baseURL = strings.TrimRight(baseURL, "/")
+	if baseURL == "" {
+		return nil, newError(op, fmt.Errorf("URL is required"))
+	}
+
+	for attempt := 0; ; attempt++ {
+		cookie, err := s.login(ctx, baseURL, apiKey)
+		if err != nil {
+			return nil, err
+		}
+
+		var body *strings.Reader
+		if form != nil {
+			body = strings.NewReader(form.Encode())
+		} else {
+			body = strings.NewReader("")
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, baseURL+path, body)
+		if err != nil {
+			return nil, newError(op, fmt.Errorf("failed to create request: %w", err))
+		}
+		if form != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		req.Header.Set("Referer", baseURL)
+
+		resp, err := core.Do(req)
+		if err != nil {
+			return nil, newError(op, fmt.Errorf("failed to make request: %w", err))
+		}
+
+		if resp.StatusCode == http.StatusForbidden && cookie != "" && attempt == 0 {
+			resp.Body.Close()
+			sessions.Delete(baseURL + "|" + apiKey)
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, &downloadclient.ErrDownloadClient{Client: clientType, Op: op, HttpCode: resp.StatusCode}
+		}
+
+		data, err := s.ReadBody(resp)
+		if err != nil {
+			return nil, newError(op, fmt.Errorf("failed to read response: %w", err))
+		}
+		return data, nil
+	}
+}
+
+// GetVersion returns the qBittorrent version, cached for an hour
+func (s *QbittorrentService) GetVersion(ctx context.Context, baseURL, apiKey string) (string, error) {
+	if version := s.GetVersionFromCache(baseURL); version != "" {
+		return version, nil
+	}
+
+	body, err := s.do(ctx, "get_version", http.MethodGet, baseURL, apiKey, "/api/v2/app/version", nil)
+	if err != nil {
+		return "", err
+	}
+
+	version := strings.TrimPrefix(strings.TrimSpace(string(body)), "v")
+	if err := s.CacheVersion(baseURL, version, time.Hour); err != nil {
+		log.Warn().Err(err).Msg("Failed to cache qBittorrent version")
+	}
+
+	return version, nil
+}
+
+func (s *QbittorrentService) GetStats(ctx context.Context, baseURL, apiKey string) (*types.DownloadClientStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	version, err := s.GetVersion(ctx, baseURL, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := s.do(ctx, "get_stats", http.MethodGet, baseURL, apiKey, "/api/v2/sync/maindata", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var mainData mainDataResponse
+	if err := json.Unmarshal(body, &mainData); err != nil {
+		return nil, newError("get_stats", fmt.Errorf("failed to parse response: %w", err))
+	}
+
+	stats := &types.DownloadClientStats{
+		ClientType:    clientType,
+		Version:       version,
+		DownloadSpeed: mainData.ServerState.DownloadSpeed,
+		UploadSpeed:   mainData.ServerState.UploadSpeed,
+		Total:         len(mainData.Torrents),
+		FreeSpace:     mainData.ServerState.FreeSpaceOnDisk,
+	}
+
+	for _, torrent := range mainData.Torrents {
+		switch torrent.State {
+		case "downloading", "forcedDL", "metaDL", "forcedMetaDL":
+			stats.Downloading++
+			stats.Active++
+		case "uploading", "forcedUP":
+			stats.Seeding++
+			stats.Active++
+		case "stalledDL":
+			stats.Stalled++
+		case "pausedDL", "pausedUP", "stoppedDL", "stoppedUP":
+			stats.Paused++
+		case "error", "missingFiles":
+			stats.Errored++
+		}
+	}
+
+	return stats, nil
+}
+
+// torrentAction runs a pause or resume action. qBittorrent 5 renamed the
+// endpoints to stop and start, older releases only know pause and resume.
+func (s *QbittorrentService) torrentAction(ctx context.Context, op, baseURL, apiKey string, hashes []string, endpoints ...string) error {
+	form := neturl.Values{"hashes": {"all"}}
+	if len(hashes) > 0 {
+		form.Set("hashes", strings.Join(hashes, "|"))
+	}
+
+	var err error
+	for _, endpoint := range endpoints {
+		_, err = s.do(ctx, op, http.MethodPost, baseURL, apiKey, "/api/v2/torrents/"+endpoint, form)
+		if clientErr, ok := err.(*downloadclient.ErrDownloadClient); ok && clientErr.HttpCode == http.StatusNotFound {
+			continue
+		}
+		return err
+	}
+	return err
+}
+
+func (s *QbittorrentService) PauseTorrents(ctx context.Context, baseURL, apiKey string, hashes []string) error {
+	return s.torrentAction(ctx, "pause", baseURL, apiKey, hashes, "stop", "pause")
+}
+
+func (s *QbittorrentService) ResumeTorrents(ctx context.Context, baseURL, apiKey string, hashes []string) error {
+	return s.torrentAction(ctx, "resume", baseURL, apiKey, hashes, "start", "resume")
+}
+
+func (s *QbittorrentService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
+	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
+
+	if url == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
+	}
+
+	ctx, cancel := core.HealthCheckContext(ctx, service, 10*time.Second)
+	defer cancel()
+
+	stats, err := s.GetStats(ctx, url, apiKey)
+	if err != nil {
+		if clientErr, ok := err.(*downloadclient.ErrDownloadClient); ok && clientErr.HttpCode > 0 {
+			message := fmt.Sprintf("Server returned %s (%d)", http.StatusText(clientErr.HttpCode), clientErr.HttpCode)
+			if clientErr.HttpCode == http.StatusUnauthorized || clientErr.HttpCode == http.StatusForbidden {
+				message = "Invalid username or password"
+			}
+			return s.CreateHealthResponse(startTime, "error", message), nil
+		}
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
+	}
+
+	status, message, extras := downloadclient.HealthResponse(stats)
+	return s.CreateHealthResponse(startTime, status, message, extras), nil
+}
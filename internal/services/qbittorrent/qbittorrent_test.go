@@ -0,0 +1,79 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStats(t *testing.T) {
+	var logins atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/auth/login" {
+			logins.Add(1)
+			require.NoError(t, r.ParseForm())
+			if r.Form.Get("username") != "admin" || r.Form.Get("password") != "secret" {
+				w.Write([]byte("Fails."))
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: "session"})
+			w.Write([]byte("Ok."))
+			return
+		}
+
+		if cookie, err := r.Cookie("SID"); err != nil || cookie.Value != "session" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v2/app/version":
+			w.Write([]byte("v4.6.7"))
+		case "/api/v2/sync/maindata":
+			w.Write([]byte(`{
+				"server_state": {"dl_info_speed": 1024, "up_info_speed": 512, "free_space_on_disk": 1073741824},
+				"torrents": {
+					"a": {"state": "downloading"},
+					"b": {"state": "stalledDL"},
+					"c": {"state": "uploading"},
+					"d": {"state": "pausedUP"},
+					"e": {"state": "error"}
+				}
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	service := &QbittorrentService{}
+	stats, err := service.GetStats(context.Background(), server.URL, "admin:secret")
+	require.NoError(t, err)
+
+	assert.Equal(t, "4.6.7", stats.Version)
+	assert.Equal(t, int64(1024), stats.DownloadSpeed)
+	assert.Equal(t, int64(512), stats.UploadSpeed)
+	assert.Equal(t, int64(1073741824), stats.FreeSpace)
+	assert.Equal(t, 5, stats.Total)
+	assert.Equal(t, 2, stats.Active)
+	assert.Equal(t, 1, stats.Stalled)
+	assert.Equal(t, 1, stats.Paused)
+	assert.Equal(t, 1, stats.Errored)
+
+	// The session cookie is reused
+	_, err = service.GetStats(context.Background(), server.URL, "admin:secret")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), logins.Load())
+
+	_, err = service.GetStats(context.Background(), server.URL, "admin:wrong")
+	assert.Error(t, err)
+}
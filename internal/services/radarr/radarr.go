@@ -56,17 +56,20 @@ type SystemStatusResponse struct {
 }
 
 func init() {
-	models.NewRadarrService = NewRadarrService
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "radarr",
+		DisplayName:    "Radarr",
+		Description:    "Monitor and manage your Radarr instance",
+		DefaultURL:     "http://localhost:7878",
+		HealthEndpoint: "/api/v3/health",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityQueue, models.CapabilityUpdates},
+		New:            NewRadarrService,
+	})
 }
 
 func NewRadarrService() models.ServiceHealthChecker {
-	service := &RadarrService{}
-	service.Type = "radarr"
-	service.DisplayName = "Radarr"
-	service.Description = "Monitor and manage your Radarr instance"
-	service.DefaultURL = "http://localhost:7878"
-	service.HealthEndpoint = "/api/v3/health"
-	return service
+	return &RadarrService{ServiceCore: core.NewServiceCore("radarr")}
 }
 
 func (s *RadarrService) GetHealthEndpoint(baseURL string) string {
@@ -92,12 +95,11 @@ func (s *RadarrService) makeRequest(ctx context.Context, method, url, apiKey str
 	//	Str("url", url).
 	//	Msg("Making request to Radarr API")
 
-	client := &http.Client{}
-	return client.Do(req)
+	return core.Do(req)
 }
 
 // DeleteQueueItem deletes a queue item with the specified options
-func (s *RadarrService) DeleteQueueItem(baseURL, apiKey string, queueId string, options types.RadarrQueueDeleteOptions) error {
+func (s *RadarrService) DeleteQueueItem(ctx context.Context, baseURL, apiKey string, queueId string, options types.RadarrQueueDeleteOptions) error {
 	if baseURL == "" {
 		return &ErrRadarr{Op: "delete_queue", Err: fmt.Errorf("URL is required")}
 	}
@@ -106,7 +108,7 @@ func (s *RadarrService) DeleteQueueItem(baseURL, apiKey string, queueId string,
 		return &ErrRadarr{Op: "delete_queue", Err: fmt.Errorf("API key is required")}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	// Build delete URL with query parameters
@@ -169,8 +171,42 @@ func (s *RadarrService) DeleteQueueItem(baseURL, apiKey string, queueId string,
 	return nil
 }
 
+// GetQueueSize returns the total number of items in the Radarr queue
+func (s *RadarrService) GetQueueSize(ctx context.Context, url, apiKey string) (int, error) {
+	if url == "" {
+		return 0, &ErrRadarr{Op: "get_queue_size", Err: fmt.Errorf("URL is required")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	queueURL := fmt.Sprintf("%s/api/v3/queue?page=1&pageSize=1", strings.TrimRight(url, "/"))
+
+	resp, err := s.makeRequest(ctx, http.MethodGet, queueURL, apiKey, nil)
+	if err != nil {
+		return 0, &ErrRadarr{Op: "get_queue_size", Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, &ErrRadarr{Op: "get_queue_size", HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return 0, &ErrRadarr{Op: "get_queue_size", Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	var queue types.RadarrQueueResponse
+	if err := json.Unmarshal(body, &queue); err != nil {
+		return 0, &ErrRadarr{Op: "get_queue_size", Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	return queue.TotalRecords, nil
+}
+
 // GetQueue fetches the current queue from Radarr
-func (s *RadarrService) GetQueue(url, apiKey string) ([]types.RadarrQueueRecord, error) {
+func (s *RadarrService) GetQueue(ctx context.Context, url, apiKey string) ([]types.RadarrQueueRecord, error) {
 	if url == "" {
 		return nil, &ErrRadarr{Op: "get_queue", Err: fmt.Errorf("URL is required")}
 	}
@@ -179,7 +215,7 @@ func (s *RadarrService) GetQueue(url, apiKey string) ([]types.RadarrQueueRecord,
 		return nil, &ErrRadarr{Op: "get_queue", Err: fmt.Errorf("API key is required")}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
 	// Build queue URL with query parameters
@@ -288,7 +324,7 @@ func (s *RadarrService) GetMovie(baseURL, apiKey string, movieID int) (*types.Ra
 }
 
 // GetSystemStatus fetches the system status from Radarr
-func (s *RadarrService) GetSystemStatus(baseURL, apiKey string) (string, error) {
+func (s *RadarrService) GetSystemStatus(ctx context.Context, baseURL, apiKey string) (string, error) {
 	if baseURL == "" {
 		return "", &ErrRadarr{Op: "get_system_status", Err: fmt.Errorf("URL is required")}
 	}
@@ -299,7 +335,7 @@ func (s *RadarrService) GetSystemStatus(baseURL, apiKey string) (string, error)
 	}
 
 	statusURL := fmt.Sprintf("%s/api/v3/system/status", strings.TrimRight(baseURL, "/"))
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
 	resp, err := s.makeRequest(ctx, http.MethodGet, statusURL, apiKey, nil)
@@ -332,13 +368,13 @@ func (s *RadarrService) GetSystemStatus(baseURL, apiKey string) (string, error)
 }
 
 // CheckForUpdates checks if there are any updates available for Radarr
-func (s *RadarrService) CheckForUpdates(baseURL, apiKey string) (bool, error) {
+func (s *RadarrService) CheckForUpdates(ctx context.Context, baseURL, apiKey string) (bool, error) {
 	if baseURL == "" {
 		return false, &ErrRadarr{Op: "check_for_updates", Err: fmt.Errorf("URL is required")}
 	}
 
 	updateURL := fmt.Sprintf("%s/api/v3/update", strings.TrimRight(baseURL, "/"))
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
 	resp, err := s.makeRequest(ctx, http.MethodGet, updateURL, apiKey, nil)
@@ -371,22 +407,23 @@ func (s *RadarrService) CheckForUpdates(baseURL, apiKey string) (bool, error) {
 	return false, nil
 }
 
-func (s *RadarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, int) {
+func (s *RadarrService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
 	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
 
 	if url == "" {
-		return s.CreateHealthResponse(startTime, "error", "URL is required"), http.StatusBadRequest
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
 	}
 
 	// Create a context with timeout for the entire health check
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := core.HealthCheckContext(ctx, service, 3*time.Second)
 	defer cancel()
 
 	// Start version check in background
 	versionChan := make(chan string, 1)
 	versionErrChan := make(chan error, 1)
 	go func() {
-		version, err := s.GetSystemStatus(url, apiKey)
+		version, err := s.GetSystemStatus(ctx, url, apiKey)
 		versionChan <- version
 		versionErrChan <- err
 	}()
@@ -395,7 +432,7 @@ func (s *RadarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, i
 	updateChan := make(chan bool, 1)
 	updateErrChan := make(chan error, 1)
 	go func() {
-		hasUpdate, err := s.CheckForUpdates(url, apiKey)
+		hasUpdate, err := s.CheckForUpdates(ctx, url, apiKey)
 		updateChan <- hasUpdate
 		updateErrChan <- err
 	}()
@@ -404,7 +441,7 @@ func (s *RadarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, i
 	queueChan := make(chan []types.RadarrQueueRecord, 1)
 	queueErrChan := make(chan error, 1)
 	go func() {
-		queue, err := s.GetQueue(url, apiKey)
+		queue, err := s.GetQueue(ctx, url, apiKey)
 		queueChan <- queue
 		queueErrChan <- err
 	}()
@@ -413,7 +450,7 @@ func (s *RadarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, i
 	healthEndpoint := s.GetHealthEndpoint(url)
 	resp, err := s.makeRequest(ctx, http.MethodGet, healthEndpoint, apiKey, nil)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
 	}
 	defer resp.Body.Close()
 
@@ -422,7 +459,7 @@ func (s *RadarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, i
 
 	body, err := s.ReadBody(resp)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), nil
 	}
 
 	if resp.StatusCode >= 400 {
@@ -442,12 +479,12 @@ func (s *RadarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, i
 			message = "Service endpoint not found"
 		}
 
-		return s.CreateHealthResponse(startTime, status, message), http.StatusOK
+		return s.CreateHealthResponse(startTime, status, message), nil
 	}
 
 	var healthIssues []HealthResponse
 	if err := json.Unmarshal(body, &healthIssues); err != nil {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to parse response: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to parse response: %v", err)), nil
 	}
 
 	// Wait for version with timeout
@@ -531,9 +568,9 @@ func (s *RadarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, i
 
 	// If there are any warnings, return them all
 	if len(allWarnings) > 0 {
-		return s.CreateHealthResponse(startTime, "warning", strings.Join(allWarnings, "\n\n"), extras), http.StatusOK
+		return s.CreateHealthResponse(startTime, "warning", strings.Join(allWarnings, "\n\n"), extras), nil
 	}
 
 	// If no warnings, the service is healthy
-	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), http.StatusOK
+	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), nil
 }
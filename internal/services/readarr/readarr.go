@@ -0,0 +1,550 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package readarr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/core"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+// Custom error types for better error handling
+type ErrReadarr struct {
+	Op       string // Operation that failed
+	Err      error  // Underlying error
+	HttpCode int    // HTTP status code if applicable
+}
+
+func (e *ErrReadarr) Error() string {
+	if e.HttpCode > 0 {
+		return fmt.Sprintf("readarr %s: server returned %s (%d)", e.Op, http.StatusText(e.HttpCode), e.HttpCode)
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("readarr %s: %v", e.Op, e.Err)
+	}
+	return fmt.Sprintf("readarr %s", e.Op)
+}
+
+func (e *ErrReadarr) Unwrap() error {
+	return e.Err
+}
+
+type ReadarrService struct {
+	core.ServiceCore
+}
+
+type HealthResponse struct {
+	Source  string `json:"source"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	WikiURL string `json:"wikiUrl"`
+}
+
+type SystemStatusResponse struct {
+	Version string `json:"version"`
+}
+
+func init() {
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "readarr",
+		DisplayName:    "Readarr",
+		Description:    "Monitor and manage your Readarr instance",
+		DefaultURL:     "http://localhost:8787",
+		HealthEndpoint: "/api/v1/health",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityQueue, models.CapabilityUpdates},
+		New:            NewReadarrService,
+	})
+}
+
+func NewReadarrService() models.ServiceHealthChecker {
+	return &ReadarrService{ServiceCore: core.NewServiceCore("readarr")}
+}
+
+func (s *ReadarrService) GetHealthEndpoint(baseURL string) string {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return fmt.Sprintf("%s/api/v1/health", baseURL)
+}
+
+// makeRequest is a helper function to make requests with proper headers
+func (s *ReadarrService) makeRequest(ctx context.Context, method, url, apiKey string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Api-Key", apiKey)
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Content-Type", "application/json")
+
+	return core.Do(req)
+}
+
+// DeleteQueueItem deletes a queue item with the specified options
+func (s *ReadarrService) DeleteQueueItem(ctx context.Context, baseURL, apiKey string, queueId string, options types.ReadarrQueueDeleteOptions) error {
+	if baseURL == "" {
+		return &ErrReadarr{Op: "delete_queue", Err: fmt.Errorf("URL is required")}
+	}
+
+	if apiKey == "" {
+		return &ErrReadarr{Op: "delete_queue", Err: fmt.Errorf("API key is required")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	deleteURL := fmt.Sprintf("%s/api/v1/queue/%s?removeFromClient=%t&blocklist=%t&skipRedownload=%t",
+		strings.TrimRight(baseURL, "/"),
+		queueId,
+		options.RemoveFromClient,
+		options.Blocklist,
+		options.SkipRedownload)
+
+	if options.ChangeCategory {
+		deleteURL += "&changeCategory=true"
+	}
+
+	log.Info().
+		Str("url", deleteURL).
+		Str("queueId", queueId).
+		Bool("removeFromClient", options.RemoveFromClient).
+		Bool("blocklist", options.Blocklist).
+		Bool("skipRedownload", options.SkipRedownload).
+		Bool("changeCategory", options.ChangeCategory).
+		Msg("Attempting to delete queue item")
+
+	resp, err := s.makeRequest(ctx, http.MethodDelete, deleteURL, apiKey, nil)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("url", deleteURL).
+			Str("queueId", queueId).
+			Msg("Failed to execute delete request")
+		return &ErrReadarr{Op: "delete_queue", Err: fmt.Errorf("failed to execute request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := s.ReadBody(resp)
+		log.Error().
+			Int("statusCode", resp.StatusCode).
+			Str("url", deleteURL).
+			Str("queueId", queueId).
+			Str("response", string(body)).
+			Msg("Delete request failed")
+
+		var errorResponse struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Message != "" {
+			return &ErrReadarr{Op: "delete_queue", Err: fmt.Errorf("%s", errorResponse.Message), HttpCode: resp.StatusCode}
+		}
+		return &ErrReadarr{Op: "delete_queue", HttpCode: resp.StatusCode}
+	}
+
+	log.Info().
+		Str("queueId", queueId).
+		Msg("Successfully deleted queue item")
+
+	return nil
+}
+
+// getQueuePage fetches a single page of the Readarr queue
+func (s *ReadarrService) getQueuePage(ctx context.Context, op, url, apiKey string, pageSize int) (*types.ReadarrQueueResponse, error) {
+	if url == "" {
+		return nil, &ErrReadarr{Op: op, Err: fmt.Errorf("URL is required")}
+	}
+
+	if apiKey == "" {
+		return nil, &ErrReadarr{Op: op, Err: fmt.Errorf("API key is required")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	queueURL := fmt.Sprintf("%s/api/v1/queue?page=1&pageSize=%d&includeUnknownAuthorItems=false&includeAuthor=true&includeBook=true",
+		strings.TrimRight(url, "/"), pageSize)
+
+	resp, err := s.makeRequest(ctx, http.MethodGet, queueURL, apiKey, nil)
+	if err != nil {
+		return nil, &ErrReadarr{Op: op, Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ErrReadarr{Op: op, HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return nil, &ErrReadarr{Op: op, Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	var queue types.ReadarrQueueResponse
+	if err := json.Unmarshal(body, &queue); err != nil {
+		return nil, &ErrReadarr{Op: op, Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	return &queue, nil
+}
+
+// GetQueueSize returns the total number of items in the Readarr queue
+func (s *ReadarrService) GetQueueSize(ctx context.Context, url, apiKey string) (int, error) {
+	queue, err := s.getQueuePage(ctx, "get_queue_size", url, apiKey, 1)
+	if err != nil {
+		return 0, err
+	}
+	return queue.TotalRecords, nil
+}
+
+// GetQueue fetches the current queue from Readarr with the author and book
+// title of every record resolved
+func (s *ReadarrService) GetQueue(ctx context.Context, url, apiKey string) (*types.ReadarrQueueResponse, error) {
+	queue, err := s.getQueuePage(ctx, "get_queue", url, apiKey, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	s.resolveTitles(ctx, url, apiKey, queue.Records)
+	return queue, nil
+}
+
+// resolveTitles fills in the author name and book title of queue records.
+// Older Readarr versions do not embed the author and book in the queue, those
+// are looked up once per ID. Lookup failures leave the release name as is.
+func (s *ReadarrService) resolveTitles(ctx context.Context, url, apiKey string, records []types.ReadarrQueueRecord) {
+	books := make(map[int]*types.ReadarrBookResponse)
+	authors := make(map[int]*types.ReadarrAuthorResponse)
+
+	for i := range records {
+		record := &records[i]
+
+		if record.Book == nil && record.BookID > 0 {
+			book, cached := books[record.BookID]
+			if !cached {
+				book, _ = s.GetBook(ctx, url, apiKey, record.BookID)
+				books[record.BookID] = book
+			}
+			record.Book = book
+		}
+
+		if record.Author == nil && record.Book != nil && record.Book.Author != nil {
+			record.Author = record.Book.Author
+		}
+
+		if record.Author == nil && record.AuthorID > 0 {
+			author, cached := authors[record.AuthorID]
+			if !cached {
+				author, _ = s.GetAuthor(ctx, url, apiKey, record.AuthorID)
+				authors[record.AuthorID] = author
+			}
+			record.Author = author
+		}
+
+		if record.Book != nil {
+			record.BookTitle = record.Book.Title
+		}
+		if record.Author != nil {
+			record.AuthorName = record.Author.AuthorName
+		} else if record.Book != nil {
+			record.AuthorName = record.Book.AuthorTitle
+		}
+	}
+}
+
+// getJSON fetches a Readarr API path and decodes the response into v
+func (s *ReadarrService) getJSON(ctx context.Context, op, baseURL, apiKey, path string, v interface{}) error {
+	if baseURL == "" {
+		return &ErrReadarr{Op: op, Err: fmt.Errorf("URL is required")}
+	}
+
+	if apiKey == "" {
+		return &ErrReadarr{Op: op, Err: fmt.Errorf("API key is required")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp, err := s.makeRequest(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+path, apiKey, nil)
+	if err != nil {
+		return &ErrReadarr{Op: op, Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &ErrReadarr{Op: op, HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return &ErrReadarr{Op: op, Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return &ErrReadarr{Op: op, Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	return nil
+}
+
+// LookupAuthor searches authors by name or foreign ID, e.g. "goodreads:1234"
+func (s *ReadarrService) LookupAuthor(ctx context.Context, baseURL, apiKey, term string) ([]types.ReadarrAuthorResponse, error) {
+	var authors []types.ReadarrAuthorResponse
+	path := "/api/v1/author/lookup?term=" + neturl.QueryEscape(term)
+	if err := s.getJSON(ctx, "lookup_author", baseURL, apiKey, path, &authors); err != nil {
+		return nil, err
+	}
+	return authors, nil
+}
+
+// LookupBook searches books by title or identifier, e.g. "isbn:9780547928227"
+func (s *ReadarrService) LookupBook(ctx context.Context, baseURL, apiKey, term string) ([]types.ReadarrBookResponse, error) {
+	var books []types.ReadarrBookResponse
+	path := "/api/v1/book/lookup?term=" + neturl.QueryEscape(term)
+	if err := s.getJSON(ctx, "lookup_book", baseURL, apiKey, path, &books); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// GetAuthor fetches author details from Readarr by ID
+func (s *ReadarrService) GetAuthor(ctx context.Context, baseURL, apiKey string, authorID int) (*types.ReadarrAuthorResponse, error) {
+	var author types.ReadarrAuthorResponse
+	if err := s.getJSON(ctx, "get_author", baseURL, apiKey, fmt.Sprintf("/api/v1/author/%d", authorID), &author); err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// GetBook fetches book details from Readarr by ID
+func (s *ReadarrService) GetBook(ctx context.Context, baseURL, apiKey string, bookID int) (*types.ReadarrBookResponse, error) {
+	var book types.ReadarrBookResponse
+	if err := s.getJSON(ctx, "get_book", baseURL, apiKey, fmt.Sprintf("/api/v1/book/%d", bookID), &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (s *ReadarrService) getSystemStatus(ctx context.Context, baseURL, apiKey string) (string, error) {
+	if baseURL == "" {
+		return "", &ErrReadarr{Op: "get_system_status", Err: fmt.Errorf("URL is required")}
+	}
+
+	// Check cache first
+	if version := s.GetVersionFromCache(baseURL); version != "" {
+		return version, nil
+	}
+
+	statusURL := fmt.Sprintf("%s/api/v1/system/status", strings.TrimRight(baseURL, "/"))
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	resp, err := s.makeRequest(ctx, http.MethodGet, statusURL, apiKey, nil)
+	if err != nil {
+		return "", &ErrReadarr{Op: "get_system_status", Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &ErrReadarr{Op: "get_system_status", HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return "", &ErrReadarr{Op: "get_system_status", Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	var status SystemStatusResponse
+	if err := json.Unmarshal(body, &status); err != nil {
+		return "", &ErrReadarr{Op: "get_system_status", Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	// Cache version for 1 hour
+	if err := s.CacheVersion(baseURL, status.Version, time.Hour); err != nil {
+		log.Warn().Err(err).Msg("Failed to cache Readarr version")
+	}
+
+	return status.Version, nil
+}
+
+// CheckForUpdates checks if there are any updates available for Readarr
+func (s *ReadarrService) CheckForUpdates(ctx context.Context, baseURL, apiKey string) (bool, error) {
+	if baseURL == "" {
+		return false, &ErrReadarr{Op: "check_for_updates", Err: fmt.Errorf("URL is required")}
+	}
+
+	updateURL := fmt.Sprintf("%s/api/v1/update", strings.TrimRight(baseURL, "/"))
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	resp, err := s.makeRequest(ctx, http.MethodGet, updateURL, apiKey, nil)
+	if err != nil {
+		return false, &ErrReadarr{Op: "check_for_updates", Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, &ErrReadarr{Op: "check_for_updates", HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return false, &ErrReadarr{Op: "check_for_updates", Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	var updates []types.UpdateResponse
+	if err := json.Unmarshal(body, &updates); err != nil {
+		return false, &ErrReadarr{Op: "check_for_updates", Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	for _, update := range updates {
+		if !update.Installed && update.Installable {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *ReadarrService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
+	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
+
+	if url == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
+	}
+
+	// Create a context with timeout for the entire health check
+	ctx, cancel := core.HealthCheckContext(ctx, service, 3*time.Second)
+	defer cancel()
+
+	// Start version check in background
+	versionChan := make(chan string, 1)
+	versionErrChan := make(chan error, 1)
+	go func() {
+		version, err := s.getSystemStatus(ctx, url, apiKey)
+		versionChan <- version
+		versionErrChan <- err
+	}()
+
+	// Start update check in background
+	updateChan := make(chan bool, 1)
+	updateErrChan := make(chan error, 1)
+	go func() {
+		hasUpdate, err := s.CheckForUpdates(ctx, url, apiKey)
+		updateChan <- hasUpdate
+		updateErrChan <- err
+	}()
+
+	// Perform health check
+	resp, err := s.makeRequest(ctx, http.MethodGet, s.GetHealthEndpoint(url), apiKey, nil)
+	if err != nil {
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
+	}
+	defer resp.Body.Close()
+
+	responseTime, _ := time.ParseDuration(resp.Header.Get("X-Response-Time") + "ms")
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), nil
+	}
+
+	if resp.StatusCode >= 400 {
+		statusText := http.StatusText(resp.StatusCode)
+		message := fmt.Sprintf("Server returned %s (%d)", statusText, resp.StatusCode)
+
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			message = fmt.Sprintf("Service is temporarily unavailable (%d %s)", resp.StatusCode, statusText)
+		case http.StatusUnauthorized:
+			message = "Invalid API key"
+		case http.StatusForbidden:
+			message = "Access forbidden"
+		case http.StatusNotFound:
+			message = "Service endpoint not found"
+		}
+
+		return s.CreateHealthResponse(startTime, "error", message), nil
+	}
+
+	var healthIssues []HealthResponse
+	if err := json.Unmarshal(body, &healthIssues); err != nil {
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to parse response: %v", err)), nil
+	}
+
+	// Wait for version with timeout
+	var version string
+	var versionErr error
+	select {
+	case version = <-versionChan:
+		versionErr = <-versionErrChan
+	case <-time.After(500 * time.Millisecond):
+		// Continue without version if it takes too long
+	}
+
+	// Wait for update check with timeout
+	var updateAvailable bool
+	var updateErr error
+	select {
+	case updateAvailable = <-updateChan:
+		updateErr = <-updateErrChan
+	case <-time.After(500 * time.Millisecond):
+		// Continue without update check if it takes too long
+	}
+
+	extras := map[string]interface{}{
+		"responseTime": responseTime.Milliseconds(),
+	}
+
+	if version != "" {
+		extras["version"] = version
+	}
+	if versionErr != nil {
+		extras["versionError"] = versionErr.Error()
+	}
+
+	if updateAvailable {
+		extras["updateAvailable"] = true
+	}
+	if updateErr != nil {
+		extras["updateError"] = updateErr.Error()
+	}
+
+	var allWarnings []string
+	for _, issue := range healthIssues {
+		if issue.Type != "warning" && issue.Type != "error" {
+			continue
+		}
+
+		warning := issue.Message
+		if issue.WikiURL != "" {
+			warning += fmt.Sprintf("\nWiki: %s", issue.WikiURL)
+		}
+		if issue.Source != "" &&
+			issue.Source != "IndexerLongTermStatusCheck" &&
+			issue.Source != "NotificationStatusCheck" {
+			warning = fmt.Sprintf("[%s] %s", issue.Source, warning)
+		}
+
+		allWarnings = append(allWarnings, warning)
+	}
+
+	if len(allWarnings) > 0 {
+		return s.CreateHealthResponse(startTime, "warning", strings.Join(allWarnings, "\n\n"), extras), nil
+	}
+
+	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), nil
+}
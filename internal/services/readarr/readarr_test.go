@@ -0,0 +1,74 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package readarr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetQueue_ResolvesTitles(t *testing.T) {
+	var bookLookups atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("X-Api-Key"))
+
+		switch r.URL.Path {
+		case "/api/v1/queue":
+			w.Write([]byte(`{"totalRecords": 3, "records": [
+				{"id": 1, "title": "Embedded.Release", "authorId": 1, "bookId": 1,
+				 "author": {"id": 1, "authorName": "Ursula K. Le Guin"},
+				 "book": {"id": 1, "title": "The Dispossessed"}},
+				{"id": 2, "title": "Raw.Release.epub", "authorId": 2, "bookId": 2},
+				{"id": 3, "title": "Another.Release.epub", "authorId": 2, "bookId": 2}
+			]}`))
+		case "/api/v1/book/2":
+			bookLookups.Add(1)
+			w.Write([]byte(`{"id": 2, "title": "Kindred", "authorId": 2, "author": {"id": 2, "authorName": "Octavia E. Butler"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	service := &ReadarrService{}
+	queue, err := service.GetQueue(context.Background(), server.URL, "test-key")
+	require.NoError(t, err)
+	require.Len(t, queue.Records, 3)
+
+	assert.Equal(t, "Ursula K. Le Guin", queue.Records[0].AuthorName)
+	assert.Equal(t, "The Dispossessed", queue.Records[0].BookTitle)
+
+	assert.Equal(t, "Octavia E. Butler", queue.Records[1].AuthorName)
+	assert.Equal(t, "Kindred", queue.Records[1].BookTitle)
+	assert.Equal(t, "Kindred", queue.Records[2].BookTitle)
+
+	// Records sharing a book only look it up once
+	assert.Equal(t, int32(1), bookLookups.Load())
+}
+
+func TestGetQueue_KeepsReleaseNameWhenLookupFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/queue" {
+			w.Write([]byte(`{"totalRecords": 1, "records": [{"id": 1, "title": "Raw.Release.epub", "authorId": 5, "bookId": 9}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	queue, err := (&ReadarrService{}).GetQueue(context.Background(), server.URL, "test-key")
+	require.NoError(t, err)
+	require.Len(t, queue.Records, 1)
+
+	assert.Equal(t, "Raw.Release.epub", queue.Records[0].Title)
+	assert.Empty(t, queue.Records[0].AuthorName)
+	assert.Empty(t, queue.Records[0].BookTitle)
+}
@@ -0,0 +1,345 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package sabnzbd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/core"
+	"github.com/autobrr/dashbrr/internal/services/downloadclient"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+const clientType = "sabnzbd"
+
+type SabnzbdService struct {
+	core.ServiceCore
+}
+
+// apiResponse holds the fields every API response may carry on failure
+type apiResponse struct {
+	Status *bool  `json:"status"`
+	Error  string `json:"error"`
+}
+
+type versionResponse struct {
+	Version string `json:"version"`
+}
+
+type queueResponse struct {
+	Queue struct {
+		Paused   bool   `json:"paused"`
+		KBPerSec string `json:"kbpersec"`
+		MBLeft   string `json:"mbleft"`
+		TimeLeft string `json:"timeleft"`
+		Slots    []struct {
+			NzoID      string `json:"nzo_id"`
+			Filename   string `json:"filename"`
+			Category   string `json:"cat"`
+			Status     string `json:"status"`
+			MB         string `json:"mb"`
+			MBLeft     string `json:"mbleft"`
+			Percentage string `json:"percentage"`
+			TimeLeft   string `json:"timeleft"`
+		} `json:"slots"`
+	} `json:"queue"`
+}
+
+type historyResponse struct {
+	History struct {
+		Slots []struct {
+			NzoID       string `json:"nzo_id"`
+			Name        string `json:"name"`
+			Category    string `json:"category"`
+			Status      string `json:"status"`
+			FailMessage string `json:"fail_message"`
+			Bytes       int64  `json:"bytes"`
+			Completed   int64  `json:"completed"`
+		} `json:"slots"`
+	} `json:"history"`
+}
+
+type warningsResponse struct {
+	Warnings []json.RawMessage `json:"warnings"`
+}
+
+func init() {
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           clientType,
+		DisplayName:    "SABnzbd",
+		Description:    "Monitor the download queue of your SABnzbd client",
+		DefaultURL:     "http://localhost:8080",
+		HealthEndpoint: "/api?mode=version",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityQueue},
+		New:            NewSabnzbdService,
+	})
+}
+
+func NewSabnzbdService() models.ServiceHealthChecker {
+	return &SabnzbdService{ServiceCore: core.NewServiceCore(clientType)}
+}
+
+// call runs an API mode and decodes the response into v
+func (s *SabnzbdService) call(ctx context.Context, baseURL, apiKey, mode string, params neturl.Values, v interface{}) error {
+	if baseURL == "" {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: mode, Err: fmt.Errorf("URL is required")}
+	}
+
+	if params == nil {
+		params = neturl.Values{}
+	}
+	params.Set("mode", mode)
+	params.Set("apikey", apiKey)
+	params.Set("output", "json")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+"/api?"+params.Encode(), nil)
+	if err != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: mode, Err: fmt.Errorf("failed to create request: %w", err)}
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := core.Do(req)
+	if err != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: mode, Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: mode, HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: mode, Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	// Errors such as a wrong API key are reported with status 200
+	var result apiResponse
+	if err := json.Unmarshal(body, &result); err == nil && result.Status != nil && !*result.Status {
+		if strings.Contains(strings.ToLower(result.Error), "api key") {
+			return &downloadclient.ErrDownloadClient{Client: clientType, Op: mode, HttpCode: http.StatusUnauthorized}
+		}
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: mode, Err: fmt.Errorf("%s", result.Error)}
+	}
+
+	if v == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: mode, Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+	return nil
+}
+
+// GetVersion returns the SABnzbd version, cached for an hour
+func (s *SabnzbdService) GetVersion(ctx context.Context, baseURL, apiKey string) (string, error) {
+	if version := s.GetVersionFromCache(baseURL); version != "" {
+		return version, nil
+	}
+
+	var version versionResponse
+	if err := s.call(ctx, baseURL, apiKey, "version", nil, &version); err != nil {
+		return "", err
+	}
+
+	if err := s.CacheVersion(baseURL, version.Version, time.Hour); err != nil {
+		log.Warn().Err(err).Msg("Failed to cache SABnzbd version")
+	}
+
+	return version.Version, nil
+}
+
+// megabytes converts the MB strings of the queue to bytes
+func megabytes(value string) int64 {
+	mb, _ := strconv.ParseFloat(value, 64)
+	return int64(mb * 1024 * 1024)
+}
+
+// parseTimeLeft converts a [days:]hours:minutes:seconds duration to seconds
+func parseTimeLeft(value string) int64 {
+	parts := strings.Split(value, ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return 0
+	}
+
+	units := []int64{24 * 3600, 3600, 60, 1}[4-len(parts):]
+	var seconds int64
+	for i, part := range parts {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return 0
+		}
+		seconds += n * units[i]
+	}
+	return seconds
+}
+
+func (s *SabnzbdService) GetQueue(ctx context.Context, baseURL, apiKey string) (*types.UsenetQueue, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var response queueResponse
+	if err := s.call(ctx, baseURL, apiKey, "queue", nil, &response); err != nil {
+		return nil, err
+	}
+
+	kbPerSec, _ := strconv.ParseFloat(response.Queue.KBPerSec, 64)
+	queue := &types.UsenetQueue{
+		ClientType: clientType,
+		Paused:     response.Queue.Paused,
+		Speed:      int64(kbPerSec * 1024),
+		SizeLeft:   megabytes(response.Queue.MBLeft),
+		TimeLeft:   parseTimeLeft(response.Queue.TimeLeft),
+		Items:      make([]types.UsenetQueueItem, 0, len(response.Queue.Slots)),
+	}
+
+	for _, slot := range response.Queue.Slots {
+		percentage, _ := strconv.Atoi(slot.Percentage)
+		queue.Items = append(queue.Items, types.UsenetQueueItem{
+			ID:         slot.NzoID,
+			Name:       slot.Filename,
+			Category:   slot.Category,
+			Status:     slot.Status,
+			Size:       megabytes(slot.MB),
+			SizeLeft:   megabytes(slot.MBLeft),
+			Percentage: percentage,
+			TimeLeft:   parseTimeLeft(slot.TimeLeft),
+			Paused:     slot.Status == "Paused",
+		})
+	}
+
+	return queue, nil
+}
+
+func (s *SabnzbdService) GetFailedHistory(ctx context.Context, baseURL, apiKey string, limit int) ([]types.UsenetHistoryItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	params := neturl.Values{
+		"failed_only": {"1"},
+		"limit":       {strconv.Itoa(limit)},
+	}
+
+	var response historyResponse
+	if err := s.call(ctx, baseURL, apiKey, "history", params, &response); err != nil {
+		return nil, err
+	}
+
+	history := make([]types.UsenetHistoryItem, 0, len(response.History.Slots))
+	for _, slot := range response.History.Slots {
+		history = append(history, types.UsenetHistoryItem{
+			ID:          slot.NzoID,
+			Name:        slot.Name,
+			Category:    slot.Category,
+			Status:      slot.Status,
+			FailMessage: slot.FailMessage,
+			Size:        slot.Bytes,
+			CompletedAt: time.Unix(slot.Completed, 0).UTC(),
+		})
+	}
+
+	return history, nil
+}
+
+func (s *SabnzbdService) GetWarnings(ctx context.Context, baseURL, apiKey string) ([]types.UsenetWarning, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var response warningsResponse
+	if err := s.call(ctx, baseURL, apiKey, "warnings", nil, &response); err != nil {
+		return nil, err
+	}
+
+	warnings := make([]types.UsenetWarning, 0, len(response.Warnings))
+	for _, raw := range response.Warnings {
+		var warning struct {
+			Text string `json:"text"`
+			Type string `json:"type"`
+			Time int64  `json:"time"`
+		}
+		if err := json.Unmarshal(raw, &warning); err != nil {
+			// Releases before 3.0 return plain strings
+			if err := json.Unmarshal(raw, &warning.Text); err != nil {
+				continue
+			}
+			warning.Type = "WARNING"
+		}
+
+		converted := types.UsenetWarning{Type: warning.Type, Text: warning.Text}
+		if warning.Time > 0 {
+			converted.Time = time.Unix(warning.Time, 0).UTC()
+		}
+		warnings = append(warnings, converted)
+	}
+
+	return warnings, nil
+}
+
+func (s *SabnzbdService) PauseQueue(ctx context.Context, baseURL, apiKey string) error {
+	return s.call(ctx, baseURL, apiKey, "pause", nil, nil)
+}
+
+func (s *SabnzbdService) ResumeQueue(ctx context.Context, baseURL, apiKey string) error {
+	return s.call(ctx, baseURL, apiKey, "resume", nil, nil)
+}
+
+func (s *SabnzbdService) RetryItem(ctx context.Context, baseURL, apiKey, id string) error {
+	return s.call(ctx, baseURL, apiKey, "retry", neturl.Values{"value": {id}}, nil)
+}
+
+func (s *SabnzbdService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
+	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
+
+	if url == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
+	}
+
+	if apiKey == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "API key is required")
+	}
+
+	ctx, cancel := core.HealthCheckContext(ctx, service, 10*time.Second)
+	defer cancel()
+
+	version, err := s.GetVersion(ctx, url, apiKey)
+	if err != nil {
+		return s.errorResponse(startTime, err), nil
+	}
+
+	// The version mode does not check the API key, the queue does
+	queue, err := s.GetQueue(ctx, url, apiKey)
+	if err != nil {
+		return s.errorResponse(startTime, err), nil
+	}
+
+	// Warnings are informational, a failure to list them does not affect health
+	warnings, _ := s.GetWarnings(ctx, url, apiKey)
+
+	status, message, extras := downloadclient.UsenetHealthResponse(clientType, version, queue, warnings)
+	return s.CreateHealthResponse(startTime, status, message, extras), nil
+}
+
+// errorResponse converts a failed request into a health response
+func (s *SabnzbdService) errorResponse(startTime time.Time, err error) models.ServiceHealth {
+	if clientErr, ok := err.(*downloadclient.ErrDownloadClient); ok && clientErr.HttpCode > 0 {
+		message := fmt.Sprintf("Server returned %s (%d)", http.StatusText(clientErr.HttpCode), clientErr.HttpCode)
+		if clientErr.HttpCode == http.StatusUnauthorized || clientErr.HttpCode == http.StatusForbidden {
+			message = "Invalid API key"
+		}
+		return s.CreateHealthResponse(startTime, "error", message)
+	}
+	return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err))
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package sabnzbd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/services/downloadclient"
+)
+
+func TestParseTimeLeft(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{"0:01:30", 90},
+		{"2:00:05", 7205},
+		{"1:02:00:00", 93600},
+		{"", 0},
+		{"unknown", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseTimeLeft(tt.value))
+		})
+	}
+}
+
+func TestGetQueue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api", r.URL.Path)
+		assert.Equal(t, "queue", r.URL.Query().Get("mode"))
+		assert.Equal(t, "test-key", r.URL.Query().Get("apikey"))
+
+		w.Write([]byte(`{"queue": {
+			"paused": false,
+			"kbpersec": "1024.00",
+			"mbleft": "150.5",
+			"timeleft": "0:02:30",
+			"slots": [
+				{"nzo_id": "SABnzbd_nzo_1", "filename": "Some.Show.S01E01", "cat": "tv", "status": "Downloading", "mb": "200.0", "mbleft": "100.0", "percentage": "50", "timeleft": "0:01:40"},
+				{"nzo_id": "SABnzbd_nzo_2", "filename": "Some.Movie", "cat": "movies", "status": "Paused", "mb": "50.5", "mbleft": "50.5", "percentage": "0", "timeleft": "0:00:00"}
+			]
+		}}`))
+	}))
+	defer server.Close()
+
+	queue, err := (&SabnzbdService{}).GetQueue(context.Background(), server.URL, "test-key")
+	require.NoError(t, err)
+
+	assert.Equal(t, clientType, queue.ClientType)
+	assert.False(t, queue.Paused)
+	assert.Equal(t, int64(1024*1024), queue.Speed)
+	assert.Equal(t, int64(150), queue.TimeLeft)
+	require.Len(t, queue.Items, 2)
+	assert.Equal(t, "SABnzbd_nzo_1", queue.Items[0].ID)
+	assert.Equal(t, 50, queue.Items[0].Percentage)
+	assert.Equal(t, int64(100), queue.Items[0].TimeLeft)
+	assert.Equal(t, int64(200*1024*1024), queue.Items[0].Size)
+	assert.True(t, queue.Items[1].Paused)
+}
+
+func TestGetQueue_InvalidAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": false, "error": "API Key Incorrect"}`))
+	}))
+	defer server.Close()
+
+	_, err := (&SabnzbdService{}).GetQueue(context.Background(), server.URL, "wrong")
+	require.Error(t, err)
+
+	clientErr, ok := err.(*downloadclient.ErrDownloadClient)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, clientErr.HttpCode)
+}
+
+func TestGetWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantType string
+		wantText string
+	}{
+		{
+			name:     "Objects",
+			response: `{"warnings": [{"text": "Server unreachable", "type": "ERROR", "time": 1700000000}]}`,
+			wantType: "ERROR",
+			wantText: "Server unreachable",
+		},
+		{
+			name:     "Plain strings",
+			response: `{"warnings": ["Disk almost full"]}`,
+			wantType: "WARNING",
+			wantText: "Disk almost full",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "warnings", r.URL.Query().Get("mode"))
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			warnings, err := (&SabnzbdService{}).GetWarnings(context.Background(), server.URL, "test-key")
+			require.NoError(t, err)
+			require.Len(t, warnings, 1)
+			assert.Equal(t, tt.wantType, warnings[0].Type)
+			assert.Equal(t, tt.wantText, warnings[0].Text)
+		})
+	}
+}
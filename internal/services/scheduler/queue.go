@@ -0,0 +1,48 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package scheduler
+
+import (
+	"time"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+// entry is the scheduling state of a single service instance
+type entry struct {
+	service  models.ServiceConfiguration
+	next     time.Time
+	failures int
+	running  bool
+	index    int // position in the queue, -1 while not queued
+}
+
+// queue is a min-heap of entries ordered by their next due time
+type queue []*entry
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+
+func (q queue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *queue) Push(x any) {
+	e := x.(*entry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *queue) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*q = old[:n-1]
+	return e
+}
@@ -0,0 +1,392 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+const (
+	// DefaultInterval is used for instances without a configured interval
+	DefaultInterval = 30 * time.Second
+	// MaxBackoff caps the delay between checks of a service that stays offline
+	MaxBackoff = 10 * time.Minute
+
+	syncInterval        = 30 * time.Second
+	maxConcurrentChecks = 10
+)
+
+// ErrUnknownService is returned by CheckNow for instances that are not configured
+var ErrUnknownService = errors.New("service is not configured")
+
+// ServiceStore defines the database operations needed by the scheduler
+type ServiceStore interface {
+	GetAllServices() ([]models.ServiceConfiguration, error)
+}
+
+// CheckFunc performs a single health check of a service
+type CheckFunc func(ctx context.Context, service models.ServiceConfiguration) models.ServiceHealth
+
+// ResultFunc receives the result of every completed health check
+type ResultFunc func(health models.ServiceHealth)
+
+// SyncFunc receives the services loaded by every sync
+type SyncFunc func(services []models.ServiceConfiguration)
+
+// Scheduler runs health checks for every configured service on its own
+// interval. Due checks are kept in a priority queue ordered by their next run.
+type Scheduler struct {
+	store    ServiceStore
+	check    CheckFunc
+	onResult ResultFunc
+
+	mu      sync.Mutex
+	onSync  []SyncFunc
+	entries map[string]*entry
+	queue   queue
+	wake    chan struct{}
+	sem     chan struct{}
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New creates a scheduler for the services in store
+func New(store ServiceStore, check CheckFunc, onResult ResultFunc) *Scheduler {
+	return &Scheduler{
+		store:    store,
+		check:    check,
+		onResult: onResult,
+		entries:  make(map[string]*entry),
+		wake:     make(chan struct{}, 1),
+		sem:      make(chan struct{}, maxConcurrentChecks),
+	}
+}
+
+// Start loads the configured services and begins running checks until Stop is
+// called or ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	if err := s.Sync(); err != nil {
+		log.Error().Err(err).Msg("Failed to load services for health checks")
+	}
+
+	s.wg.Add(1)
+	go s.run(runCtx)
+}
+
+// Stop cancels all running checks and waits for them to return
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// OnSync registers fn to be called with the services every time they are
+// reloaded, so state kept for the configured services can follow changes
+func (s *Scheduler) OnSync(fn SyncFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onSync = append(s.onSync, fn)
+}
+
+// Sync reloads the services from the store. New instances are checked right
+// away, removed instances are dropped and changed settings apply from the
+// next run.
+func (s *Scheduler) Sync() error {
+	services, err := s.store.GetAllServices()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	hooks := s.onSync
+	s.mu.Unlock()
+	for _, fn := range hooks {
+		fn(services)
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool, len(services))
+
+	s.mu.Lock()
+	for _, service := range services {
+		if service.URL == "" {
+			continue
+		}
+		seen[service.InstanceID] = true
+
+		e, exists := s.entries[service.InstanceID]
+		if !exists {
+			e = &entry{service: service, next: now.Add(jitter(service)), index: -1}
+			s.entries[service.InstanceID] = e
+			heap.Push(&s.queue, e)
+			continue
+		}
+
+		e.service = service
+		// A shorter interval should not wait for the previously scheduled run
+		if e.index >= 0 && e.failures == 0 {
+			if due := now.Add(interval(service)); due.Before(e.next) {
+				e.next = due
+				heap.Fix(&s.queue, e.index)
+			}
+		}
+	}
+
+	for instanceID, e := range s.entries {
+		if seen[instanceID] {
+			continue
+		}
+		delete(s.entries, instanceID)
+		if e.index >= 0 {
+			heap.Remove(&s.queue, e.index)
+		}
+	}
+	s.mu.Unlock()
+
+	s.notify()
+	return nil
+}
+
+// CheckNow runs a health check for the instance immediately and reschedules
+// its next run relative to now
+func (s *Scheduler) CheckNow(ctx context.Context, instanceID string) (models.ServiceHealth, error) {
+	e := s.lookup(instanceID)
+	if e == nil {
+		// The service may have been added since the last sync
+		if err := s.Sync(); err != nil {
+			return models.ServiceHealth{}, err
+		}
+		if e = s.lookup(instanceID); e == nil {
+			return models.ServiceHealth{}, ErrUnknownService
+		}
+	}
+
+	s.mu.Lock()
+	// A scheduled run already in flight owns the queue position
+	owned := !e.running
+	if owned {
+		if e.index >= 0 {
+			heap.Remove(&s.queue, e.index)
+		}
+		e.running = true
+	}
+	service := e.service
+	s.mu.Unlock()
+
+	health := s.check(ctx, service)
+	health.ServiceID = instanceID
+
+	if owned {
+		s.complete(e, health, ctx.Err() == nil)
+	}
+	if ctx.Err() != nil {
+		return health, ctx.Err()
+	}
+
+	if s.onResult != nil {
+		s.onResult(health)
+	}
+	return health, nil
+}
+
+// NextCheck returns when the instance is due to be checked next
+func (s *Scheduler) NextCheck(instanceID string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, exists := s.entries[instanceID]
+	if !exists || e.index < 0 {
+		return time.Time{}, false
+	}
+	return e.next, true
+}
+
+func (s *Scheduler) lookup(instanceID string) *entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries[instanceID]
+}
+
+// run dispatches due checks and periodically picks up configuration changes
+func (s *Scheduler) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	syncTicker := time.NewTicker(syncInterval)
+	defer syncTicker.Stop()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		for _, e := range s.popDue(time.Now()) {
+			s.dispatch(ctx, e)
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(s.untilNext())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-s.wake:
+		case <-syncTicker.C:
+			if err := s.Sync(); err != nil {
+				log.Error().Err(err).Msg("Failed to reload services for health checks")
+			}
+		}
+	}
+}
+
+// popDue removes every entry that is due from the queue and marks it running
+func (s *Scheduler) popDue(now time.Time) []*entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*entry
+	for s.queue.Len() > 0 && !s.queue[0].next.After(now) {
+		e := heap.Pop(&s.queue).(*entry)
+		e.running = true
+		due = append(due, e)
+	}
+	return due
+}
+
+// untilNext returns the time until the next queued check is due
+func (s *Scheduler) untilNext() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.queue.Len() == 0 {
+		return syncInterval
+	}
+	return time.Until(s.queue[0].next)
+}
+
+func (s *Scheduler) dispatch(ctx context.Context, e *entry) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		select {
+		case s.sem <- struct{}{}:
+			defer func() { <-s.sem }()
+		case <-ctx.Done():
+			return
+		}
+
+		s.mu.Lock()
+		service := e.service
+		s.mu.Unlock()
+
+		health := s.check(ctx, service)
+		health.ServiceID = service.InstanceID
+
+		// A check aborted by shutdown says nothing about the service
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.complete(e, health, true)
+		if s.onResult != nil {
+			s.onResult(health)
+		}
+	}()
+}
+
+// complete requeues an entry after its check finished. Failed checks back off
+// exponentially while the service stays offline.
+func (s *Scheduler) complete(e *entry, health models.ServiceHealth, counted bool) {
+	s.mu.Lock()
+	e.running = false
+	if s.entries[e.service.InstanceID] != e {
+		// Removed while the check was running
+		s.mu.Unlock()
+		return
+	}
+
+	if counted {
+		if isFailure(health.Status) {
+			e.failures++
+		} else {
+			e.failures = 0
+		}
+	}
+	e.next = time.Now().Add(delay(e.service, e.failures))
+	heap.Push(&s.queue, e)
+	s.mu.Unlock()
+
+	s.notify()
+}
+
+// notify wakes the run loop so it picks up queue changes
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func isFailure(status string) bool {
+	return status == "offline" || status == "error"
+}
+
+// interval returns the configured check interval of a service
+func interval(service models.ServiceConfiguration) time.Duration {
+	if service.Options.Interval > 0 {
+		return time.Duration(service.Options.Interval) * time.Second
+	}
+	return DefaultInterval
+}
+
+// jitter returns a random delay within the configured jitter of a service
+func jitter(service models.ServiceConfiguration) time.Duration {
+	if service.Options.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(time.Duration(service.Options.Jitter) * time.Second)))
+}
+
+// delay returns the time until the next check after the given number of
+// consecutive failures. The first failure keeps the regular interval so a
+// short outage is noticed quickly, every further one doubles it.
+func delay(service models.ServiceConfiguration, failures int) time.Duration {
+	base := interval(service)
+	d := base
+	for i := 1; i < failures && d < MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > MaxBackoff {
+		d = max(MaxBackoff, base)
+	}
+	return d + jitter(service)
+}
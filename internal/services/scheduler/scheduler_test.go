@@ -0,0 +1,183 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+type mockStore struct {
+	mu       sync.Mutex
+	services []models.ServiceConfiguration
+}
+
+func (m *mockStore) GetAllServices() ([]models.ServiceConfiguration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.services, nil
+}
+
+func (m *mockStore) set(services ...models.ServiceConfiguration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.services = services
+}
+
+func TestDelay(t *testing.T) {
+	service := models.ServiceConfiguration{Options: models.ServiceOptions{Interval: 60}}
+
+	assert.Equal(t, time.Minute, delay(service, 0))
+	assert.Equal(t, time.Minute, delay(service, 1))
+	assert.Equal(t, 2*time.Minute, delay(service, 2))
+	assert.Equal(t, 4*time.Minute, delay(service, 3))
+	assert.Equal(t, MaxBackoff, delay(service, 10))
+
+	assert.Equal(t, DefaultInterval, delay(models.ServiceConfiguration{}, 0))
+
+	// An interval longer than the backoff cap is never shortened
+	slow := models.ServiceConfiguration{Options: models.ServiceOptions{Interval: 3600}}
+	assert.Equal(t, time.Hour, delay(slow, 5))
+}
+
+func TestJitter(t *testing.T) {
+	service := models.ServiceConfiguration{Options: models.ServiceOptions{Jitter: 5}}
+	for i := 0; i < 100; i++ {
+		d := jitter(service)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.Less(t, d, 5*time.Second)
+	}
+	assert.Zero(t, jitter(models.ServiceConfiguration{}))
+}
+
+func TestScheduler_RunsChecks(t *testing.T) {
+	store := &mockStore{}
+	store.set(
+		models.ServiceConfiguration{InstanceID: "sonarr-1", URL: "http://sonarr"},
+		models.ServiceConfiguration{InstanceID: "radarr-1", URL: "http://radarr"},
+		models.ServiceConfiguration{InstanceID: "plex-1"}, // unconfigured, never checked
+	)
+
+	results := make(chan models.ServiceHealth, 10)
+	s := New(store,
+		func(ctx context.Context, service models.ServiceConfiguration) models.ServiceHealth {
+			return models.ServiceHealth{Status: "online"}
+		},
+		func(health models.ServiceHealth) { results <- health },
+	)
+	s.Start(context.Background())
+	defer s.Stop()
+
+	checked := make(map[string]bool)
+	for len(checked) < 2 {
+		select {
+		case health := <-results:
+			assert.Equal(t, "online", health.Status)
+			checked[health.ServiceID] = true
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout waiting for checks, got %v", checked)
+		}
+	}
+
+	assert.True(t, checked["sonarr-1"])
+	assert.True(t, checked["radarr-1"])
+
+	require.Eventually(t, func() bool {
+		next, ok := s.NextCheck("sonarr-1")
+		return ok && next.After(time.Now().Add(DefaultInterval-time.Second))
+	}, time.Second, 10*time.Millisecond)
+
+	_, ok := s.NextCheck("plex-1")
+	assert.False(t, ok)
+}
+
+func TestScheduler_CheckNow(t *testing.T) {
+	store := &mockStore{}
+	store.set(models.ServiceConfiguration{
+		InstanceID: "tailscale-1",
+		URL:        "http://tailscale",
+		Options:    models.ServiceOptions{Interval: 300},
+	})
+
+	var calls int
+	var mu sync.Mutex
+	s := New(store,
+		func(ctx context.Context, service models.ServiceConfiguration) models.ServiceHealth {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			return models.ServiceHealth{Status: "offline"}
+		},
+		nil,
+	)
+
+	_, err := s.CheckNow(context.Background(), "unknown-1")
+	assert.ErrorIs(t, err, ErrUnknownService)
+
+	health, err := s.CheckNow(context.Background(), "tailscale-1")
+	require.NoError(t, err)
+	assert.Equal(t, "tailscale-1", health.ServiceID)
+	assert.Equal(t, "offline", health.Status)
+	assert.Equal(t, 1, calls)
+
+	next, ok := s.NextCheck("tailscale-1")
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), next, time.Second)
+
+	// A second failure backs off
+	_, err = s.CheckNow(context.Background(), "tailscale-1")
+	require.NoError(t, err)
+	next, _ = s.NextCheck("tailscale-1")
+	assert.WithinDuration(t, time.Now().Add(MaxBackoff), next, time.Second)
+}
+
+func TestScheduler_SyncRemovesServices(t *testing.T) {
+	store := &mockStore{}
+	store.set(models.ServiceConfiguration{InstanceID: "sonarr-1", URL: "http://sonarr"})
+
+	s := New(store, func(ctx context.Context, service models.ServiceConfiguration) models.ServiceHealth {
+		return models.ServiceHealth{Status: "online"}
+	}, nil)
+
+	require.NoError(t, s.Sync())
+	_, ok := s.NextCheck("sonarr-1")
+	assert.True(t, ok)
+
+	store.set()
+	require.NoError(t, s.Sync())
+	_, ok = s.NextCheck("sonarr-1")
+	assert.False(t, ok)
+
+	_, err := s.CheckNow(context.Background(), "sonarr-1")
+	assert.ErrorIs(t, err, ErrUnknownService)
+}
+
+func TestScheduler_OnSync(t *testing.T) {
+	store := &mockStore{}
+	store.set(models.ServiceConfiguration{InstanceID: "push-1", URL: "/api/push/old-token"})
+
+	s := New(store, func(ctx context.Context, service models.ServiceConfiguration) models.ServiceHealth {
+		return models.ServiceHealth{Status: "online"}
+	}, nil)
+
+	var synced [][]models.ServiceConfiguration
+	s.OnSync(func(services []models.ServiceConfiguration) {
+		synced = append(synced, services)
+	})
+
+	require.NoError(t, s.Sync())
+	store.set(models.ServiceConfiguration{InstanceID: "push-1", URL: "/api/push/new-token"})
+	require.NoError(t, s.Sync())
+
+	require.Len(t, synced, 2)
+	assert.Equal(t, "/api/push/old-token", synced[0][0].URL)
+	assert.Equal(t, "/api/push/new-token", synced[1][0].URL)
+}
@@ -56,17 +56,20 @@ type SystemStatusResponse struct {
 }
 
 func init() {
-	models.NewSonarrService = NewSonarrService
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "sonarr",
+		DisplayName:    "Sonarr",
+		Description:    "Monitor and manage your Sonarr instance",
+		DefaultURL:     "http://localhost:8989",
+		HealthEndpoint: "/api/v3/health",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityQueue, models.CapabilityStats, models.CapabilityUpdates},
+		New:            NewSonarrService,
+	})
 }
 
 func NewSonarrService() models.ServiceHealthChecker {
-	service := &SonarrService{}
-	service.Type = "sonarr"
-	service.DisplayName = "Sonarr"
-	service.Description = "Monitor and manage your Sonarr instance"
-	service.DefaultURL = "http://localhost:8989"
-	service.HealthEndpoint = "/api/v3/health"
-	return service
+	return &SonarrService{ServiceCore: core.NewServiceCore("sonarr")}
 }
 
 func (s *SonarrService) GetHealthEndpoint(baseURL string) string {
@@ -92,8 +95,7 @@ func (s *SonarrService) makeRequest(ctx context.Context, method, url, apiKey str
 	//	Str("url", url).
 	//	Msg("Making request to Sonarr API")
 
-	client := &http.Client{}
-	return client.Do(req)
+	return core.Do(req)
 }
 
 // DeleteQueueItem deletes a queue item with the specified options
@@ -248,8 +250,42 @@ func (s *SonarrService) GetSeries(baseURL, apiKey string, seriesID int) (*types.
 	return &series, nil
 }
 
+// GetQueueSize returns the total number of items in the Sonarr queue
+func (s *SonarrService) GetQueueSize(ctx context.Context, url, apiKey string) (int, error) {
+	if url == "" {
+		return 0, &ErrSonarr{Op: "get_queue_size", Err: fmt.Errorf("URL is required")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	queueURL := fmt.Sprintf("%s/api/v3/queue?page=1&pageSize=1", strings.TrimRight(url, "/"))
+
+	resp, err := s.makeRequest(ctx, http.MethodGet, queueURL, apiKey, nil)
+	if err != nil {
+		return 0, &ErrSonarr{Op: "get_queue_size", Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, &ErrSonarr{Op: "get_queue_size", HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return 0, &ErrSonarr{Op: "get_queue_size", Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	var queue types.SonarrQueueResponse
+	if err := json.Unmarshal(body, &queue); err != nil {
+		return 0, &ErrSonarr{Op: "get_queue_size", Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	return queue.TotalRecords, nil
+}
+
 // GetQueue fetches the current queue from Sonarr
-func (s *SonarrService) GetQueue(url, apiKey string) ([]types.QueueRecord, error) {
+func (s *SonarrService) GetQueue(ctx context.Context, url, apiKey string) ([]types.QueueRecord, error) {
 	if url == "" {
 		return nil, &ErrSonarr{Op: "get_queue", Err: fmt.Errorf("URL is required")}
 	}
@@ -258,7 +294,7 @@ func (s *SonarrService) GetQueue(url, apiKey string) ([]types.QueueRecord, error
 		return nil, &ErrSonarr{Op: "get_queue", Err: fmt.Errorf("API key is required")}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
 	queueURL := fmt.Sprintf("%s/api/v3/queue?page=1&pageSize=10&includeUnknownSeriesItems=false&includeSeries=false",
@@ -291,7 +327,7 @@ func (s *SonarrService) GetQueue(url, apiKey string) ([]types.QueueRecord, error
 	return queue.Records, nil
 }
 
-func (s *SonarrService) getSystemStatus(baseURL, apiKey string) (string, error) {
+func (s *SonarrService) getSystemStatus(ctx context.Context, baseURL, apiKey string) (string, error) {
 	if baseURL == "" {
 		return "", &ErrSonarr{Op: "get_system_status", Err: fmt.Errorf("URL is required")}
 	}
@@ -302,7 +338,7 @@ func (s *SonarrService) getSystemStatus(baseURL, apiKey string) (string, error)
 	}
 
 	statusURL := fmt.Sprintf("%s/api/v3/system/status", strings.TrimRight(baseURL, "/"))
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
 	resp, err := s.makeRequest(ctx, http.MethodGet, statusURL, apiKey, nil)
@@ -334,13 +370,14 @@ func (s *SonarrService) getSystemStatus(baseURL, apiKey string) (string, error)
 	return status.Version, nil
 }
 
-func (s *SonarrService) checkForUpdates(baseURL, apiKey string) (bool, error) {
+// CheckForUpdates checks if there are any updates available for Sonarr
+func (s *SonarrService) CheckForUpdates(ctx context.Context, baseURL, apiKey string) (bool, error) {
 	if baseURL == "" {
 		return false, &ErrSonarr{Op: "check_for_updates", Err: fmt.Errorf("URL is required")}
 	}
 
 	updateURL := fmt.Sprintf("%s/api/v3/update", strings.TrimRight(baseURL, "/"))
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
 	resp, err := s.makeRequest(ctx, http.MethodGet, updateURL, apiKey, nil)
@@ -373,22 +410,23 @@ func (s *SonarrService) checkForUpdates(baseURL, apiKey string) (bool, error) {
 	return false, nil
 }
 
-func (s *SonarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, int) {
+func (s *SonarrService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
 	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
 
 	if url == "" {
-		return s.CreateHealthResponse(startTime, "error", "URL is required"), http.StatusBadRequest
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
 	}
 
 	// Create a context with timeout for the entire health check
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := core.HealthCheckContext(ctx, service, 3*time.Second)
 	defer cancel()
 
 	// Start version check in background
 	versionChan := make(chan string, 1)
 	versionErrChan := make(chan error, 1)
 	go func() {
-		version, err := s.getSystemStatus(url, apiKey)
+		version, err := s.getSystemStatus(ctx, url, apiKey)
 		versionChan <- version
 		versionErrChan <- err
 	}()
@@ -397,7 +435,7 @@ func (s *SonarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, i
 	updateChan := make(chan bool, 1)
 	updateErrChan := make(chan error, 1)
 	go func() {
-		hasUpdate, err := s.checkForUpdates(url, apiKey)
+		hasUpdate, err := s.CheckForUpdates(ctx, url, apiKey)
 		updateChan <- hasUpdate
 		updateErrChan <- err
 	}()
@@ -406,7 +444,7 @@ func (s *SonarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, i
 	queueChan := make(chan []types.QueueRecord, 1)
 	queueErrChan := make(chan error, 1)
 	go func() {
-		queue, err := s.GetQueue(url, apiKey)
+		queue, err := s.GetQueue(ctx, url, apiKey)
 		queueChan <- queue
 		queueErrChan <- err
 	}()
@@ -415,7 +453,7 @@ func (s *SonarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, i
 	healthEndpoint := s.GetHealthEndpoint(url)
 	resp, err := s.makeRequest(ctx, http.MethodGet, healthEndpoint, apiKey, nil)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
 	}
 	defer resp.Body.Close()
 
@@ -424,7 +462,7 @@ func (s *SonarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, i
 
 	body, err := s.ReadBody(resp)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to read response: %v", err)), nil
 	}
 
 	if resp.StatusCode >= 400 {
@@ -444,12 +482,12 @@ func (s *SonarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, i
 			message = "Service endpoint not found"
 		}
 
-		return s.CreateHealthResponse(startTime, status, message), http.StatusOK
+		return s.CreateHealthResponse(startTime, status, message), nil
 	}
 
 	var healthIssues []HealthResponse
 	if err := json.Unmarshal(body, &healthIssues); err != nil {
-		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to parse response: %v", err)), http.StatusOK
+		return s.CreateHealthResponse(startTime, "error", fmt.Sprintf("Failed to parse response: %v", err)), nil
 	}
 
 	// Wait for version with timeout
@@ -532,9 +570,9 @@ func (s *SonarrService) CheckHealth(url, apiKey string) (models.ServiceHealth, i
 
 	// If there are any warnings, return them all
 	if len(allWarnings) > 0 {
-		return s.CreateHealthResponse(startTime, "warning", strings.Join(allWarnings, "\n\n"), extras), http.StatusOK
+		return s.CreateHealthResponse(startTime, "warning", strings.Join(allWarnings, "\n\n"), extras), nil
 	}
 
 	// If no warnings, the service is healthy
-	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), http.StatusOK
+	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), nil
 }
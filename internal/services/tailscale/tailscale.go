@@ -51,17 +51,21 @@ type TailscaleAPIResponse struct {
 }
 
 func init() {
-	models.NewTailscaleService = NewTailscaleService
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "tailscale",
+		DisplayName:    "Tailscale",
+		Description:    "Manage and monitor your Tailscale network",
+		DefaultURL:     "https://api.tailscale.com",
+		HealthEndpoint: "/api/v2/tailnet/-/devices",
+		Auth:           models.AuthToken,
+		Capabilities:   []models.Capability{models.CapabilityDevices},
+		CLI:            models.CLIMetadata{FixedURL: true},
+		New:            NewTailscaleService,
+	})
 }
 
 func NewTailscaleService() models.ServiceHealthChecker {
-	service := &TailscaleService{}
-	service.Type = "tailscale"
-	service.DisplayName = "Tailscale"
-	service.Description = "Manage and monitor your Tailscale network"
-	service.DefaultURL = "https://api.tailscale.com"
-	service.HealthEndpoint = "/api/v2/tailnet/-/devices"
-	return service
+	return &TailscaleService{ServiceCore: core.NewServiceCore("tailscale")}
 }
 
 func (s *TailscaleService) getDevicesWithContext(ctx context.Context, apiKey string) (*TailscaleAPIResponse, time.Duration, error) {
@@ -104,14 +108,15 @@ func (s *TailscaleService) getDevicesWithContext(ctx context.Context, apiKey str
 	return &apiResponse, responseTime, nil
 }
 
-func (s *TailscaleService) CheckHealth(_ string, apiKey string) (models.ServiceHealth, int) {
+func (s *TailscaleService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
 	startTime := time.Now()
+	apiKey := service.APIKey
 
 	if apiKey == "" {
-		return s.CreateHealthResponse(startTime, "error", "Service not configured: missing API key"), http.StatusBadRequest
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "Service not configured: missing API key")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := core.HealthCheckContext(ctx, service, 3*time.Second)
 	defer cancel()
 
 	versionChan := make(chan string, 1)
@@ -131,7 +136,7 @@ func (s *TailscaleService) CheckHealth(_ string, apiKey string) (models.ServiceH
 
 	apiResponse, responseTime, err := s.getDevicesWithContext(ctx, apiKey)
 	if err != nil {
-		return s.CreateHealthResponse(startTime, "error", err.Error()), http.StatusServiceUnavailable
+		return s.CreateHealthError(startTime, http.StatusServiceUnavailable, "error", err.Error())
 	}
 
 	var version string
@@ -153,7 +158,7 @@ func (s *TailscaleService) CheckHealth(_ string, apiKey string) (models.ServiceH
 		"responseTime": responseTime.Milliseconds(),
 	}
 
-	return s.CreateHealthResponse(startTime, "online", fmt.Sprintf("%d devices online", onlineCount), extras), http.StatusOK
+	return s.CreateHealthResponse(startTime, "online", fmt.Sprintf("%d devices online", onlineCount), extras), nil
 }
 
 func isDeviceOnline(lastSeen string) bool {
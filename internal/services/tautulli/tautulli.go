@@ -0,0 +1,438 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package tautulli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/core"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+// Custom error types for better error handling
+type ErrTautulli struct {
+	Op       string // Command that failed
+	Err      error  // Underlying error
+	HttpCode int    // HTTP status code if applicable
+}
+
+func (e *ErrTautulli) Error() string {
+	if e.HttpCode > 0 {
+		return fmt.Sprintf("tautulli %s: server returned %s (%d)", e.Op, http.StatusText(e.HttpCode), e.HttpCode)
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("tautulli %s: %v", e.Op, e.Err)
+	}
+	return fmt.Sprintf("tautulli %s", e.Op)
+}
+
+func (e *ErrTautulli) Unwrap() error {
+	return e.Err
+}
+
+type TautulliService struct {
+	core.ServiceCore
+}
+
+// flexInt decodes the numeric fields Tautulli returns as numbers, numeric
+// strings or empty strings depending on the command
+type flexInt int64
+
+func (f *flexInt) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*f = 0
+		return nil
+	}
+	value, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return err
+	}
+	*f = flexInt(value)
+	return nil
+}
+
+// flexString decodes identifiers Tautulli returns as either numbers or strings
+type flexString string
+
+func (f *flexString) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*f = ""
+		return nil
+	}
+	*f = flexString(bytes.Trim(data, `"`))
+	return nil
+}
+
+type apiResponse struct {
+	Response struct {
+		Result  string          `json:"result"`
+		Message *string         `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	} `json:"response"`
+}
+
+type infoResponse struct {
+	Version string `json:"tautulli_version"`
+}
+
+type serverStatusResponse struct {
+	Connected bool `json:"connected"`
+}
+
+type homeStat struct {
+	StatID string `json:"stat_id"`
+	Rows   []struct {
+		RatingKey     flexString `json:"rating_key"`
+		Title         string     `json:"title"`
+		Year          flexInt    `json:"year"`
+		Thumb         string     `json:"thumb"`
+		TotalPlays    flexInt    `json:"total_plays"`
+		TotalDuration flexInt    `json:"total_duration"`
+		UsersWatched  flexInt    `json:"users_watched"`
+		UserID        flexString `json:"user_id"`
+		FriendlyName  string     `json:"friendly_name"`
+		UserThumb     string     `json:"user_thumb"`
+		Platform      string     `json:"platform"`
+	} `json:"rows"`
+}
+
+type recentlyAddedResponse struct {
+	RecentlyAdded []struct {
+		RatingKey        flexString `json:"rating_key"`
+		MediaType        string     `json:"media_type"`
+		Title            string     `json:"title"`
+		ParentTitle      string     `json:"parent_title"`
+		GrandparentTitle string     `json:"grandparent_title"`
+		Year             flexInt    `json:"year"`
+		Thumb            string     `json:"thumb"`
+		LibraryName      string     `json:"library_name"`
+		AddedAt          flexInt    `json:"added_at"`
+	} `json:"recently_added"`
+}
+
+type historyResponse struct {
+	RecordsFiltered int `json:"recordsFiltered"`
+	Data            []struct {
+		ID                flexInt    `json:"id"`
+		RatingKey         flexString `json:"rating_key"`
+		MediaType         string     `json:"media_type"`
+		FullTitle         string     `json:"full_title"`
+		GrandparentTitle  string     `json:"grandparent_title"`
+		ParentMediaIndex  flexInt    `json:"parent_media_index"`
+		MediaIndex        flexInt    `json:"media_index"`
+		UserID            flexString `json:"user_id"`
+		FriendlyName      string     `json:"friendly_name"`
+		UserThumb         string     `json:"user_thumb"`
+		Platform          string     `json:"platform"`
+		Player            string     `json:"player"`
+		Started           flexInt    `json:"started"`
+		Stopped           flexInt    `json:"stopped"`
+		PlayDuration      flexInt    `json:"play_duration"`
+		Duration          flexInt    `json:"duration"`
+		PercentComplete   flexInt    `json:"percent_complete"`
+		WatchedStatus     float64    `json:"watched_status"`
+		TranscodeDecision string     `json:"transcode_decision"`
+	} `json:"data"`
+}
+
+func init() {
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           "tautulli",
+		DisplayName:    "Tautulli",
+		Description:    "Monitor Plex watch statistics and history with Tautulli",
+		DefaultURL:     "http://localhost:8181",
+		HealthEndpoint: "/api/v2?cmd=get_tautulli_info",
+		Auth:           models.AuthAPIKey,
+		Capabilities:   []models.Capability{models.CapabilityStats},
+		New:            NewTautulliService,
+	})
+}
+
+func NewTautulliService() models.ServiceHealthChecker {
+	return &TautulliService{ServiceCore: core.NewServiceCore("tautulli")}
+}
+
+// call runs an API command and decodes the response data into v
+func (s *TautulliService) call(ctx context.Context, baseURL, apiKey, cmd string, params neturl.Values, v interface{}) error {
+	if baseURL == "" {
+		return &ErrTautulli{Op: cmd, Err: fmt.Errorf("URL is required")}
+	}
+
+	if params == nil {
+		params = neturl.Values{}
+	}
+	params.Set("apikey", apiKey)
+	params.Set("cmd", cmd)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+"/api/v2?"+params.Encode(), nil)
+	if err != nil {
+		return &ErrTautulli{Op: cmd, Err: fmt.Errorf("failed to create request: %w", err)}
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := core.Do(req)
+	if err != nil {
+		return &ErrTautulli{Op: cmd, Err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &ErrTautulli{Op: cmd, HttpCode: resp.StatusCode}
+	}
+
+	body, err := s.ReadBody(resp)
+	if err != nil {
+		return &ErrTautulli{Op: cmd, Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	var response apiResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return &ErrTautulli{Op: cmd, Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	if response.Response.Result != "success" {
+		message := "request failed"
+		if response.Response.Message != nil {
+			message = *response.Response.Message
+		}
+		if strings.Contains(strings.ToLower(message), "apikey") {
+			return &ErrTautulli{Op: cmd, HttpCode: http.StatusUnauthorized}
+		}
+		return &ErrTautulli{Op: cmd, Err: fmt.Errorf("%s", message)}
+	}
+
+	if err := json.Unmarshal(response.Response.Data, v); err != nil {
+		return &ErrTautulli{Op: cmd, Err: fmt.Errorf("failed to parse data: %w", err)}
+	}
+
+	return nil
+}
+
+// GetVersion returns the Tautulli version, cached for an hour
+func (s *TautulliService) GetVersion(ctx context.Context, baseURL, apiKey string) (string, error) {
+	if version := s.GetVersionFromCache(baseURL); version != "" {
+		return version, nil
+	}
+
+	var info infoResponse
+	if err := s.call(ctx, baseURL, apiKey, "get_tautulli_info", nil, &info); err != nil {
+		return "", err
+	}
+
+	if err := s.CacheVersion(baseURL, info.Version, time.Hour); err != nil {
+		log.Warn().Err(err).Msg("Failed to cache Tautulli version")
+	}
+
+	return info.Version, nil
+}
+
+// GetHomeStats returns the most watched media, top users and top platforms of
+// the last days days, with at most count entries per list
+func (s *TautulliService) GetHomeStats(ctx context.Context, baseURL, apiKey string, days, count int) (*types.TautulliHomeStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	params := neturl.Values{
+		"time_range":  {strconv.Itoa(days)},
+		"stats_count": {strconv.Itoa(count)},
+		"stats_type":  {"plays"},
+	}
+
+	var stats []homeStat
+	if err := s.call(ctx, baseURL, apiKey, "get_home_stats", params, &stats); err != nil {
+		return nil, err
+	}
+
+	result := &types.TautulliHomeStats{
+		Days:              days,
+		MostWatchedMovies: []types.TautulliMediaStat{},
+		MostWatchedShows:  []types.TautulliMediaStat{},
+		MostWatchedMusic:  []types.TautulliMediaStat{},
+		TopUsers:          []types.TautulliUserStat{},
+		TopPlatforms:      []types.TautulliPlatformStat{},
+	}
+
+	for _, stat := range stats {
+		for _, row := range stat.Rows {
+			media := types.TautulliMediaStat{
+				RatingKey:     string(row.RatingKey),
+				Title:         row.Title,
+				Year:          int(row.Year),
+				Thumb:         row.Thumb,
+				TotalPlays:    int(row.TotalPlays),
+				TotalDuration: int64(row.TotalDuration),
+				UsersWatched:  int(row.UsersWatched),
+			}
+
+			switch stat.StatID {
+			case "top_movies":
+				result.MostWatchedMovies = append(result.MostWatchedMovies, media)
+			case "top_tv":
+				result.MostWatchedShows = append(result.MostWatchedShows, media)
+			case "top_music":
+				result.MostWatchedMusic = append(result.MostWatchedMusic, media)
+			case "top_users":
+				result.TopUsers = append(result.TopUsers, types.TautulliUserStat{
+					User: types.PlexUser{
+						ID:    string(row.UserID),
+						Title: row.FriendlyName,
+						Thumb: row.UserThumb,
+					},
+					TotalPlays:    int(row.TotalPlays),
+					TotalDuration: int64(row.TotalDuration),
+				})
+			case "top_platforms":
+				result.TopPlatforms = append(result.TopPlatforms, types.TautulliPlatformStat{
+					Platform:      row.Platform,
+					TotalPlays:    int(row.TotalPlays),
+					TotalDuration: int64(row.TotalDuration),
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// GetRecentlyAdded returns the last count items added to the Plex libraries
+func (s *TautulliService) GetRecentlyAdded(ctx context.Context, baseURL, apiKey string, count int) ([]types.TautulliRecentlyAdded, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	var response recentlyAddedResponse
+	if err := s.call(ctx, baseURL, apiKey, "get_recently_added", neturl.Values{"count": {strconv.Itoa(count)}}, &response); err != nil {
+		return nil, err
+	}
+
+	items := make([]types.TautulliRecentlyAdded, 0, len(response.RecentlyAdded))
+	for _, item := range response.RecentlyAdded {
+		items = append(items, types.TautulliRecentlyAdded{
+			RatingKey:           string(item.RatingKey),
+			Type:                item.MediaType,
+			Title:               item.Title,
+			ParentTitle:         item.ParentTitle,
+			GrandparentTitle:    item.GrandparentTitle,
+			Year:                int(item.Year),
+			Thumb:               item.Thumb,
+			LibrarySectionTitle: item.LibraryName,
+			AddedAt:             int64(item.AddedAt),
+		})
+	}
+
+	return items, nil
+}
+
+// GetHistory returns the latest length plays, of a single user when userID is set
+func (s *TautulliService) GetHistory(ctx context.Context, baseURL, apiKey, userID string, length int) (*types.TautulliHistoryResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	params := neturl.Values{"length": {strconv.Itoa(length)}}
+	if userID != "" {
+		params.Set("user_id", userID)
+	}
+
+	var response historyResponse
+	if err := s.call(ctx, baseURL, apiKey, "get_history", params, &response); err != nil {
+		return nil, err
+	}
+
+	history := &types.TautulliHistoryResponse{
+		Total: response.RecordsFiltered,
+		Items: make([]types.TautulliHistoryItem, 0, len(response.Data)),
+	}
+
+	for _, item := range response.Data {
+		// play_duration was added in v2.12, older releases only report duration
+		duration := item.PlayDuration
+		if duration == 0 {
+			duration = item.Duration
+		}
+
+		history.Items = append(history.Items, types.TautulliHistoryItem{
+			ID:               int64(item.ID),
+			RatingKey:        string(item.RatingKey),
+			Type:             item.MediaType,
+			Title:            item.FullTitle,
+			GrandparentTitle: item.GrandparentTitle,
+			ParentIndex:      int(item.ParentMediaIndex),
+			Index:            int(item.MediaIndex),
+			User: &types.PlexUser{
+				ID:    string(item.UserID),
+				Title: item.FriendlyName,
+				Thumb: item.UserThumb,
+			},
+			Player: &types.PlexPlayer{
+				Title:    item.Player,
+				Platform: item.Platform,
+			},
+			StartedAt:         int64(item.Started),
+			StoppedAt:         int64(item.Stopped),
+			Duration:          int64(duration),
+			PercentComplete:   int(item.PercentComplete),
+			Watched:           item.WatchedStatus >= 1,
+			TranscodeDecision: item.TranscodeDecision,
+		})
+	}
+
+	return history, nil
+}
+
+func (s *TautulliService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
+	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
+
+	if url == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
+	}
+
+	if apiKey == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "API key is required")
+	}
+
+	ctx, cancel := core.HealthCheckContext(ctx, service, 5*time.Second)
+	defer cancel()
+
+	version, err := s.GetVersion(ctx, url, apiKey)
+	if err != nil {
+		if tautulliErr, ok := err.(*ErrTautulli); ok && tautulliErr.HttpCode > 0 {
+			message := fmt.Sprintf("Server returned %s (%d)", http.StatusText(tautulliErr.HttpCode), tautulliErr.HttpCode)
+			if tautulliErr.HttpCode == http.StatusUnauthorized {
+				message = "Invalid API key"
+			}
+			return s.CreateHealthResponse(startTime, "error", message), nil
+		}
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
+	}
+
+	extras := map[string]interface{}{}
+	if version != "" {
+		extras["version"] = version
+	}
+
+	// Tautulli keeps running when it loses the connection to Plex, but no
+	// activity is recorded until it reconnects
+	var status serverStatusResponse
+	if err := s.call(ctx, url, apiKey, "server_status", nil, &status); err != nil {
+		return s.CreateHealthResponse(startTime, "warning", fmt.Sprintf("Failed to get Plex server status: %v", err), extras), nil
+	}
+
+	if !status.Connected {
+		return s.CreateHealthResponse(startTime, "warning", "Tautulli is not connected to the Plex server", extras), nil
+	}
+
+	return s.CreateHealthResponse(startTime, "online", "Healthy", extras), nil
+}
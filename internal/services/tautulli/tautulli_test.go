@@ -0,0 +1,117 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package tautulli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/dashbrr/internal/models"
+)
+
+func newTestServer(t *testing.T, responses map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2", r.URL.Path)
+
+		if r.URL.Query().Get("apikey") != "test-key" {
+			w.Write([]byte(`{"response": {"result": "error", "message": "Invalid apikey", "data": {}}}`))
+			return
+		}
+
+		data, ok := responses[r.URL.Query().Get("cmd")]
+		if !ok {
+			w.Write([]byte(`{"response": {"result": "error", "message": "Unknown command", "data": {}}}`))
+			return
+		}
+		w.Write([]byte(`{"response": {"result": "success", "message": null, "data": ` + data + `}}`))
+	}))
+}
+
+func TestGetHomeStats(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"get_home_stats": `[
+			{"stat_id": "top_movies", "rows": [{"rating_key": 12, "title": "Heat", "year": 1995, "total_plays": 4, "total_duration": 40000}]},
+			{"stat_id": "top_tv", "rows": [{"rating_key": "34", "title": "The Wire", "year": "", "total_plays": 10, "total_duration": 30000}]},
+			{"stat_id": "top_users", "rows": [{"user_id": 5, "friendly_name": "alice", "total_plays": 9, "total_duration": 25000}]},
+			{"stat_id": "top_platforms", "rows": [{"platform": "Android", "total_plays": 7, "total_duration": 20000}]},
+			{"stat_id": "most_concurrent", "rows": [{"title": "Concurrent Streams", "count": 3}]}
+		]`,
+	})
+	defer server.Close()
+
+	stats, err := (&TautulliService{}).GetHomeStats(context.Background(), server.URL, "test-key", 7, 5)
+	require.NoError(t, err)
+
+	assert.Equal(t, 7, stats.Days)
+	require.Len(t, stats.MostWatchedMovies, 1)
+	assert.Equal(t, "12", stats.MostWatchedMovies[0].RatingKey)
+	assert.Equal(t, 1995, stats.MostWatchedMovies[0].Year)
+	require.Len(t, stats.MostWatchedShows, 1)
+	assert.Equal(t, "34", stats.MostWatchedShows[0].RatingKey)
+	assert.Zero(t, stats.MostWatchedShows[0].Year)
+	assert.Empty(t, stats.MostWatchedMusic)
+	require.Len(t, stats.TopUsers, 1)
+	assert.Equal(t, "5", stats.TopUsers[0].User.ID)
+	assert.Equal(t, "alice", stats.TopUsers[0].User.Title)
+	require.Len(t, stats.TopPlatforms, 1)
+	assert.Equal(t, 7, stats.TopPlatforms[0].TotalPlays)
+}
+
+func TestGetHistory(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"get_history": `{"recordsFiltered": 120, "data": [
+			{"id": 900, "rating_key": 77, "media_type": "episode", "full_title": "The Wire - The Target", "grandparent_title": "The Wire",
+			 "parent_media_index": "1", "media_index": 1, "user_id": 5, "friendly_name": "alice", "platform": "Android", "player": "Pixel",
+			 "started": 1700000000, "stopped": 1700003000, "play_duration": 2900, "duration": 3000, "percent_complete": 97,
+			 "watched_status": 1, "transcode_decision": "direct play"}
+		]}`,
+	})
+	defer server.Close()
+
+	history, err := (&TautulliService{}).GetHistory(context.Background(), server.URL, "test-key", "5", 25)
+	require.NoError(t, err)
+
+	assert.Equal(t, 120, history.Total)
+	require.Len(t, history.Items, 1)
+	item := history.Items[0]
+	assert.Equal(t, "77", item.RatingKey)
+	assert.Equal(t, 1, item.ParentIndex)
+	assert.Equal(t, int64(2900), item.Duration)
+	assert.True(t, item.Watched)
+	assert.Equal(t, "alice", item.User.Title)
+	assert.Equal(t, "Android", item.Player.Platform)
+}
+
+func TestCheckHealth(t *testing.T) {
+	tests := []struct {
+		name           string
+		apiKey         string
+		connected      string
+		expectedStatus string
+	}{
+		{name: "Connected", apiKey: "test-key", connected: "true", expectedStatus: "online"},
+		{name: "Plex unreachable", apiKey: "test-key", connected: "false", expectedStatus: "warning"},
+		{name: "Invalid API key", apiKey: "wrong", connected: "true", expectedStatus: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, map[string]string{
+				"get_tautulli_info": `{"tautulli_version": "v2.14.4"}`,
+				"server_status":     `{"result": "success", "connected": ` + tt.connected + `}`,
+			})
+			defer server.Close()
+
+			service := NewTautulliService().(*TautulliService)
+			health, err := service.CheckHealth(context.Background(), models.ServiceConfiguration{URL: server.URL, APIKey: tt.apiKey})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, health.Status)
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package transmission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/autobrr/dashbrr/internal/models"
+	"github.com/autobrr/dashbrr/internal/services/core"
+	"github.com/autobrr/dashbrr/internal/services/downloadclient"
+	"github.com/autobrr/dashbrr/internal/types"
+)
+
+const (
+	clientType      = "transmission"
+	rpcPath         = "/transmission/rpc"
+	sessionIDHeader = "X-Transmission-Session-Id"
+)
+
+// Torrent status values of the RPC protocol
+const (
+	statusStopped      = 0
+	statusDownloadWait = 3
+	statusDownload     = 4
+	statusSeed         = 6
+)
+
+// sessionIDs holds the CSRF session id handed out by each instance
+var sessionIDs sync.Map
+
+type TransmissionService struct {
+	core.ServiceCore
+}
+
+type rpcRequest struct {
+	Method    string      `json:"method"`
+	Arguments interface{} `json:"arguments,omitempty"`
+}
+
+type rpcResponse struct {
+	Result    string          `json:"result"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type sessionResponse struct {
+	Version     string `json:"version"`
+	DownloadDir string `json:"download-dir"`
+}
+
+type sessionStatsResponse struct {
+	DownloadSpeed int64 `json:"downloadSpeed"`
+	UploadSpeed   int64 `json:"uploadSpeed"`
+}
+
+type freeSpaceResponse struct {
+	SizeBytes int64 `json:"size-bytes"`
+}
+
+type torrentsResponse struct {
+	Torrents []struct {
+		Status    int  `json:"status"`
+		Error     int  `json:"error"`
+		IsStalled bool `json:"isStalled"`
+	} `json:"torrents"`
+}
+
+func init() {
+	models.RegisterService(models.ServiceDescriptor{
+		Type:           clientType,
+		DisplayName:    "Transmission",
+		Description:    "Monitor transfers and torrents of your Transmission client",
+		DefaultURL:     "http://localhost:9091",
+		HealthEndpoint: rpcPath,
+		Auth:           models.AuthOptional,
+		Capabilities:   []models.Capability{models.CapabilityStats},
+		CLI:            models.CLIMetadata{Credential: "username:password"},
+		New:            NewTransmissionService,
+	})
+}
+
+func NewTransmissionService() models.ServiceHealthChecker {
+	return &TransmissionService{ServiceCore: core.NewServiceCore(clientType)}
+}
+
+// rpcURL accepts both the web interface address and the full RPC endpoint
+func rpcURL(baseURL string) string {
+	return strings.TrimSuffix(strings.TrimRight(baseURL, "/"), rpcPath) + rpcPath
+}
+
+// call performs an RPC call and decodes its arguments into v. Transmission
+// answers the first request of a session with 409 and the session id to use,
+// the call is then repeated with it.
+func (s *TransmissionService) call(ctx context.Context, baseURL, apiKey, method string, arguments, v interface{}) error {
+	if baseURL == "" {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("URL is required")}
+	}
+
+	payload, err := json.Marshal(rpcRequest{Method: method, Arguments: arguments})
+	if err != nil {
+		return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("failed to encode request: %w", err)}
+	}
+
+	endpoint := rpcURL(baseURL)
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("failed to create request: %w", err)}
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID, ok := sessionIDs.Load(endpoint); ok {
+			req.Header.Set(sessionIDHeader, sessionID.(string))
+		}
+		if apiKey != "" {
+			req.SetBasicAuth(downloadclient.SplitCredentials(apiKey))
+		}
+
+		resp, err := core.Do(req)
+		if err != nil {
+			return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("failed to make request: %w", err)}
+		}
+
+		if resp.StatusCode == http.StatusConflict && attempt == 0 {
+			resp.Body.Close()
+			sessionIDs.Store(endpoint, resp.Header.Get(sessionIDHeader))
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, HttpCode: resp.StatusCode}
+		}
+
+		body, err := s.ReadBody(resp)
+		if err != nil {
+			return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("failed to read response: %w", err)}
+		}
+
+		var response rpcResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("failed to parse response: %w", err)}
+		}
+		if response.Result != "success" {
+			return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("%s", response.Result)}
+		}
+
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(response.Arguments, v); err != nil {
+			return &downloadclient.ErrDownloadClient{Client: clientType, Op: method, Err: fmt.Errorf("failed to parse arguments: %w", err)}
+		}
+		return nil
+	}
+}
+
+func (s *TransmissionService) GetStats(ctx context.Context, baseURL, apiKey string) (*types.DownloadClientStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	var session sessionResponse
+	if err := s.call(ctx, baseURL, apiKey, "session-get", map[string]interface{}{
+		"fields": []string{"version", "download-dir"},
+	}, &session); err != nil {
+		return nil, err
+	}
+
+	// The version looks like "4.0.5 (a6fe2a64aa)"
+	version, _, _ := strings.Cut(session.Version, " ")
+	if err := s.CacheVersion(baseURL, version, time.Hour); err != nil {
+		log.Warn().Err(err).Msg("Failed to cache Transmission version")
+	}
+
+	var sessionStats sessionStatsResponse
+	if err := s.call(ctx, baseURL, apiKey, "session-stats", nil, &sessionStats); err != nil {
+		return nil, err
+	}
+
+	var torrents torrentsResponse
+	if err := s.call(ctx, baseURL, apiKey, "torrent-get", map[string]interface{}{
+		"fields": []string{"status", "error", "isStalled"},
+	}, &torrents); err != nil {
+		return nil, err
+	}
+
+	stats := &types.DownloadClientStats{
+		ClientType:    clientType,
+		Version:       version,
+		DownloadSpeed: sessionStats.DownloadSpeed,
+		UploadSpeed:   sessionStats.UploadSpeed,
+		Total:         len(torrents.Torrents),
+		FreeSpace:     -1,
+	}
+
+	for _, torrent := range torrents.Torrents {
+		switch {
+		case torrent.Error != 0:
+			stats.Errored++
+		case torrent.Status == statusStopped:
+			stats.Paused++
+		case torrent.IsStalled && (torrent.Status == statusDownload || torrent.Status == statusDownloadWait):
+			stats.Stalled++
+		case torrent.Status == statusDownload:
+			stats.Downloading++
+			stats.Active++
+		case torrent.Status == statusSeed:
+			stats.Seeding++
+			stats.Active++
+		}
+	}
+
+	// free-space needs a path, without a download directory the space is unknown
+	if session.DownloadDir != "" {
+		var freeSpace freeSpaceResponse
+		if err := s.call(ctx, baseURL, apiKey, "free-space", map[string]interface{}{
+			"path": session.DownloadDir,
+		}, &freeSpace); err == nil {
+			stats.FreeSpace = freeSpace.SizeBytes
+		}
+	}
+
+	return stats, nil
+}
+
+// torrentAction calls method for the torrents with the given hashes, or for all of them
+func (s *TransmissionService) torrentAction(ctx context.Context, baseURL, apiKey, method string, hashes []string) error {
+	var arguments interface{}
+	if len(hashes) > 0 {
+		arguments = map[string]interface{}{"ids": hashes}
+	}
+	return s.call(ctx, baseURL, apiKey, method, arguments, nil)
+}
+
+func (s *TransmissionService) PauseTorrents(ctx context.Context, baseURL, apiKey string, hashes []string) error {
+	return s.torrentAction(ctx, baseURL, apiKey, "torrent-stop", hashes)
+}
+
+func (s *TransmissionService) ResumeTorrents(ctx context.Context, baseURL, apiKey string, hashes []string) error {
+	return s.torrentAction(ctx, baseURL, apiKey, "torrent-start", hashes)
+}
+
+func (s *TransmissionService) CheckHealth(ctx context.Context, service models.ServiceConfiguration) (models.ServiceHealth, error) {
+	startTime := time.Now()
+	url, apiKey := service.URL, service.APIKey
+
+	if url == "" {
+		return s.CreateHealthError(startTime, http.StatusBadRequest, "error", "URL is required")
+	}
+
+	ctx, cancel := core.HealthCheckContext(ctx, service, 10*time.Second)
+	defer cancel()
+
+	stats, err := s.GetStats(ctx, url, apiKey)
+	if err != nil {
+		if clientErr, ok := err.(*downloadclient.ErrDownloadClient); ok && clientErr.HttpCode > 0 {
+			message := fmt.Sprintf("Server returned %s (%d)", http.StatusText(clientErr.HttpCode), clientErr.HttpCode)
+			if clientErr.HttpCode == http.StatusUnauthorized {
+				message = "Invalid username or password"
+			}
+			return s.CreateHealthResponse(startTime, "error", message), nil
+		}
+		return s.CreateHealthResponse(startTime, "offline", fmt.Sprintf("Failed to connect: %v", err)), nil
+	}
+
+	status, message, extras := downloadclient.HealthResponse(stats)
+	return s.CreateHealthResponse(startTime, status, message, extras), nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package transmission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStats_SessionHandshake(t *testing.T) {
+	var methods []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, rpcPath, r.URL.Path)
+
+		if r.Header.Get(sessionIDHeader) != "csrf-token" {
+			w.Header().Set(sessionIDHeader, "csrf-token")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		var req rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		methods = append(methods, req.Method)
+
+		switch req.Method {
+		case "session-get":
+			w.Write([]byte(`{"result": "success", "arguments": {"version": "4.0.5 (a6fe2a64aa)", "download-dir": "/downloads"}}`))
+		case "session-stats":
+			w.Write([]byte(`{"result": "success", "arguments": {"downloadSpeed": 2048, "uploadSpeed": 128}}`))
+		case "torrent-get":
+			w.Write([]byte(`{"result": "success", "arguments": {"torrents": [
+				{"status": 4, "error": 0, "isStalled": false},
+				{"status": 4, "error": 0, "isStalled": true},
+				{"status": 6, "error": 0, "isStalled": false},
+				{"status": 0, "error": 0, "isStalled": false},
+				{"status": 0, "error": 3, "isStalled": false}
+			]}}`))
+		case "free-space":
+			w.Write([]byte(`{"result": "success", "arguments": {"path": "/downloads", "size-bytes": 5000}}`))
+		default:
+			w.Write([]byte(`{"result": "method name not recognized"}`))
+		}
+	}))
+	defer server.Close()
+
+	stats, err := (&TransmissionService{}).GetStats(context.Background(), server.URL, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"session-get", "session-stats", "torrent-get", "free-space"}, methods)
+	assert.Equal(t, "4.0.5", stats.Version)
+	assert.Equal(t, int64(2048), stats.DownloadSpeed)
+	assert.Equal(t, int64(5000), stats.FreeSpace)
+	assert.Equal(t, 5, stats.Total)
+	assert.Equal(t, 2, stats.Active)
+	assert.Equal(t, 1, stats.Stalled)
+	assert.Equal(t, 1, stats.Paused)
+	assert.Equal(t, 1, stats.Errored)
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/autobrr/dashbrr/internal/config"
+)
+
+func TestTransport_PropagatesTraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// Disabled tracing still installs the propagator
+	shutdown, err := Init(context.Background(), config.TracingConfig{})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, parent := Tracer().Start(context.Background(), "parent")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v3/system/status", nil)
+	require.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	client := spans[0]
+	assert.Equal(t, trace.SpanKindClient, client.SpanKind())
+	assert.Equal(t, parent.SpanContext().TraceID(), client.SpanContext().TraceID())
+	assert.Equal(t, parent.SpanContext().SpanID(), client.Parent().SpanID())
+	assert.Contains(t, traceparent, client.SpanContext().TraceID().String())
+	assert.Contains(t, traceparent, client.SpanContext().SpanID().String())
+}
+
+func TestMiddleware_SkipsUntracedPaths(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+
+	paths := []string{"/api/settings", "/metrics", "/health", "/api/health/events", "/assets/index.js"}
+	for _, path := range paths {
+		router.GET(path, func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+	for _, path := range paths {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /api/settings", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+}
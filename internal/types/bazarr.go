@@ -0,0 +1,27 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package types
+
+// BazarrStatsResponse represents the subtitle statistics of a Bazarr instance
+type BazarrStatsResponse struct {
+	Version            string                 `json:"version,omitempty"`
+	WantedEpisodes     int                    `json:"wantedEpisodes"`
+	WantedMovies       int                    `json:"wantedMovies"`
+	ThrottledProviders int                    `json:"throttledProviders"`
+	Providers          []BazarrProviderStatus `json:"providers"`
+	HealthIssues       []BazarrHealthIssue    `json:"healthIssues,omitempty"`
+}
+
+// BazarrProviderStatus represents a throttled subtitle provider reported by Bazarr
+type BazarrProviderStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Retry  string `json:"retry"`
+}
+
+// BazarrHealthIssue represents an issue reported by Bazarr's health endpoint
+type BazarrHealthIssue struct {
+	Object string `json:"object"`
+	Issue  string `json:"issue"`
+}
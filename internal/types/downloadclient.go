@@ -0,0 +1,25 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package types
+
+// DownloadClientStats represents the transfer and torrent state of a download client
+type DownloadClientStats struct {
+	ClientType    string `json:"clientType"`
+	Version       string `json:"version"`
+	DownloadSpeed int64  `json:"downloadSpeed"` // Bytes per second
+	UploadSpeed   int64  `json:"uploadSpeed"`   // Bytes per second
+	Total         int    `json:"total"`
+	Active        int    `json:"active"`
+	Downloading   int    `json:"downloading"`
+	Seeding       int    `json:"seeding"`
+	Paused        int    `json:"paused"`
+	Stalled       int    `json:"stalled"`
+	Errored       int    `json:"errored"`
+	FreeSpace     int64  `json:"freeSpace"` // Bytes, -1 when the client does not report it
+}
+
+// DownloadClientActionRequest selects the torrents a pause or resume action applies to
+type DownloadClientActionRequest struct {
+	Hashes []string `json:"hashes"` // Empty applies the action to all torrents
+}
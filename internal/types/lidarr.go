@@ -0,0 +1,51 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package types
+
+// LidarrQueueResponse represents the queue response from Lidarr API
+type LidarrQueueResponse struct {
+	Page          int           `json:"page"`
+	PageSize      int           `json:"pageSize"`
+	SortKey       string        `json:"sortKey"`
+	SortDirection string        `json:"sortDirection"`
+	TotalRecords  int           `json:"totalRecords"`
+	Records       []QueueRecord `json:"records"`
+}
+
+// LidarrQueueDeleteOptions represents the options for deleting a queue item in Lidarr
+type LidarrQueueDeleteOptions struct {
+	RemoveFromClient bool `json:"removeFromClient"`
+	Blocklist        bool `json:"blocklist"`
+	SkipRedownload   bool `json:"skipRedownload"`
+	ChangeCategory   bool `json:"changeCategory"`
+}
+
+// LidarrArtist represents an artist from Lidarr's artist endpoint
+type LidarrArtist struct {
+	ID         int                    `json:"id"`
+	ArtistName string                 `json:"artistName"`
+	Monitored  bool                   `json:"monitored"`
+	Statistics LidarrArtistStatistics `json:"statistics"`
+}
+
+// LidarrArtistStatistics represents library statistics of a single artist
+type LidarrArtistStatistics struct {
+	AlbumCount      int   `json:"albumCount"`
+	TrackFileCount  int   `json:"trackFileCount"`
+	TrackCount      int   `json:"trackCount"`
+	TotalTrackCount int   `json:"totalTrackCount"`
+	SizeOnDisk      int64 `json:"sizeOnDisk"`
+}
+
+// LidarrStatsResponse represents the library statistics of a Lidarr instance
+type LidarrStatsResponse struct {
+	ArtistCount    int   `json:"artistCount"`
+	AlbumCount     int   `json:"albumCount"`
+	TrackCount     int   `json:"trackCount"`
+	TrackFileCount int   `json:"trackFileCount"`
+	Monitored      int   `json:"monitored"`
+	Unmonitored    int   `json:"unmonitored"`
+	MissingCount   int   `json:"missingCount"`
+	SizeOnDisk     int64 `json:"sizeOnDisk"`
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package types
+
+import "time"
+
+// MediaSessionsResponse represents the active sessions of a Jellyfin or Emby server
+type MediaSessionsResponse struct {
+	ServerType string         `json:"serverType"`
+	Size       int            `json:"size"`
+	Sessions   []MediaSession `json:"sessions"`
+}
+
+// MediaSession is a playback session normalized across media servers. Field
+// names follow PlexSession so streams from every server can be listed together.
+type MediaSession struct {
+	SessionKey       string   `json:"sessionKey"`
+	User             string   `json:"user"`
+	Client           string   `json:"client"`
+	Device           string   `json:"device"`
+	ClientVersion    string   `json:"clientVersion,omitempty"`
+	RemoteAddress    string   `json:"remoteAddress,omitempty"`
+	Type             string   `json:"type"`
+	Title            string   `json:"title"`
+	GrandparentTitle string   `json:"grandparentTitle,omitempty"`
+	ParentIndex      int      `json:"parentIndex,omitempty"`
+	Index            int      `json:"index,omitempty"`
+	State            string   `json:"state"`
+	ViewOffset       int64    `json:"viewOffset"`
+	Duration         int64    `json:"duration"`
+	PlayMethod       string   `json:"playMethod"`
+	Transcoding      bool     `json:"transcoding"`
+	TranscodeReasons []string `json:"transcodeReasons,omitempty"`
+	VideoCodec       string   `json:"videoCodec,omitempty"`
+	AudioCodec       string   `json:"audioCodec,omitempty"`
+	Bitrate          int      `json:"bitrate,omitempty"`
+}
+
+// MediaServerStats represents library and scheduled task status of a Jellyfin or Emby server
+type MediaServerStats struct {
+	ServerType   string               `json:"serverType"`
+	ServerName   string               `json:"serverName"`
+	Version      string               `json:"version"`
+	Libraries    MediaLibraryCounts   `json:"libraries"`
+	Tasks        []MediaScheduledTask `json:"tasks"`
+	RunningTasks int                  `json:"runningTasks"`
+	FailedTasks  int                  `json:"failedTasks"`
+}
+
+// MediaLibraryCounts represents the item counts reported by /Items/Counts
+type MediaLibraryCounts struct {
+	MovieCount      int `json:"movieCount"`
+	SeriesCount     int `json:"seriesCount"`
+	EpisodeCount    int `json:"episodeCount"`
+	ArtistCount     int `json:"artistCount"`
+	AlbumCount      int `json:"albumCount"`
+	SongCount       int `json:"songCount"`
+	MusicVideoCount int `json:"musicVideoCount"`
+	BoxSetCount     int `json:"boxSetCount"`
+	BookCount       int `json:"bookCount"`
+}
+
+// MediaScheduledTask represents a scheduled task and the result of its last run
+type MediaScheduledTask struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Category   string     `json:"category"`
+	State      string     `json:"state"`
+	Progress   float64    `json:"progress,omitempty"`
+	LastStatus string     `json:"lastStatus,omitempty"`
+	LastError  string     `json:"lastError,omitempty"`
+	LastRun    *time.Time `json:"lastRun,omitempty"`
+}
@@ -40,39 +40,45 @@ type MediaRequest struct {
 		Title             string   `json:"title,omitempty"`
 		ExternalServiceID int      `json:"externalServiceId,omitempty"`
 	} `json:"media"`
-	RequestedBy struct {
-		ID           int    `json:"id"`
-		Email        string `json:"email"`
-		Username     string `json:"username"`
-		PlexToken    string `json:"plexToken"`
-		PlexUsername string `json:"plexUsername"`
-		UserType     int    `json:"userType"`
-		Permissions  int    `json:"permissions"`
-		Avatar       string `json:"avatar"`
-		CreatedAt    string `json:"createdAt"`
-		UpdatedAt    string `json:"updatedAt"`
-		RequestCount int    `json:"requestCount"`
-	} `json:"requestedBy"`
-	ModifiedBy struct {
-		ID           int    `json:"id"`
-		Email        string `json:"email"`
-		Username     string `json:"username"`
-		PlexToken    string `json:"plexToken"`
-		PlexUsername string `json:"plexUsername"`
-		UserType     int    `json:"userType"`
-		Permissions  int    `json:"permissions"`
-		Avatar       string `json:"avatar"`
-		CreatedAt    string `json:"createdAt"`
-		UpdatedAt    string `json:"updatedAt"`
-		RequestCount int    `json:"requestCount"`
-	} `json:"modifiedBy"`
-	Is4k       bool   `json:"is4k"`
-	ServerID   int    `json:"serverId"`
-	ProfileID  int    `json:"profileId"`
-	RootFolder string `json:"rootFolder"`
+	RequestedBy RequestUser `json:"requestedBy"`
+	ModifiedBy  RequestUser `json:"modifiedBy"`
+	Is4k        bool        `json:"is4k"`
+	ServerID    int         `json:"serverId"`
+	ProfileID   int         `json:"profileId"`
+	RootFolder  string      `json:"rootFolder"`
+}
+
+// RequestUser is the user that created or modified a request. Overseerr users
+// carry a Plex username, Jellyseerr users a Jellyfin username.
+type RequestUser struct {
+	ID               int    `json:"id"`
+	Email            string `json:"email"`
+	Username         string `json:"username"`
+	DisplayName      string `json:"displayName,omitempty"`
+	PlexToken        string `json:"plexToken"`
+	PlexUsername     string `json:"plexUsername"`
+	JellyfinUsername string `json:"jellyfinUsername,omitempty"`
+	UserType         int    `json:"userType"`
+	Permissions      int    `json:"permissions"`
+	Avatar           string `json:"avatar"`
+	CreatedAt        string `json:"createdAt"`
+	UpdatedAt        string `json:"updatedAt"`
+	RequestCount     int    `json:"requestCount"`
 }
 
 type RequestsStats struct {
 	PendingCount int            `json:"pendingCount"`
 	Requests     []MediaRequest `json:"requests"`
 }
+
+// RequestCountResponse represents the request count response from the Overseerr and Jellyseerr API
+type RequestCountResponse struct {
+	Total      int `json:"total"`
+	Movie      int `json:"movie"`
+	TV         int `json:"tv"`
+	Pending    int `json:"pending"`
+	Approved   int `json:"approved"`
+	Declined   int `json:"declined"`
+	Processing int `json:"processing"`
+	Available  int `json:"available"`
+}
@@ -23,3 +23,19 @@ type ProwlarrStats struct {
 	Stats    ProwlarrStatsResponse `json:"stats"`
 	Indexers []ProwlarrIndexer     `json:"indexers"`
 }
+
+// ProwlarrIndexerStatsResponse represents the indexer stats response from Prowlarr API
+type ProwlarrIndexerStatsResponse struct {
+	Indexers []ProwlarrIndexerStats `json:"indexers"`
+}
+
+// ProwlarrIndexerStats represents the usage statistics of a single indexer
+type ProwlarrIndexerStats struct {
+	IndexerID             int    `json:"indexerId"`
+	IndexerName           string `json:"indexerName"`
+	AverageResponseTime   int    `json:"averageResponseTime"`
+	NumberOfQueries       int    `json:"numberOfQueries"`
+	NumberOfGrabs         int    `json:"numberOfGrabs"`
+	NumberOfFailedQueries int    `json:"numberOfFailedQueries"`
+	NumberOfFailedGrabs   int    `json:"numberOfFailedGrabs"`
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package types
+
+// ReadarrQueueResponse represents the queue response from Readarr API
+type ReadarrQueueResponse struct {
+	Page          int                  `json:"page"`
+	PageSize      int                  `json:"pageSize"`
+	SortKey       string               `json:"sortKey"`
+	SortDirection string               `json:"sortDirection"`
+	TotalRecords  int                  `json:"totalRecords"`
+	Records       []ReadarrQueueRecord `json:"records"`
+}
+
+// ReadarrQueueRecord represents a record in the Readarr queue
+type ReadarrQueueRecord struct {
+	ID                      int                    `json:"id"`
+	AuthorID                int                    `json:"authorId"`
+	BookID                  int                    `json:"bookId"`
+	Title                   string                 `json:"title"`
+	Status                  string                 `json:"status"`
+	TimeLeft                string                 `json:"timeleft,omitempty"`
+	EstimatedCompletionTime string                 `json:"estimatedCompletionTime"`
+	Indexer                 string                 `json:"indexer"`
+	DownloadClient          string                 `json:"downloadClient"`
+	Size                    int64                  `json:"size"`
+	SizeLeft                int64                  `json:"sizeleft"`
+	TrackedDownloadStatus   string                 `json:"trackedDownloadStatus"`
+	TrackedDownloadState    string                 `json:"trackedDownloadState"`
+	StatusMessages          []StatusMessage        `json:"statusMessages"`
+	ErrorMessage            string                 `json:"errorMessage"`
+	DownloadId              string                 `json:"downloadId"`
+	Protocol                string                 `json:"protocol"`
+	Author                  *ReadarrAuthorResponse `json:"author,omitempty"`
+	Book                    *ReadarrBookResponse   `json:"book,omitempty"`
+	AuthorName              string                 `json:"authorName,omitempty"`
+	BookTitle               string                 `json:"bookTitle,omitempty"`
+}
+
+// ReadarrQueueDeleteOptions represents the options for deleting a queue item in Readarr
+type ReadarrQueueDeleteOptions struct {
+	RemoveFromClient bool `json:"removeFromClient"`
+	Blocklist        bool `json:"blocklist"`
+	SkipRedownload   bool `json:"skipRedownload"`
+	ChangeCategory   bool `json:"changeCategory"`
+}
+
+// ReadarrAuthorResponse represents an author from Readarr's author endpoint
+type ReadarrAuthorResponse struct {
+	ID              int    `json:"id"`
+	AuthorName      string `json:"authorName"`
+	ForeignAuthorID string `json:"foreignAuthorId"`
+	Overview        string `json:"overview,omitempty"`
+	Status          string `json:"status"`
+	Path            string `json:"path,omitempty"`
+	Monitored       bool   `json:"monitored"`
+}
+
+// ReadarrBookResponse represents a book from Readarr's book endpoint
+type ReadarrBookResponse struct {
+	ID            int                    `json:"id"`
+	Title         string                 `json:"title"`
+	AuthorID      int                    `json:"authorId"`
+	AuthorTitle   string                 `json:"authorTitle,omitempty"`
+	ForeignBookID string                 `json:"foreignBookId"`
+	ReleaseDate   string                 `json:"releaseDate,omitempty"`
+	Overview      string                 `json:"overview,omitempty"`
+	Monitored     bool                   `json:"monitored"`
+	Author        *ReadarrAuthorResponse `json:"author,omitempty"`
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package types
+
+// TautulliHomeStats are the Plex watch statistics over the last Days days
+type TautulliHomeStats struct {
+	Days              int                    `json:"days"`
+	MostWatchedMovies []TautulliMediaStat    `json:"mostWatchedMovies"`
+	MostWatchedShows  []TautulliMediaStat    `json:"mostWatchedShows"`
+	MostWatchedMusic  []TautulliMediaStat    `json:"mostWatchedMusic"`
+	TopUsers          []TautulliUserStat     `json:"topUsers"`
+	TopPlatforms      []TautulliPlatformStat `json:"topPlatforms"`
+}
+
+// TautulliMediaStat is a movie, show or artist ranked by plays.
+// TotalDuration is in seconds.
+type TautulliMediaStat struct {
+	RatingKey     string `json:"ratingKey"`
+	Title         string `json:"title"`
+	Year          int    `json:"year,omitempty"`
+	Thumb         string `json:"thumb,omitempty"`
+	TotalPlays    int    `json:"totalPlays"`
+	TotalDuration int64  `json:"totalDuration"`
+	UsersWatched  int    `json:"usersWatched,omitempty"`
+}
+
+type TautulliUserStat struct {
+	User          PlexUser `json:"user"`
+	TotalPlays    int      `json:"totalPlays"`
+	TotalDuration int64    `json:"totalDuration"`
+}
+
+type TautulliPlatformStat struct {
+	Platform      string `json:"platform"`
+	TotalPlays    int    `json:"totalPlays"`
+	TotalDuration int64  `json:"totalDuration"`
+}
+
+// TautulliRecentlyAdded is an item recently added to a Plex library. The field
+// names follow PlexSession.
+type TautulliRecentlyAdded struct {
+	RatingKey           string `json:"ratingKey"`
+	Type                string `json:"type"`
+	Title               string `json:"title"`
+	ParentTitle         string `json:"parentTitle,omitempty"`
+	GrandparentTitle    string `json:"grandparentTitle,omitempty"`
+	Year                int    `json:"year,omitempty"`
+	Thumb               string `json:"thumb,omitempty"`
+	LibrarySectionTitle string `json:"librarySectionTitle"`
+	AddedAt             int64  `json:"addedAt"`
+}
+
+type TautulliHistoryResponse struct {
+	Total int                   `json:"total"`
+	Items []TautulliHistoryItem `json:"items"`
+}
+
+// TautulliHistoryItem is a finished or stopped play. The field names follow
+// PlexSession, StartedAt and StoppedAt are unix timestamps and Duration is
+// the watched time in seconds.
+type TautulliHistoryItem struct {
+	ID                int64       `json:"id"`
+	RatingKey         string      `json:"ratingKey"`
+	Type              string      `json:"type"`
+	Title             string      `json:"title"`
+	GrandparentTitle  string      `json:"grandparentTitle,omitempty"`
+	ParentIndex       int         `json:"parentIndex,omitempty"`
+	Index             int         `json:"index,omitempty"`
+	User              *PlexUser   `json:"User,omitempty"`
+	Player            *PlexPlayer `json:"Player,omitempty"`
+	StartedAt         int64       `json:"startedAt"`
+	StoppedAt         int64       `json:"stoppedAt"`
+	Duration          int64       `json:"duration"`
+	PercentComplete   int         `json:"percentComplete"`
+	Watched           bool        `json:"watched"`
+	TranscodeDecision string      `json:"transcodeDecision"`
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2024, s0up and the autobrr contributors.
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package types
+
+import "time"
+
+// UsenetQueue represents the download queue of a usenet client
+type UsenetQueue struct {
+	ClientType string            `json:"clientType"`
+	Paused     bool              `json:"paused"`
+	Speed      int64             `json:"speed"`    // Bytes per second
+	SizeLeft   int64             `json:"sizeLeft"` // Bytes
+	TimeLeft   int64             `json:"timeLeft"` // Seconds, 0 when idle or paused
+	Items      []UsenetQueueItem `json:"items"`
+}
+
+// UsenetQueueItem represents a download in the queue of a usenet client
+type UsenetQueueItem struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Category   string `json:"category"`
+	Status     string `json:"status"`
+	Size       int64  `json:"size"`     // Bytes
+	SizeLeft   int64  `json:"sizeLeft"` // Bytes
+	Percentage int    `json:"percentage"`
+	TimeLeft   int64  `json:"timeLeft"` // Seconds
+	Paused     bool   `json:"paused"`
+}
+
+// UsenetHistoryItem represents a failed download in the history of a usenet client
+type UsenetHistoryItem struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Category    string    `json:"category"`
+	Status      string    `json:"status"`
+	FailMessage string    `json:"failMessage,omitempty"`
+	Size        int64     `json:"size"` // Bytes
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// UsenetWarning represents a warning or error logged by a usenet client
+type UsenetWarning struct {
+	Type string    `json:"type"`
+	Text string    `json:"text"`
+	Time time.Time `json:"time"`
+}